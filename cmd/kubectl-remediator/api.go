@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// the status API and admin API of kube-remediator, through the service proxy of the api-server or at --url
+type apiClient struct {
+	client *http.Client
+	base   string // the paths of the API are appended to it
+	token  string // of the admin API
+}
+
+// the api-server removes the Authorization header before proxying, so the admin token goes in its own header
+const tokenHeader = "X-Remediator-Token"
+
+func newAPIClient(options *options) (*apiClient, error) {
+	if options.url != "" {
+		return &apiClient{
+			client: &http.Client{Timeout: options.timeout},
+			base:   strings.TrimSuffix(options.url, "/"),
+			token:  options.token,
+		}, nil
+	}
+	// like kubectl, $KUBECONFIG can list several files
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = options.kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules, &clientcmd.ConfigOverrides{CurrentContext: options.context},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err // untested section
+	}
+	return &apiClient{
+		client: &http.Client{Transport: transport, Timeout: options.timeout},
+		base: fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy",
+			strings.TrimSuffix(config.Host, "/"), options.namespace, options.service),
+		token: options.token,
+	}, nil
+}
+
+func (c *apiClient) get(path string, query url.Values, into interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, c.base+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err // untested section
+	}
+	return c.do(request, into)
+}
+
+func (c *apiClient) post(path string, into interface{}) error {
+	request, err := http.NewRequest(http.MethodPost, c.base+path, nil)
+	if err != nil {
+		return err // untested section
+	}
+	request.Header.Set(tokenHeader, c.token)
+	return c.do(request, into)
+}
+
+// errors of the API are plain text, like "unknown remediator"
+func (c *apiClient) do(request *http.Request, into interface{}) error {
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err // untested section
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, into)
+}
@@ -0,0 +1,267 @@
+// kubectl plugin for a running kube-remediator, installed by putting kubectl-remediator on the PATH:
+// kubectl remediator status, kubectl remediator actions -n payments, kubectl remediator pause crashloop
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/spf13/cobra"
+	"io"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type options struct {
+	kubeconfig string
+	context    string // of the kubeconfig, "" means its current context
+	namespace  string // of kube-remediator
+	service    string // name:port of kube-remediator in namespace
+	url        string // of kube-remediator, instead of the service proxy, like after kubectl port-forward
+	token      string // of the admin API
+	timeout    time.Duration
+	output     string // table or json
+}
+
+// what /api/v1/remediators serves, only the parts shown
+type remediatorsStatus struct {
+	Paused         bool                        `json:"paused"`
+	PausedClusters []string                    `json:"pausedClusters"`
+	Remediators    map[string]remediatorStatus `json:"remediators"`
+}
+
+type remediatorStatus struct {
+	Running   bool           `json:"running"`
+	Healthy   bool           `json:"healthy"`
+	Paused    bool           `json:"paused"`
+	DryRun    bool           `json:"dryRun"`
+	LastScan  time.Time      `json:"lastScan"`
+	Unhealthy int            `json:"unhealthy"`
+	Outcomes  map[string]int `json:"outcomes"`
+}
+
+// what the admin API answers to pause and resume
+type pausedState struct {
+	PausedAll bool     `json:"pausedAll"`
+	Paused    []string `json:"paused"`
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	options := &options{}
+	root := &cobra.Command{
+		Use:          "kubectl remediator",
+		Short:        "Shows what kube-remediator does and pauses, resumes or scans its remediators",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // errors are about kube-remediator, not how it was called
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&options.kubeconfig, "kubeconfig", "", "kubeconfig to use (default $KUBECONFIG or ~/.kube/config)")
+	flags.StringVar(&options.context, "context", "", "context of the kubeconfig to use (default its current context)")
+	flags.StringVar(&options.namespace, "remediator-namespace", "default", "namespace kube-remediator runs in")
+	flags.StringVar(&options.service, "service", "kube-remediator:8080", "name:port of the Service of kube-remediator, reached through the api-server")
+	flags.StringVar(&options.url, "url", "", "URL of kube-remediator to use instead of the Service, like http://localhost:8080 after kubectl port-forward")
+	flags.StringVar(&options.token, "token", os.Getenv("KUBE_REMEDIATOR_HTTP_ADMIN_TOKEN"), "token of the admin API, http.admin.token (default $KUBE_REMEDIATOR_HTTP_ADMIN_TOKEN)")
+	flags.DurationVar(&options.timeout, "timeout", 30*time.Second, "timeout of each request, 0 means none")
+
+	root.AddCommand(
+		newStatusCommand(options),
+		newActionsCommand(options),
+		newAdminCommand(options, "pause", "Stop a remediator from acting, also after a restart"),
+		newAdminCommand(options, "resume", "Let a paused remediator act again"),
+		newAdminCommand(options, "scan", "Make a remediator scan right away instead of waiting for its interval"),
+	)
+	return root
+}
+
+func newStatusCommand(options *options) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "status",
+		Short: "Show the kill switch and whether each remediator runs, is healthy, paused or dry running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAPIClient(options)
+			if err != nil {
+				return err
+			}
+			if options.output == "json" {
+				return printJSON(cmd.OutOrStdout(), client, "/api/v1/remediators", url.Values{})
+			}
+			var status remediatorsStatus
+			if err := client.get("/api/v1/remediators", url.Values{}, &status); err != nil {
+				return err
+			}
+			printStatus(cmd.OutOrStdout(), status, time.Now())
+			return nil
+		},
+	}
+	addOutputFlag(command, options)
+	return command
+}
+
+func newActionsCommand(options *options) *cobra.Command {
+	var namespace, remediator string
+	var limit int
+	command := &cobra.Command{
+		Use:   "actions",
+		Short: "Show the latest decisions of kube-remediator, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAPIClient(options)
+			if err != nil {
+				return err
+			}
+			query := url.Values{"limit": {strconv.Itoa(limit)}}
+			if namespace != "" {
+				query.Set("namespace", namespace)
+			}
+			if remediator != "" {
+				query.Set("remediator", remediator)
+			}
+			if options.output == "json" {
+				return printJSON(cmd.OutOrStdout(), client, "/api/v1/actions", query)
+			}
+			var actions struct {
+				Actions []audit.Record `json:"actions"`
+			}
+			if err := client.get("/api/v1/actions", query, &actions); err != nil {
+				return err
+			}
+			printActions(cmd.OutOrStdout(), actions.Actions, time.Now())
+			return nil
+		},
+	}
+	command.Flags().StringVarP(&namespace, "namespace", "n", "", "only actions on objects in this namespace (default all)")
+	command.Flags().StringVar(&remediator, "remediator", "", "only actions of this remediator, like OldPodDeleter (default all)")
+	command.Flags().IntVar(&limit, "limit", 20, "at most this many actions, 0 means all kube-remediator keeps")
+	addOutputFlag(command, options)
+	return command
+}
+
+// verb is the last part of POST /api/v1/remediators/<name>/<verb> of the admin API
+func newAdminCommand(options *options, verb string, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   verb + " <remediator>",
+		Short: short + ", the remediator in any case or the start of it, like crashloop",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newAPIClient(options)
+			if err != nil {
+				return err
+			}
+			path := "/api/v1/remediators/" + url.PathEscape(args[0]) + "/" + verb
+			if verb == "scan" {
+				var scanning struct {
+					Remediators []string `json:"remediators"`
+				}
+				if err := client.post(path, &scanning); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Scanning: %s\n", strings.Join(scanning.Remediators, ", "))
+				return nil
+			}
+			var state pausedState
+			if err := client.post(path, &state); err != nil {
+				return err
+			}
+			paused := strings.Join(state.Paused, ", ")
+			switch {
+			case state.PausedAll:
+				paused = "all"
+			case paused == "":
+				paused = "none"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Paused: %s\n", paused)
+			return nil
+		},
+	}
+}
+
+func addOutputFlag(command *cobra.Command, options *options) {
+	command.Flags().StringVarP(&options.output, "output", "o", "table", "table or json, json prints the response of the API as is")
+}
+
+func printJSON(out io.Writer, client *apiClient, path string, query url.Values) error {
+	var response json.RawMessage
+	if err := client.get(path, query, &response); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(out, string(response))
+	return err
+}
+
+func printStatus(out io.Writer, status remediatorsStatus, now time.Time) {
+	killSwitch := "released"
+	if status.Paused {
+		killSwitch = "engaged"
+		if len(status.PausedClusters) > 0 {
+			killSwitch += " in " + strings.Join(status.PausedClusters, ", ")
+		}
+	}
+	fmt.Fprintf(out, "Kill switch: %s\n\n", killSwitch)
+
+	names := make([]string, 0, len(status.Remediators))
+	for name := range status.Remediators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REMEDIATOR\tRUNNING\tHEALTHY\tPAUSED\tDRY-RUN\tLAST SCAN\tUNHEALTHY\tOUTCOMES")
+	for _, name := range names {
+		r := status.Remediators[name]
+		fmt.Fprintf(table, "%s\t%t\t%t\t%t\t%t\t%s\t%d\t%s\n",
+			name, r.Running, r.Healthy, r.Paused, r.DryRun, age(r.LastScan, now), r.Unhealthy, outcomes(r.Outcomes))
+	}
+	table.Flush()
+}
+
+func printActions(out io.Writer, actions []audit.Record, now time.Time) {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "AGE\tREMEDIATOR\tOBJECT\tREASON\tACTION\tOUTCOME\tDETAIL")
+	for _, action := range actions {
+		object := action.Object.Kind + "/" + action.Object.Name
+		if action.Object.Namespace != "" {
+			object = action.Object.Kind + "/" + action.Object.Namespace + "/" + action.Object.Name
+		}
+		remediator := action.Remediator
+		if action.Cluster != "" {
+			remediator = action.Cluster + "/" + remediator
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			age(action.Time, now), remediator, object, action.Reason, action.Action, action.Outcome, action.Detail)
+	}
+	table.Flush()
+}
+
+// like kubectl get shows it, - for never
+func age(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return duration.ShortHumanDuration(now.Sub(t))
+}
+
+// like dry-run=2,success=3, sorted
+func outcomes(counts map[string]int) string {
+	var all []string
+	for outcome, count := range counts {
+		all = append(all, fmt.Sprintf("%s=%d", outcome, count))
+	}
+	if len(all) == 0 {
+		return "-"
+	}
+	sort.Strings(all)
+	return strings.Join(all, ",")
+}
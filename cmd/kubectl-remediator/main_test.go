@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serves what kube-remediator would under prefix, like the service proxy of the api-server
+func fakeRemediator(t *testing.T, prefix string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		switch {
+		case r.Method == http.MethodGet && path == "/api/v1/remediators":
+			fmt.Fprint(w, `{"paused": false, "remediators": {
+				"OldPodDeleter": {"running": true, "healthy": true, "paused": true, "lastScan": "0001-01-01T00:00:00Z", "unhealthy": 1, "outcomes": {"success": 3, "dry-run": 1}}}}`)
+		case r.Method == http.MethodGet && path == "/api/v1/actions":
+			assert.Equal(t, r.URL.Query().Get("namespace"), "payments")
+			assert.Equal(t, r.URL.Query().Get("limit"), "20")
+			fmt.Fprint(w, `{"actions": [{"remediator": "CrashLoopBackOffRescheduler", "object": {"kind": "Pod", "namespace": "payments", "name": "api-x2x"},
+				"reason": "CrashLoopBackOff", "action": "deleted", "outcome": "success"}]}`)
+		case r.Method == http.MethodPost && r.Header.Get(tokenHeader) != "secret":
+			http.Error(w, "missing or wrong bearer token", http.StatusUnauthorized)
+		case r.Method == http.MethodPost && path == "/api/v1/remediators/crashloop/pause":
+			fmt.Fprint(w, `{"pausedAll": false, "paused": ["crashloopbackoffrescheduler"]}`)
+		case r.Method == http.MethodPost && path == "/api/v1/remediators/crashloop/resume":
+			fmt.Fprint(w, `{"pausedAll": false, "paused": []}`)
+		case r.Method == http.MethodPost && path == "/api/v1/remediators/old/scan":
+			fmt.Fprint(w, `{"remediators": ["OldPodDeleter"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func execute(args ...string) (string, error) {
+	root := newRootCommand()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestShowsStatus(t *testing.T) {
+	server := fakeRemediator(t, "")
+	defer server.Close()
+	out, err := execute("status", "--url", server.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "Kill switch: released\n\n"+
+		"REMEDIATOR     RUNNING  HEALTHY  PAUSED  DRY-RUN  LAST SCAN  UNHEALTHY  OUTCOMES\n"+
+		"OldPodDeleter  true     true     true    false    -          1          dry-run=1,success=3\n")
+
+	out, err = execute("status", "--url", server.URL, "-o", "json")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out, `{"paused": false`), out)
+}
+
+func TestShowsActionsOfNamespace(t *testing.T) {
+	server := fakeRemediator(t, "")
+	defer server.Close()
+	out, err := execute("actions", "--url", server.URL, "-n", "payments")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "AGE  REMEDIATOR                   OBJECT                REASON            ACTION   OUTCOME  DETAIL\n"+
+		"-    CrashLoopBackOffRescheduler  Pod/payments/api-x2x  CrashLoopBackOff  deleted  success  \n")
+}
+
+func TestPausesResumesAndScans(t *testing.T) {
+	server := fakeRemediator(t, "")
+	defer server.Close()
+	out, err := execute("pause", "crashloop", "--url", server.URL, "--token", "secret")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "Paused: crashloopbackoffrescheduler\n")
+
+	out, err = execute("resume", "crashloop", "--url", server.URL, "--token", "secret")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "Paused: none\n")
+
+	out, err = execute("scan", "old", "--url", server.URL, "--token", "secret")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "Scanning: OldPodDeleter\n")
+
+	_, err = execute("pause", "crashloop", "--url", server.URL, "--token", "wrong")
+	assert.ErrorContains(t, err, "401 Unauthorized: missing or wrong bearer token")
+	_, err = execute("pause", "nope", "--url", server.URL, "--token", "secret")
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
+func TestGoesThroughTheServiceProxy(t *testing.T) {
+	server := fakeRemediator(t, "/api/v1/namespaces/kube-system/services/kube-remediator:8080/proxy")
+	defer server.Close()
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	assert.NilError(t, err)
+	defer os.Remove(kubeconfig.Name())
+	_, err = fmt.Fprintf(kubeconfig, `apiVersion: v1
+kind: Config
+clusters: [{name: test, cluster: {server: %q}}]
+users: [{name: test, user: {token: abc}}]
+contexts: [{name: test, context: {cluster: test, user: test}}]
+current-context: test
+`, server.URL)
+	assert.NilError(t, err)
+	assert.NilError(t, kubeconfig.Close())
+
+	out, err := execute("status", "--kubeconfig", kubeconfig.Name(), "--remediator-namespace", "kube-system", "--timeout", time.Second.String())
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out, "OldPodDeleter  true"), out)
+}
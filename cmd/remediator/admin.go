@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// changes for runbooks and chatops bots, every one of them is logged so it is clear who paused what
+//   - POST /api/v1/remediators/<name>/pause: stops it from acting until it is resumed, also after a restart
+//   - POST /api/v1/remediators/<name>/resume
+//   - POST /api/v1/remediators/<name>/scan: scans right away instead of waiting for its interval
+//
+// name is the remediator in any case or the start of it, like crashloop for CrashLoopBackOffRescheduler
+type adminAPI struct {
+	logger   *zap.Logger
+	controls *remediator.Controls
+	pauses   *remediator.PauseStore
+	token    string // required, callers send "Authorization: Bearer <token>" or tokenHeader
+}
+
+const adminPrefix = "/api/v1/remediators/"
+
+// for calls through the service proxy of the api-server, which removes the Authorization header, like kubectl remediator
+const tokenHeader = "X-Remediator-Token"
+
+// whether r has the bearer token, otherwise it was answered with 401
+func authorized(w http.ResponseWriter, r *http.Request, token string) bool {
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if given == "" {
+		given = r.Header.Get(tokenHeader)
+	}
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "missing or wrong bearer token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// what is paused after the change
+type pausedState struct {
+	PausedAll bool     `json:"pausedAll"`
+	Paused    []string `json:"paused"` // on their own, sorted, lowercase
+}
+
+func (a *adminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, adminPrefix), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	name, ok := remediator.Lookup(parts[0])
+	if !ok {
+		message := fmt.Sprintf("unknown remediator %q, use one of %s", parts[0], strings.Join(remediator.Names(), ", "))
+		http.Error(w, message, http.StatusNotFound)
+		return
+	}
+	switch parts[1] {
+	case "pause":
+		a.logger.Info("Pausing", zap.String("remediator", name), zap.String("remote", r.RemoteAddr))
+		a.controls.Pause(name)
+		a.savePauses(w, r)
+	case "resume":
+		a.logger.Info("Resuming", zap.String("remediator", name), zap.String("remote", r.RemoteAddr))
+		a.controls.Resume(name)
+		a.savePauses(w, r)
+	case "scan":
+		started := a.controls.Scan(name)
+		a.logger.Info("Requesting scan", zap.String("remediator", name), zap.String("remote", r.RemoteAddr))
+		if len(started) == 0 {
+			http.Error(w, name+" is not running", http.StatusConflict)
+			return
+		}
+		writeJSON(w, struct {
+			Remediators []string `json:"remediators"` // that started a scan, "cluster/remediator" with several clusters
+		}{started})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// the change applies right away, failing to keep it only means it is lost when restarting
+func (a *adminAPI) savePauses(w http.ResponseWriter, r *http.Request) {
+	if err := a.pauses.Save(r.Context(), a.controls); err != nil {
+		a.logger.Error("Error keeping paused remediators", zap.Error(err))
+		http.Error(w, "changed until the next restart, keeping it failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var state pausedState
+	state.PausedAll, state.Paused = a.controls.PausedRemediators()
+	if state.Paused == nil {
+		state.Paused = []string{}
+	}
+	writeJSON(w, state)
+}
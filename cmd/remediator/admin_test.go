@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newAdminAPI(client *fake.Client) *adminAPI {
+	return &adminAPI{
+		logger:   zap.NewNop(),
+		controls: remediator.NewControls(),
+		pauses:   remediator.NewPauseStore(client, "default", "kube-remediator-paused"),
+		token:    "secret",
+	}
+}
+
+func postAdmin(api *adminAPI, url string, token string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, url, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	api.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestPausesAndResumesThroughAdmin(t *testing.T) {
+	client := fake.NewClient()
+	api := newAdminAPI(client)
+
+	recorder := postAdmin(api, "/api/v1/remediators/crashloop/pause", "secret")
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	var state pausedState
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.DeepEqual(t, state, pausedState{Paused: []string{"crashloopbackoffrescheduler"}})
+	assert.Equal(t, api.controls.Paused("CrashLoopBackOffRescheduler"), true)
+
+	configMap, err := client.GetConfigMap(context.Background(), "default", "kube-remediator-paused")
+	assert.NilError(t, err)
+	assert.Equal(t, configMap.Data["remediators"], "crashloopbackoffrescheduler")
+
+	recorder = postAdmin(api, "/api/v1/remediators/CrashLoopBackOffRescheduler/resume", "secret")
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	assert.Equal(t, api.controls.Paused("CrashLoopBackOffRescheduler"), false)
+	configMap, err = client.GetConfigMap(context.Background(), "default", "kube-remediator-paused")
+	assert.NilError(t, err)
+	assert.Equal(t, configMap.Data["remediators"], "")
+}
+
+func TestRejectsAdminRequests(t *testing.T) {
+	api := newAdminAPI(fake.NewClient())
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/crashloop/pause", "wrong").Code, http.StatusUnauthorized)
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/crashloop/pause", "").Code, http.StatusUnauthorized)
+	assert.Equal(t, api.controls.Paused("CrashLoopBackOffRescheduler"), false)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/remediators/crashloop/pause", nil)
+	request.Header.Set("X-Remediator-Token", "secret") // through the service proxy of the api-server
+	api.ServeHTTP(recorder, request)
+	assert.Equal(t, recorder.Code, http.StatusOK)
+
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/nope/pause", "secret").Code, http.StatusNotFound)
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/crashloop/restart", "secret").Code, http.StatusNotFound)
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/crashloop", "secret").Code, http.StatusNotFound)
+	assert.Equal(t, postAdmin(api, "/api/v1/remediators/oldpoddeleter/scan", "secret").Code, http.StatusConflict) // not running
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/remediators/crashloop/pause", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	api.ServeHTTP(recorder, request)
+	assert.Equal(t, recorder.Code, http.StatusMethodNotAllowed)
+}
+
+func TestReportsPausesThatWereNotKept(t *testing.T) {
+	client := fake.NewClient()
+	client.Fail("SetConfigMapData", errors.New("forbidden"))
+	api := newAdminAPI(client)
+	recorder := postAdmin(api, "/api/v1/remediators/OldPodDeleter/pause", "secret")
+	assert.Equal(t, recorder.Code, http.StatusInternalServerError)
+	assert.Equal(t, api.controls.Paused("OldPodDeleter"), true) // until the next restart
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// receives the webhooks of Alertmanager on POST /api/v1/alerts and hands the alerts to every AlertmanagerRemediator,
+// answers 503 when no remediator took them, like on a follower of a leader election, so Alertmanager retries
+type alertReceiver struct {
+	logger *zap.Logger
+	alerts *remediator.Alerts
+	token  string // required, Alertmanager sends "Authorization: Bearer <token>"
+}
+
+const alertsPath = "/api/v1/alerts"
+
+// what Alertmanager POSTs, https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type alertmanagerWebhook struct {
+	Version  string             `json:"version"`
+	Status   string             `json:"status"`
+	Receiver string             `json:"receiver"`
+	Alerts   []remediator.Alert `json:"alerts"`
+}
+
+func (a *alertReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, a.token) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var webhook alertmanagerWebhook
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&webhook); err != nil {
+		http.Error(w, "invalid webhook: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	took := a.alerts.Deliver(webhook.Alerts)
+	a.logger.Debug("Received alerts", zap.String("receiver", webhook.Receiver), zap.Int("alerts", len(webhook.Alerts)), zap.Int("remediators", took))
+	if took == 0 {
+		http.Error(w, "no remediator took the alerts, not the leader or none is running", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, struct {
+		Remediators int `json:"remediators"` // that took the alerts, one per cluster
+	}{took})
+}
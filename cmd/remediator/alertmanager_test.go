@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postAlerts(receiver *alertReceiver, method string, body string, token string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, alertsPath, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	receiver.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRejectsAlertsWithoutRemediators(t *testing.T) {
+	receiver := &alertReceiver{logger: zap.NewNop(), alerts: remediator.NewAlerts(), token: "secret"}
+	webhook := `{"version": "4", "status": "firing", "receiver": "kube-remediator", "alerts": [{"status": "firing", "labels": {"alertname": "KubePodCrashLooping"}}]}`
+
+	assert.Equal(t, postAlerts(receiver, http.MethodPost, webhook, "wrong").Code, http.StatusUnauthorized)
+	assert.Equal(t, postAlerts(receiver, http.MethodGet, "", "secret").Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, postAlerts(receiver, http.MethodPost, "{", "secret").Code, http.StatusBadRequest)
+	// none is running, Alertmanager retries
+	assert.Equal(t, postAlerts(receiver, http.MethodPost, webhook, "secret").Code, http.StatusServiceUnavailable)
+}
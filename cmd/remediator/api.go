@@ -0,0 +1,97 @@
+package main
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// read-only status for dashboards and chatops bots
+//   - /api/v1/actions: the latest decisions, newest first, ?remediator=OldPodDeleter&namespace=payments&limit=10
+//   - /api/v1/remediators: whether the kill switch pauses everything and what each remediator did since the start,
+//     named "cluster/remediator" when there are several clusters
+type statusAPI struct {
+	state    *debugState
+	history  *audit.History
+	controls *remediator.Controls // nil without the admin API and the gRPC service
+}
+
+type remediatorStatus struct {
+	Running                     bool `json:"running"` // false when disabled by a reload
+	Healthy                     bool `json:"healthy"` // false when its scan loop is stuck or it is not running
+	Paused                      bool `json:"paused"`  // through the admin API or the gRPC service
+	*remediator.RemediatorState      // nil when not running
+	audit.Stats
+}
+
+func (a *statusAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/v1/actions":
+		a.actions(w, r)
+	case "/api/v1/remediators":
+		a.remediators(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *statusAPI) actions(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+	recent := a.history.Recent(r.URL.Query().Get("remediator"), limit)
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		recent = []audit.Record{}
+		for _, record := range a.history.Recent(r.URL.Query().Get("remediator"), 0) {
+			if record.Object.Namespace == namespace && (limit == 0 || len(recent) < limit) {
+				recent = append(recent, record)
+			}
+		}
+	}
+	writeJSON(w, struct {
+		Actions []audit.Record `json:"actions"`
+	}{recent})
+}
+
+func (a *statusAPI) remediators(w http.ResponseWriter) {
+	status := struct {
+		Paused         bool                        `json:"paused"`                   // kill switch engaged, in any cluster when there are several
+		PausedClusters []string                    `json:"pausedClusters,omitempty"` // with several clusters
+		Remediators    map[string]remediatorStatus `json:"remediators"`
+	}{Remediators: map[string]remediatorStatus{}}
+	for name, stats := range a.history.Stats() {
+		status.Remediators[name] = remediatorStatus{Stats: stats}
+	}
+	for cluster, running := range a.state.running() {
+		if running.shared.killSwitch.Engaged() {
+			status.Paused = true
+			if cluster != "" {
+				status.PausedClusters = append(status.PausedClusters, cluster)
+			}
+		}
+		for name, r := range running.remediators {
+			paused := a.controls.Paused(name)
+			name = clusterName(cluster, name)
+			state := r.State()
+			stats, ok := status.Remediators[name]
+			if !ok {
+				stats.Outcomes = map[string]int{}
+			}
+			stats.Running, stats.Healthy, stats.Paused, stats.RemediatorState = true, r.Healthy(), paused, &state
+			status.Remediators[name] = stats
+		}
+	}
+	sort.Strings(status.PausedClusters)
+	writeJSON(w, status)
+}
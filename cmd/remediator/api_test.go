@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveAPI(t *testing.T, api *statusAPI, method string, url string, into interface{}) int {
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, httptest.NewRequest(method, url, nil))
+	if recorder.Code == http.StatusOK {
+		assert.Equal(t, recorder.Header().Get("Content-Type"), "application/json")
+		assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), into))
+	}
+	return recorder.Code
+}
+
+func TestServesRecentActions(t *testing.T) {
+	history := audit.NewHistory(10)
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Name: "a"}, Outcome: "success"})
+	history.Record(audit.Record{Remediator: "CrashLoopBackOffRescheduler", Object: audit.ObjectRef{Kind: "Pod", Namespace: "payments", Name: "b"}, Outcome: "error"})
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Name: "c"}, Outcome: "skipped"})
+	api := &statusAPI{state: &debugState{shared: &shared{}}, history: history}
+
+	var response struct {
+		Actions []audit.Record `json:"actions"`
+	}
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/actions", &response), http.StatusOK)
+	assert.Equal(t, len(response.Actions), 3)
+	assert.Equal(t, response.Actions[0].Object.Name, "c")
+
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/actions?remediator=OldPodDeleter&limit=1", &response), http.StatusOK)
+	assert.Equal(t, len(response.Actions), 1)
+	assert.Equal(t, response.Actions[0].Object.Name, "c")
+
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/actions?namespace=payments&limit=1", &response), http.StatusOK)
+	assert.Equal(t, len(response.Actions), 1)
+	assert.Equal(t, response.Actions[0].Object.Name, "b")
+
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/actions?limit=many", nil), http.StatusBadRequest)
+	assert.Equal(t, serveAPI(t, api, "POST", "/api/v1/actions", nil), http.StatusMethodNotAllowed)
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/foo", nil), http.StatusNotFound)
+}
+
+func TestServesRemediatorStatus(t *testing.T) {
+	history := audit.NewHistory(10)
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "success"})
+	history.Record(audit.Record{Remediator: "FailedPodRescheduler", Outcome: "dry-run"})
+	state := &debugState{shared: &shared{}}
+	state.use(&shared{}, &remediator.Policy{}, map[string]remediator.Remediator{
+		"OldPodDeleter":               &remediator.OldPodDeleter{},
+		"CrashLoopBackOffRescheduler": &remediator.OldPodDeleter{},
+	})
+	controls := remediator.NewControls()
+	controls.Pause("OldPodDeleter")
+	api := &statusAPI{state: state, history: history, controls: controls}
+
+	var response map[string]interface{}
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/remediators", &response), http.StatusOK)
+	assert.Equal(t, response["paused"], false)
+	remediators := response["remediators"].(map[string]interface{})
+	assert.Equal(t, len(remediators), 3)
+
+	old := remediators["OldPodDeleter"].(map[string]interface{})
+	assert.Equal(t, old["running"], true)
+	assert.Equal(t, old["healthy"], true)
+	assert.Equal(t, old["paused"], true)
+	assert.Equal(t, old["inMaintenanceWindow"], true)
+	assert.DeepEqual(t, old["outcomes"], map[string]interface{}{"success": float64(1)})
+	assert.DeepEqual(t, remediators["CrashLoopBackOffRescheduler"].(map[string]interface{})["outcomes"], map[string]interface{}{})
+
+	stopped := remediators["FailedPodRescheduler"].(map[string]interface{})
+	assert.Equal(t, stopped["running"], false)
+	assert.Equal(t, stopped["healthy"], false)
+	assert.Equal(t, stopped["lastScan"], nil)
+	assert.DeepEqual(t, stopped["outcomes"], map[string]interface{}{"dry-run": float64(1)})
+}
+
+func TestServesRemediatorStatusOfEachCluster(t *testing.T) {
+	history := audit.NewHistory(10)
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Cluster: "prod", Outcome: "success"})
+	state := &debugState{shared: &shared{}}
+	state.use(&shared{cluster: "prod"}, &remediator.Policy{}, map[string]remediator.Remediator{"OldPodDeleter": &remediator.OldPodDeleter{}})
+	state.use(&shared{cluster: "staging"}, &remediator.Policy{}, map[string]remediator.Remediator{"OldPodDeleter": &remediator.OldPodDeleter{}})
+	api := &statusAPI{state: state, history: history}
+
+	var response map[string]interface{}
+	assert.Equal(t, serveAPI(t, api, "GET", "/api/v1/remediators", &response), http.StatusOK)
+	assert.Equal(t, response["paused"], false)
+	remediators := response["remediators"].(map[string]interface{})
+	assert.Equal(t, len(remediators), 2)
+	assert.DeepEqual(t, remediators["prod/OldPodDeleter"].(map[string]interface{})["outcomes"], map[string]interface{}{"success": float64(1)})
+	assert.Equal(t, remediators["staging/OldPodDeleter"].(map[string]interface{})["running"], true)
+}
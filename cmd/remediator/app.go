@@ -2,22 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"github.com/aksgithub/kube_remediator/pkg/events"
+	"github.com/aksgithub/kube_remediator/pkg/healthz"
 	"github.com/aksgithub/kube_remediator/pkg/http"
 	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
 	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"os"
 	"os/signal"
 	"reflect"
-	"strings"
+	"sort"
 	"sync"
 	"syscall"
+	"time"
 )
 
 // catch interrupts to gracefully exit since otherwise goroutines get killed without running defer
 // TODO: is there no better way of doing this ?
-func signalHandler(cancelFn func(), wg *sync.WaitGroup, logger *zap.Logger) {
+// also returns when ctx is done, like after --once scanned
+func signalHandler(ctx context.Context, cancelFn func(), wg *sync.WaitGroup, logger *zap.Logger) {
 	defer cancelFn()
 	defer wg.Done()
 	c := make(chan os.Signal, 1)
@@ -28,62 +41,703 @@ func signalHandler(cancelFn func(), wg *sync.WaitGroup, logger *zap.Logger) {
 		syscall.SIGABRT,
 		syscall.SIGILL,
 		syscall.SIGFPE)
-	signal := <-c
-	logger.Sugar().Warnf("Signal %v Received, Shutting Down", signal) // TODO: prefer structured logging
+	defer signal.Stop(c)
+	select {
+	case received := <-c:
+		logger.Sugar().Warnf("Signal %v Received, Shutting Down", received) // TODO: prefer structured logging
+	case <-ctx.Done():
+	}
+}
+
+// parts that keep running when the config is reloaded, changing their settings requires a restart,
+// every cluster has a copy with its own clients, caches and kill switch, see startCluster
+type shared struct {
+	cluster       string // "" when there is only the one cluster
+	clientOptions k8s.ClientOptions
+	pageSize      int64 // of Pod lists
+	skipped       *metrics.Skipped_Metrics
+	metrics       *metrics.Remediation_Metrics
+	audit         *audit.Log
+	history       *audit.History
+	notifiers     notify.Notifiers
+	queues        map[string]*notify.Queue // notifiers by name
+	tracer        *tracing.Tracer
+	health        *healthz.Health
+	rateLimiter   *remediator.RateLimiter // shared by all clusters
+	drain         *remediator.Drain       // shared by all clusters
+	killSwitch    *remediator.KillSwitch
+	watchdog      *remediator.Watchdog       // nil when disabled and with --once
+	controls      *remediator.Controls       // shared by all clusters, nil without the gRPC service and the admin API
+	alerts        *remediator.Alerts         // shared by all clusters, nil without the Alertmanager receiver
+	pauses        *remediator.PauseStore     // keeps what controls pause, nil without the admin API
+	leader        *remediator.LeaderElection // nil when every replica remediates
+	leaderMetrics *metrics.Leader_Metrics
+	nodes         *k8s.NodeCache
+	pods          *k8s.PodCache       // watches the namespaces remediators ask for
+	namespaces    *k8s.NamespaceCache // started when first needed
+	stream        *events.Stream
+	enabledSince  map[string]time.Time // when each remediator of the cluster was first enabled, nil before they started
+	once          bool                 // --once, remediators scan one time and nothing watches
+	errors        *loggedErrors        // counted for --once, nil otherwise
 }
 
-func main() {
+// fileSettings is the config as read from configFile, options are applied on top of it,
+// returns an error only with --once, when a remediator failed
+func run(options *options, configFile string, fileSettings *config.Config) error {
 	ctx, cancel := context.WithCancel(context.Background())
+	// notifiers and the tracer stop after the remediators, so what they did while draining still gets out
+	deliver, stopDelivering := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
 
-	// build a logger:
-	// - without timestamps because docker already logs with timestamps
-	// - use "message" instead of "msg" for consistency with other services / datadog parsing
-	// - remove caller since it points to shared methods most of the time anyway
-	loggerConfig := zap.NewProductionConfig()
-	loggerConfig.EncoderConfig.TimeKey = ""
-	loggerConfig.EncoderConfig.MessageKey = "message"
-	loggerConfig.DisableCaller = true
+	// the level is shared by all loggers built from it, so changing it changes all of them
+	loggerConfig := options.apply(fileSettings).Log.ZapConfig()
+
+	var errorCount *loggedErrors
+	if options.once {
+		errorCount = &loggedErrors{}
+	}
 
 	// general logger
-	logger, err := loggerConfig.Build()
+	logger, err := loggerConfig.Build(zap.Hooks(errorCount.hook))
 	runtime.Must(err)
 
 	wg.Add(1)
-	go signalHandler(cancel, &wg, logger)
+	go signalHandler(ctx, cancel, &wg, logger)
 
-	remediators := []remediator.BaseIntf{
-		&remediator.OldPodDeleter{},
-		&remediator.CrashLoopBackOffRescheduler{},
-		&remediator.FailedPodRescheduler{},
-		&remediator.CompletedPodDeleter{},
+	startSettings := options.apply(fileSettings)
+	clusters, err := startSettings.ListClusters()
+	runtime.Must(err)
+	// none configured means the own cluster or the one of --kubeconfig and --context, without a cluster label
+	if len(clusters) == 0 {
+		clusters = []config.ClusterConfig{{}}
+	}
+	shared := startShared(ctx, deliver, &wg, logger, startSettings, options.clientOptions(startSettings))
+	shared.once = options.once
+	shared.errors = errorCount
+	// what was done is summed up before exiting, see onceResult
+	if shared.once && shared.history == nil {
+		shared.history = audit.NewHistory(0)
 	}
 
-	for _, r := range remediators {
-		// remediator.OldPodDeleter -> OldPodDeleter
-		name := strings.Split(reflect.TypeOf(r).String(), ".")[1]
+	reload := make(chan *config.Config)
+	wg.Add(1)
+	go config.Watch(ctx, &wg, logger.With(zap.String("component", "config")), configFile, fileSettings, reload)
 
-		// make each logged line show what remediator it came from
-		loggerConfig.InitialFields = map[string]interface{}{"remediator": name}
+	server := http.NewServer(logger.With(zap.String("component", "http")), fileSettings.HTTP.Port, shared.health)
+	server.Handle("/log/level", loggerConfig.Level) // GET shows it, PUT {"level":"debug"} changes it
+	if fileSettings.Metrics.Prometheus {
+		server.Handle("/metrics", metrics.Handler())
+	}
+	debug := &debugState{shared: shared}
+	if fileSettings.HTTP.Debug {
+		server.HandlePprof()
+		server.Handle("/debug/state", debug)
+	}
+	if fileSettings.HTTP.API.Enabled {
+		server.Handle("/api/v1/", &statusAPI{state: debug, history: shared.history, controls: shared.controls})
+	}
+	if fileSettings.HTTP.Admin.Enabled {
+		server.Handle(adminPrefix, &adminAPI{
+			logger:   logger.With(zap.String("component", "admin")),
+			controls: shared.controls,
+			pauses:   shared.pauses,
+			token:    startSettings.HTTP.Admin.Token,
+		})
+	}
+	if fileSettings.HTTP.Alertmanager.Enabled {
+		server.Handle(alertsPath, &alertReceiver{
+			logger: logger.With(zap.String("component", "alertmanager")),
+			alerts: shared.alerts,
+			token:  startSettings.HTTP.Alertmanager.Token,
+		})
+	}
+	wg.Add(1)
+	go server.Serve(ctx, &wg)
+	if fileSettings.GRPC.Enabled {
+		controlServer := &controlServer{
+			logger:   logger.With(zap.String("component", "grpc")),
+			controls: shared.controls,
+			pauses:   shared.pauses,
+			history:  shared.history,
+			token:    startSettings.GRPC.Token,
+		}
+		wg.Add(1)
+		go controlServer.Serve(ctx, &wg, fileSettings.GRPC.Port)
+	}
+
+	// every cluster restarts its remediators on its own, each of them gets every reload
+	started := time.Now()
+	var reloads []chan *config.Config
+	// with --once everything stops when the remediators of every cluster scanned
+	var remediating sync.WaitGroup
+	for _, cluster := range clusters {
+		// not ready until the remediators of every cluster run
+		shared.health.ForCluster(cluster.Name).AddReadyCheck("startup", func() error { return errors.New("remediators not started") })
+		clusterReload := make(chan *config.Config, 1)
+		reloads = append(reloads, clusterReload)
+		wg.Add(1)
+		remediating.Add(1)
+		go func(cluster config.ClusterConfig) {
+			defer remediating.Done()
+			runCluster(ctx, &wg, logger, loggerConfig, options, fileSettings, clusterReload, shared, cluster, debug, started)
+		}(cluster)
+	}
+	if shared.once {
+		go func() {
+			remediating.Wait()
+			cancel()
+		}()
+	}
+
+	for ctx.Err() == nil {
+		select {
+		case next := <-reload:
+			if level := options.apply(next).Log; level.Level != options.apply(fileSettings).Log.Level {
+				logger.Info("Changing log level", zap.String("level", level.Level))
+				loggerConfig.Level.SetLevel(level.ZapLevel())
+			}
+			fileSettings = next
+			for _, clusterReload := range reloads {
+				// a cluster still restarting only needs the latest config
+				select {
+				case <-clusterReload:
+				default:
+				}
+				clusterReload <- next
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	// detection stopped with ctx, remediations that started get until the drain timeout, and then so do the
+	// notifications about them
+	shared.drain.Start()
+	drained := shared.drain.Wait(&remediating)
+	stopDelivering()
+	if !drained || !shared.drain.Wait(&wg) {
+		logger.Warn("Exiting before everything was drained", zap.Duration("drainTimeout", startSettings.Shutdown.DrainTimeout))
+	}
+	shared.audit.Close()
+	metrics.StatsD.Close()
+	if err := metrics.Push(options.apply(fileSettings).Metrics.Push, os.Stdout); err != nil {
+		logger.Error("Error pushing metrics", zap.Error(err))
+	}
+	if shared.once {
+		return shared.onceResult(logger)
+	}
+	return nil
+}
+
+// remediators of the cluster are restarted with a new policy whenever the config or a RemediationPolicy changes
+func runCluster(ctx context.Context, wg *sync.WaitGroup, logger *zap.Logger, loggerConfig zap.Config, options *options, fileSettings *config.Config, reload <-chan *config.Config, base *shared, cluster config.ClusterConfig, debug *debugState, started time.Time) {
+	defer wg.Done()
+	if cluster.Name != "" {
+		logger = logger.With(zap.String("cluster", cluster.Name))
+	}
+	shared := base.startCluster(ctx, wg, logger, options.apply(fileSettings), cluster)
+
+	policies := shared.startPolicies(ctx, logger, fileSettings)
+	effective := func() *config.Config {
+		return policies.Apply(options.apply(fileSettings))
+	}
+
+	settings := effective()
+	var previous *config.Config
+	var policy *remediator.Policy
+	for ctx.Err() == nil {
+		policy = newPolicy(ctx, logger, settings, previous, policy, shared)
+
+		remediatorsCtx, stopRemediators := context.WithCancel(ctx)
+		var remediatorsWg sync.WaitGroup
+		running, err := runRemediators(remediatorsCtx, &remediatorsWg, loggerConfig, settings, policy, shared, started)
+		if err != nil {
+			// none runs rather than some, until the config changes again
+			logger.Error("Error starting remediators, waiting for a new config", zap.Error(err))
+			stopRemediators()
+			remediatorsWg.Wait()
+			running = map[string]remediator.Remediator{}
+		}
+		logger.Info("Remediators running", zap.Strings("remediators", runningNames(running)))
+		debug.use(shared, policy, running)
+		shared.watchdog.Watch(running)
+		shared.health.Remove("startup")
+		if shared.once {
+			remediatorsWg.Wait()
+			stopRemediators()
+			return
+		}
+
+		// status updates of policies and overridden settings do not change anything
+		next := settings
+		for ctx.Err() == nil && reflect.DeepEqual(next, settings) {
+			select {
+			case fileSettings = <-reload:
+			case <-policies.Changed():
+			case <-ctx.Done():
+			}
+			next = effective()
+		}
+		if ctx.Err() == nil {
+			logger.Info("Restarting remediators with new config")
+		}
+		previous, settings = settings, next
+		stopRemediators()
+		remediatorsWg.Wait()
+	}
+}
+
+// nil when disabled, it then never changes and applies nothing
+func (s *shared) startPolicies(ctx context.Context, logger *zap.Logger, settings *config.Config) *config.PolicyWatcher {
+	policiesConfig := settings.RemediationPolicies
+	if !policiesConfig.Enabled {
+		return nil
+	}
+	policiesLogger := logger.With(zap.String("component", "remediationPolicies"))
+	k8sClient, err := k8s.NewClient(policiesLogger, s.clientOptionsFor("remediationPolicies"))
+	runtime.Must(err)
+	policies, err := config.NewPolicyWatcher(policiesLogger, k8sClient, policiesConfig.AdminNamespace)
+	runtime.Must(err)
+	policiesLogger.Info("Waiting for RemediationPolicy cache")
+	policies.Start(ctx.Done())
+	return policies
+}
+
+// the parts of every cluster, see startCluster for those of each cluster,
+// deliver is done after the remediators drained, notifiers and the tracer run until then
+func startShared(ctx context.Context, deliver context.Context, wg *sync.WaitGroup, logger *zap.Logger, settings *config.Config, clientOptions k8s.ClientOptions) *shared {
+	shared := &shared{clientOptions: clientOptions, pageSize: settings.Client.PageSize, queues: map[string]*notify.Queue{}}
+	shared.drain = remediator.NewDrain(settings.Shutdown.DrainTimeout)
+
+	// every cluster adds its checks, see startCluster
+	shared.health = healthz.NewHealth()
 
-		logger, err := loggerConfig.Build()
+	shared.skipped = metrics.NewSkippedMetrics(logger)
+	shared.skipped.Register()
+
+	shared.metrics = metrics.NewRemediationMetrics(logger)
+	shared.metrics.Register()
+
+	// before any client or queue is created, so all of them are reported
+	metrics.NewClientGoMetrics(logger).Register()
+
+	if settings.Metrics.StatsD.Enabled {
+		var err error
+		metrics.StatsD, err = metrics.NewStatsD(logger.With(zap.String("component", "statsd")), settings.Metrics.StatsD)
 		runtime.Must(err)
+	}
 
-		k8sClient, err := k8s.NewClient(logger)
+	if settings.Audit.Enabled {
+		var err error
+		shared.audit, err = audit.Open(settings.Audit.Path)
+		runtime.Must(err)
+	}
+	if settings.HTTP.API.Enabled || settings.GRPC.Enabled {
+		shared.history = audit.NewHistory(settings.HTTP.API.RecentActions)
+	}
+	if settings.GRPC.Enabled || settings.HTTP.Admin.Enabled {
+		shared.controls = remediator.NewControls()
+	}
+	if settings.HTTP.Alertmanager.Enabled {
+		shared.alerts = remediator.NewAlerts()
+	}
+	// in the own cluster or the one of --kubeconfig, before any remediator starts so none acts while paused
+	if settings.HTTP.Admin.Enabled {
+		adminLogger := logger.With(zap.String("component", "admin"))
+		k8sClient, err := k8s.NewClient(adminLogger, shared.clientOptionsFor("admin"))
 		runtime.Must(err)
+		shared.pauses = remediator.NewPauseStore(k8sClient, settings.HTTP.Admin.Namespace, settings.HTTP.Admin.ConfigMap)
+		runtime.Must(shared.pauses.Load(ctx, shared.controls))
+	}
 
-		err = r.Setup(logger, k8sClient)
-		if err != nil {
-			logger.Panic("Error initializing", zap.Error(err))
+	if settings.Notifications.Slack.Enabled {
+		slack, err := notify.NewSlack(settings.Notifications.Slack)
+		runtime.Must(err)
+		shared.notifyWith(deliver, wg, logger, "slack", settings.Notifications.Slack.DeliveryConfig, slack.Send)
+	}
+	if settings.Notifications.PagerDuty.Enabled {
+		pagerDuty, err := notify.NewPagerDuty(settings.Notifications.PagerDuty)
+		runtime.Must(err)
+		shared.notifyWith(deliver, wg, logger, "pagerDuty", settings.Notifications.PagerDuty.DeliveryConfig, pagerDuty.Send)
+	}
+	if settings.Notifications.Webhook.Enabled {
+		webhook, err := notify.NewWebhook(settings.Notifications.Webhook)
+		runtime.Must(err)
+		shared.notifyWith(deliver, wg, logger, "webhook", settings.Notifications.Webhook.DeliveryConfig, webhook.Send)
+	}
+	if settings.Notifications.Email.Enabled {
+		email, err := notify.NewEmail(logger.With(zap.String("component", "email")), settings.Notifications.Email)
+		runtime.Must(err)
+		shared.notifyWith(deliver, wg, logger, "email", settings.Notifications.Email.DeliveryConfig, email.Send)
+		wg.Add(1)
+		go email.Run(deliver, wg)
+	}
+
+	// sends to the notifiers above and then is one of them to see every remediation
+	if settings.Report.Enabled {
+		reportMetrics := metrics.NewReportMetrics(logger)
+		reportMetrics.Register()
+		reporter := notify.NewReporter(logger.With(zap.String("component", "report")), settings.Report, shared.notifiers, reportMetrics)
+		shared.notifiers = append(shared.notifiers, reporter)
+		wg.Add(1)
+		go reporter.Run(ctx, wg, settings.Report.Interval)
+	}
+
+	if settings.Tracing.Enabled {
+		shared.tracer = tracing.NewTracer(
+			logger.With(zap.String("component", "tracing")),
+			settings.Tracing.Endpoint, settings.Tracing.Headers, settings.Tracing.ServiceName,
+		)
+		wg.Add(1)
+		go shared.tracer.Run(deliver, wg, settings.Tracing.Interval)
+	}
+
+	if settings.LeaderElection.Enabled {
+		shared.leaderMetrics = metrics.NewLeaderMetrics(logger)
+		shared.leaderMetrics.Register()
+	}
+
+	// 0 means unlimited
+	if max := settings.RateLimit.Max; max > 0 {
+		rateLimiterLogger := logger.With(zap.String("component", "rateLimiter"))
+		shared.rateLimiter = remediator.NewRateLimiter(rateLimiterLogger, max, settings.RateLimit.Interval)
+		wg.Add(1)
+		go shared.rateLimiter.Run(ctx, wg)
+	}
+
+	return shared
+}
+
+// a copy of shared for one cluster, logger already names the cluster
+func (s *shared) startCluster(ctx context.Context, wg *sync.WaitGroup, logger *zap.Logger, settings *config.Config, cluster config.ClusterConfig) *shared {
+	shared := *s
+	shared.cluster = cluster.Name
+	// a cluster of the config picks its own kubeconfig or context, the flags only pick the default one
+	if cluster.Kubeconfig != "" || cluster.Context != "" {
+		if cluster.Kubeconfig != "" {
+			shared.clientOptions.Kubeconfig = cluster.Kubeconfig
 		}
+		shared.clientOptions.Context = cluster.Context
+		shared.clientOptions.InCluster = false
+	}
+	shared.skipped = s.skipped.ForCluster(cluster.Name)
+	shared.metrics = s.metrics.ForCluster(cluster.Name)
+	shared.health = s.health.ForCluster(cluster.Name)
 
+	healthLogger := logger.With(zap.String("component", "health"))
+	healthClient, err := k8s.NewClient(healthLogger, shared.clientOptionsFor("health"))
+	runtime.Must(err)
+	shared.health.AddReadyCheck("api-server", healthClient.Ping)
+
+	// "" means no kill switch
+	if configMap := settings.KillSwitch.ConfigMap; configMap != "" {
+		killSwitchLogger := logger.With(zap.String("component", "killSwitch"))
+		k8sClient, err := k8s.NewClient(killSwitchLogger, shared.clientOptionsFor("killSwitch"))
+		runtime.Must(err)
+		shared.killSwitch, err = remediator.NewKillSwitch(
+			killSwitchLogger, k8sClient, settings.KillSwitch.Namespace, configMap, settings.KillSwitch.Key,
+		)
+		runtime.Must(err)
+		shared.killSwitch.UseNotifier(notify.ClusterNotifier{Cluster: shared.cluster, Notifier: shared.notifiers})
 		wg.Add(1)
-		go r.Run(ctx, &wg)
+		go shared.killSwitch.Run(ctx, wg)
+	}
+
+	// a scan right after starting would miss an engaged kill switch otherwise
+	if shared.once && !shared.killSwitch.WaitForSync(ctx.Done()) {
+		return &shared // untested section
 	}
 
+	// every cluster has its own Lease, so a replica can lead one cluster and follow in another,
+	// a CronJob runs one Pod at a time and needs none
+	if settings.LeaderElection.Enabled && !shared.once {
+		leaderLogger := logger.With(zap.String("component", "leaderElection"))
+		k8sClient, err := k8s.NewClient(leaderLogger, shared.clientOptionsFor("leaderElection"))
+		runtime.Must(err)
+		identity, err := os.Hostname() // the Pod name
+		runtime.Must(err)
+		shared.leader, err = remediator.NewLeaderElection(leaderLogger, k8sClient, settings.LeaderElection, identity)
+		runtime.Must(err)
+		shared.leader.UseMetrics(s.leaderMetrics.ForCluster(cluster.Name))
+		shared.health.AddStatus("leader", shared.leader.Status)
+		wg.Add(1)
+		go shared.leader.Run(ctx, wg)
+	}
+
+	// a CronJob is retried by Kubernetes, its scan ends before any remediator could be stuck
+	if settings.Watchdog.Enabled && !shared.once {
+		shared.watchdog = remediator.NewWatchdog(logger.With(zap.String("component", "watchdog")), settings.Watchdog.Multiple)
+		shared.watchdog.UseNotifier(notify.ClusterNotifier{Cluster: shared.cluster, Notifier: shared.notifiers})
+		shared.health.AddReadyCheck("watchdog", shared.watchdog.Check)
+		wg.Add(1)
+		go shared.watchdog.Run(ctx, wg, settings.Watchdog.Interval)
+	}
+
+	if settings.SkipDrainingNodes {
+		nodesLogger := logger.With(zap.String("component", "nodes"))
+		k8sClient, err := k8s.NewClient(nodesLogger, shared.clientOptionsFor("nodes"))
+		runtime.Must(err)
+		shared.nodes, err = k8s.NewNodeCache(k8sClient, settings.SlimCaches)
+		runtime.Must(err)
+		nodesLogger.Info("Waiting for Node cache")
+		shared.nodes.Start(ctx.Done())
+		shared.health.AddSyncCheck("nodes", shared.nodes.HasSynced)
+	}
+
+	// one scan lists the Pods itself, nil makes remediators do that
+	if shared.once {
+		return &shared
+	}
+
+	podsLogger := logger.With(zap.String("component", "pods"))
+	podsClient, err := k8s.NewClient(podsLogger, shared.clientOptionsFor("pods"))
+	runtime.Must(err)
+	shared.pods = k8s.NewPodCache(podsClient, ctx.Done(), settings.SlimCaches)
+	shared.health.AddSyncCheck("pods", shared.pods.HasSynced)
+
+	// "events": remediators that support it react to Pod events instead of watching all Pods
+	if settings.Detection == config.DetectionEvents {
+		streamLogger := logger.With(zap.String("component", "events"))
+		k8sClient, err := k8s.NewClient(streamLogger, shared.clientOptionsFor("events"))
+		runtime.Must(err)
+		shared.stream = events.NewStream(streamLogger, k8sClient, ctx.Done(), settings.Workers)
+		shared.health.AddSyncCheck("events", shared.stream.HasSynced)
+		wg.Add(1)
+		go shared.stream.Run(ctx, wg)
+	}
+
+	return &shared
+}
+
+// events are sent in the background so a slow endpoint does not hold up remediation, delivery picks the events
+// the endpoint gets
+func (s *shared) notifyWith(ctx context.Context, wg *sync.WaitGroup, logger *zap.Logger, name string, delivery notify.DeliveryConfig, send func(notify.Event) error) {
+	sink := notify.NewSink(logger.With(zap.String("component", name)), delivery, 100, send)
+	s.notifiers = append(s.notifiers, sink)
+	s.queues[name] = sink.Queue
 	wg.Add(1)
-	go http.NewServer(logger).Serve(ctx, &wg)
+	go sink.Run(ctx, wg)
+}
+
+// the options of the client of a component or remediator, its User-Agent names it
+func (s *shared) clientOptionsFor(component string) k8s.ClientOptions {
+	options := s.clientOptions
+	options.UserAgent = k8s.UserAgent(version, component)
+	return options
+}
+
+// started when first needed and then kept
+func (s *shared) namespaceCache(ctx context.Context, logger *zap.Logger) *k8s.NamespaceCache {
+	if s.namespaces == nil {
+		namespacesLogger := logger.With(zap.String("component", "namespaces"))
+		k8sClient, err := k8s.NewClient(namespacesLogger, s.clientOptionsFor("namespaces"))
+		runtime.Must(err)
+		s.namespaces, err = k8s.NewNamespaceCache(k8sClient)
+		runtime.Must(err)
+		namespacesLogger.Info("Waiting for Namespace cache")
+		s.namespaces.Start(ctx.Done())
+		s.health.AddSyncCheck("namespaces", s.namespaces.HasSynced)
+	}
+	return s.namespaces
+}
+
+// previous is the policy built from the previous settings, its state is kept when the settings did not change
+func newPolicy(ctx context.Context, logger *zap.Logger, settings, previousSettings *config.Config, previous *remediator.Policy, shared *shared) *remediator.Policy {
+	namespaces, err := remediator.NewNamespaceFilter(settings.IncludeNamespaces, settings.ExcludeNamespaces)
+	runtime.Must(err)
+
+	labelSelector, err := labels.Parse(settings.LabelSelector)
+	runtime.Must(err)
+
+	policy := &remediator.Policy{
+		Cluster:                     shared.cluster,
+		Skipped:                     shared.skipped,
+		Metrics:                     shared.metrics,
+		Audit:                       shared.audit,
+		History:                     shared.history,
+		Notifiers:                   shared.notifiers,
+		Tracer:                      shared.tracer,
+		Health:                      shared.health,
+		RateLimiter:                 shared.rateLimiter,
+		Drain:                       shared.drain,
+		KillSwitch:                  shared.killSwitch,
+		Controls:                    shared.controls,
+		Alerts:                      shared.alerts,
+		Leader:                      shared.leader,
+		Nodes:                       shared.nodes,
+		Pods:                        shared.pods,
+		PageSize:                    shared.pageSize,
+		DryRun:                      settings.DryRun,
+		ServerSideDryRun:            settings.ServerSideDryRun,
+		MinPodAge:                   settings.MinPodAge,
+		MinReadyReplicas:            settings.MinReadyReplicas,
+		MaxAttemptsPerOwner:         settings.MaxAttemptsPerOwner,
+		AttemptsAnnotation:          settings.AttemptsAnnotation,
+		DeleteAfterBlockedEvictions: settings.DeleteAfterBlockedEvictions,
+		PreconditionResourceVersion: settings.PreconditionResourceVersion,
+		ConfirmBeforeAction:         settings.ConfirmBeforeAction,
+		Workers:                     settings.Workers,
+		Actions:                     settings.Actions,
+		Namespaces:                  namespaces,
+		LabelSelector:               labelSelector,
+		ExcludedPriorityClasses:     settings.ExcludePriorityClasses,
+		OwnerKinds:                  settings.OwnerKinds,
+		OptMode:                     settings.OptMode,
+		OptInAnnotation:             settings.OptInAnnotation,
+		OptOutAnnotation:            settings.OptOutAnnotation,
+	}
+	runtime.Must(policy.Validate())
+
+	// keep remembered remediations unless their settings changed
+	reloaded := previousSettings != nil
+
+	// 0 means no cooldown
+	if reloaded && previousSettings.OwnerCooldown == settings.OwnerCooldown {
+		policy.Cooldown = previous.Cooldown
+	} else if cooldown := settings.OwnerCooldown; cooldown > 0 {
+		policy.Cooldown = remediator.NewCooldown(cooldown)
+	}
+
+	if reloaded && reflect.DeepEqual(previousSettings.NamespaceOverrides, settings.NamespaceOverrides) {
+		policy.NamespaceOverrides = previous.NamespaceOverrides
+	} else {
+		policy.NamespaceOverrides, err = remediator.NewNamespaceOverrides(settings.NamespaceOverrides)
+		runtime.Must(err)
+		if policy.NamespaceOverrides.UsesNamespaceSelector() {
+			policy.NamespaceOverrides.Namespaces = shared.namespaceCache(ctx, logger)
+		}
+	}
+
+	if settings.NamespaceAnnotations.Enabled {
+		policy.NamespaceAnnotations = &remediator.NamespaceAnnotations{
+			Prefix:     settings.NamespaceAnnotations.Prefix,
+			Namespaces: shared.namespaceCache(ctx, logger),
+		}
+	}
 
-	<-ctx.Done()
-	wg.Wait()
+	// 0 means unlimited
+	if reloaded && previousSettings.MaxUnavailablePerOwner == settings.MaxUnavailablePerOwner {
+		policy.UnavailableLimit = previous.UnavailableLimit
+	} else if maxUnavailable := settings.MaxUnavailablePerOwner; maxUnavailable != "0" && maxUnavailable != "" {
+		policy.UnavailableLimit, err = remediator.NewUnavailableLimit(maxUnavailable)
+		runtime.Must(err)
+	}
+
+	// 0 means no backoff
+	if reloaded && previousSettings.Backoff == settings.Backoff {
+		policy.Backoff = previous.Backoff
+	} else if initial := settings.Backoff.Initial; initial > 0 {
+		policy.Backoff = remediator.NewBackoff(
+			initial,
+			settings.Backoff.Factor,
+			settings.Backoff.Max,
+			settings.Backoff.ResetAfter,
+		)
+	}
+
+	if settings.OwnerAnnotations.Enabled {
+		policy.OwnerAnnotationPrefix = settings.OwnerAnnotations.Prefix
+	}
+
+	if settings.CaptureLogs.Enabled {
+		policy.CaptureLogLines = settings.CaptureLogs.Lines
+	}
+
+	if settings.CaptureEvents.Enabled {
+		policy.CaptureEvents = settings.CaptureEvents.Count
+	}
+
+	if settings.Diagnostics.Enabled {
+		diagnosticsLogger := logger.With(zap.String("component", "diagnostics"))
+		k8sClient, err := k8s.NewClient(diagnosticsLogger, shared.clientOptionsFor("diagnostics"))
+		runtime.Must(err)
+		policy.Diagnostics, err = diagnostics.NewCollector(diagnosticsLogger, k8sClient, settings.Diagnostics)
+		runtime.Must(err)
+		policy.RequireDiagnostics = settings.Diagnostics.Required
+	}
+
+	if settings.Approval.Enabled {
+		policy.Approval = &remediator.Approval{
+			RequestAnnotation: settings.Approval.RequestAnnotation,
+			ApproveAnnotation: settings.Approval.ApproveAnnotation,
+			Expiry:            settings.Approval.Expiry,
+		}
+	}
+
+	return policy
+}
+
+// started is when the process started, observation periods do not start over on reload, those of remediators a reload
+// enables start then, only the remediators of remediators.enabled run, all of them when it is empty
+// returns the started remediators by name, on an error those started so far keep running until ctx is done
+func runRemediators(ctx context.Context, wg *sync.WaitGroup, loggerConfig zap.Config, settings *config.Config, policy *remediator.Policy, shared *shared, started time.Time) (map[string]remediator.Remediator, error) {
+	running := map[string]remediator.Remediator{}
+	enabled := time.Now()
+	if shared.enabledSince == nil {
+		shared.enabledSince = map[string]time.Time{}
+		enabled = started
+	}
+	for _, name := range remediator.Names() {
+		if !isEnabled(name, settings.Remediators.Enabled) {
+			continue
+		}
+		r, _ := remediator.New(name, settings.Remediators) // registered
+
+		// make each logged line show what remediator it came from
+		loggerConfig.InitialFields = map[string]interface{}{"remediator": name}
+		if shared.cluster != "" {
+			loggerConfig.InitialFields["cluster"] = shared.cluster
+		}
+
+		logger, err := loggerConfig.Build(zap.Hooks(shared.errors.hook))
+		if err != nil {
+			return running, err // untested section
+		}
+
+		k8sClient, err := k8s.NewClient(logger, shared.clientOptionsFor(name))
+		if err != nil {
+			return running, fmt.Errorf("%s: %v", name, err) // untested section
+		}
+
+		remediatorPolicy := *policy
+		remediatorPolicy.Remediator = name
+		if remediatorPolicy.Maintenance, err = settings.Maintenance.Build(name); err != nil {
+			return running, fmt.Errorf("%s: maintenance: %v", name, err)
+		}
+		if remediatorPolicy.DeleteOptions, err = settings.Deletion.Build(name); err != nil {
+			return running, fmt.Errorf("%s: deletion: %v", name, err)
+		}
+		remediatorPolicy.Hooks = settings.Hooks.Build(name)
+		if _, ok := shared.enabledSince[name]; !ok {
+			shared.enabledSince[name] = enabled
+		}
+		// a process of --once is always new, observing would never end, and it scans right away
+		if !shared.once {
+			remediatorPolicy.ObserveUntil = settings.Observation.Build(name, shared.enabledSince[name])
+			remediatorPolicy.Reconcile = settings.Reconcile.Build(name)
+		}
+		remediatorPolicy.Once = shared.once
+		if remediatorPolicy.Filters, err = settings.Filters.Build(name, shared.nodes); err != nil {
+			return running, fmt.Errorf("%s: %v", name, err)
+		}
+
+		if eventDriven, ok := r.(remediator.EventDriven); ok && shared.stream != nil {
+			eventDriven.UseEventStream(shared.stream)
+		}
+
+		// a panic restarts the remediator instead of the process
+		if err = remediator.Start(ctx, wg, logger, r, k8sClient, &remediatorPolicy); err != nil {
+			return running, fmt.Errorf("%s: %v", name, err)
+		}
+		running[name] = r
+	}
+	return running, nil
+}
+
+func runningNames(running map[string]remediator.Remediator) []string {
+	names := make([]string, 0, len(running))
+	for name := range running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap/zapcore"
+	restclient "k8s.io/client-go/rest"
+	"os"
+	"strings"
+	"time"
+)
+
+// set when building with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+type options struct {
+	configFile    string // "" finds config/remediator.{json,yaml,yml,toml}
+	kubeconfig    string
+	context       string // of the kubeconfig, "" means its current context
+	inCluster     bool
+	apiTimeout    time.Duration // "" uses client.timeout from the config
+	apiTimeoutSet bool
+	logLevel      string // "" uses log.level from the config
+	dryRun        bool
+	dryRunSet     bool
+	remediators   []string
+	once          bool // scan once and exit instead of watching
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	options := &options{}
+	root := &cobra.Command{
+		Use:          "remediator",
+		Short:        "Detects and fixes common Pod and Node problems",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // errors are about the config, not how it was called
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.complete(cmd); err != nil {
+				return err
+			}
+			configFile, settings, err := options.load()
+			if err != nil {
+				return err
+			}
+			return run(options, configFile, settings)
+		},
+	}
+
+	root.PersistentFlags().StringVar(&options.configFile, "config", "", "config file (default config/remediator.{json,yaml,yml,toml})")
+	root.PersistentFlags().BoolVar(&options.dryRun, "dry-run", false, "only log what would be done, overrides dryRun from the config")
+	addClusterFlags(root, options)
+	root.Flags().BoolVar(&options.once, "once", false, "scan once with every enabled remediator and exit, non-zero when any of them failed, for a CronJob or CI")
+
+	root.AddCommand(newVersionCommand(), newValidateConfigCommand(options), newPrintConfigCommand(options), newSchemaCommand(),
+		newSimulateCommand(options))
+	return root
+}
+
+// flags of the commands that talk to clusters
+func addClusterFlags(command *cobra.Command, options *options) {
+	flags := command.Flags()
+	flags.StringVar(&options.kubeconfig, "kubeconfig", "", "kubeconfig to use, also inside a cluster (default the own cluster, outside of one $KUBECONFIG or ~/.kube/config)")
+	flags.StringVar(&options.context, "context", "", "context of the kubeconfig to use (default its current context)")
+	flags.BoolVar(&options.inCluster, "in-cluster", false, "use the service account of the Pod even when $KUBECONFIG is set")
+	flags.DurationVar(&options.apiTimeout, "api-timeout", 0, "timeout of each call to the api-server, 0 means none, overrides client.timeout from the config")
+	flags.StringVar(&options.logLevel, "log-level", "", "debug, info, warn or error, overrides log.level from the config")
+	flags.StringSliceVar(&options.remediators, "remediators", nil, "remediators to run, comma separated, overrides remediators.enabled (default all)")
+}
+
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintln(cmd.OutOrStdout(), version)
+		},
+	}
+}
+
+func newValidateConfigCommand(options *options) *cobra.Command {
+	return &cobra.Command{
+		Use:          "validate-config",
+		Short:        "Check the config file and exit",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configFile, _, err := options.load()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configFile)
+			return nil
+		},
+	}
+}
+
+func newPrintConfigCommand(options *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "print-config",
+		Short: "Print the config that would be used as YAML: defaults, config file, environment and flags",
+		Long: "Print the config that would be used as YAML: defaults, config file, environment and flags.\n" +
+			"RemediationPolicies and Namespace annotations are read from the cluster and not included.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.dryRunSet = cmd.Flags().Changed("dry-run")
+			_, settings, err := options.load()
+			if err != nil {
+				return err
+			}
+			printed, err := options.apply(settings).YAML()
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(printed)
+			return err
+		},
+	}
+}
+
+func newSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the config file, for editors and CI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+			return err
+		},
+	}
+}
+
+// check flags that cobra cannot check itself
+func (o *options) complete(cmd *cobra.Command) error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(o.logLevel)); o.logLevel != "" && err != nil {
+		return fmt.Errorf("invalid --log-level: %v", err)
+	}
+	if o.inCluster && (o.kubeconfig != "" || o.context != "") {
+		return fmt.Errorf("--in-cluster can not be combined with --kubeconfig or --context")
+	}
+	o.dryRunSet = cmd.Flags().Changed("dry-run")
+	o.apiTimeoutSet = cmd.Flags().Changed("api-timeout")
+
+	known := remediator.Names()
+	for _, name := range o.remediators {
+		if !isEnabled(name, known) {
+			return fmt.Errorf("unknown remediator %q in --remediators, use %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+func (o *options) load() (string, *config.Config, error) {
+	configFile := o.configFile
+	if configFile == "" {
+		var err error
+		configFile, err = config.Find("config/remediator")
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	settings, err := config.Load(configFile)
+	return configFile, settings, err
+}
+
+// copy of settings with flags that override the config applied
+func (o *options) apply(settings *config.Config) *config.Config {
+	applied := *settings
+	if o.dryRunSet {
+		applied.DryRun = o.dryRun
+	}
+	if o.logLevel != "" {
+		applied.Log.Level = o.logLevel
+	}
+	if o.apiTimeoutSet {
+		applied.Client.Timeout = o.apiTimeout
+	}
+	if len(o.remediators) > 0 {
+		applied.Remediators.Enabled = o.remediators
+	}
+	return &applied
+}
+
+// of the default cluster, see startCluster for the others
+func (o *options) clientOptions(settings *config.Config) k8s.ClientOptions {
+	return k8s.ClientOptions{
+		Kubeconfig:  o.kubeconfig,
+		Context:     o.context,
+		InCluster:   o.inCluster,
+		QPS:         settings.Client.QPS,
+		Burst:       settings.Client.Burst,
+		Timeout:     settings.Client.Timeout,
+		ContentType: settings.Client.ContentType,
+		TokenFile:   settings.Identity.TokenFile,
+		Impersonate: restclient.ImpersonationConfig{
+			UserName: settings.Identity.User,
+			Groups:   settings.Identity.Groups,
+		},
+		ServiceAccount: settings.Identity.ServiceAccount,
+	}
+}
+
+// empty enabled means all, matched case-insensitive
+func isEnabled(name string, enabled []string) bool {
+	if len(enabled) == 0 {
+		return true
+	}
+	for _, e := range enabled {
+		if strings.EqualFold(e, name) {
+			return true
+		}
+	}
+	return false
+}
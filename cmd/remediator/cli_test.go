@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func execute(args ...string) (string, error) {
+	root := newRootCommand()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestPrintsVersion(t *testing.T) {
+	out, err := execute("version")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "dev\n")
+}
+
+func TestValidatesConfig(t *testing.T) {
+	out, err := execute("validate-config", "--config", "../../config/remediator.json")
+	assert.NilError(t, err)
+	assert.Equal(t, out, "../../config/remediator.json is valid\n")
+}
+
+func TestValidateConfigFailsForMissingConfig(t *testing.T) {
+	_, err := execute("validate-config", "--config", "missing.json")
+	assert.ErrorContains(t, err, "missing.json")
+}
+
+func TestPrintsEffectiveConfig(t *testing.T) {
+	out, err := execute("print-config", "--config", "../../config/remediator.json", "--dry-run")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(out, "detection: informer\nhttp:\n  port: 8080\n  debug: false\n  api:\n    enabled: false\n    recentActions: 100\n  admin:\n    enabled: false\n    token: \"\"\n    namespace: default\n    configMap: kube-remediator-paused\n  alertmanager:\n    enabled: false\n    token: \"\"\ngrpc:\n  enabled: false\n  port: 9090\n  token: \"\"\naudit:\n  enabled: false\n  path: '-'\n"), out)
+	assert.Assert(t, strings.Contains(out, "\ndryRun: true\n"), out)
+	assert.Assert(t, strings.Contains(out, "\nrateLimit:\n  max: 0\n  interval: 5m0s\n"), out)
+	assert.Assert(t, strings.Contains(out, "\n  timeZone: UTC\n"), out) // squashed into maintenance
+	assert.Assert(t, strings.Contains(out, "    failureThreshold: 5\n"), out)
+}
+
+func TestPrintedConfigLoadsAgain(t *testing.T) {
+	out, err := execute("print-config", "--config", "../../config/remediator.json")
+	assert.NilError(t, err)
+	file, err := ioutil.TempFile("", "remediator*.yaml")
+	assert.NilError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(out)
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+
+	printed, err := config.Load(file.Name())
+	assert.NilError(t, err)
+	shipped, err := config.Load("../../config/remediator.json")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config.Diff(shipped, printed), []config.Change(nil))
+}
+
+func TestRejectsUnknownRemediators(t *testing.T) {
+	_, err := execute("--remediators", "OldPodDeleter,Foo")
+	assert.ErrorContains(t, err, `unknown remediator "Foo"`)
+}
+
+func TestRejectsUnknownLogLevel(t *testing.T) {
+	_, err := execute("--log-level", "loud")
+	assert.ErrorContains(t, err, "invalid --log-level")
+}
+
+func TestRejectsInClusterWithKubeconfig(t *testing.T) {
+	_, err := execute("--in-cluster", "--context", "staging")
+	assert.ErrorContains(t, err, "--in-cluster can not be combined with --kubeconfig or --context")
+}
+
+func TestDryRunFlagOverridesConfig(t *testing.T) {
+	settings := config.Default()
+	assert.Equal(t, (&options{}).apply(&settings).DryRun, false)
+	assert.Equal(t, (&options{dryRun: true, dryRunSet: true}).apply(&settings).DryRun, true)
+
+	settings.DryRun = true
+	assert.Equal(t, (&options{dryRun: false, dryRunSet: true}).apply(&settings).DryRun, false)
+	assert.Equal(t, settings.DryRun, true) // not changed in place
+}
+
+func TestLogLevelFlagOverridesConfig(t *testing.T) {
+	settings := config.Default()
+	assert.Equal(t, (&options{}).apply(&settings).Log.Level, "info")
+	assert.Equal(t, (&options{logLevel: "debug"}).apply(&settings).Log.Level, "debug")
+}
+
+func TestAPITimeoutFlagOverridesConfig(t *testing.T) {
+	settings := config.Default()
+	assert.Equal(t, (&options{}).apply(&settings).Client.Timeout, 30*time.Second)
+	assert.Equal(t, (&options{apiTimeout: 0, apiTimeoutSet: true}).apply(&settings).Client.Timeout, time.Duration(0))
+}
+
+func TestClientsIdentifyTheirComponent(t *testing.T) {
+	shared := &shared{clientOptions: k8s.ClientOptions{Context: "staging"}}
+	options := shared.clientOptionsFor("OldPodDeleter")
+	assert.Equal(t, options.Context, "staging")
+	assert.Assert(t, strings.HasPrefix(options.UserAgent, "kube-remediator/dev ("), options.UserAgent)
+	assert.Assert(t, strings.HasSuffix(options.UserAgent, ") OldPodDeleter"), options.UserAgent)
+	assert.Equal(t, shared.clientOptions.UserAgent, "")
+}
+
+func TestRemediatorsFlagOverridesConfig(t *testing.T) {
+	settings := config.Default()
+	settings.Remediators.Enabled = []string{"CrashLoopBackOffRescheduler"}
+	assert.DeepEqual(t, (&options{}).apply(&settings).Remediators.Enabled, []string{"CrashLoopBackOffRescheduler"})
+	assert.DeepEqual(t, (&options{remediators: []string{"OldPodDeleter"}}).apply(&settings).Remediators.Enabled, []string{"OldPodDeleter"})
+}
+
+func TestIsEnabled(t *testing.T) {
+	assert.Equal(t, isEnabled("OldPodDeleter", nil), true)
+	assert.Equal(t, isEnabled("OldPodDeleter", []string{"oldpoddeleter"}), true)
+	assert.Equal(t, isEnabled("OldPodDeleter", []string{"NodeProblemRemediator"}), false)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/control"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the Control service of pkg/control/control.proto, every change is logged so it is clear who paused what
+type controlServer struct {
+	logger   *zap.Logger
+	controls *remediator.Controls
+	pauses   *remediator.PauseStore // nil without the admin API, pauses are then lost when restarting
+	history  *audit.History
+	token    string // required, "" rejects every call
+}
+
+func (s *controlServer) Serve(ctx context.Context, wg *sync.WaitGroup, port int) {
+	defer wg.Done()
+	s.logger.Info("Starting", zap.Int("port", port))
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		s.logger.Error("Error listening", zap.Error(err)) // untested section
+		return
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.authenticate))
+	control.RegisterControlServer(server, s)
+	go server.Serve(listener)
+	<-ctx.Done()
+	s.logger.Info("Stopping", zap.String("reason", "Signal"))
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second): // like the http server
+		server.Stop() // untested section
+	}
+}
+
+func (s *controlServer) authenticate(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var given string
+	if values := md.Get("authorization"); len(values) > 0 {
+		given = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	// an empty token would let calls without one through
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "missing or wrong bearer token")
+	}
+	return handler(ctx, request)
+}
+
+func (s *controlServer) Pause(ctx context.Context, request *control.RemediatorRequest) (*control.ControlState, error) {
+	name, err := lookupRemediator(request.Remediator)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Pausing", zap.String("remediator", allOr(name)))
+	s.controls.Pause(name)
+	return s.savePauses(ctx)
+}
+
+func (s *controlServer) Resume(ctx context.Context, request *control.RemediatorRequest) (*control.ControlState, error) {
+	name, err := lookupRemediator(request.Remediator)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Resuming", zap.String("remediator", allOr(name)))
+	s.controls.Resume(name)
+	return s.savePauses(ctx)
+}
+
+func (s *controlServer) Scan(ctx context.Context, request *control.RemediatorRequest) (*control.ScanResponse, error) {
+	name, err := lookupRemediator(request.Remediator)
+	if err != nil {
+		return nil, err
+	}
+	started := s.controls.Scan(name)
+	s.logger.Info("Requesting scan", zap.String("remediator", allOr(name)), zap.Strings("started", started))
+	if len(started) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is not running", allOr(name))
+	}
+	return &control.ScanResponse{Remediators: started}, nil
+}
+
+func (s *controlServer) ListActions(ctx context.Context, request *control.ListActionsRequest) (*control.ListActionsResponse, error) {
+	if request.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+	response := &control.ListActionsResponse{}
+	for _, record := range s.history.Recent(request.Remediator, int(request.Limit)) {
+		response.Actions = append(response.Actions, &control.Action{
+			Time:       record.Time.UTC().Format(time.RFC3339),
+			Remediator: record.Remediator,
+			Cluster:    record.Cluster,
+			Kind:       record.Object.Kind,
+			Namespace:  record.Object.Namespace,
+			Name:       record.Object.Name,
+			Reason:     record.Reason,
+			Action:     record.Action,
+			Decision:   record.Decision,
+			Outcome:    record.Outcome,
+			DryRun:     record.DryRun,
+			Detail:     record.Detail,
+		})
+	}
+	return response, nil
+}
+
+func (s *controlServer) SetDryRun(ctx context.Context, request *control.SetDryRunRequest) (*control.ControlState, error) {
+	var dryRun *bool
+	switch request.DryRun {
+	case control.DryRun_ON, control.DryRun_OFF:
+		on := request.DryRun == control.DryRun_ON
+		dryRun = &on
+	case control.DryRun_CONFIG:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown dry run %v", request.DryRun)
+	}
+	s.logger.Info("Setting dry run", zap.Stringer("dryRun", request.DryRun))
+	s.controls.SetDryRun(dryRun)
+	return s.state(), nil
+}
+
+func (s *controlServer) GetState(ctx context.Context, request *control.StateRequest) (*control.ControlState, error) {
+	return s.state(), nil
+}
+
+func (s *controlServer) state() *control.ControlState {
+	state := &control.ControlState{DryRun: control.DryRun_CONFIG}
+	state.PausedAll, state.Paused = s.controls.PausedRemediators()
+	if dryRun := s.controls.DryRun(); dryRun != nil {
+		state.DryRun = control.DryRun_OFF
+		if *dryRun {
+			state.DryRun = control.DryRun_ON
+		}
+	}
+	return state
+}
+
+// the change applies right away, failing to keep it only means it is lost when restarting
+func (s *controlServer) savePauses(ctx context.Context) (*control.ControlState, error) {
+	if err := s.pauses.Save(ctx, s.controls); err != nil {
+		s.logger.Error("Error keeping paused remediators", zap.Error(err))
+		return nil, status.Errorf(codes.Unavailable, "changed until the next restart, keeping it failed: %v", err)
+	}
+	return s.state(), nil
+}
+
+// the registered name, see remediator.Lookup, "" means all remediators
+func lookupRemediator(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if registered, ok := remediator.Lookup(name); ok {
+		return registered, nil
+	}
+	return "", status.Errorf(codes.NotFound, "unknown remediator %q, use one of %s", name, strings.Join(remediator.Names(), ", "))
+}
+
+func allOr(remediator string) string {
+	if remediator == "" {
+		return "all"
+	}
+	return remediator
+}
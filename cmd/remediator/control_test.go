@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/control"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func newControlServer() *controlServer {
+	return &controlServer{logger: zap.NewNop(), controls: remediator.NewControls(), history: audit.NewHistory(10)}
+}
+
+func TestPausesAndResumesThroughControl(t *testing.T) {
+	server := newControlServer()
+	ctx := context.Background()
+
+	state, err := server.Pause(ctx, &control.RemediatorRequest{Remediator: "OldPodDeleter"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, state.Paused, []string{"oldpoddeleter"})
+	assert.Equal(t, server.controls.Paused("OldPodDeleter"), true)
+
+	state, err = server.Pause(ctx, &control.RemediatorRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, state.PausedAll, true)
+
+	state, err = server.Resume(ctx, &control.RemediatorRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, state.PausedAll, false)
+	assert.Equal(t, len(state.Paused), 0)
+
+	_, err = server.Pause(ctx, &control.RemediatorRequest{Remediator: "Nope"})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+}
+
+func TestSetsDryRunThroughControl(t *testing.T) {
+	server := newControlServer()
+	ctx := context.Background()
+
+	state, err := server.SetDryRun(ctx, &control.SetDryRunRequest{DryRun: control.DryRun_ON})
+	assert.NilError(t, err)
+	assert.Equal(t, state.DryRun, control.DryRun_ON)
+	assert.Equal(t, *server.controls.DryRun(), true)
+
+	state, err = server.SetDryRun(ctx, &control.SetDryRunRequest{DryRun: control.DryRun_OFF})
+	assert.NilError(t, err)
+	assert.Equal(t, state.DryRun, control.DryRun_OFF)
+
+	state, err = server.GetState(ctx, &control.StateRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, state.DryRun, control.DryRun_OFF)
+
+	state, err = server.SetDryRun(ctx, &control.SetDryRunRequest{DryRun: control.DryRun_CONFIG})
+	assert.NilError(t, err)
+	assert.Equal(t, state.DryRun, control.DryRun_CONFIG)
+	assert.Assert(t, server.controls.DryRun() == nil)
+
+	_, err = server.SetDryRun(ctx, &control.SetDryRunRequest{DryRun: 7})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+}
+
+func TestRefusesScansWhenNothingRuns(t *testing.T) {
+	_, err := newControlServer().Scan(context.Background(), &control.RemediatorRequest{Remediator: "OldPodDeleter"})
+	assert.Equal(t, status.Code(err), codes.FailedPrecondition)
+}
+
+func TestListsActionsThroughControl(t *testing.T) {
+	server := newControlServer()
+	server.history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Name: "a"}, Outcome: "success"})
+	server.history.Record(audit.Record{Remediator: "CrashLoopBackOffRescheduler", Object: audit.ObjectRef{Kind: "Pod", Name: "b"}, Outcome: "error"})
+
+	response, err := server.ListActions(context.Background(), &control.ListActionsRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(response.Actions), 2)
+	assert.Equal(t, response.Actions[0].Name, "b")
+
+	response, err = server.ListActions(context.Background(), &control.ListActionsRequest{Remediator: "OldPodDeleter", Limit: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, len(response.Actions), 1)
+	assert.Equal(t, response.Actions[0].Outcome, "success")
+
+	_, err = server.ListActions(context.Background(), &control.ListActionsRequest{Limit: -1})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+}
+
+func TestAuthenticatesControlWithToken(t *testing.T) {
+	server := newControlServer()
+	server.token = "secret"
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) { return "ok", nil }
+	call := func(ctx context.Context) error {
+		_, err := server.authenticate(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		return err
+	}
+
+	assert.Equal(t, status.Code(call(context.Background())), codes.Unauthenticated)
+	wrong := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer nope"))
+	assert.Equal(t, status.Code(call(wrong)), codes.Unauthenticated)
+	right := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	assert.NilError(t, call(right))
+
+	server.token = ""
+	assert.Equal(t, status.Code(call(context.Background())), codes.Unauthenticated)
+}
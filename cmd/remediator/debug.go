@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"net/http"
+	"sync"
+)
+
+// served on /debug/state and /api/v1, swapped whenever the remediators of a cluster restart
+type debugState struct {
+	shared *shared
+
+	lock     sync.Mutex
+	clusters map[string]clusterState // "" when there is only the one cluster
+}
+
+// what runs for a cluster
+type clusterState struct {
+	shared      *shared
+	policy      *remediator.Policy
+	remediators map[string]remediator.Remediator
+}
+
+// shared is the copy of the cluster
+func (d *debugState) use(shared *shared, policy *remediator.Policy, remediators map[string]remediator.Remediator) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.clusters == nil {
+		d.clusters = map[string]clusterState{}
+	}
+	d.clusters[shared.cluster] = clusterState{shared: shared, policy: policy, remediators: remediators}
+}
+
+func (d *debugState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state := struct {
+		Policy        *remediator.PolicyState               `json:"policy"`                    // nil before the remediators started
+		Clusters      map[string]remediator.PolicyState     `json:"clusterPolicies,omitempty"` // with several clusters, instead of policy
+		Remediators   map[string]remediator.RemediatorState `json:"remediators"`
+		Notifications map[string]int                        `json:"notificationQueues"` // events waiting to be sent
+	}{Remediators: map[string]remediator.RemediatorState{}, Notifications: map[string]int{}}
+	for cluster, running := range d.running() {
+		policyState := running.policy.State()
+		if cluster == "" {
+			state.Policy = &policyState
+		} else {
+			if state.Clusters == nil {
+				state.Clusters = map[string]remediator.PolicyState{}
+			}
+			state.Clusters[cluster] = policyState
+		}
+		for name, r := range running.remediators {
+			state.Remediators[clusterName(cluster, name)] = r.State()
+		}
+	}
+	for name, queue := range d.shared.queues {
+		state.Notifications[name] = queue.Len()
+	}
+
+	writeJSON(w, state)
+}
+
+// by cluster, a copy
+func (d *debugState) running() map[string]clusterState {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	running := make(map[string]clusterState, len(d.clusters))
+	for cluster, state := range d.clusters {
+		running[cluster] = state
+	}
+	return running
+}
+
+// "cluster/name" when there are several clusters, like the stats of audit.History
+func clusterName(cluster string, name string) string {
+	if cluster == "" {
+		return name
+	}
+	return cluster + "/" + name
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
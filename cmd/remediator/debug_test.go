@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServesDebugState(t *testing.T) {
+	queue := notify.NewQueue(zap.NewNop(), 10, func(notify.Event) error { return nil })
+	queue.Notify(notify.Event{})
+	debug := &debugState{shared: &shared{queues: map[string]*notify.Queue{"slack": queue}}}
+
+	var state map[string]interface{}
+	recorder := httptest.NewRecorder()
+	debug.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/state", nil))
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.Equal(t, state["policy"], nil)
+
+	policy := &remediator.Policy{Cooldown: remediator.NewCooldown(time.Hour)}
+	policy.Cooldown.TryStart("default/ReplicaSet/foo")
+	debug.use(&shared{}, policy, map[string]remediator.Remediator{"OldPodDeleter": &remediator.OldPodDeleter{}})
+	recorder = httptest.NewRecorder()
+	debug.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/state", nil))
+	assert.Equal(t, recorder.Header().Get("Content-Type"), "application/json")
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.Assert(t, state["policy"].(map[string]interface{})["cooldowns"].(map[string]interface{})["default/ReplicaSet/foo"] != nil)
+	assert.Equal(t, state["remediators"].(map[string]interface{})["OldPodDeleter"].(map[string]interface{})["lastScan"], "0001-01-01T00:00:00Z")
+	assert.DeepEqual(t, state["notificationQueues"], map[string]interface{}{"slack": float64(1)})
+}
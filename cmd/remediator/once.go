@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync/atomic"
+)
+
+// counts what is logged at error level or above, like Pods that could not be listed, so --once fails when any
+// remediator ran into one, a nil loggedErrors counts nothing
+type loggedErrors struct {
+	count int64
+}
+
+func (l *loggedErrors) hook(entry zapcore.Entry) error {
+	if l != nil && entry.Level >= zapcore.ErrorLevel {
+		atomic.AddInt64(&l.count, 1)
+	}
+	return nil
+}
+
+func (l *loggedErrors) Count() int64 {
+	if l == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&l.count)
+}
+
+// logs what every remediator decided in its one scan, an error when a remediation failed or an error was logged
+func (s *shared) onceResult(logger *zap.Logger) error {
+	failed := 0
+	for name, stats := range s.history.Stats() {
+		logger.Info("Scanned once", zap.String("remediator", name), zap.Any("outcomes", stats.Outcomes))
+		failed += stats.Outcomes["error"]
+	}
+	if logged := s.errors.Count(); failed > 0 || logged > 0 {
+		return fmt.Errorf("%d remediations failed, %d errors logged", failed, logged)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestOnceSucceedsWithoutErrors(t *testing.T) {
+	shared := &shared{once: true, history: audit.NewHistory(0), errors: &loggedErrors{}}
+	shared.history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "success"})
+	assert.NilError(t, shared.onceResult(zap.NewNop()))
+}
+
+func TestOnceFailsWhenRemediationsFailed(t *testing.T) {
+	shared := &shared{once: true, history: audit.NewHistory(0), errors: &loggedErrors{}}
+	shared.history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "error"})
+	assert.Error(t, shared.onceResult(zap.NewNop()), "1 remediations failed, 0 errors logged")
+}
+
+func TestOnceFailsWhenErrorsWereLogged(t *testing.T) {
+	shared := &shared{once: true, history: audit.NewHistory(0), errors: &loggedErrors{}}
+	core, _ := observer.New(zapcore.InfoLevel) // hooks only see what is logged
+	logger := zap.New(core, zap.Hooks(shared.errors.hook))
+	logger.Warn("Pod not found")
+	assert.NilError(t, shared.onceResult(logger))
+
+	logger.Error("Error listing Pods")
+	assert.Error(t, shared.onceResult(logger), "0 remediations failed, 1 errors logged")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"io"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// what every remediator decided in one dry run scan, written to by an audit.Log
+type simulation struct {
+	lock    sync.Mutex
+	records []audit.Record
+}
+
+// the report of simulate -o json
+type simulationReport struct {
+	WouldRemediate []audit.Record `json:"wouldRemediate"`
+	Skipped        []audit.Record `json:"skipped"`
+}
+
+func newSimulateCommand(options *options) *cobra.Command {
+	var output string
+	var showSkipped bool
+	command := &cobra.Command{
+		Use:   "simulate",
+		Short: "Scan once in dry run and report what every remediator would remediate and why, without changing anything",
+		Long: "Scan once in dry run and report what every remediator would remediate and why, without changing anything.\n" +
+			"Uses the config, RemediationPolicies and kill switch like a running remediator, lists Pods instead of watching them\n" +
+			"and sends no notifications, for reviewing a rollout or tuning the config.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.complete(cmd); err != nil {
+				return err
+			}
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid --output %q, use table or json", output)
+			}
+			_, settings, err := options.load()
+			if err != nil {
+				return err
+			}
+			result, logged := simulate(options, settings)
+			report := result.report()
+			if output == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(report)
+			} else {
+				err = report.print(cmd.OutOrStdout(), showSkipped)
+			}
+			if err != nil {
+				return err // untested section
+			}
+			if logged > 0 {
+				return fmt.Errorf("%d errors logged, the report can miss Pods", logged)
+			}
+			return nil
+		},
+	}
+	addClusterFlags(command, options)
+	command.Flags().StringVarP(&output, "output", "o", "table", "table or json")
+	command.Flags().BoolVar(&showSkipped, "skipped", false, "also list the Pods that would be skipped and why, not only how many")
+	return command
+}
+
+// every cluster scans once in dry run, like --once, returns what was decided and how many errors were logged
+func simulate(options *options, fileSettings *config.Config) (*simulation, int64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	settings := options.apply(fileSettings)
+	// the report is the output, the logs only say what went wrong
+	loggerConfig := settings.Log.ZapConfig()
+	if options.logLevel == "" {
+		loggerConfig.Level.SetLevel(zap.WarnLevel)
+	}
+	errorCount := &loggedErrors{}
+	logger, err := loggerConfig.Build(zap.Hooks(errorCount.hook))
+	runtime.Must(err)
+
+	wg.Add(1)
+	go signalHandler(ctx, cancel, &wg, logger)
+
+	clusters, err := settings.ListClusters()
+	runtime.Must(err)
+	if len(clusters) == 0 {
+		clusters = []config.ClusterConfig{{}}
+	}
+	base := &shared{
+		clientOptions: options.clientOptions(settings),
+		pageSize:      settings.Client.PageSize,
+		once:          true,
+		errors:        errorCount,
+	}
+	result := &simulation{}
+	started := time.Now()
+	for _, cluster := range clusters {
+		clusterLogger := logger
+		if cluster.Name != "" {
+			clusterLogger = logger.With(zap.String("cluster", cluster.Name))
+		}
+		shared := base.startCluster(ctx, &wg, clusterLogger, settings, cluster)
+		effective := shared.startPolicies(ctx, clusterLogger, settings).Apply(settings)
+		effective.DryRun = true
+		policy := newPolicy(ctx, clusterLogger, effective, nil, nil, shared)
+		policy.Audit = audit.NewLog(result)
+
+		var remediatorsWg sync.WaitGroup
+		if _, err := runRemediators(ctx, &remediatorsWg, loggerConfig, effective, policy, shared, started); err != nil {
+			clusterLogger.Error("Error starting remediators", zap.Error(err)) // counted like every logged error
+		}
+		remediatorsWg.Wait()
+	}
+
+	cancel()
+	wg.Wait()
+	return result, errorCount.Count()
+}
+
+// audit.Log writes one record per call
+func (s *simulation) Write(line []byte) (int, error) {
+	var record audit.Record
+	if err := json.Unmarshal(line, &record); err != nil {
+		return 0, err // untested section
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, record)
+	return len(line), nil
+}
+
+// sorted by cluster, remediator and object
+func (s *simulation) report() simulationReport {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	report := simulationReport{WouldRemediate: []audit.Record{}, Skipped: []audit.Record{}}
+	for _, record := range s.records {
+		if record.Outcome == metrics.ResultSkipped {
+			report.Skipped = append(report.Skipped, record)
+		} else {
+			report.WouldRemediate = append(report.WouldRemediate, record)
+		}
+	}
+	sortRecords(report.WouldRemediate)
+	sortRecords(report.Skipped)
+	return report
+}
+
+func sortRecords(records []audit.Record) {
+	key := func(r audit.Record) string {
+		return strings.Join([]string{r.Cluster, r.Remediator, r.Object.Kind, r.Object.Namespace, r.Object.Name}, "/")
+	}
+	sort.SliceStable(records, func(i, j int) bool { return key(records[i]) < key(records[j]) })
+}
+
+// a table of what would be remediated, of what would be skipped with showSkipped, and how many were skipped why
+func (r simulationReport) print(out io.Writer, showSkipped bool) error {
+	records := r.WouldRemediate
+	if showSkipped {
+		records = append(append([]audit.Record{}, records...), r.Skipped...)
+	}
+	if len(records) > 0 {
+		table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "REMEDIATOR\tOBJECT\tREASON\tACTION\tDECISION\tDETAIL")
+		for _, record := range records {
+			object := record.Object.Kind + "/" + record.Object.Namespace + "/" + record.Object.Name
+			if record.Object.Namespace == "" {
+				object = record.Object.Kind + "/" + record.Object.Name
+			}
+			remediator := record.Remediator
+			if record.Cluster != "" {
+				remediator = record.Cluster + "/" + remediator
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				remediator, object, record.Reason, record.Action, record.Decision, record.Detail)
+		}
+		if err := table.Flush(); err != nil {
+			return err // untested section
+		}
+		fmt.Fprintln(out)
+	}
+
+	why := map[string]int{}
+	for _, record := range r.Skipped {
+		why[record.Detail]++
+	}
+	reasons := make([]string, 0, len(why))
+	for detail, count := range why {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, detail))
+	}
+	sort.Strings(reasons)
+	summary := fmt.Sprintf("%d would be remediated, %d skipped", len(r.WouldRemediate), len(r.Skipped))
+	if len(reasons) > 0 {
+		summary += ": " + strings.Join(reasons, ", ")
+	}
+	_, err := fmt.Fprintln(out, summary)
+	return err
+}
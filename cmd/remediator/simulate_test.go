@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func simulated(t *testing.T) *simulation {
+	result := &simulation{}
+	log := audit.NewLog(result)
+	for _, record := range []audit.Record{
+		{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Namespace: "payments", Name: "worker-b"}, Reason: "Old", Action: "evicted", Decision: "remediate", Outcome: "dry-run", Detail: "dry run"},
+		{Remediator: "CrashLoopBackOffRescheduler", Object: audit.ObjectRef{Kind: "Pod", Namespace: "payments", Name: "api-x2x"}, Reason: "CrashLoopBackOff", Action: "deleted", Decision: "remediate", Outcome: "dry-run", Detail: "dry run"},
+		{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Namespace: "kube-system", Name: "etcd"}, Reason: "Old", Action: "evicted", Decision: "skip", Outcome: "skipped", Detail: "static Pod"},
+		{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "job"}, Reason: "Old", Action: "evicted", Decision: "skip", Outcome: "skipped", Detail: "no owner"},
+		{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "debug"}, Reason: "Old", Action: "evicted", Decision: "skip", Outcome: "skipped", Detail: "no owner"},
+	} {
+		assert.NilError(t, log.Record(record))
+	}
+	return result
+}
+
+func TestReportsWhatWouldBeRemediated(t *testing.T) {
+	var out bytes.Buffer
+	assert.NilError(t, simulated(t).report().print(&out, false))
+	assert.Equal(t, out.String(), ""+
+		"REMEDIATOR                   OBJECT                 REASON            ACTION   DECISION   DETAIL\n"+
+		"CrashLoopBackOffRescheduler  Pod/payments/api-x2x   CrashLoopBackOff  deleted  remediate  dry run\n"+
+		"OldPodDeleter                Pod/payments/worker-b  Old               evicted  remediate  dry run\n"+
+		"\n"+
+		"2 would be remediated, 3 skipped: 1 static Pod, 2 no owner\n")
+}
+
+func TestReportsSkippedPods(t *testing.T) {
+	var out bytes.Buffer
+	report := simulated(t).report()
+	assert.Equal(t, len(report.WouldRemediate), 2)
+	assert.Equal(t, report.Skipped[0].Object.Name, "debug")
+	assert.NilError(t, report.print(&out, true))
+	assert.Assert(t, bytes.Contains(out.Bytes(), []byte("OldPodDeleter                Pod/default/debug      Old               evicted  skip       no owner\n")), out.String())
+}
+
+func TestReportsNothingToRemediate(t *testing.T) {
+	var out bytes.Buffer
+	assert.NilError(t, (&simulation{}).report().print(&out, false))
+	assert.Equal(t, out.String(), "0 would be remediated, 0 skipped\n")
+}
+
+func TestRejectsUnknownSimulateOutput(t *testing.T) {
+	_, err := execute("simulate", "--config", "../../config/remediator.json", "-o", "yaml")
+	assert.Error(t, err, `invalid --output "yaml", use table or json`)
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// decisions about an unhealthy Pod or Node
+const (
+	DecisionRemediate = "remediate"
+	DecisionSkip      = "skip"
+)
+
+// what was looked at
+type ObjectRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// one decision, written as a line of JSON
+type Record struct {
+	Time        time.Time      `json:"time"`
+	Remediator  string         `json:"remediator"`
+	Cluster     string         `json:"cluster,omitempty"` // "" when there is only the one cluster
+	Object      ObjectRef      `json:"object"`
+	Reason      string         `json:"reason"`   // the detected problem
+	Action      string         `json:"action"`   // deleted, evicted or cordoned
+	Decision    string         `json:"decision"` // remediate or skip
+	Outcome     string         `json:"outcome"`  // success, error, skipped or dry-run
+	DryRun      bool           `json:"dryRun"`
+	Detail      string         `json:"detail,omitempty"`      // why it was skipped or the error
+	Logs        *ContainerLogs `json:"logs,omitempty"`        // evidence captured before acting
+	Diagnostics string         `json:"diagnostics,omitempty"` // where the diagnostics bundle was stored
+}
+
+// the end of the logs of the previous run of a failing container
+type ContainerLogs struct {
+	Container string `json:"container"`
+	Tail      string `json:"tail"`
+}
+
+// Log appends records to a file or stdout, separate from the operational logs on stderr,
+// a nil Log records nothing
+type Log struct {
+	lock   sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// "-" means stdout
+func Open(path string) (*Log, error) {
+	if path == "-" {
+		return NewLog(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{writer: file, closer: file}, nil
+}
+
+func NewLog(writer io.Writer) *Log {
+	return &Log{writer: writer}
+}
+
+// records without time get the current time
+func (l *Log) Record(record Record) error {
+	if l == nil {
+		return nil
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err // untested section
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, err = l.writer.Write(append(line, '\n'))
+	return err
+}
+
+func (l *Log) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
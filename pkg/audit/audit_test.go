@@ -0,0 +1,68 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritesOneJSONLinePerRecord(t *testing.T) {
+	var buffer bytes.Buffer
+	log := audit.NewLog(&buffer)
+	assert.NilError(t, log.Record(audit.Record{
+		Time:       time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC),
+		Remediator: "CrashLoopBackOffRescheduler",
+		Object:     audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "foo", UID: "123"},
+		Reason:     "CrashLoopBackOff",
+		Action:     "evicted",
+		Decision:   audit.DecisionRemediate,
+		Outcome:    "success",
+	}))
+	assert.NilError(t, log.Record(audit.Record{Decision: audit.DecisionSkip, Detail: "owner in cooldown"}))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.Equal(t, len(lines), 2)
+	assert.Equal(t, lines[0], `{"time":"2019-07-01T12:00:00Z","remediator":"CrashLoopBackOffRescheduler",`+
+		`"object":{"kind":"Pod","namespace":"default","name":"foo","uid":"123"},"reason":"CrashLoopBackOff",`+
+		`"action":"evicted","decision":"remediate","outcome":"success","dryRun":false}`)
+
+	var record audit.Record
+	assert.NilError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, record.Detail, "owner in cooldown")
+	assert.Assert(t, time.Since(record.Time) < time.Minute)
+}
+
+func TestAppendsToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	assert.NilError(t, ioutil.WriteFile(path, []byte("{}\n"), 0644))
+
+	log, err := audit.Open(path)
+	assert.NilError(t, err)
+	assert.NilError(t, log.Record(audit.Record{Decision: audit.DecisionSkip}))
+	assert.NilError(t, log.Close())
+
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, strings.Count(string(content), "\n"), 2)
+}
+
+func TestFailsToOpenMissingDirectory(t *testing.T) {
+	_, err := audit.Open("/missing/audit.log")
+	assert.ErrorContains(t, err, "no such file")
+}
+
+func TestNilLogRecordsNothing(t *testing.T) {
+	var log *audit.Log
+	assert.NilError(t, log.Record(audit.Record{}))
+	assert.NilError(t, log.Close())
+}
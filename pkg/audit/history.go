@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// History keeps the latest records in memory for the status API and counts all of them per remediator and cluster,
+// a nil History keeps nothing
+type History struct {
+	lock    sync.Mutex
+	records []Record // ring, next is the oldest once full
+	next    int
+	full    bool
+	stats   map[string]*Stats
+}
+
+// what a remediator decided since the start
+type Stats struct {
+	Outcomes   map[string]int `json:"outcomes"` // outcome -> records
+	LastRecord time.Time      `json:"lastRecord"`
+}
+
+// size is how many of the latest records are kept
+func NewHistory(size int) *History {
+	return &History{records: make([]Record, size), stats: map[string]*Stats{}}
+}
+
+// records without time get the current time
+func (h *History) Record(record Record) {
+	if h == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	key := record.Remediator
+	if record.Cluster != "" {
+		key = record.Cluster + "/" + key
+	}
+	stats, ok := h.stats[key]
+	if !ok {
+		stats = &Stats{Outcomes: map[string]int{}}
+		h.stats[key] = stats
+	}
+	stats.Outcomes[record.Outcome]++
+	stats.LastRecord = record.Time
+
+	if len(h.records) == 0 {
+		return
+	}
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// newest first, at most limit (0 means all kept), "" for records of all remediators
+func (h *History) Recent(remediator string, limit int) []Record {
+	recent := []Record{}
+	if h == nil {
+		return recent
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	kept := h.next
+	if h.full {
+		kept = len(h.records)
+	}
+	for i := 1; i <= kept && (limit <= 0 || len(recent) < limit); i++ {
+		record := h.records[(h.next-i+len(h.records))%len(h.records)]
+		if remediator == "" || record.Remediator == remediator {
+			recent = append(recent, record)
+		}
+	}
+	return recent
+}
+
+// remediator -> its stats, "cluster/remediator" when there are several clusters, a copy
+func (h *History) Stats() map[string]Stats {
+	all := map[string]Stats{}
+	if h == nil {
+		return all
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for remediator, stats := range h.stats {
+		outcomes := make(map[string]int, len(stats.Outcomes))
+		for outcome, count := range stats.Outcomes {
+			outcomes[outcome] = count
+		}
+		all[remediator] = Stats{Outcomes: outcomes, LastRecord: stats.LastRecord}
+	}
+	return all
+}
@@ -0,0 +1,51 @@
+package audit_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestHistoryKeepsLatestRecordsNewestFirst(t *testing.T) {
+	history := audit.NewHistory(3)
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Name: "a"}, Outcome: "success"})
+	history.Record(audit.Record{Remediator: "CrashLoopBackOffRescheduler", Object: audit.ObjectRef{Name: "b"}, Outcome: "error"})
+	assert.DeepEqual(t, names(history.Recent("", 0)), []string{"b", "a"})
+
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Name: "c"}, Outcome: "skipped"})
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Object: audit.ObjectRef{Name: "d"}, Outcome: "success"})
+	assert.DeepEqual(t, names(history.Recent("", 0)), []string{"d", "c", "b"})
+	assert.DeepEqual(t, names(history.Recent("", 2)), []string{"d", "c"})
+	assert.DeepEqual(t, names(history.Recent("OldPodDeleter", 0)), []string{"d", "c"})
+	assert.Assert(t, time.Since(history.Recent("", 1)[0].Time) < time.Minute)
+}
+
+func TestHistoryCountsAllRecords(t *testing.T) {
+	history := audit.NewHistory(1)
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "success"})
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "success"})
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Outcome: "dry-run"})
+	history.Record(audit.Record{Remediator: "OldPodDeleter", Cluster: "prod", Outcome: "error"})
+
+	stats := history.Stats()
+	assert.DeepEqual(t, stats["OldPodDeleter"].Outcomes, map[string]int{"success": 2, "dry-run": 1})
+	assert.DeepEqual(t, stats["prod/OldPodDeleter"].Outcomes, map[string]int{"error": 1})
+	assert.Assert(t, !stats["OldPodDeleter"].LastRecord.IsZero())
+	assert.Equal(t, len(history.Recent("", 0)), 1)
+}
+
+func TestNilHistoryKeepsNothing(t *testing.T) {
+	var history *audit.History
+	history.Record(audit.Record{Remediator: "OldPodDeleter"})
+	assert.Equal(t, len(history.Recent("", 0)), 0)
+	assert.Equal(t, len(history.Stats()), 0)
+}
+
+func names(records []audit.Record) []string {
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Object.Name)
+	}
+	return names
+}
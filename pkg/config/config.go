@@ -0,0 +1,705 @@
+package config
+
+import (
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DetectionInformer = "informer"
+	DetectionEvents   = "events"
+)
+
+// formats the config file can be in, picked by its extension
+var Extensions = []string{"json", "yaml", "yml", "toml"}
+
+// environment variables with this prefix override the config file, see EnvName
+const EnvPrefix = "KUBE_REMEDIATOR_"
+
+type HTTPConfig struct {
+	Port         int                `mapstructure:"port"`  // serves /healthz and /metrics
+	Debug        bool               `mapstructure:"debug"` // also serve /debug/pprof and /debug/state
+	API          APIConfig          `mapstructure:"api"`
+	Admin        AdminConfig        `mapstructure:"admin"`
+	Alertmanager AlertmanagerConfig `mapstructure:"alertmanager"` // webhooks for the AlertmanagerRemediator
+}
+
+// read-only JSON on /api/v1 for dashboards and chatops bots
+type APIConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	RecentActions int  `mapstructure:"recentActions"` // latest decisions kept in memory
+}
+
+// POST /api/v1/remediators/<name>/pause, /resume and /scan for runbooks and chatops bots, paused remediators are
+// kept in a ConfigMap so they stay paused after a restart
+type AdminConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Token     string `mapstructure:"token"` // callers send "Authorization: Bearer <token>", required
+	Namespace string `mapstructure:"namespace"`
+	ConfigMap string `mapstructure:"configMap"`
+}
+
+// POST /api/v1/alerts, the url of a webhook_config of Alertmanager
+type AlertmanagerConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"` // Alertmanager sends "Authorization: Bearer <token>" with http_config.authorization, required
+}
+
+// gRPC service to pause and resume remediators, start scans, flip dry runs and list the latest decisions
+type GRPCConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Port    int    `mapstructure:"port"`
+	Token   string `mapstructure:"token"` // callers send "authorization: Bearer <token>", required
+}
+
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"` // "-" means stdout
+}
+
+// where metrics go, Prometheus, StatsD or both
+type MetricsConfig struct {
+	Prometheus bool                 `mapstructure:"prometheus"` // serve /metrics
+	StatsD     metrics.StatsDConfig `mapstructure:"statsd"`
+	Push       metrics.PushConfig   `mapstructure:"push"` // at exit, for runs that end before they are scraped
+}
+
+// spans are exported as OTLP/HTTP JSON, e.g. to an OpenTelemetry collector
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Endpoint    string            `mapstructure:"endpoint"`
+	Headers     map[string]string `mapstructure:"headers"` // e.g. for authentication
+	ServiceName string            `mapstructure:"serviceName"`
+	Interval    time.Duration     `mapstructure:"interval"` // how often finished spans are exported
+}
+
+// limits of the api-server clients, every component has its own client with its own QPS and burst
+type ClientConfig struct {
+	QPS      float32       `mapstructure:"qps"`      // sustained requests per second
+	Burst    int           `mapstructure:"burst"`    // requests allowed at once above qps
+	Timeout  time.Duration `mapstructure:"timeout"`  // of each call, 0 means none
+	PageSize int64         `mapstructure:"pageSize"` // Pods per list request when scanning, 0 means all at once
+	// "protobuf" for built-in kinds, falling back to JSON for custom resources, or "json" only
+	ContentType string `mapstructure:"contentType"`
+}
+
+// a cluster to remediate besides or instead of the own one, all clusters share the rest of the config
+type ClusterConfig struct {
+	Name       string `mapstructure:"name"`       // the cluster label of logs, metrics, audit records and notifications
+	Kubeconfig string `mapstructure:"kubeconfig"` // "" means the --kubeconfig flag, $KUBECONFIG or ~/.kube/config
+	Context    string `mapstructure:"context"`    // "" means the current context of the kubeconfig
+}
+
+// who kube-remediator is to the api-server, so its actions are attributed to an audited identity
+type IdentityConfig struct {
+	TokenFile      string   `mapstructure:"tokenFile"`      // a token of another identity, like a projected service account token
+	User           string   `mapstructure:"user"`           // impersonated by every call, "" means none
+	Groups         []string `mapstructure:"groups"`         // of the impersonated user
+	ServiceAccount string   `mapstructure:"serviceAccount"` // Pods and owners are changed as this service account of their namespace
+}
+
+// where humans are told about actions and failures
+type NotificationsConfig struct {
+	Slack     notify.SlackConfig     `mapstructure:"slack"`
+	PagerDuty notify.PagerDutyConfig `mapstructure:"pagerDuty"`
+	Webhook   notify.WebhookConfig   `mapstructure:"webhook"`
+	Email     notify.EmailConfig     `mapstructure:"email"`
+}
+
+type ShutdownConfig struct {
+	// after SIGTERM, for remediations that already started and the notifications about them, 0 means none
+	DrainTimeout time.Duration `mapstructure:"drainTimeout"`
+}
+
+// fails readiness and notifies when a remediator made no progress for multiple times its interval
+type WatchdogConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Multiple float64       `mapstructure:"multiple"` // of the interval of a remediator, at least 1
+	Interval time.Duration `mapstructure:"interval"` // between checks for notifications, readiness probes check themselves
+}
+
+type RateLimitConfig struct {
+	Max      int           `mapstructure:"max"` // 0 means unlimited
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type KillSwitchConfig struct {
+	Namespace string `mapstructure:"namespace"`
+	ConfigMap string `mapstructure:"configMap"` // "" means no kill switch
+	Key       string `mapstructure:"key"`
+}
+
+type ApprovalConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	RequestAnnotation string        `mapstructure:"requestAnnotation"`
+	ApproveAnnotation string        `mapstructure:"approveAnnotation"`
+	Expiry            time.Duration `mapstructure:"expiry"`
+}
+
+type BackoffConfig struct {
+	Initial    time.Duration `mapstructure:"initial"` // 0 means no backoff
+	Factor     float64       `mapstructure:"factor"`
+	Max        time.Duration `mapstructure:"max"`
+	ResetAfter time.Duration `mapstructure:"resetAfter"`
+}
+
+type RemediationPoliciesConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	AdminNamespace string `mapstructure:"adminNamespace"` // policies in it can target any namespace
+}
+
+// annotations on the owner of a remediated Pod, so whoever investigates a restart sees who did it
+type OwnerAnnotationsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Prefix  string `mapstructure:"prefix"`
+}
+
+// the end of the previous logs of the failing container, kept with the record of a deleted or evicted Pod
+type CaptureLogsConfig struct {
+	Enabled bool  `mapstructure:"enabled"`
+	Lines   int64 `mapstructure:"lines"`
+}
+
+// the most recent Warning events of a deleted or evicted Pod, sent along with the notification
+type CaptureEventsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Count   int  `mapstructure:"count"`
+}
+
+type NamespaceAnnotationsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Prefix  string `mapstructure:"prefix"`
+}
+
+// settings of each remediator, nested under its name
+type RemediatorsConfig = remediator.Configs
+
+// Everything that can be configured, global settings apply to all remediators
+type Config struct {
+	Detection                   string                               `mapstructure:"detection"`
+	HTTP                        HTTPConfig                           `mapstructure:"http"`
+	GRPC                        GRPCConfig                           `mapstructure:"grpc"`
+	Audit                       AuditConfig                          `mapstructure:"audit"`
+	Metrics                     MetricsConfig                        `mapstructure:"metrics"`
+	Notifications               NotificationsConfig                  `mapstructure:"notifications"`
+	Report                      notify.ReportConfig                  `mapstructure:"report"`
+	Tracing                     TracingConfig                        `mapstructure:"tracing"`
+	Log                         LogConfig                            `mapstructure:"log"`
+	Client                      ClientConfig                         `mapstructure:"client"`
+	Shutdown                    ShutdownConfig                       `mapstructure:"shutdown"`
+	Watchdog                    WatchdogConfig                       `mapstructure:"watchdog"`
+	Clusters                    []ClusterConfig                      `mapstructure:"clusters"`
+	ClustersDirectory           string                               `mapstructure:"clustersDirectory"` // a kubeconfig per cluster, named after the file
+	Identity                    IdentityConfig                       `mapstructure:"identity"`
+	DryRun                      bool                                 `mapstructure:"dryRun"`
+	ServerSideDryRun            bool                                 `mapstructure:"serverSideDryRun"` // dry runs are sent to the api-server
+	NamespaceOverrides          []remediator.NamespaceOverrideConfig `mapstructure:"namespaceOverrides"`
+	NamespaceAnnotations        NamespaceAnnotationsConfig           `mapstructure:"namespaceAnnotations"`
+	MinPodAge                   time.Duration                        `mapstructure:"minPodAge"`
+	DeleteAfterBlockedEvictions int                                  `mapstructure:"deleteAfterBlockedEvictions"`
+	SkipDrainingNodes           bool                                 `mapstructure:"skipDrainingNodes"`
+	SlimCaches                  bool                                 `mapstructure:"slimCaches"`
+	PreconditionResourceVersion bool                                 `mapstructure:"preconditionResourceVersion"`
+	ConfirmBeforeAction         bool                                 `mapstructure:"confirmBeforeAction"`
+	Workers                     int                                  `mapstructure:"workers"` // handle the Pods informers or the event stream noticed
+	RateLimit                   RateLimitConfig                      `mapstructure:"rateLimit"`
+	OwnerCooldown               time.Duration                        `mapstructure:"ownerCooldown"`
+	MaxUnavailablePerOwner      string                               `mapstructure:"maxUnavailablePerOwner"` // "1" or "25%", "0" means unlimited
+	MinReadyReplicas            int                                  `mapstructure:"minReadyReplicas"`
+	MaxAttemptsPerOwner         int                                  `mapstructure:"maxAttemptsPerOwner"`
+	AttemptsAnnotation          string                               `mapstructure:"attemptsAnnotation"`
+	OwnerAnnotations            OwnerAnnotationsConfig               `mapstructure:"ownerAnnotations"`
+	CaptureLogs                 CaptureLogsConfig                    `mapstructure:"captureLogs"`
+	CaptureEvents               CaptureEventsConfig                  `mapstructure:"captureEvents"`
+	Diagnostics                 diagnostics.Config                   `mapstructure:"diagnostics"`
+	KillSwitch                  KillSwitchConfig                     `mapstructure:"killSwitch"`
+	LeaderElection              remediator.LeaderElectionConfig      `mapstructure:"leaderElection"`
+	Approval                    ApprovalConfig                       `mapstructure:"approval"`
+	Backoff                     BackoffConfig                        `mapstructure:"backoff"`
+	IncludeNamespaces           []string                             `mapstructure:"includeNamespaces"`
+	ExcludeNamespaces           []string                             `mapstructure:"excludeNamespaces"`
+	LabelSelector               string                               `mapstructure:"labelSelector"`
+	ExcludePriorityClasses      []string                             `mapstructure:"excludePriorityClasses"`
+	OwnerKinds                  []string                             `mapstructure:"ownerKinds"`
+	OptMode                     string                               `mapstructure:"optMode"`
+	OptInAnnotation             string                               `mapstructure:"optInAnnotation"`
+	OptOutAnnotation            string                               `mapstructure:"optOutAnnotation"`
+	Maintenance                 remediator.MaintenanceConfig         `mapstructure:"maintenance"`
+	Observation                 remediator.ObservationConfig         `mapstructure:"observation"`
+	Actions                     map[string]string                    `mapstructure:"actions"` // by reason or remediator
+	Filters                     remediator.FiltersConfig             `mapstructure:"filters"` // by remediator
+	Deletion                    remediator.DeletionConfig            `mapstructure:"deletion"`
+	Hooks                       remediator.HooksConfig               `mapstructure:"hooks"` // around each action
+	Reconcile                   remediator.ReconcileConfig           `mapstructure:"reconcile"`
+	RemediationPolicies         RemediationPoliciesConfig            `mapstructure:"remediationPolicies"`
+	Remediators                 RemediatorsConfig                    `mapstructure:"remediators"`
+}
+
+// used for everything that is not in the config file
+func Default() Config {
+	return Config{
+		Detection: DetectionInformer,
+		HTTP: HTTPConfig{
+			Port:  8080,
+			API:   APIConfig{RecentActions: 100},
+			Admin: AdminConfig{Namespace: "default", ConfigMap: "kube-remediator-paused"},
+		},
+		GRPC:    GRPCConfig{Port: 9090},
+		Audit:   AuditConfig{Path: "-"},
+		Metrics: MetricsConfig{Prometheus: true, StatsD: metrics.DefaultStatsDConfig(), Push: metrics.DefaultPushConfig()},
+		Notifications: NotificationsConfig{
+			Slack:     notify.DefaultSlackConfig(),
+			PagerDuty: notify.DefaultPagerDutyConfig(),
+			Webhook:   notify.DefaultWebhookConfig(),
+			Email:     notify.DefaultEmailConfig(),
+		},
+		Report: notify.DefaultReportConfig(),
+		Tracing: TracingConfig{
+			Endpoint:    "http://localhost:4318/v1/traces",
+			Headers:     map[string]string{},
+			ServiceName: "kube-remediator",
+			Interval:    5 * time.Second,
+		},
+		Log:                    LogConfig{Level: "info", Format: LogFormatJSON, Sampling: true},
+		Client:                 ClientConfig{QPS: 20, Burst: 50, Timeout: 30 * time.Second, PageSize: 500, ContentType: k8s.ContentTypeProtobuf},
+		Shutdown:               ShutdownConfig{DrainTimeout: 25 * time.Second}, // within the 30s Kubernetes waits before SIGKILL
+		Watchdog:               WatchdogConfig{Enabled: true, Multiple: 3, Interval: time.Minute},
+		SkipDrainingNodes:      true,
+		Workers:                2,
+		RateLimit:              RateLimitConfig{Interval: 5 * time.Minute},
+		MaxUnavailablePerOwner: "0",
+		AttemptsAnnotation:     "kube-remediator/remediations",
+		OwnerAnnotations:       OwnerAnnotationsConfig{Prefix: "kube-remediator/"},
+		CaptureLogs:            CaptureLogsConfig{Lines: 50},
+		CaptureEvents:          CaptureEventsConfig{Count: 5},
+		Diagnostics:            diagnostics.DefaultConfig(),
+		KillSwitch: KillSwitchConfig{
+			Namespace: "default",
+			ConfigMap: "kube-remediator-killswitch",
+			Key:       "paused",
+		},
+		LeaderElection: remediator.DefaultLeaderElectionConfig(),
+		Approval: ApprovalConfig{
+			RequestAnnotation: "kube-remediator/approval-requested",
+			ApproveAnnotation: "kube-remediator/approved",
+			Expiry:            24 * time.Hour,
+		},
+		Backoff: BackoffConfig{
+			Factor:     5,
+			Max:        2 * time.Hour,
+			ResetAfter: 15 * time.Minute,
+		},
+		ExcludePriorityClasses: []string{"system-cluster-critical", "system-node-critical"},
+		OptMode:                remediator.OptOut,
+		OptInAnnotation:        "kube-remediator/enable",
+		OptOutAnnotation:       "kube-remediator/disable",
+		NamespaceAnnotations:   NamespaceAnnotationsConfig{Prefix: "kube-remediator/"},
+		Actions:                map[string]string{},
+		Filters:                remediator.FiltersConfig{},
+		Hooks:                  remediator.HooksConfig{Pre: []remediator.HookConfig{}, Post: []remediator.HookConfig{}},
+		RemediationPolicies:    RemediationPoliciesConfig{AdminNamespace: "default"},
+		Remediators: RemediatorsConfig{
+			AlertmanagerRemediator:      remediator.DefaultAlertmanagerConfig(),
+			CrashLoopBackOffRescheduler: remediator.DefaultCrashLoopBackOffConfig(),
+			NodeProblemRemediator:       remediator.DefaultNodeProblemConfig(),
+			RuleRemediator:              remediator.DefaultRulesConfig(),
+		},
+	}
+}
+
+// first existing config file, base is its path without extension
+func Find(base string) (string, error) {
+	for _, extension := range Extensions {
+		file := base + "." + extension
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no config file %s.{%s}", base, strings.Join(Extensions, ","))
+}
+
+// read the config file on top of the defaults and validate it
+func Load(file string) (*Config, error) {
+	extension := strings.TrimPrefix(filepath.Ext(file), ".")
+	supported := false
+	for _, known := range Extensions {
+		supported = supported || extension == known
+	}
+	if !supported {
+		return nil, fmt.Errorf("unsupported config format %q of %s, use one of %v", extension, file, Extensions)
+	}
+
+	settings := viper.New()
+	settings.SetConfigFile(file)
+	if err := settings.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	// only the file, environment variables are all strings and checked when decoding
+	if problems := JSONSchema().Validate(settings.AllSettings()); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid %s: %s", file, strings.Join(problems, "; "))
+	}
+	bindEnv(settings, "", reflect.TypeOf(Config{}))
+
+	config := Default()
+	// an empty list in the file is not decoded at all, so it would keep the default
+	if settings.IsSet("excludePriorityClasses") {
+		config.ExcludePriorityClasses = nil
+	}
+	// lists and maps in the file replace the defaults instead of being merged into them
+	decoding := func(decoderConfig *mapstructure.DecoderConfig) {
+		decoderConfig.ZeroFields = true
+		decoderConfig.DecodeHook = mapstructure.ComposeDecodeHookFunc(durationHook, mapstructure.StringToSliceHookFunc(","))
+	}
+	if err := settings.Unmarshal(&config, decoding); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", file, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", file, err)
+	}
+	return &config, nil
+}
+
+// like mapstructure.StringToTimeDurationHookFunc, but saying what a duration looks like
+func durationHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	duration, err := time.ParseDuration(data.(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q, use a number with a unit like \"90s\", \"10m\" or \"1h30m\"", data)
+	}
+	return duration, nil
+}
+
+// environment variable that overrides a key: rateLimit.max -> KUBE_REMEDIATOR_RATE_LIMIT_MAX
+func EnvName(key string) string {
+	name := EnvPrefix
+	for _, r := range key {
+		switch {
+		case r == '.':
+			name += "_"
+		case unicode.IsUpper(r):
+			name += "_" + string(r)
+		default:
+			name += string(unicode.ToUpper(r))
+		}
+	}
+	return name
+}
+
+// every key that can be set from a string: numbers, booleans, durations, strings and comma separated lists of strings,
+// maps and lists of objects can only be set in the file
+func bindEnv(settings *viper.Viper, key string, typ reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := strings.Split(field.Tag.Get("mapstructure"), ",")[0] // "" when squashed
+			fieldKey := key
+			if name != "" {
+				fieldKey = strings.TrimPrefix(key+"."+name, ".")
+			}
+			bindEnv(settings, fieldKey, field.Type)
+		}
+	case reflect.Map: // only in the file
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.String {
+			settings.BindEnv(key, EnvName(key))
+		}
+	case reflect.Ptr:
+		bindEnv(settings, key, typ.Elem())
+	default:
+		settings.BindEnv(key, EnvName(key))
+	}
+}
+
+// catch mistakes that would otherwise only show up as odd behavior at runtime
+func (c *Config) Validate() error {
+	if c.Detection != DetectionInformer && c.Detection != DetectionEvents {
+		return fmt.Errorf("unknown detection %q, use %q or %q", c.Detection, DetectionInformer, DetectionEvents)
+	}
+	if c.HTTP.Port < 1 || c.HTTP.Port > 65535 {
+		return fmt.Errorf("http.port must be between 1 and 65535, got %d", c.HTTP.Port)
+	}
+	durations := map[string]time.Duration{
+		"minPodAge":             c.MinPodAge,
+		"ownerCooldown":         c.OwnerCooldown,
+		"backoff.initial":       c.Backoff.Initial,
+		"observation.period":    c.Observation.Period,
+		"shutdown.drainTimeout": c.Shutdown.DrainTimeout,
+	}
+	for key, duration := range durations {
+		if duration < 0 {
+			return fmt.Errorf("%s must not be negative, got %v", key, duration)
+		}
+	}
+	counts := map[string]int{
+		"deleteAfterBlockedEvictions": c.DeleteAfterBlockedEvictions,
+		"rateLimit.max":               c.RateLimit.Max,
+		"minReadyReplicas":            c.MinReadyReplicas,
+		"maxAttemptsPerOwner":         c.MaxAttemptsPerOwner,
+	}
+	for key, count := range counts {
+		if count < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", key, count)
+		}
+	}
+	if c.RateLimit.Max > 0 && c.RateLimit.Interval <= 0 {
+		return fmt.Errorf("rateLimit.interval must be positive, got %v", c.RateLimit.Interval)
+	}
+	if c.Backoff.Initial > 0 && c.Backoff.Factor < 1 {
+		return fmt.Errorf("backoff.factor must be at least 1, got %v", c.Backoff.Factor)
+	}
+	if c.Watchdog.Enabled && (c.Watchdog.Multiple < 1 || c.Watchdog.Interval <= 0) {
+		return fmt.Errorf("watchdog.multiple must be at least 1 and watchdog.interval positive, got %v and %v", c.Watchdog.Multiple, c.Watchdog.Interval)
+	}
+	if c.Approval.Enabled && c.Approval.Expiry <= 0 {
+		return fmt.Errorf("approval.expiry must be positive, got %v", c.Approval.Expiry)
+	}
+	if c.MaxAttemptsPerOwner > 0 && c.AttemptsAnnotation == "" {
+		return fmt.Errorf("attemptsAnnotation is required when maxAttemptsPerOwner is set")
+	}
+	if c.OwnerAnnotations.Enabled && c.OwnerAnnotations.Prefix == "" {
+		return fmt.Errorf("ownerAnnotations.prefix is required when ownerAnnotations is enabled")
+	}
+	if c.CaptureLogs.Enabled && c.CaptureLogs.Lines <= 0 {
+		return fmt.Errorf("captureLogs.lines must be positive, got %d", c.CaptureLogs.Lines)
+	}
+	if c.CaptureEvents.Enabled && c.CaptureEvents.Count <= 0 {
+		return fmt.Errorf("captureEvents.count must be positive, got %d", c.CaptureEvents.Count)
+	}
+	if c.Audit.Enabled && c.Audit.Path == "" {
+		return fmt.Errorf("audit.path is required when audit is enabled, use \"-\" for stdout")
+	}
+	if c.GRPC.Enabled && (c.GRPC.Port <= 0 || c.GRPC.Port == c.HTTP.Port) {
+		return fmt.Errorf("grpc.port must be positive and differ from http.port, got %d", c.GRPC.Port)
+	}
+	// it listens on every interface and can turn the dry run off
+	if c.GRPC.Enabled && c.GRPC.Token == "" {
+		return fmt.Errorf("grpc.token is required when the Control API is enabled")
+	}
+	if c.HTTP.Admin.Enabled && (c.HTTP.Admin.Token == "" || c.HTTP.Admin.Namespace == "" || c.HTTP.Admin.ConfigMap == "") {
+		return fmt.Errorf("http.admin.token, http.admin.namespace and http.admin.configMap are required when the admin API is enabled")
+	}
+	if c.HTTP.Alertmanager.Enabled && c.HTTP.Alertmanager.Token == "" {
+		return fmt.Errorf("http.alertmanager.token is required when the Alertmanager receiver is enabled")
+	}
+	if (c.HTTP.API.Enabled || c.GRPC.Enabled) && c.HTTP.API.RecentActions <= 0 {
+		return fmt.Errorf("http.api.recentActions must be positive, got %d", c.HTTP.API.RecentActions)
+	}
+	if err := c.Metrics.StatsD.Validate(); err != nil {
+		return err
+	}
+	if err := c.Metrics.Push.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Slack.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.PagerDuty.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Webhook.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Email.Validate(); err != nil {
+		return err
+	}
+	if err := c.Report.Validate(); err != nil {
+		return err
+	}
+	if err := c.Diagnostics.Validate(); err != nil {
+		return err
+	}
+	if err := c.LeaderElection.Validate(); err != nil {
+		return err
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
+	if c.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", c.Workers)
+	}
+	if c.Client.QPS <= 0 {
+		return fmt.Errorf("client.qps must be positive, got %v", c.Client.QPS)
+	}
+	if c.Client.Burst < 1 {
+		return fmt.Errorf("client.burst must be at least 1, got %d", c.Client.Burst)
+	}
+	if c.Client.ContentType != k8s.ContentTypeProtobuf && c.Client.ContentType != k8s.ContentTypeJSON {
+		return fmt.Errorf("client.contentType must be %q or %q, got %q", k8s.ContentTypeProtobuf, k8s.ContentTypeJSON, c.Client.ContentType)
+	}
+	if c.Client.PageSize < 0 {
+		return fmt.Errorf("client.pageSize must not be negative, got %d", c.Client.PageSize)
+	}
+	if c.Identity.User == "" && len(c.Identity.Groups) > 0 {
+		return fmt.Errorf("identity.user is required when identity.groups are set")
+	}
+	if c.Identity.ServiceAccount != "" {
+		if problems := validation.IsDNS1123Subdomain(c.Identity.ServiceAccount); len(problems) > 0 {
+			return fmt.Errorf("identity.serviceAccount %q: %s", c.Identity.ServiceAccount, strings.Join(problems, ", "))
+		}
+	}
+	names := map[string]bool{}
+	for i, cluster := range c.Clusters {
+		if cluster.Name == "" || strings.Contains(cluster.Name, "/") {
+			return fmt.Errorf("clusters[%d].name must not be empty or contain /, got %q", i, cluster.Name)
+		}
+		if names[cluster.Name] {
+			return fmt.Errorf("clusters[%d].name %q is used twice", i, cluster.Name)
+		}
+		names[cluster.Name] = true
+	}
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		return fmt.Errorf("tracing.endpoint is required when tracing is enabled")
+	}
+	if c.Tracing.Enabled && c.Tracing.Interval <= 0 {
+		return fmt.Errorf("tracing.interval must be positive, got %v", c.Tracing.Interval)
+	}
+	if c.NamespaceAnnotations.Enabled && c.NamespaceAnnotations.Prefix == "" {
+		return fmt.Errorf("namespaceAnnotations.prefix is required when namespaceAnnotations are enabled")
+	}
+	if c.RemediationPolicies.Enabled && c.RemediationPolicies.AdminNamespace == "" {
+		return fmt.Errorf("remediationPolicies.adminNamespace is required when remediationPolicies are enabled")
+	}
+	if err := (&remediator.Policy{OptMode: c.OptMode, Actions: c.Actions}).Validate(); err != nil {
+		return err
+	}
+	if _, err := remediator.NewNamespaceFilter(c.IncludeNamespaces, c.ExcludeNamespaces); err != nil {
+		return err
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("labelSelector: %v", err)
+	}
+	if c.MaxUnavailablePerOwner != "0" && c.MaxUnavailablePerOwner != "" {
+		if _, err := remediator.NewUnavailableLimit(c.MaxUnavailablePerOwner); err != nil {
+			return err
+		}
+	}
+	if _, err := remediator.NewNamespaceOverrides(c.NamespaceOverrides); err != nil {
+		return err
+	}
+	if err := c.Reconcile.Validate(); err != nil {
+		return err
+	}
+	if err := c.Filters.Validate(); err != nil {
+		return err
+	}
+	if err := c.Hooks.Validate(); err != nil {
+		return err
+	}
+	for name, filter := range c.Filters {
+		if !isRemediator(name) {
+			return fmt.Errorf("filters: unknown remediator %q, use %s", name, strings.Join(remediator.Names(), ", "))
+		}
+		if filter.NodeSelector != "" && !c.SkipDrainingNodes {
+			return fmt.Errorf("filters.%s.nodeSelector needs skipDrainingNodes, which caches the Nodes", name)
+		}
+	}
+	for _, name := range c.Remediators.Enabled {
+		if !isRemediator(name) {
+			return fmt.Errorf("remediators.enabled: unknown remediator %q, use %s", name, strings.Join(remediator.Names(), ", "))
+		}
+	}
+	overrides := map[string][]string{}
+	for name := range c.Maintenance.Remediators {
+		overrides["maintenance.remediators"] = append(overrides["maintenance.remediators"], name)
+	}
+	for name := range c.Observation.Remediators {
+		overrides["observation.remediators"] = append(overrides["observation.remediators"], name)
+	}
+	for name := range c.Deletion.Remediators {
+		overrides["deletion.remediators"] = append(overrides["deletion.remediators"], name)
+	}
+	for name := range c.Reconcile.Remediators {
+		overrides["reconcile.remediators"] = append(overrides["reconcile.remediators"], name)
+	}
+	for key, names := range overrides {
+		for _, name := range names {
+			if !isRemediator(name) {
+				return fmt.Errorf("%s: unknown remediator %q, use %s", key, name, strings.Join(remediator.Names(), ", "))
+			}
+		}
+	}
+	// what starting each remediator builds, so a reload never gets that far with a config it can not start
+	for _, name := range remediator.Names() {
+		if _, err := c.Maintenance.Build(name); err != nil {
+			return fmt.Errorf("maintenance: %v", err)
+		}
+		if _, err := c.Deletion.Build(name); err != nil {
+			return fmt.Errorf("deletion: %v", err)
+		}
+		// the Nodes are only looked up when filtering
+		if _, err := c.Filters.Build(name, &k8s.NodeCache{}); err != nil {
+			return err
+		}
+	}
+	if err := c.Remediators.AlertmanagerRemediator.Validate(); err != nil {
+		return fmt.Errorf("remediators.alertmanagerRemediator: %v", err)
+	}
+	if err := c.Remediators.CrashLoopBackOffRescheduler.Validate(); err != nil {
+		return fmt.Errorf("remediators.crashLoopBackOffRescheduler: %v", err)
+	}
+	if err := c.Remediators.NodeProblemRemediator.Validate(); err != nil {
+		return fmt.Errorf("remediators.nodeProblemRemediator: %v", err)
+	}
+	if err := c.Remediators.RuleRemediator.Validate(); err != nil {
+		return fmt.Errorf("remediators.ruleRemediator: %v", err)
+	}
+	return nil
+}
+
+// matched case-insensitive like --remediators
+func isRemediator(name string) bool {
+	for _, known := range remediator.Names() {
+		if strings.EqualFold(known, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// the clusters followed by one per file in clustersDirectory, which uses the current context of the file,
+// empty means only the own cluster or the one of the --kubeconfig flag
+func (c *Config) ListClusters() ([]ClusterConfig, error) {
+	clusters := append([]ClusterConfig{}, c.Clusters...)
+	if c.ClustersDirectory == "" {
+		return clusters, nil
+	}
+	files, err := ioutil.ReadDir(c.ClustersDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("clustersDirectory: %v", err)
+	}
+	names := map[string]bool{}
+	for _, cluster := range clusters {
+		names[cluster.Name] = true
+	}
+	for _, file := range files {
+		// mounted Secrets and ConfigMaps keep their data in hidden directories
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		if names[name] {
+			return nil, fmt.Errorf("clustersDirectory: cluster %q is configured twice", name)
+		}
+		names[name] = true
+		clusters = append(clusters, ClusterConfig{Name: name, Kubeconfig: filepath.Join(c.ClustersDirectory, file.Name())})
+	}
+	return clusters, nil
+}
@@ -0,0 +1,370 @@
+package config_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"go.uber.org/zap/zapcore"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, content string) (*config.Config, error) {
+	return loadAs(t, "json", content)
+}
+
+func loadAs(t *testing.T, extension string, content string) (*config.Config, error) {
+	file, err := ioutil.TempFile("", "remediator*."+extension)
+	assert.NilError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(content)
+	assert.NilError(t, err)
+	assert.NilError(t, file.Close())
+	return config.Load(file.Name())
+}
+
+func TestLoadsShippedConfig(t *testing.T) {
+	settings, err := config.Load("../../config/remediator.json")
+	assert.NilError(t, err)
+	assert.Equal(t, settings.Detection, config.DetectionInformer)
+	assert.Equal(t, settings.RateLimit.Interval, 5*time.Minute)
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.FailureThreshold, int32(5))
+	assert.DeepEqual(t, settings.Remediators.NodeProblemRemediator.Conditions["kerneldeadlock"], []string{"cordon", "reschedule"})
+}
+
+func TestUsesDefaultsForMissingSettings(t *testing.T) {
+	settings, err := load(t, `{"minPodAge": "10m", "remediators": {"crashLoopBackOffRescheduler": {"failureThreshold": 3}}}`)
+	assert.NilError(t, err)
+	assert.Equal(t, settings.MinPodAge, 10*time.Minute)
+	assert.Equal(t, settings.SkipDrainingNodes, true)
+	assert.Equal(t, settings.KillSwitch.ConfigMap, "kube-remediator-killswitch")
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.FailureThreshold, int32(3))
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.Annotation, "kube-remediator/CrashLoopBackOffRemediator")
+	assert.Equal(t, len(settings.Remediators.NodeProblemRemediator.Conditions), 3)
+}
+
+func TestListsReplaceDefaults(t *testing.T) {
+	settings, err := load(t, `{"excludePriorityClasses": ["batch-low"]}`)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, settings.ExcludePriorityClasses, []string{"batch-low"})
+
+	settings, err = load(t, `{"excludePriorityClasses": []}`)
+	assert.NilError(t, err)
+	assert.Equal(t, len(settings.ExcludePriorityClasses), 0)
+}
+
+func TestRejectsInvalidSettings(t *testing.T) {
+	_, err := load(t, `{"detection": "polling"}`)
+	assert.ErrorContains(t, err, "unknown detection")
+
+	_, err = load(t, `{"minPodAge": "-1m"}`)
+	assert.ErrorContains(t, err, "minPodAge must not be negative")
+
+	_, err = load(t, `{"shutdown": {"drainTimeout": "-1s"}}`)
+	assert.ErrorContains(t, err, "shutdown.drainTimeout must not be negative")
+
+	_, err = load(t, `{"remediators": {"ruleRemediator": {"rules": [{"name": "Evicted", "expression": "status.reason =="}]}}}`)
+	assert.ErrorContains(t, err, "remediators.ruleRemediator: rules[0]: Evicted: ERROR")
+
+	_, err = load(t, `{"hooks": {"pre": [{"name": "snapshot", "url": "http://localhost", "container": "snapshotter"}]}}`)
+	assert.ErrorContains(t, err, "hooks.pre[0]: set either url or container and command")
+
+	_, err = load(t, `{"grpc": {"enabled": true}}`)
+	assert.ErrorContains(t, err, "grpc.token is required")
+
+	_, err = load(t, `{"http": {"alertmanager": {"enabled": true}}}`)
+	assert.ErrorContains(t, err, "http.alertmanager.token is required")
+
+	_, err = load(t, `{"remediators": {"alertmanagerRemediator": {"alerts": [{"alert": "KubePodCrashLooping", "action": "reboot"}]}}}`)
+	assert.ErrorContains(t, err, `remediators.alertmanagerRemediator: alerts[0]: unknown action "reboot"`)
+
+	_, err = load(t, `{"maintenance": {"timeZone": "Mars/Olympus_Mons"}}`)
+	assert.ErrorContains(t, err, "maintenance: unknown time zone Mars/Olympus_Mons")
+
+	_, err = load(t, `{"maintenance": {"remediators": {"oldPodDeleter": {"windows": [{"start": "25:00", "end": "06:00"}]}}}}`)
+	assert.ErrorContains(t, err, `maintenance: invalid time of day "25:00"`)
+
+	_, err = load(t, `{"maintenance": {"remediators": {"podDeleter": {"timeZone": "UTC"}}}}`)
+	assert.ErrorContains(t, err, `maintenance.remediators: unknown remediator "poddeleter"`)
+
+	_, err = load(t, `{"observation": {"remediators": {"podDeleter": "1h"}}}`)
+	assert.ErrorContains(t, err, `observation.remediators: unknown remediator "poddeleter"`)
+
+	_, err = load(t, `{"deletion": {"propagationPolicy": "Cascade"}}`)
+	assert.ErrorContains(t, err, `deletion: unknown propagationPolicy "Cascade"`)
+
+	_, err = load(t, `{"watchdog": {"multiple": 0.5}}`)
+	assert.ErrorContains(t, err, "watchdog.multiple must be at least 1")
+
+	_, err = load(t, `{"audit": {"enabled": true, "path": ""}}`)
+	assert.ErrorContains(t, err, "audit.path is required")
+
+	_, err = load(t, `{"tracing": {"enabled": true, "endpoint": ""}}`)
+	assert.ErrorContains(t, err, "tracing.endpoint is required")
+
+	_, err = load(t, `{"tracing": {"enabled": true, "interval": "0s"}}`)
+	assert.ErrorContains(t, err, "tracing.interval must be positive")
+
+	_, err = load(t, `{"metrics": {"statsd": {"enabled": true, "address": "localhost"}}}`)
+	assert.ErrorContains(t, err, "metrics.statsd.address")
+
+	_, err = load(t, `{"notifications": {"slack": {"enabled": true, "webhookURL": "http://localhost", "route": {"minSeverity": "fatal"}}}}`)
+	assert.ErrorContains(t, err, "notifications.slack.route.minSeverity must be one of")
+
+	_, err = load(t, `{"ownerAnnotations": {"enabled": true, "prefix": ""}}`)
+	assert.ErrorContains(t, err, "ownerAnnotations.prefix is required")
+
+	_, err = load(t, `{"actions": {"CrashLoopBackOff": "reboot"}}`)
+	assert.ErrorContains(t, err, `actions.crashloopbackoff: unknown action "reboot", use delete, evict, restart, scale, cordon, notify`)
+
+	_, err = load(t, `{"filters": {"OldPodDeleter": {"minAge": "-1h"}}}`)
+	assert.ErrorContains(t, err, "filters.oldpoddeleter.minAge must not be negative")
+
+	_, err = load(t, `{"filters": {"PodDeleter": {"minAge": "1h"}}}`)
+	assert.ErrorContains(t, err, `filters: unknown remediator "poddeleter"`)
+
+	_, err = load(t, `{"skipDrainingNodes": false, "filters": {"OldPodDeleter": {"nodeSelector": "agentpool=user"}}}`)
+	assert.ErrorContains(t, err, "filters.oldpoddeleter.nodeSelector needs skipDrainingNodes")
+
+	_, err = load(t, `{"workers": 0}`)
+	assert.ErrorContains(t, err, "workers must be at least 1")
+
+	_, err = load(t, `{"client": {"qps": 0}}`)
+	assert.ErrorContains(t, err, "client.qps must be positive")
+
+	_, err = load(t, `{"client": {"burst": 0}}`)
+	assert.ErrorContains(t, err, "client.burst must be at least 1")
+
+	_, err = load(t, `{"client": {"contentType": "yaml"}}`)
+	assert.ErrorContains(t, err, `client.contentType must be "protobuf" or "json"`)
+
+	_, err = load(t, `{"client": {"pageSize": -1}}`)
+	assert.ErrorContains(t, err, "client.pageSize must not be negative")
+
+	_, err = load(t, `{"leaderElection": {"enabled": true, "leaseDuration": "10s"}}`)
+	assert.ErrorContains(t, err, "leaderElection.leaseDuration must be more than renewDeadline")
+
+	_, err = load(t, `{"leaderElection": {"enabled": true, "retryPeriod": "10s"}}`)
+	assert.ErrorContains(t, err, "leaderElection.renewDeadline must be more than 1.2 times retryPeriod")
+
+	_, err = load(t, `{"clusters": [{"kubeconfig": "/etc/kubeconfig"}]}`)
+	assert.ErrorContains(t, err, "clusters[0].name must not be empty")
+
+	_, err = load(t, `{"clusters": [{"name": "prod"}, {"name": "prod", "context": "prod-admin"}]}`)
+	assert.ErrorContains(t, err, `clusters[1].name "prod" is used twice`)
+
+	_, err = load(t, `{"identity": {"groups": ["auditors"]}}`)
+	assert.ErrorContains(t, err, "identity.user is required")
+
+	_, err = load(t, `{"identity": {"serviceAccount": "Remediator:actor"}}`)
+	assert.ErrorContains(t, err, `identity.serviceAccount "Remediator:actor"`)
+
+	_, err = load(t, `{"captureLogs": {"enabled": true, "lines": 0}}`)
+	assert.ErrorContains(t, err, "captureLogs.lines must be positive")
+
+	_, err = load(t, `{"captureEvents": {"enabled": true, "count": 0}}`)
+	assert.ErrorContains(t, err, "captureEvents.count must be positive")
+
+	_, err = load(t, `{"diagnostics": {"enabled": true}}`)
+	assert.ErrorContains(t, err, "diagnostics needs either diagnostics.directory or diagnostics.s3.bucket")
+
+	_, err = load(t, `{"diagnostics": {"enabled": true, "s3": {"bucket": "postmortems"}}}`)
+	assert.ErrorContains(t, err, "diagnostics.s3.accessKeyId and diagnostics.s3.secretAccessKey are required")
+
+	_, err = load(t, `{"http": {"api": {"enabled": true, "recentActions": 0}}}`)
+	assert.ErrorContains(t, err, "http.api.recentActions must be positive")
+
+	_, err = load(t, `{"http": {"port": 9090}, "grpc": {"enabled": true, "port": 9090}}`)
+	assert.ErrorContains(t, err, "grpc.port must be positive and differ from http.port")
+
+	_, err = load(t, `{"http": {"admin": {"enabled": true}}}`)
+	assert.ErrorContains(t, err, "http.admin.token, http.admin.namespace and http.admin.configMap are required")
+
+	_, err = load(t, `{"metrics": {"push": {"url": "http://pushgateway:9091", "job": ""}}}`)
+	assert.ErrorContains(t, err, "metrics.push.job is required")
+
+	_, err = load(t, `{"log": {"level": "loud"}}`)
+	assert.ErrorContains(t, err, "log.level")
+
+	_, err = load(t, `{"log": {"format": "xml"}}`)
+	assert.ErrorContains(t, err, `unknown log.format "xml"`)
+
+	_, err = load(t, `{"rateLimit": {"max": 5, "interval": "0s"}}`)
+	assert.ErrorContains(t, err, "rateLimit.interval must be positive")
+
+	_, err = load(t, `{"remediators": {"crashLoopBackOffRescheduler": {"failureThreshold": 0}}}`)
+	assert.ErrorContains(t, err, "remediators.crashLoopBackOffRescheduler: failureThreshold")
+
+	_, err = load(t, `{"remediators": {"enabled": ["crashLoopBackOffRescheduler", "PVCCleaner"]}}`)
+	assert.ErrorContains(t, err, `remediators.enabled: unknown remediator "PVCCleaner", use AlertmanagerRemediator, CompletedPodDeleter, `)
+
+	_, err = load(t, `{"remediators": {"nodeProblemRemediator": {"conditions": {"KernelDeadlock": ["reboot"]}}}}`)
+	assert.ErrorContains(t, err, "unknown action")
+}
+
+func TestRejectsMalformedSettings(t *testing.T) {
+	_, err := load(t, `{"minPodAge": "soon"}`)
+	assert.ErrorContains(t, err, "minPodAge")
+	assert.ErrorContains(t, err, `invalid duration "soon", use a number with a unit like "90s"`)
+}
+
+func TestRejectsUnknownKeys(t *testing.T) {
+	_, err := load(t, `{"minPodAgee": "10m", "remediators": {"crashLoopBackOffRescheduler": {"failureTreshold": 3}}}`)
+	assert.ErrorContains(t, err, `unknown key "minpodagee", did you mean "minPodAge"?; `+
+		`unknown key "remediators.crashLoopBackOffRescheduler.failuretreshold", did you mean "failureThreshold"?`)
+
+	_, err = load(t, `{"namespaceOverrides": [{"namespaces": ["dev"], "dryrun": true, "timeout": "1m"}]}`)
+	assert.ErrorContains(t, err, `unknown key "namespaceOverrides[0].timeout"`)
+
+	_, err = load(t, `{"maintenance": {"timeZone": "UTC", "namespaces": {"payments": {"window": []}}}}`)
+	assert.ErrorContains(t, err, `unknown key "maintenance.namespaces.payments.window", did you mean "windows"?`)
+}
+
+func TestRejectsContradictingNamespaces(t *testing.T) {
+	_, err := load(t, `{"includeNamespaces": ["team-a"], "excludeNamespaces": ["team-*"]}`)
+	assert.ErrorContains(t, err, `namespace "team-a" is both included and excluded`)
+
+	_, err = load(t, `{"namespaceOverrides": [{"namespaces": ["dev"], "failureThreshold": -1}]}`)
+	assert.ErrorContains(t, err, "must not be negative")
+}
+
+func TestLoadsYAML(t *testing.T) {
+	settings, err := loadAs(t, "yaml", `
+minPodAge: 10m
+excludePriorityClasses: [batch-low]
+namespaceOverrides:
+- namespaces: [kube-system]
+  dryRun: true
+remediators:
+  crashLoopBackOffRescheduler:
+    failureThreshold: 3
+`)
+	assert.NilError(t, err)
+	assert.Equal(t, settings.MinPodAge, 10*time.Minute)
+	assert.DeepEqual(t, settings.ExcludePriorityClasses, []string{"batch-low"})
+	assert.DeepEqual(t, settings.NamespaceOverrides[0].Namespaces, []string{"kube-system"})
+	assert.Equal(t, *settings.NamespaceOverrides[0].DryRun, true)
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.FailureThreshold, int32(3))
+}
+
+func TestLoadsTOML(t *testing.T) {
+	settings, err := loadAs(t, "toml", `
+minPodAge = "10m"
+
+[remediators.crashLoopBackOffRescheduler]
+failureThreshold = 3
+`)
+	assert.NilError(t, err)
+	assert.Equal(t, settings.MinPodAge, 10*time.Minute)
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.FailureThreshold, int32(3))
+}
+
+func TestRejectsUnsupportedFormats(t *testing.T) {
+	_, err := loadAs(t, "hcl", `minPodAge = "10m"`)
+	assert.ErrorContains(t, err, "unsupported config format")
+}
+
+func TestFindsConfigInAnyFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	base := filepath.Join(dir, "remediator")
+
+	_, err = config.Find(base)
+	assert.ErrorContains(t, err, "no config file")
+
+	assert.NilError(t, ioutil.WriteFile(base+".yaml", []byte("dryRun: true"), 0644))
+	file, err := config.Find(base)
+	assert.NilError(t, err)
+	assert.Equal(t, file, base+".yaml")
+}
+
+func TestListsClusters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusters")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "staging.yaml"), []byte("kind: Config"), 0644))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "dev"), []byte("kind: Config"), 0644))
+	assert.NilError(t, os.Mkdir(filepath.Join(dir, "..data"), 0755))
+
+	settings := config.Default()
+	clusters, err := settings.ListClusters()
+	assert.NilError(t, err)
+	assert.Equal(t, len(clusters), 0)
+
+	settings.Clusters = []config.ClusterConfig{{Name: "prod", Context: "prod-admin"}}
+	settings.ClustersDirectory = dir
+	clusters, err = settings.ListClusters()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, clusters, []config.ClusterConfig{
+		{Name: "prod", Context: "prod-admin"},
+		{Name: "dev", Kubeconfig: filepath.Join(dir, "dev")},
+		{Name: "staging", Kubeconfig: filepath.Join(dir, "staging.yaml")},
+	})
+
+	settings.Clusters[0].Name = "dev"
+	_, err = settings.ListClusters()
+	assert.ErrorContains(t, err, `cluster "dev" is configured twice`)
+
+	settings.ClustersDirectory = filepath.Join(dir, "missing")
+	_, err = settings.ListClusters()
+	assert.ErrorContains(t, err, "clustersDirectory")
+}
+
+func TestBuildsLoggerConfig(t *testing.T) {
+	zapConfig := config.Default().Log.ZapConfig()
+	assert.Equal(t, zapConfig.Encoding, "json")
+	assert.Equal(t, zapConfig.Level.Level(), zapcore.InfoLevel)
+	assert.Equal(t, zapConfig.EncoderConfig.MessageKey, "message")
+	assert.Assert(t, zapConfig.Sampling != nil)
+
+	zapConfig = config.LogConfig{Level: "debug", Format: config.LogFormatConsole}.ZapConfig()
+	assert.Equal(t, zapConfig.Encoding, "console")
+	assert.Equal(t, zapConfig.Level.Level(), zapcore.DebugLevel)
+	assert.Assert(t, zapConfig.Sampling == nil)
+	_, err := zapConfig.Build()
+	assert.NilError(t, err)
+}
+
+func TestEnvNames(t *testing.T) {
+	assert.Equal(t, config.EnvName("dryRun"), "KUBE_REMEDIATOR_DRY_RUN")
+	assert.Equal(t, config.EnvName("rateLimit.max"), "KUBE_REMEDIATOR_RATE_LIMIT_MAX")
+	assert.Equal(t, config.EnvName("remediators.crashLoopBackOffRescheduler.failureThreshold"),
+		"KUBE_REMEDIATOR_REMEDIATORS_CRASH_LOOP_BACK_OFF_RESCHEDULER_FAILURE_THRESHOLD")
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	env := map[string]string{
+		"KUBE_REMEDIATOR_DRY_RUN":                  "true",
+		"KUBE_REMEDIATOR_MIN_POD_AGE":              "5m",
+		"KUBE_REMEDIATOR_RATE_LIMIT_MAX":           "3",
+		"KUBE_REMEDIATOR_EXCLUDE_PRIORITY_CLASSES": "batch-low,batch-high",
+		"KUBE_REMEDIATOR_MAINTENANCE_TIME_ZONE":    "Europe/Berlin",
+		"KUBE_REMEDIATOR_LOG_FORMAT":               "console",
+		"KUBE_REMEDIATOR_REMEDIATORS_CRASH_LOOP_BACK_OFF_RESCHEDULER_FAILURE_THRESHOLD": "2",
+	}
+	for name, value := range env {
+		assert.NilError(t, os.Setenv(name, value))
+		defer os.Unsetenv(name)
+	}
+
+	settings, err := load(t, `{"minPodAge": "10m", "rateLimit": {"interval": "1m"}}`)
+	assert.NilError(t, err)
+	assert.Equal(t, settings.DryRun, true)
+	assert.Equal(t, settings.MinPodAge, 5*time.Minute)
+	assert.Equal(t, settings.RateLimit.Max, 3)
+	assert.Equal(t, settings.RateLimit.Interval, time.Minute)
+	assert.DeepEqual(t, settings.ExcludePriorityClasses, []string{"batch-low", "batch-high"})
+	assert.Equal(t, settings.Maintenance.TimeZone, "Europe/Berlin")
+	assert.Equal(t, settings.Log.Format, config.LogFormatConsole)
+	assert.Equal(t, settings.Remediators.CrashLoopBackOffRescheduler.FailureThreshold, int32(2))
+}
+
+func TestRejectsInvalidEnv(t *testing.T) {
+	assert.NilError(t, os.Setenv("KUBE_REMEDIATOR_MIN_POD_AGE", "soon"))
+	defer os.Unsetenv("KUBE_REMEDIATOR_MIN_POD_AGE")
+	_, err := load(t, `{}`)
+	assert.ErrorContains(t, err, "minPodAge")
+}
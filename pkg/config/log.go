@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console" // easier to read when running locally
+)
+
+// level can also be changed at runtime on /log/level, format and sampling need a restart
+type LogConfig struct {
+	Level    string `mapstructure:"level"` // debug, info, warn or error
+	Format   string `mapstructure:"format"`
+	Sampling bool   `mapstructure:"sampling"` // drop repeated messages when logging a lot
+}
+
+func (c LogConfig) Validate() error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("log.level: %v", err)
+	}
+	if c.Format != LogFormatJSON && c.Format != LogFormatConsole {
+		return fmt.Errorf("unknown log.format %q, use %q or %q", c.Format, LogFormatJSON, LogFormatConsole)
+	}
+	return nil
+}
+
+// the level is only valid after Validate, it falls back to info
+func (c LogConfig) ZapLevel() zapcore.Level {
+	var level zapcore.Level
+	level.UnmarshalText([]byte(c.Level))
+	return level
+}
+
+// build a logger:
+// - without timestamps because docker already logs with timestamps
+// - use "message" instead of "msg" for consistency with other services / datadog parsing
+// - remove caller since it points to shared methods most of the time anyway
+func (c LogConfig) ZapConfig() zap.Config {
+	zapConfig := zap.NewProductionConfig()
+	if c.Format == LogFormatConsole {
+		zapConfig.Encoding = LogFormatConsole
+		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+	zapConfig.EncoderConfig.TimeKey = ""
+	zapConfig.EncoderConfig.MessageKey = "message"
+	zapConfig.DisableCaller = true
+	zapConfig.Level = zap.NewAtomicLevelAt(c.ZapLevel())
+	if !c.Sampling {
+		zapConfig.Sampling = nil
+	}
+	return zapConfig
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"sort"
+	"strings"
+	"time"
+)
+
+// custom resource from kubernetes/crd.yaml
+var RemediationPolicyResource = schema.GroupVersionResource{
+	Group:    "kube-remediator.io",
+	Version:  "v1alpha1",
+	Resource: "remediationpolicies",
+}
+
+// what a RemediationPolicy changes for the namespaces it targets, unset values use the config file
+type RemediationPolicySpec struct {
+	Namespaces        []string                   `mapstructure:"namespaces"`        // only in the admin namespace
+	NamespaceSelector string                     `mapstructure:"namespaceSelector"` // only in the admin namespace
+	FailureThreshold  int32                      `mapstructure:"failureThreshold"`
+	Interval          time.Duration              `mapstructure:"interval"`
+	OwnerCooldown     time.Duration              `mapstructure:"ownerCooldown"`
+	DryRun            *bool                      `mapstructure:"dryRun"`
+	Maintenance       *remediator.ScheduleConfig `mapstructure:"maintenance"`
+}
+
+// Watches RemediationPolicy objects so cluster admins and namespace owners can tune remediation without
+// editing the config file. Policies in the admin namespace can target any namespace and win over the config file,
+// policies in other namespaces only apply to their own namespace and lose against both.
+type PolicyWatcher struct {
+	logger         *zap.Logger
+	informer       cache.SharedIndexInformer
+	adminNamespace string
+	changed        chan struct{}
+}
+
+func NewPolicyWatcher(logger *zap.Logger, client k8s.ClientInterface, adminNamespace string) (*PolicyWatcher, error) {
+	informerFactory, err := client.NewDynamicSharedInformerFactory("")
+	if err != nil {
+		return nil, err
+	}
+	watcher := &PolicyWatcher{
+		logger:         logger,
+		informer:       informerFactory.ForResource(RemediationPolicyResource).Informer(),
+		adminNamespace: adminNamespace,
+		changed:        make(chan struct{}, 1),
+	}
+	watcher.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { watcher.notify() },
+		UpdateFunc: func(interface{}, interface{}) { watcher.notify() },
+		DeleteFunc: func(interface{}) { watcher.notify() },
+	})
+	return watcher, nil
+}
+
+// start watching and wait until all policies are cached, false when stopped before that
+func (w *PolicyWatcher) Start(stop <-chan struct{}) bool {
+	go w.informer.Run(stop)
+	synced := cache.WaitForCacheSync(stop, w.informer.HasSynced)
+	// the initial list is not a change
+	select {
+	case <-w.changed:
+	default:
+	}
+	return synced
+}
+
+// receives when a policy was added, updated or deleted, never receives for a nil watcher
+func (w *PolicyWatcher) Changed() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.changed
+}
+
+// many events only need a single reload
+func (w *PolicyWatcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// copy of settings with the policies merged into namespaceOverrides and maintenance.namespaces,
+// invalid policies are logged and ignored, a nil watcher returns settings as they are
+func (w *PolicyWatcher) Apply(settings *Config) *Config {
+	if w == nil {
+		return settings
+	}
+
+	var admin, owned []*unstructured.Unstructured
+	for _, item := range w.informer.GetStore().List() {
+		policy, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if policy.GetNamespace() == w.adminNamespace {
+			admin = append(admin, policy)
+		} else {
+			owned = append(owned, policy)
+		}
+	}
+
+	applied := *settings
+	applied.NamespaceOverrides = nil
+	applied.Maintenance.Namespaces = map[string]remediator.ScheduleConfig{}
+	for _, override := range w.convert(admin) {
+		applied.NamespaceOverrides = append(applied.NamespaceOverrides, override.config)
+		override.addMaintenance(applied.Maintenance.Namespaces)
+	}
+	applied.NamespaceOverrides = append(applied.NamespaceOverrides, settings.NamespaceOverrides...)
+	for namespace, schedule := range settings.Maintenance.Namespaces {
+		if _, ok := applied.Maintenance.Namespaces[namespace]; !ok {
+			applied.Maintenance.Namespaces[namespace] = schedule
+		}
+	}
+	for _, override := range w.convert(owned) {
+		applied.NamespaceOverrides = append(applied.NamespaceOverrides, override.config)
+		override.addMaintenance(applied.Maintenance.Namespaces)
+	}
+	return &applied
+}
+
+type policyOverride struct {
+	config      remediator.NamespaceOverrideConfig
+	maintenance *remediator.ScheduleConfig
+}
+
+// schedules are looked up by exact namespace, so they only apply to namespaces listed by name
+func (o policyOverride) addMaintenance(namespaces map[string]remediator.ScheduleConfig) {
+	if o.maintenance == nil {
+		return
+	}
+	for _, namespace := range o.config.Namespaces {
+		if _, ok := namespaces[namespace]; !ok {
+			namespaces[namespace] = *o.maintenance
+		}
+	}
+}
+
+// sorted by namespace and name so the first matching override does not depend on the order of the cache
+func (w *PolicyWatcher) convert(policies []*unstructured.Unstructured) []policyOverride {
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].GetNamespace() != policies[j].GetNamespace() {
+			return policies[i].GetNamespace() < policies[j].GetNamespace()
+		}
+		return policies[i].GetName() < policies[j].GetName()
+	})
+
+	var overrides []policyOverride
+	for _, policy := range policies {
+		override, err := w.convertPolicy(policy)
+		if err != nil {
+			w.logger.Warn("Ignoring invalid RemediationPolicy",
+				zap.String("namespace", policy.GetNamespace()), zap.String("name", policy.GetName()), zap.Error(err))
+			continue
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides
+}
+
+func (w *PolicyWatcher) convertPolicy(policy *unstructured.Unstructured) (policyOverride, error) {
+	var spec RemediationPolicySpec
+	content, _, err := unstructured.NestedMap(policy.Object, "spec")
+	if err != nil {
+		return policyOverride{}, err
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:  mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused: true,
+		Result:      &spec,
+	})
+	if err != nil {
+		return policyOverride{}, err
+	}
+	if err := decoder.Decode(content); err != nil {
+		return policyOverride{}, err
+	}
+
+	if policy.GetNamespace() != w.adminNamespace {
+		if len(spec.Namespaces) > 0 || spec.NamespaceSelector != "" {
+			return policyOverride{}, fmt.Errorf("only policies in %s can set namespaces or namespaceSelector", w.adminNamespace)
+		}
+		spec.Namespaces = []string{policy.GetNamespace()}
+	}
+	if spec.Maintenance != nil {
+		if spec.NamespaceSelector != "" {
+			return policyOverride{}, fmt.Errorf("maintenance can not be combined with namespaceSelector")
+		}
+		for _, namespace := range spec.Namespaces {
+			if strings.ContainsAny(namespace, "*?[/") {
+				return policyOverride{}, fmt.Errorf("maintenance needs namespaces listed by name, got %q", namespace)
+			}
+		}
+		if _, err := remediator.ParseSchedule(*spec.Maintenance); err != nil {
+			return policyOverride{}, fmt.Errorf("maintenance: %v", err)
+		}
+	}
+
+	override := policyOverride{
+		config: remediator.NamespaceOverrideConfig{
+			Namespaces:        spec.Namespaces,
+			NamespaceSelector: spec.NamespaceSelector,
+			FailureThreshold:  spec.FailureThreshold,
+			Interval:          spec.Interval,
+			OwnerCooldown:     spec.OwnerCooldown,
+			DryRun:            spec.DryRun,
+		},
+		maintenance: spec.Maintenance,
+	}
+	if _, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{override.config}); err != nil {
+		return policyOverride{}, err
+	}
+	return override, nil
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"testing"
+	"time"
+)
+
+func remediationPolicy(namespace, name string, spec map[string]interface{}) runtime.Object {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kube-remediator.io/v1alpha1",
+		"kind":       "RemediationPolicy",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       spec,
+	}}
+}
+
+// close stop when done
+func startPolicyWatcher(t *testing.T, stop chan struct{}, policies ...runtime.Object) *config.PolicyWatcher {
+	ctrl := gomock.NewController(t)
+	client := mock_k8s.NewMockClientInterface(ctrl)
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), policies...)
+	client.EXPECT().NewDynamicSharedInformerFactory("").Return(dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0), nil)
+
+	watcher, err := config.NewPolicyWatcher(zap.NewNop(), client, "kube-remediator")
+	assert.NilError(t, err)
+	assert.Assert(t, watcher.Start(stop))
+	return watcher
+}
+
+func TestAppliesRemediationPolicies(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	watcher := startPolicyWatcher(t, stop,
+		remediationPolicy("payments", "slow", map[string]interface{}{
+			"failureThreshold": int64(10),
+			"ownerCooldown":    "30m",
+			"maintenance":      map[string]interface{}{"timeZone": "America/New_York"},
+		}),
+		remediationPolicy("kube-remediator", "prod", map[string]interface{}{
+			"namespaceSelector": "env=prod",
+			"dryRun":            true,
+		}),
+	)
+	settings := config.Default()
+	settings.NamespaceOverrides = []remediator.NamespaceOverrideConfig{{Namespaces: []string{"dev-*"}, FailureThreshold: 3}}
+
+	applied := watcher.Apply(&settings)
+	assert.Equal(t, len(applied.NamespaceOverrides), 3)
+	assert.Equal(t, applied.NamespaceOverrides[0].NamespaceSelector, "env=prod")
+	assert.Equal(t, *applied.NamespaceOverrides[0].DryRun, true)
+	assert.DeepEqual(t, applied.NamespaceOverrides[1].Namespaces, []string{"dev-*"})
+	assert.DeepEqual(t, applied.NamespaceOverrides[2].Namespaces, []string{"payments"})
+	assert.Equal(t, applied.NamespaceOverrides[2].FailureThreshold, int32(10))
+	assert.Equal(t, applied.NamespaceOverrides[2].OwnerCooldown, 30*time.Minute)
+	assert.Equal(t, applied.Maintenance.Namespaces["payments"].TimeZone, "America/New_York")
+	assert.Equal(t, len(settings.NamespaceOverrides), 1)
+}
+
+func TestIgnoresInvalidRemediationPolicies(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	watcher := startPolicyWatcher(t, stop,
+		remediationPolicy("payments", "other-namespace", map[string]interface{}{"namespaces": []interface{}{"billing"}}),
+		remediationPolicy("payments", "typo", map[string]interface{}{"failureTreshold": int64(10)}),
+		remediationPolicy("kube-remediator", "glob-maintenance", map[string]interface{}{
+			"namespaces":  []interface{}{"team-*"},
+			"maintenance": map[string]interface{}{"timeZone": "UTC"},
+		}),
+	)
+	settings := config.Default()
+	applied := watcher.Apply(&settings)
+	assert.Equal(t, len(applied.NamespaceOverrides), 0)
+	assert.Equal(t, len(applied.Maintenance.Namespaces), 0)
+}
+
+func TestNilPolicyWatcherChangesNothing(t *testing.T) {
+	var watcher *config.PolicyWatcher
+	settings := config.Default()
+	assert.Equal(t, watcher.Apply(&settings), &settings)
+	assert.Assert(t, watcher.Changed() == nil)
+}
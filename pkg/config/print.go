@@ -0,0 +1,52 @@
+package config
+
+import (
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// the config as YAML with the keys of the config file in their order, durations written like in the file
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(printable(reflect.ValueOf(*c)))
+}
+
+func printable(value reflect.Value) interface{} {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(value.Int()).String()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		var fields yaml.MapSlice
+		for i := 0; i < value.NumField(); i++ {
+			name := strings.Split(value.Type().Field(i).Tag.Get("mapstructure"), ",")[0]
+			field := printable(value.Field(i))
+			if name == "" { // squashed
+				fields = append(fields, field.(yaml.MapSlice)...)
+				continue
+			}
+			fields = append(fields, yaml.MapItem{Key: name, Value: field})
+		}
+		return fields
+	case reflect.Map:
+		entries := map[string]interface{}{} // sorted by key when marshalled
+		for _, key := range value.MapKeys() {
+			entries[key.String()] = printable(value.MapIndex(key))
+		}
+		return entries
+	case reflect.Slice:
+		items := make([]interface{}, 0, value.Len()) // empty instead of null
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, printable(value.Index(i)))
+		}
+		return items
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return printable(value.Elem())
+	default:
+		return value.Interface()
+	}
+}
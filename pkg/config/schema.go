@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// what time.ParseDuration accepts
+const durationPattern = `^[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$|^0$`
+
+var durationRegexp = regexp.MustCompile(durationPattern)
+
+// JSON Schema (draft-07) of the config file, generated from Config so it can not get out of date,
+// only the parts needed to describe the config are supported
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // "string" or ["string", "null"]
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or *Schema
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+func JSONSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.Title = "kube-remediator config"
+	schema.Properties["$schema"] = &Schema{Type: "string"} // lets editors find the schema of a JSON config
+	return schema
+}
+
+func schemaFor(typ reflect.Type) *Schema {
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		return &Schema{Type: "string", Pattern: durationPattern}
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			if name == "" { // squashed
+				for squashed, property := range schemaFor(field.Type).Properties {
+					schema.Properties[squashed] = property
+				}
+				continue
+			}
+			schema.Properties[name] = schemaFor(field.Type)
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(typ.Elem())}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaFor(typ.Elem())}
+	case reflect.Ptr:
+		schema := schemaFor(typ.Elem())
+		schema.Type = []string{schema.Type.(string), "null"}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+// problems of the value as read from a config file, keyed like in the file,
+// viper lowercases keys so they are compared case-insensitive
+func (s *Schema) Validate(value interface{}) []string {
+	problems := s.validate("", value)
+	sort.Strings(problems)
+	return problems
+}
+
+func (s *Schema) validate(key string, value interface{}) []string {
+	if value == nil {
+		if s.allows("null") {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s must not be null", describe(key))}
+	}
+
+	values := reflect.ValueOf(value)
+	switch {
+	case s.allows("object"):
+		if values.Kind() != reflect.Map {
+			return []string{fmt.Sprintf("%s must be an object, got %v", describe(key), value)}
+		}
+		var problems []string
+		for _, k := range values.MapKeys() {
+			name := fmt.Sprint(k.Interface())
+			canonical, property := s.property(name)
+			if property == nil {
+				message := fmt.Sprintf("unknown key %q", strings.TrimPrefix(key+"."+name, "."))
+				if suggestion := s.closestProperty(name); suggestion != "" {
+					message += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				problems = append(problems, message)
+				continue
+			}
+			propertyKey := strings.TrimPrefix(key+"."+canonical, ".")
+			problems = append(problems, property.validate(propertyKey, values.MapIndex(k).Interface())...)
+		}
+		return problems
+	case s.allows("array"):
+		if values.Kind() != reflect.Slice {
+			return []string{fmt.Sprintf("%s must be a list, got %v", describe(key), value)}
+		}
+		var problems []string
+		for i := 0; i < values.Len(); i++ {
+			problems = append(problems, s.Items.validate(fmt.Sprintf("%s[%d]", key, i), values.Index(i).Interface())...)
+		}
+		return problems
+	case s.allows("boolean"):
+		if values.Kind() != reflect.Bool {
+			return []string{fmt.Sprintf("%s must be true or false, got %v", describe(key), value)}
+		}
+	case s.allows("integer"):
+		if !isNumber(values) || !isWhole(values) {
+			return []string{fmt.Sprintf("%s must be a whole number, got %v", describe(key), value)}
+		}
+	case s.allows("number"):
+		if !isNumber(values) {
+			return []string{fmt.Sprintf("%s must be a number, got %v", describe(key), value)}
+		}
+	case s.allows("string"):
+		text, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s must be a string, got %v", describe(key), value)}
+		}
+		if s.Pattern == durationPattern && !durationRegexp.MatchString(text) {
+			return []string{fmt.Sprintf("%s: invalid duration %q, use a number with a unit like \"90s\", \"10m\" or \"1h30m\"", describe(key), text)}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) allows(typ string) bool {
+	switch types := s.Type.(type) {
+	case string:
+		return types == typ
+	case []string:
+		for _, t := range types {
+			if t == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schema of a key, maps allow any key, "" and nil when the key is unknown
+func (s *Schema) property(name string) (string, *Schema) {
+	if additional, ok := s.AdditionalProperties.(*Schema); ok {
+		return name, additional
+	}
+	for key, property := range s.Properties {
+		if strings.EqualFold(key, name) {
+			return key, property
+		}
+	}
+	return "", nil
+}
+
+// most similar key when it is only a few typos away
+func (s *Schema) closestProperty(name string) string {
+	closest, closestDistance := "", 3
+	for key := range s.Properties {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < closestDistance ||
+			(distance == closestDistance && closest != "" && key < closest) {
+			closest, closestDistance = key, distance
+		}
+	}
+	return closest
+}
+
+func isNumber(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// JSON numbers are decoded as float64
+func isWhole(value reflect.Value) bool {
+	number := value.Convert(reflect.TypeOf(float64(0))).Float()
+	return number == math.Trunc(number)
+}
+
+func describe(key string) string {
+	if key == "" {
+		return "config"
+	}
+	return key
+}
+
+// Levenshtein distance
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
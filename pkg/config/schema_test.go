@@ -0,0 +1,46 @@
+package config_test
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestShippedSchemaIsUpToDate(t *testing.T) {
+	shipped, err := ioutil.ReadFile("../../config/remediator.schema.json")
+	assert.NilError(t, err)
+	generated, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+	assert.NilError(t, err)
+	assert.Equal(t, string(shipped), string(generated)+"\n", "run: go run ./cmd/remediator schema > config/remediator.schema.json")
+}
+
+func TestSchemaDescribesSettings(t *testing.T) {
+	schema := config.JSONSchema()
+	assert.Equal(t, schema.Properties["dryRun"].Type, "boolean")
+	assert.Equal(t, schema.Properties["rateLimit"].Properties["max"].Type, "integer")
+	assert.DeepEqual(t, schema.Properties["namespaceOverrides"].Items.Properties["dryRun"].Type, []string{"boolean", "null"})
+	assert.Equal(t, schema.Properties["timeZone"], (*config.Schema)(nil)) // squashed into maintenance
+	assert.Equal(t, schema.Properties["maintenance"].Properties["timeZone"].Type, "string")
+	assert.Equal(t, schema.Properties["maintenance"].Properties["namespaces"].AdditionalProperties.(*config.Schema).Type, "object")
+}
+
+func TestRejectsSettingsOfWrongType(t *testing.T) {
+	_, err := load(t, `{"dryRun": "yes"}`)
+	assert.ErrorContains(t, err, "dryRun must be true or false, got yes")
+
+	_, err = load(t, `{"rateLimit": {"max": 1.5}}`)
+	assert.ErrorContains(t, err, "rateLimit.max must be a whole number, got 1.5")
+
+	_, err = load(t, `{"excludeNamespaces": "kube-system"}`)
+	assert.ErrorContains(t, err, "excludeNamespaces must be a list, got kube-system")
+
+	_, err = load(t, `{"maintenance": {"namespaces": {"payments": {"windows": [{"start": 22}]}}}}`)
+	assert.ErrorContains(t, err, "maintenance.namespaces.payments.windows[0].start must be a string, got 22")
+}
+
+func TestAcceptsNullForOptionalSettings(t *testing.T) {
+	_, err := load(t, `{"deletion": {"gracePeriodSeconds": null}, "$schema": "remediator.schema.json"}`)
+	assert.NilError(t, err)
+}
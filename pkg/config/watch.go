@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// settings used by long running parts that are only built on start
+var restartRequired = []string{"detection", "http", "grpc", "audit", "metrics", "notifications", "report", "tracing", "log.format", "log.sampling", "client", "shutdown", "watchdog", "clusters", "clustersDirectory", "identity", "rateLimit", "killSwitch", "leaderElection", "skipDrainingNodes", "slimCaches", "remediationPolicies"}
+
+type Change struct {
+	Key  string // "rateLimit.max"
+	From interface{}
+	To   interface{}
+}
+
+// only applied when the process restarts, reloading does not pick it up
+func (c Change) RequiresRestart() bool {
+	for _, key := range restartRequired {
+		if c.Key == key || strings.HasPrefix(c.Key, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// settings that differ between the configs, keyed like in the config file
+func Diff(old, new *Config) []Change {
+	return diff("", reflect.ValueOf(*old), reflect.ValueOf(*new))
+}
+
+func diff(key string, old, new reflect.Value) []Change {
+	if old.Kind() != reflect.Struct {
+		if reflect.DeepEqual(old.Interface(), new.Interface()) {
+			return nil
+		}
+		return []Change{{Key: key, From: old.Interface(), To: new.Interface()}}
+	}
+
+	var changes []Change
+	for i := 0; i < old.NumField(); i++ {
+		name := strings.Split(old.Type().Field(i).Tag.Get("mapstructure"), ",")[0] // "" when squashed
+		fieldKey := key
+		if name != "" {
+			fieldKey = strings.TrimPrefix(key+"."+name, ".")
+		}
+		changes = append(changes, diff(fieldKey, old.Field(i), new.Field(i))...)
+	}
+	return changes
+}
+
+// Reloads the config file whenever it changes and sends it to reload, so operators do not need to restart to tweak
+// a setting. Changes are logged, invalid configs are logged and ignored.
+func Watch(ctx context.Context, wg *sync.WaitGroup, logger *zap.Logger, file string, current *Config, reload chan<- *Config) {
+	defer wg.Done()
+	defer logger.Info("Stopping", zap.String("reason", "Signal"))
+	logger.Info("Starting", zap.String("file", file))
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Error("Error watching config, changes need a restart", zap.Error(err))
+		return
+	}
+	defer watcher.Close()
+
+	// mounted ConfigMaps swap a symlinked directory instead of writing the file, so watch the directory
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		logger.Error("Error watching config, changes need a restart", zap.Error(err))
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watcher.Errors:
+			logger.Warn("Error watching config", zap.Error(err))
+		case <-watcher.Events:
+			next, err := Load(file)
+			if err != nil {
+				logger.Warn("Keeping current config, error reloading", zap.Error(err))
+				continue
+			}
+
+			// editors and ConfigMap updates cause multiple events per change
+			changes := Diff(current, next)
+			if len(changes) == 0 {
+				continue
+			}
+			for _, change := range changes {
+				changeInfo := []zap.Field{zap.String("setting", change.Key), zap.Any("from", change.From), zap.Any("to", change.To)}
+				if change.RequiresRestart() {
+					logger.Warn("Config changed, requires a restart", changeInfo...)
+				} else {
+					logger.Info("Config changed", changeInfo...)
+				}
+			}
+			current = next
+
+			select {
+			case reload <- next:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/config"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiffListsChangedSettings(t *testing.T) {
+	old := config.Default()
+	new := config.Default()
+	new.DryRun = true
+	new.RateLimit.Max = 5
+	new.Maintenance.TimeZone = "Europe/Berlin"
+	new.Remediators.CrashLoopBackOffRescheduler.FailureThreshold = 3
+
+	changes := config.Diff(&old, &new)
+	assert.DeepEqual(t, changes, []config.Change{
+		{Key: "dryRun", From: false, To: true},
+		{Key: "rateLimit.max", From: 0, To: 5},
+		{Key: "maintenance.timeZone", From: "", To: "Europe/Berlin"},
+		{Key: "remediators.crashLoopBackOffRescheduler.failureThreshold", From: int32(5), To: int32(3)},
+	})
+	assert.Equal(t, changes[0].RequiresRestart(), false)
+	assert.Equal(t, changes[1].RequiresRestart(), true)
+}
+
+func TestDiffIsEmptyWithoutChanges(t *testing.T) {
+	old := config.Default()
+	new := config.Default()
+	assert.Equal(t, len(config.Diff(&old, &new)), 0)
+}
+
+// watches a config file in a new directory and returns what was reloaded after writing each content
+func watch(t *testing.T, contents ...string) []*config.Config {
+	dir, err := ioutil.TempDir("", "config")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "remediator.json")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(`{}`), 0644))
+	current, err := config.Load(file)
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	reload := make(chan *config.Config)
+	logger, _ := zap.NewDevelopment()
+	wg.Add(1)
+	go config.Watch(ctx, &wg, logger, file, current, reload)
+	time.Sleep(50 * time.Millisecond) // let the watcher start
+
+	var reloaded []*config.Config
+	for _, content := range contents {
+		assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0644))
+		select {
+		case next := <-reload:
+			reloaded = append(reloaded, next)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return reloaded
+}
+
+func TestWatchReloadsChangedConfig(t *testing.T) {
+	reloaded := watch(t, `{"dryRun": true}`)
+	assert.Equal(t, len(reloaded), 1)
+	assert.Equal(t, reloaded[0].DryRun, true)
+}
+
+func TestWatchIgnoresInvalidConfig(t *testing.T) {
+	reloaded := watch(t, `{"detection": "polling"}`, `{"minPodAge": "1m"}`)
+	assert.Equal(t, len(reloaded), 1)
+	assert.Equal(t, reloaded[0].MinPodAge, time.Minute)
+}
@@ -0,0 +1,800 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/control/control.proto
+
+package control
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type DryRun int32
+
+const (
+	// dryRun of the config
+	DryRun_CONFIG DryRun = 0
+	DryRun_ON     DryRun = 1
+	DryRun_OFF    DryRun = 2
+)
+
+var DryRun_name = map[int32]string{
+	0: "CONFIG",
+	1: "ON",
+	2: "OFF",
+}
+
+var DryRun_value = map[string]int32{
+	"CONFIG": 0,
+	"ON":     1,
+	"OFF":    2,
+}
+
+func (x DryRun) String() string {
+	return proto.EnumName(DryRun_name, int32(x))
+}
+
+func (DryRun) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{0}
+}
+
+type RemediatorRequest struct {
+	// like CrashLoopBackOffRescheduler, any case, empty means all of them
+	Remediator           string   `protobuf:"bytes,1,opt,name=remediator,proto3" json:"remediator,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemediatorRequest) Reset()         { *m = RemediatorRequest{} }
+func (m *RemediatorRequest) String() string { return proto.CompactTextString(m) }
+func (*RemediatorRequest) ProtoMessage()    {}
+func (*RemediatorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{0}
+}
+
+func (m *RemediatorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemediatorRequest.Unmarshal(m, b)
+}
+func (m *RemediatorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemediatorRequest.Marshal(b, m, deterministic)
+}
+func (m *RemediatorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemediatorRequest.Merge(m, src)
+}
+func (m *RemediatorRequest) XXX_Size() int {
+	return xxx_messageInfo_RemediatorRequest.Size(m)
+}
+func (m *RemediatorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemediatorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemediatorRequest proto.InternalMessageInfo
+
+func (m *RemediatorRequest) GetRemediator() string {
+	if m != nil {
+		return m.Remediator
+	}
+	return ""
+}
+
+type StateRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateRequest) Reset()         { *m = StateRequest{} }
+func (m *StateRequest) String() string { return proto.CompactTextString(m) }
+func (*StateRequest) ProtoMessage()    {}
+func (*StateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{1}
+}
+
+func (m *StateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateRequest.Unmarshal(m, b)
+}
+func (m *StateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateRequest.Marshal(b, m, deterministic)
+}
+func (m *StateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateRequest.Merge(m, src)
+}
+func (m *StateRequest) XXX_Size() int {
+	return xxx_messageInfo_StateRequest.Size(m)
+}
+func (m *StateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateRequest proto.InternalMessageInfo
+
+type ControlState struct {
+	// Pause with an empty remediator pauses all of them
+	PausedAll bool `protobuf:"varint,1,opt,name=paused_all,json=pausedAll,proto3" json:"paused_all,omitempty"`
+	// paused on their own, sorted, lowercase
+	Paused               []string `protobuf:"bytes,2,rep,name=paused,proto3" json:"paused,omitempty"`
+	DryRun               DryRun   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3,enum=kuberemediator.control.v1.DryRun" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ControlState) Reset()         { *m = ControlState{} }
+func (m *ControlState) String() string { return proto.CompactTextString(m) }
+func (*ControlState) ProtoMessage()    {}
+func (*ControlState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{2}
+}
+
+func (m *ControlState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ControlState.Unmarshal(m, b)
+}
+func (m *ControlState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ControlState.Marshal(b, m, deterministic)
+}
+func (m *ControlState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ControlState.Merge(m, src)
+}
+func (m *ControlState) XXX_Size() int {
+	return xxx_messageInfo_ControlState.Size(m)
+}
+func (m *ControlState) XXX_DiscardUnknown() {
+	xxx_messageInfo_ControlState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ControlState proto.InternalMessageInfo
+
+func (m *ControlState) GetPausedAll() bool {
+	if m != nil {
+		return m.PausedAll
+	}
+	return false
+}
+
+func (m *ControlState) GetPaused() []string {
+	if m != nil {
+		return m.Paused
+	}
+	return nil
+}
+
+func (m *ControlState) GetDryRun() DryRun {
+	if m != nil {
+		return m.DryRun
+	}
+	return DryRun_CONFIG
+}
+
+type SetDryRunRequest struct {
+	DryRun               DryRun   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3,enum=kuberemediator.control.v1.DryRun" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetDryRunRequest) Reset()         { *m = SetDryRunRequest{} }
+func (m *SetDryRunRequest) String() string { return proto.CompactTextString(m) }
+func (*SetDryRunRequest) ProtoMessage()    {}
+func (*SetDryRunRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{3}
+}
+
+func (m *SetDryRunRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetDryRunRequest.Unmarshal(m, b)
+}
+func (m *SetDryRunRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetDryRunRequest.Marshal(b, m, deterministic)
+}
+func (m *SetDryRunRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetDryRunRequest.Merge(m, src)
+}
+func (m *SetDryRunRequest) XXX_Size() int {
+	return xxx_messageInfo_SetDryRunRequest.Size(m)
+}
+func (m *SetDryRunRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetDryRunRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetDryRunRequest proto.InternalMessageInfo
+
+func (m *SetDryRunRequest) GetDryRun() DryRun {
+	if m != nil {
+		return m.DryRun
+	}
+	return DryRun_CONFIG
+}
+
+type ScanResponse struct {
+	// that started a scan, sorted, "cluster/remediator" when there are several clusters
+	Remediators          []string `protobuf:"bytes,1,rep,name=remediators,proto3" json:"remediators,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScanResponse) Reset()         { *m = ScanResponse{} }
+func (m *ScanResponse) String() string { return proto.CompactTextString(m) }
+func (*ScanResponse) ProtoMessage()    {}
+func (*ScanResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{4}
+}
+
+func (m *ScanResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScanResponse.Unmarshal(m, b)
+}
+func (m *ScanResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScanResponse.Marshal(b, m, deterministic)
+}
+func (m *ScanResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScanResponse.Merge(m, src)
+}
+func (m *ScanResponse) XXX_Size() int {
+	return xxx_messageInfo_ScanResponse.Size(m)
+}
+func (m *ScanResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScanResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScanResponse proto.InternalMessageInfo
+
+func (m *ScanResponse) GetRemediators() []string {
+	if m != nil {
+		return m.Remediators
+	}
+	return nil
+}
+
+type ListActionsRequest struct {
+	// empty means all
+	Remediator string `protobuf:"bytes,1,opt,name=remediator,proto3" json:"remediator,omitempty"`
+	// 0 means all that are kept
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListActionsRequest) Reset()         { *m = ListActionsRequest{} }
+func (m *ListActionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListActionsRequest) ProtoMessage()    {}
+func (*ListActionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{5}
+}
+
+func (m *ListActionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListActionsRequest.Unmarshal(m, b)
+}
+func (m *ListActionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListActionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListActionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListActionsRequest.Merge(m, src)
+}
+func (m *ListActionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListActionsRequest.Size(m)
+}
+func (m *ListActionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListActionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListActionsRequest proto.InternalMessageInfo
+
+func (m *ListActionsRequest) GetRemediator() string {
+	if m != nil {
+		return m.Remediator
+	}
+	return ""
+}
+
+func (m *ListActionsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type ListActionsResponse struct {
+	Actions              []*Action `protobuf:"bytes,1,rep,name=actions,proto3" json:"actions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListActionsResponse) Reset()         { *m = ListActionsResponse{} }
+func (m *ListActionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListActionsResponse) ProtoMessage()    {}
+func (*ListActionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{6}
+}
+
+func (m *ListActionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListActionsResponse.Unmarshal(m, b)
+}
+func (m *ListActionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListActionsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListActionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListActionsResponse.Merge(m, src)
+}
+func (m *ListActionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListActionsResponse.Size(m)
+}
+func (m *ListActionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListActionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListActionsResponse proto.InternalMessageInfo
+
+func (m *ListActionsResponse) GetActions() []*Action {
+	if m != nil {
+		return m.Actions
+	}
+	return nil
+}
+
+// a line of the audit log
+type Action struct {
+	// RFC 3339
+	Time                 string   `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Remediator           string   `protobuf:"bytes,2,opt,name=remediator,proto3" json:"remediator,omitempty"`
+	Cluster              string   `protobuf:"bytes,3,opt,name=cluster,proto3" json:"cluster,omitempty"`
+	Kind                 string   `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	Namespace            string   `protobuf:"bytes,5,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Reason               string   `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	Action               string   `protobuf:"bytes,8,opt,name=action,proto3" json:"action,omitempty"`
+	Decision             string   `protobuf:"bytes,9,opt,name=decision,proto3" json:"decision,omitempty"`
+	Outcome              string   `protobuf:"bytes,10,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	DryRun               bool     `protobuf:"varint,11,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Detail               string   `protobuf:"bytes,12,opt,name=detail,proto3" json:"detail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Action) Reset()         { *m = Action{} }
+func (m *Action) String() string { return proto.CompactTextString(m) }
+func (*Action) ProtoMessage()    {}
+func (*Action) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d86f37c5ba60cb40, []int{7}
+}
+
+func (m *Action) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Action.Unmarshal(m, b)
+}
+func (m *Action) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Action.Marshal(b, m, deterministic)
+}
+func (m *Action) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Action.Merge(m, src)
+}
+func (m *Action) XXX_Size() int {
+	return xxx_messageInfo_Action.Size(m)
+}
+func (m *Action) XXX_DiscardUnknown() {
+	xxx_messageInfo_Action.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Action proto.InternalMessageInfo
+
+func (m *Action) GetTime() string {
+	if m != nil {
+		return m.Time
+	}
+	return ""
+}
+
+func (m *Action) GetRemediator() string {
+	if m != nil {
+		return m.Remediator
+	}
+	return ""
+}
+
+func (m *Action) GetCluster() string {
+	if m != nil {
+		return m.Cluster
+	}
+	return ""
+}
+
+func (m *Action) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Action) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Action) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Action) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Action) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *Action) GetDecision() string {
+	if m != nil {
+		return m.Decision
+	}
+	return ""
+}
+
+func (m *Action) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *Action) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *Action) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("kuberemediator.control.v1.DryRun", DryRun_name, DryRun_value)
+	proto.RegisterType((*RemediatorRequest)(nil), "kuberemediator.control.v1.RemediatorRequest")
+	proto.RegisterType((*StateRequest)(nil), "kuberemediator.control.v1.StateRequest")
+	proto.RegisterType((*ControlState)(nil), "kuberemediator.control.v1.ControlState")
+	proto.RegisterType((*SetDryRunRequest)(nil), "kuberemediator.control.v1.SetDryRunRequest")
+	proto.RegisterType((*ScanResponse)(nil), "kuberemediator.control.v1.ScanResponse")
+	proto.RegisterType((*ListActionsRequest)(nil), "kuberemediator.control.v1.ListActionsRequest")
+	proto.RegisterType((*ListActionsResponse)(nil), "kuberemediator.control.v1.ListActionsResponse")
+	proto.RegisterType((*Action)(nil), "kuberemediator.control.v1.Action")
+}
+
+func init() { proto.RegisterFile("pkg/control/control.proto", fileDescriptor_d86f37c5ba60cb40) }
+
+var fileDescriptor_d86f37c5ba60cb40 = []byte{
+	// 575 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x5d, 0x6f, 0xd3, 0x30,
+	0x14, 0x25, 0x59, 0x9b, 0x34, 0xb7, 0xd5, 0x54, 0x0c, 0x02, 0x6f, 0x02, 0x54, 0x22, 0x21, 0x2a,
+	0x3e, 0x52, 0xd8, 0x5e, 0xd0, 0xf6, 0x34, 0x86, 0x3a, 0x81, 0x50, 0x8b, 0xdc, 0x37, 0x04, 0x54,
+	0x6e, 0x62, 0x8d, 0xa8, 0xf9, 0x28, 0xb1, 0x83, 0xb4, 0x47, 0x7e, 0x13, 0x7f, 0x86, 0x9f, 0x83,
+	0xfc, 0xd1, 0x36, 0x5d, 0x45, 0x54, 0x24, 0x78, 0x8a, 0xcf, 0xb9, 0xbe, 0xc7, 0xc7, 0xf6, 0xbd,
+	0x0e, 0x1c, 0x2c, 0xe6, 0x97, 0x83, 0x30, 0xcf, 0x44, 0x91, 0x27, 0xcb, 0x6f, 0xb0, 0x28, 0x72,
+	0x91, 0xa3, 0x83, 0x79, 0x39, 0x63, 0x05, 0x4b, 0x59, 0x14, 0x53, 0x91, 0x17, 0xc1, 0x32, 0xfa,
+	0xfd, 0xa5, 0x7f, 0x0c, 0x37, 0xc9, 0x2a, 0x40, 0xd8, 0xb7, 0x92, 0x71, 0x81, 0x1e, 0x00, 0xac,
+	0x67, 0x63, 0xab, 0x67, 0xf5, 0x3d, 0x52, 0x61, 0xfc, 0x7d, 0xe8, 0x4c, 0x04, 0x15, 0xcc, 0xcc,
+	0xf7, 0x7f, 0x58, 0xd0, 0x39, 0xd7, 0x9a, 0x8a, 0x47, 0xf7, 0x01, 0x16, 0xb4, 0xe4, 0x2c, 0x9a,
+	0xd2, 0x24, 0x51, 0x02, 0x2d, 0xe2, 0x69, 0xe6, 0x2c, 0x49, 0xd0, 0x1d, 0x70, 0x34, 0xc0, 0x76,
+	0x6f, 0xaf, 0xef, 0x11, 0x83, 0xd0, 0x09, 0xb8, 0x51, 0x71, 0x35, 0x2d, 0xca, 0x0c, 0xef, 0xf5,
+	0xac, 0xfe, 0xfe, 0xd1, 0xc3, 0xe0, 0x8f, 0xce, 0x83, 0x37, 0xc5, 0x15, 0x29, 0x33, 0xe2, 0x44,
+	0xea, 0xeb, 0x8f, 0xa0, 0x3b, 0x61, 0xc2, 0x90, 0x66, 0x1f, 0x15, 0x3d, 0xeb, 0x6f, 0xf5, 0x5e,
+	0x40, 0x67, 0x12, 0xd2, 0x8c, 0x30, 0xbe, 0xc8, 0x33, 0xce, 0x50, 0x0f, 0xda, 0xeb, 0x3c, 0x8e,
+	0x2d, 0x65, 0xbc, 0x4a, 0xf9, 0xef, 0x00, 0xbd, 0x8f, 0xb9, 0x38, 0x0b, 0x45, 0x9c, 0x67, 0x7c,
+	0xc7, 0xb3, 0x44, 0xb7, 0xa1, 0x99, 0xc4, 0x69, 0x2c, 0xb0, 0xdd, 0xb3, 0xfa, 0x4d, 0xa2, 0x81,
+	0x4f, 0xe0, 0xd6, 0x86, 0x96, 0x31, 0x71, 0x0a, 0x2e, 0xd5, 0x94, 0x32, 0xd0, 0xae, 0xdd, 0x90,
+	0x4e, 0x26, 0xcb, 0x0c, 0xff, 0xa7, 0x0d, 0x8e, 0xe6, 0x10, 0x82, 0x86, 0x88, 0x53, 0x66, 0xec,
+	0xa8, 0xf1, 0x35, 0xa3, 0xf6, 0x96, 0x51, 0x0c, 0x6e, 0x98, 0x94, 0x5c, 0xb0, 0x42, 0x5d, 0x8e,
+	0x47, 0x96, 0x50, 0xaa, 0xcd, 0xe3, 0x2c, 0xc2, 0x0d, 0xad, 0x26, 0xc7, 0xe8, 0x1e, 0x78, 0x19,
+	0x4d, 0x19, 0x5f, 0xd0, 0x90, 0xe1, 0xa6, 0x0a, 0xac, 0x09, 0x99, 0x21, 0x01, 0x76, 0x74, 0x86,
+	0x1c, 0xcb, 0xa2, 0x28, 0x18, 0xe5, 0x79, 0x86, 0x5d, 0xc5, 0x1a, 0x24, 0x79, 0xbd, 0x03, 0xdc,
+	0xd2, 0xbc, 0x46, 0xe8, 0x10, 0x5a, 0x11, 0x0b, 0x63, 0x2e, 0x23, 0x9e, 0x8a, 0xac, 0xb0, 0xf4,
+	0x9a, 0x97, 0x22, 0xcc, 0x53, 0x86, 0x41, 0x7b, 0x35, 0x10, 0xdd, 0x5d, 0x97, 0x44, 0x5b, 0x95,
+	0xa5, 0xb9, 0x6f, 0xb9, 0x4c, 0xc4, 0x04, 0x8d, 0x13, 0xdc, 0xd1, 0xcb, 0x68, 0xf4, 0xe4, 0x11,
+	0x38, 0xba, 0x32, 0x10, 0x80, 0x73, 0x3e, 0x1e, 0x0d, 0xdf, 0x5e, 0x74, 0x6f, 0x20, 0x07, 0xec,
+	0xf1, 0xa8, 0x6b, 0x21, 0x17, 0xf6, 0xc6, 0xc3, 0x61, 0xd7, 0x3e, 0xfa, 0xd5, 0x00, 0xd7, 0xb4,
+	0x00, 0xfa, 0x02, 0xcd, 0x0f, 0xb2, 0xa0, 0xd1, 0xb3, 0x9a, 0xdb, 0xd9, 0xea, 0xba, 0xc3, 0xc7,
+	0x35, 0xb3, 0x37, 0xba, 0x6b, 0x0a, 0x0e, 0x61, 0xbc, 0x4c, 0xff, 0xdb, 0x02, 0x9f, 0xa1, 0x21,
+	0x6b, 0xff, 0x1f, 0xca, 0x6f, 0xb4, 0x52, 0x02, 0xed, 0x4a, 0x71, 0xa3, 0xe7, 0x35, 0x79, 0xdb,
+	0x0d, 0x75, 0x18, 0xec, 0x3a, 0xdd, 0xac, 0x46, 0xc1, 0x5b, 0x3d, 0x0c, 0xe8, 0x69, 0x9d, 0xc7,
+	0x6b, 0xcf, 0xc7, 0xee, 0xe7, 0xf5, 0x09, 0x5a, 0x17, 0x4c, 0xe8, 0x71, 0xed, 0x29, 0x54, 0x1e,
+	0xcd, 0x9d, 0xd5, 0x5f, 0x9f, 0x7c, 0x7c, 0x75, 0x19, 0x8b, 0xaf, 0xe5, 0x2c, 0x08, 0xf3, 0x74,
+	0x40, 0xe7, 0x5c, 0xa3, 0x81, 0x4c, 0x9f, 0xae, 0xf3, 0x07, 0x95, 0xf7, 0xff, 0xd4, 0x7c, 0x67,
+	0x8e, 0xfa, 0x01, 0x1c, 0xff, 0x1e, 0x00, 0x70, 0xea, 0x19, 0xce, 0x1d, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ControlClient is the client API for Control service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ControlClient interface {
+	// stops the remediator from acting on Pods and Nodes until it is resumed, it keeps scanning and records skips
+	Pause(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ControlState, error)
+	Resume(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ControlState, error)
+	// starts a scan of the remediator right away instead of waiting for its interval
+	Scan(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ScanResponse, error)
+	// the latest decisions, newest first
+	ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (*ListActionsResponse, error)
+	// overrides dryRun of the config
+	SetDryRun(ctx context.Context, in *SetDryRunRequest, opts ...grpc.CallOption) (*ControlState, error)
+	GetState(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*ControlState, error)
+}
+
+type controlClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewControlClient(cc *grpc.ClientConn) ControlClient {
+	return &controlClient{cc}
+}
+
+func (c *controlClient) Pause(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ControlState, error) {
+	out := new(ControlState)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Resume(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ControlState, error) {
+	out := new(ControlState)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) Scan(ctx context.Context, in *RemediatorRequest, opts ...grpc.CallOption) (*ScanResponse, error) {
+	out := new(ScanResponse)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/Scan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) ListActions(ctx context.Context, in *ListActionsRequest, opts ...grpc.CallOption) (*ListActionsResponse, error) {
+	out := new(ListActionsResponse)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/ListActions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) SetDryRun(ctx context.Context, in *SetDryRunRequest, opts ...grpc.CallOption) (*ControlState, error) {
+	out := new(ControlState)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/SetDryRun", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) GetState(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*ControlState, error) {
+	out := new(ControlState)
+	err := c.cc.Invoke(ctx, "/kuberemediator.control.v1.Control/GetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ControlServer is the server API for Control service.
+type ControlServer interface {
+	// stops the remediator from acting on Pods and Nodes until it is resumed, it keeps scanning and records skips
+	Pause(context.Context, *RemediatorRequest) (*ControlState, error)
+	Resume(context.Context, *RemediatorRequest) (*ControlState, error)
+	// starts a scan of the remediator right away instead of waiting for its interval
+	Scan(context.Context, *RemediatorRequest) (*ScanResponse, error)
+	// the latest decisions, newest first
+	ListActions(context.Context, *ListActionsRequest) (*ListActionsResponse, error)
+	// overrides dryRun of the config
+	SetDryRun(context.Context, *SetDryRunRequest) (*ControlState, error)
+	GetState(context.Context, *StateRequest) (*ControlState, error)
+}
+
+// UnimplementedControlServer can be embedded to have forward compatible implementations.
+type UnimplementedControlServer struct {
+}
+
+func (*UnimplementedControlServer) Pause(ctx context.Context, req *RemediatorRequest) (*ControlState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (*UnimplementedControlServer) Resume(ctx context.Context, req *RemediatorRequest) (*ControlState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (*UnimplementedControlServer) Scan(ctx context.Context, req *RemediatorRequest) (*ScanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (*UnimplementedControlServer) ListActions(ctx context.Context, req *ListActionsRequest) (*ListActionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListActions not implemented")
+}
+func (*UnimplementedControlServer) SetDryRun(ctx context.Context, req *SetDryRunRequest) (*ControlState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDryRun not implemented")
+}
+func (*UnimplementedControlServer) GetState(ctx context.Context, req *StateRequest) (*ControlState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+
+func RegisterControlServer(s *grpc.Server, srv ControlServer) {
+	s.RegisterService(&_Control_serviceDesc, srv)
+}
+
+func _Control_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemediatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Pause(ctx, req.(*RemediatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemediatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Resume(ctx, req.(*RemediatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemediatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/Scan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).Scan(ctx, req.(*RemediatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_ListActions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListActionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).ListActions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/ListActions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).ListActions(ctx, req.(*ListActionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_SetDryRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDryRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).SetDryRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/SetDryRun",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).SetDryRun(ctx, req.(*SetDryRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kuberemediator.control.v1.Control/GetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).GetState(ctx, req.(*StateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Control_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "kuberemediator.control.v1.Control",
+	HandlerType: (*ControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Pause",
+			Handler:    _Control_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Control_Resume_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _Control_Scan_Handler,
+		},
+		{
+			MethodName: "ListActions",
+			Handler:    _Control_ListActions_Handler,
+		},
+		{
+			MethodName: "SetDryRun",
+			Handler:    _Control_SetDryRun_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _Control_GetState_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/control/control.proto",
+}
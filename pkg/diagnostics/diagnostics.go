@@ -0,0 +1,161 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	"text/tabwriter"
+	"time"
+)
+
+// Collects what is needed for a post-mortem of a Pod into a bundle before the Pod is deleted
+type Config struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Required  bool     `mapstructure:"required"`  // skip the remediation when the bundle could not be stored
+	LogLines  int64    `mapstructure:"logLines"`  // of the current and previous run of each container
+	Directory string   `mapstructure:"directory"` // for example a mounted PersistentVolumeClaim
+	S3        S3Config `mapstructure:"s3"`        // S3 or any store with an S3 compatible API, like GCS
+}
+
+// a store for bundles named like "namespace/pod-20190701T120000Z.tar.gz", returns where the bundle went
+type Store interface {
+	Put(name string, bundle []byte) (string, error)
+}
+
+type Collector struct {
+	logger *zap.Logger
+	client k8s.ClientInterface
+	store  Store
+	lines  int64
+	now    func() time.Time
+}
+
+func DefaultConfig() Config {
+	return Config{LogLines: 1000, S3: DefaultS3Config()}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.LogLines <= 0 {
+		return fmt.Errorf("diagnostics.logLines must be positive, got %d", c.LogLines)
+	}
+	if (c.Directory == "") == (c.S3.Bucket == "") {
+		return fmt.Errorf("diagnostics needs either diagnostics.directory or diagnostics.s3.bucket")
+	}
+	if c.S3.Bucket != "" {
+		return c.S3.Validate()
+	}
+	return nil
+}
+
+func NewCollector(logger *zap.Logger, client k8s.ClientInterface, config Config) (*Collector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var store Store = &Directory{Path: config.Directory}
+	if config.S3.Bucket != "" {
+		store = NewS3(config.S3)
+	}
+	return &Collector{logger: logger, client: client, store: store, lines: config.LogLines, now: time.Now}, nil
+}
+
+// stores a bundle of the Pod, its events and the logs of its containers, returns where it went
+// logs and events that can not be fetched are left out with the error in their place
+func (c *Collector) Collect(ctx context.Context, pod *apiv1.Pod) (string, error) {
+	bundle, err := c.bundle(ctx, pod)
+	if err != nil {
+		return "", err // untested section
+	}
+	name := fmt.Sprintf("%s/%s-%s.tar.gz", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, c.now().UTC().Format("20060102T150405Z"))
+	location, err := c.store.Put(name, bundle)
+	if err != nil {
+		return "", err
+	}
+	c.logger.Info("Stored diagnostics", zap.String("name", pod.ObjectMeta.Name),
+		zap.String("namespace", pod.ObjectMeta.Namespace), zap.String("location", location))
+	return location, nil
+}
+
+func (c *Collector) bundle(ctx context.Context, pod *apiv1.Pod) ([]byte, error) {
+	var buffer bytes.Buffer
+	compressed := gzip.NewWriter(&buffer)
+	archive := tar.NewWriter(compressed)
+	modified := c.now()
+	add := func(name string, content []byte) error {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: modified}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := archive.Write(content)
+		return err
+	}
+
+	spec, err := json.MarshalIndent(pod, "", "  ")
+	if err != nil {
+		return nil, err // untested section
+	}
+	if err := add("pod.json", spec); err != nil {
+		return nil, err // untested section
+	}
+	if err := add("events.txt", c.events(ctx, pod)); err != nil {
+		return nil, err // untested section
+	}
+	restarts := map[string]int32{}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		restarts[status.Name] = status.RestartCount
+	}
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if err := add("logs/"+container.Name+".log", c.logs(ctx, pod, container.Name, false)); err != nil {
+			return nil, err // untested section
+		}
+		if restarts[container.Name] == 0 {
+			continue
+		}
+		if err := add("logs/"+container.Name+".previous.log", c.logs(ctx, pod, container.Name, true)); err != nil {
+			return nil, err // untested section
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err // untested section
+	}
+	if err := compressed.Close(); err != nil {
+		return nil, err // untested section
+	}
+	return buffer.Bytes(), nil
+}
+
+// like the events of `kubectl describe pod`, oldest first
+func (c *Collector) events(ctx context.Context, pod *apiv1.Pod) []byte {
+	events, err := k8s.GetPodEvents(ctx, c.client, pod)
+	if err != nil {
+		return []byte("error: " + err.Error() + "\n")
+	}
+	var text bytes.Buffer
+	table := tabwriter.NewWriter(&text, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "LAST SEEN\tTYPE\tREASON\tFROM\tCOUNT\tMESSAGE")
+	for i := range events {
+		event := &events[i]
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n", k8s.EventLastSeen(event).UTC().Format(time.RFC3339),
+			event.Type, event.Reason, event.Source.Component, k8s.EventCount(event), event.Message)
+	}
+	table.Flush()
+	return text.Bytes()
+}
+
+func (c *Collector) logs(ctx context.Context, pod *apiv1.Pod, container string, previous bool) []byte {
+	lines := c.lines
+	logs, err := c.client.GetPodLogs(ctx, pod, &apiv1.PodLogOptions{Container: container, Previous: previous, TailLines: &lines})
+	if err != nil {
+		return []byte("error: " + err.Error() + "\n")
+	}
+	return []byte(logs)
+}
@@ -0,0 +1,127 @@
+package diagnostics_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var collected = time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func crashingPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-xyz", Namespace: "payments", UID: "1234"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate"}},
+			Containers:     []corev1.Container{{Name: "api"}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "migrate"}},
+			ContainerStatuses:     []corev1.ContainerStatus{{Name: "api", RestartCount: 7}},
+		},
+	}
+}
+
+// files in the bundle by name
+func unpack(t *testing.T, bundle []byte) map[string]string {
+	compressed, err := gzip.NewReader(bytes.NewReader(bundle))
+	assert.NilError(t, err)
+	archive := tar.NewReader(compressed)
+	files := map[string]string{}
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		content, err := ioutil.ReadAll(archive)
+		assert.NilError(t, err)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func TestCollectsPodEventsAndLogsIntoDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "diagnostics")
+	assert.NilError(t, err)
+	defer os.RemoveAll(directory)
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	client := mock_k8s.NewMockClientInterface(controller)
+	pod := crashingPod()
+
+	client.EXPECT().GetEvents(gomock.Any(), "payments", metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod,involvedObject.name=api-xyz,involvedObject.uid=1234"}).Return(&corev1.EventList{Items: []corev1.Event{
+		{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 12, LastTimestamp: metav1.NewTime(collected)},
+		{Type: "Normal", Reason: "Pulled", Message: "Container image pulled", Count: 1, LastTimestamp: metav1.NewTime(collected.Add(-time.Hour))},
+	}}, nil)
+	logs := func(container string, previous bool, text string, err error) {
+		client.EXPECT().GetPodLogs(gomock.Any(), pod, gomock.Any()).DoAndReturn(func(_ context.Context, _ *corev1.Pod, options *corev1.PodLogOptions) (string, error) {
+			assert.Equal(t, options.Container, container)
+			assert.Equal(t, options.Previous, previous)
+			assert.Equal(t, *options.TailLines, int64(100))
+			return text, err
+		})
+	}
+	logs("migrate", false, "migrated\n", nil)
+	logs("api", false, "", errors.New("container is waiting"))
+	logs("api", true, "panic: boom\n", nil)
+
+	config := diagnostics.DefaultConfig()
+	config.Enabled, config.Directory, config.LogLines = true, directory, 100
+	collector, err := diagnostics.NewCollector(zap.NewNop(), client, config)
+	assert.NilError(t, err)
+	collector.UseClock(func() time.Time { return collected })
+
+	location, err := collector.Collect(context.Background(), pod)
+	assert.NilError(t, err)
+	assert.Equal(t, location, filepath.Join(directory, "payments", "api-xyz-20190701T120000Z.tar.gz"))
+	bundle, err := ioutil.ReadFile(location)
+	assert.NilError(t, err)
+	files := unpack(t, bundle)
+	assert.Equal(t, len(files), 5)
+	assert.Assert(t, strings.Contains(files["pod.json"], `"name": "api-xyz"`))
+	events := strings.Split(strings.TrimSpace(files["events.txt"]), "\n")
+	assert.Equal(t, len(events), 3)
+	assert.Assert(t, strings.HasPrefix(events[0], "LAST SEEN"), events[0])
+	assert.Assert(t, strings.Contains(events[1], "Pulled"), events[1])
+	assert.Assert(t, strings.Contains(events[2], "Back-off restarting failed container"), events[2])
+	assert.Equal(t, files["logs/migrate.log"], "migrated\n")
+	assert.Equal(t, files["logs/api.log"], "error: container is waiting\n")
+	assert.Equal(t, files["logs/api.previous.log"], "panic: boom\n")
+}
+
+func TestRejectsInvalidDiagnosticsConfig(t *testing.T) {
+	config := diagnostics.DefaultConfig()
+	assert.NilError(t, config.Validate())
+
+	config.Enabled = true
+	assert.ErrorContains(t, config.Validate(), "either diagnostics.directory or diagnostics.s3.bucket")
+
+	config.Directory, config.S3.Bucket = "/diagnostics", "postmortems"
+	assert.ErrorContains(t, config.Validate(), "either diagnostics.directory or diagnostics.s3.bucket")
+
+	config.Directory = ""
+	config.S3.AccessKeyID, config.S3.SecretAccessKey = "id", "secret"
+	assert.NilError(t, config.Validate())
+
+	config.S3.Endpoint = "storage"
+	assert.ErrorContains(t, config.Validate(), "diagnostics.s3.endpoint")
+
+	config.S3.Endpoint, config.LogLines = "https://storage.googleapis.com", 0
+	assert.ErrorContains(t, config.Validate(), "diagnostics.logLines must be positive")
+}
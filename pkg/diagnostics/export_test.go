@@ -0,0 +1,13 @@
+package diagnostics
+
+import (
+	"time"
+)
+
+func (c *Collector) UseClock(now func() time.Time) {
+	c.now = now
+}
+
+func (s *S3) UseClock(now func() time.Time) {
+	s.now = now
+}
@@ -0,0 +1,132 @@
+package diagnostics
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Uploads bundles to a bucket with requests signed by AWS Signature Version 4,
+// GCS works the same with its XML API endpoint and HMAC keys
+type S3Config struct {
+	Endpoint        string `mapstructure:"endpoint"` // https://storage.googleapis.com for GCS
+	Region          string `mapstructure:"region"`   // auto for GCS
+	Bucket          string `mapstructure:"bucket"`
+	Prefix          string `mapstructure:"prefix"` // prepended to the name of each bundle
+	AccessKeyID     string `mapstructure:"accessKeyId"`
+	SecretAccessKey string `mapstructure:"secretAccessKey"`
+}
+
+// writes bundles into a directory, creating one directory per namespace
+type Directory struct {
+	Path string
+}
+
+type S3 struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func DefaultS3Config() S3Config {
+	return S3Config{Endpoint: "https://s3.amazonaws.com", Region: "us-east-1"}
+}
+
+func (c S3Config) Validate() error {
+	if _, err := url.ParseRequestURI(c.Endpoint); err != nil {
+		return fmt.Errorf("diagnostics.s3.endpoint: %v", err)
+	}
+	if c.Region == "" {
+		return fmt.Errorf("diagnostics.s3.region is required")
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return fmt.Errorf("diagnostics.s3.accessKeyId and diagnostics.s3.secretAccessKey are required")
+	}
+	return nil
+}
+
+func (d *Directory) Put(name string, bundle []byte) (string, error) {
+	path := filepath.Join(d.Path, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, ioutil.WriteFile(path, bundle, 0644)
+}
+
+func NewS3(config S3Config) *S3 {
+	return &S3{config: config, client: &http.Client{Timeout: 30 * time.Second}, now: time.Now}
+}
+
+// path style, so buckets with dots in their name work over https
+func (s *S3) Put(name string, bundle []byte) (string, error) {
+	var escaped []string
+	for _, segment := range strings.Split(s.config.Bucket+"/"+s.config.Prefix+name, "/") {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	location := strings.TrimSuffix(s.config.Endpoint, "/") + "/" + strings.Join(escaped, "/")
+	request, err := http.NewRequest(http.MethodPut, location, bytes.NewReader(bundle))
+	if err != nil {
+		return "", err // untested section
+	}
+	request.Header.Set("Content-Type", "application/gzip")
+	s.sign(request, bundle)
+	response, err := s.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("uploading %s failed with %s: %s", location, response.Status, body)
+	}
+	return location, nil
+}
+
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+func (s *S3) sign(request *http.Request, payload []byte) {
+	now := s.now().UTC()
+	date := now.Format("20060102")
+	hash := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(hash[:])
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		"", // query
+		"content-type:" + request.Header.Get("Content-Type"),
+		"host:" + request.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + request.Header.Get("X-Amz-Date"),
+		"",
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + request.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + s.config.SecretAccessKey)
+	for _, part := range []string{date, s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,57 @@
+package diagnostics_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func s3Config(endpoint string) diagnostics.S3Config {
+	config := diagnostics.DefaultS3Config()
+	config.Endpoint, config.Bucket, config.Prefix = endpoint, "postmortems", "prod/"
+	config.AccessKeyID, config.SecretAccessKey = "AKIDEXAMPLE", "secret"
+	return config
+}
+
+func TestUploadsSignedBundleToBucket(t *testing.T) {
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	store := diagnostics.NewS3(s3Config(server.URL))
+	store.UseClock(func() time.Time { return collected })
+
+	location, err := store.Put("payments/api-xyz-20190701T120000Z.tar.gz", []byte("bundle"))
+	assert.NilError(t, err)
+	assert.Equal(t, location, server.URL+"/postmortems/prod/payments/api-xyz-20190701T120000Z.tar.gz")
+	assert.Equal(t, request.Method, http.MethodPut)
+	assert.Equal(t, request.URL.Path, "/postmortems/prod/payments/api-xyz-20190701T120000Z.tar.gz")
+	assert.Equal(t, string(body), "bundle")
+	hash := sha256.Sum256(body)
+	assert.Equal(t, request.Header.Get("X-Amz-Content-Sha256"), hex.EncodeToString(hash[:]))
+	assert.Equal(t, request.Header.Get("X-Amz-Date"), "20190701T120000Z")
+	assert.Assert(t, regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20190701/us-east-1/s3/aws4_request, `+
+		`SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`).MatchString(request.Header.Get("Authorization")),
+		request.Header.Get("Authorization"))
+}
+
+func TestFailsWhenBucketRejectsUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("SignatureDoesNotMatch"))
+	}))
+	defer server.Close()
+
+	_, err := diagnostics.NewS3(s3Config(server.URL)).Put("payments/api.tar.gz", []byte("bundle"))
+	assert.ErrorContains(t, err, "403 Forbidden: SignatureDoesNotMatch")
+}
@@ -0,0 +1,228 @@
+package events
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sync"
+	"time"
+)
+
+// the Events remediators can subscribe to: crashing containers, Pods that can not be scheduled, failing probes and
+// volumes that can not be mounted
+const (
+	ReasonBackOff          = "BackOff"
+	ReasonFailedScheduling = "FailedScheduling"
+	ReasonUnhealthy        = "Unhealthy"
+	ReasonFailedMount      = "FailedMount"
+)
+
+var Reasons = []string{ReasonBackOff, ReasonFailedScheduling, ReasonUnhealthy, ReasonFailedMount}
+
+// gets of a Pod that fail before the queue gives up on it until its next Event
+const retries = 5
+
+// remediates the Pod, called by the workers of the stream
+type Handler func(ctx context.Context, pod *v1.Pod)
+
+type subscription struct {
+	namespace string
+	handler   Handler
+}
+
+// pod that had an event we are interested in
+type item struct {
+	namespace string
+	name      string
+	reason    string
+}
+
+// Watches the Events of Pods in the namespaces remediators asked for and feeds the affected Pods to the remediators
+// subscribed to their reason, like BackOff, so they do not need to watch or list all Pods themselves, one queue and
+// its workers are shared by all of them, so a Pod is fetched once for all remediators handling its Event
+type Stream struct {
+	logger  *zap.Logger
+	client  k8s.ClientInterface
+	stop    <-chan struct{}
+	queue   workqueue.RateLimitingInterface
+	workers int
+	started time.Time
+
+	lock          sync.RWMutex
+	informers     map[string]cache.SharedIndexInformer // by namespace, "" watches all of them
+	subscriptions map[string][]*subscription           // event reason -> subscriptions
+}
+
+// stop ends all watches, the stream outlives the remediators using it when the config is reloaded, workers handle
+// the queued Pods, less than one means one
+func NewStream(logger *zap.Logger, client k8s.ClientInterface, stop <-chan struct{}, workers int) *Stream {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Stream{
+		logger:        logger,
+		client:        client,
+		stop:          stop,
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "events"),
+		workers:       workers,
+		started:       time.Now(),
+		informers:     map[string]cache.SharedIndexInformer{},
+		subscriptions: map[string][]*subscription{},
+	}
+}
+
+// start watching the Events of the namespaces that are not watched yet, "" means all of them, like PodCache.Watch
+// so remediators also work with RBAC in just a few namespaces
+func (s *Stream) Watch(namespaces []string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, namespace := range namespaces {
+		if _, ok := s.informers[namespace]; ok {
+			continue
+		}
+		informerFactory, err := s.client.NewSharedInformerFactory(namespace)
+		if err != nil {
+			return err
+		}
+		informer := informerFactory.Core().V1().Events().Informer()
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: s.enqueue,
+			// repeated events are aggregated by bumping their count
+			UpdateFunc: func(oldObj, newObj interface{}) { s.enqueue(newObj) },
+		})
+		s.informers[namespace] = informer
+		go informer.Run(s.stop)
+	}
+	return nil
+}
+
+// call handler with the involved Pod whenever an event with one of the reasons is seen, namespace "" means all,
+// only Events of watched namespaces are seen, returns a func that stops calling the handler, it does not wait for
+// calls that already started
+func (s *Stream) Subscribe(namespace string, reasons []string, handler Handler) func() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	added := &subscription{namespace: namespace, handler: handler}
+	for _, reason := range reasons {
+		s.subscriptions[reason] = append(s.subscriptions[reason], added)
+	}
+	return func() { s.unsubscribe(reasons, added) }
+}
+
+func (s *Stream) unsubscribe(reasons []string, removed *subscription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, reason := range reasons {
+		var kept []*subscription
+		for _, subscription := range s.subscriptions[reason] {
+			if subscription != removed {
+				kept = append(kept, subscription)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.subscriptions, reason)
+		} else {
+			s.subscriptions[reason] = kept
+		}
+	}
+}
+
+// true once the Events of all watched namespaces are cached
+func (s *Stream) HasSynced() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, informer := range s.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// feeds the Pods of seen Events to the subscribers until ctx is done, then waits for the handlers that started
+func (s *Stream) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer s.logger.Info("Stopping", zap.String("reason", "Signal"))
+	s.logger.Info("Starting")
+
+	var workers sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for s.processNextItem() {
+			}
+		}()
+	}
+	<-ctx.Done()
+	s.queue.ShutDown()
+	workers.Wait()
+}
+
+func (s *Stream) enqueue(obj interface{}) {
+	event, ok := obj.(*v1.Event)
+	if !ok || event.InvolvedObject.Kind != "Pod" {
+		return
+	}
+
+	// old events are covered by the initial reconcile each remediator does on start
+	if !event.LastTimestamp.IsZero() && event.LastTimestamp.Time.Before(s.started) {
+		return
+	}
+
+	s.lock.RLock()
+	_, subscribed := s.subscriptions[event.Reason]
+	s.lock.RUnlock()
+	if !subscribed {
+		return
+	}
+
+	s.queue.Add(item{
+		namespace: event.InvolvedObject.Namespace,
+		name:      event.InvolvedObject.Name,
+		reason:    event.Reason,
+	})
+}
+
+func (s *Stream) processNextItem() bool {
+	obj, shutdown := s.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer s.queue.Done(obj)
+
+	// queued Pods are not part of a scan, each starts its own trace, shutting down is up to the handlers
+	ctx := context.Background()
+	item := obj.(item)
+	info := []zap.Field{zap.String("name", item.name), zap.String("namespace", item.namespace)}
+	pod, err := s.client.GetPod(ctx, item.namespace, item.name)
+	switch {
+	case err == nil:
+	case errors.IsNotFound(err):
+		s.queue.Forget(obj) // gone while it waited
+		return true
+	case s.queue.NumRequeues(obj) < retries:
+		s.logger.Info("Retrying Pod", append(info, zap.Error(err))...)
+		s.queue.AddRateLimited(obj)
+		return true
+	default:
+		s.logger.Error("Error getting pod, giving up until its next Event", append(info, zap.Error(err))...)
+		s.queue.Forget(obj)
+		return true
+	}
+	s.queue.Forget(obj)
+
+	s.lock.RLock()
+	subscriptions := s.subscriptions[item.reason]
+	s.lock.RUnlock()
+	for _, subscription := range subscriptions {
+		if subscription.namespace == "" || subscription.namespace == pod.ObjectMeta.Namespace {
+			subscription.handler(ctx, pod)
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/events"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+type TestStreamSuite struct {
+	suite.Suite
+	logger         *zap.Logger
+	mockController *gomock.Controller
+	mockClient     *mock_k8s.MockClientInterface
+	event          corev1.Event
+	pod            corev1.Pod
+	unsubscribe    bool
+	t              *testing.T
+}
+
+func TestSuiteStream(t *testing.T) {
+	suite.Run(t, &TestStreamSuite{t: t})
+}
+
+func (suite *TestStreamSuite) SetupTest() {
+	suite.logger, _ = zap.NewDevelopment()
+	suite.mockController = gomock.NewController(suite.t)
+	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.pod = corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	suite.unsubscribe = false
+	suite.event = corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "foo.123", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "foo", Namespace: "default"},
+		Reason:         "BackOff",
+	}
+}
+
+func (suite *TestStreamSuite) TearDownTest() {
+	suite.mockController.Finish()
+}
+
+// runs the stream watching namespace until the handler was called or the timeout is reached
+func (suite *TestStreamSuite) run(namespace string) []*corev1.Pod {
+	clientSet := fake.NewSimpleClientset(&suite.event)
+	factory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0, informers.WithNamespace(namespace))
+	suite.mockClient.EXPECT().NewSharedInformerFactory(namespace).Return(factory, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := events.NewStream(suite.logger, suite.mockClient, ctx.Done(), 2)
+	assert.NilError(suite.t, stream.Watch([]string{namespace}))
+	assert.NilError(suite.t, stream.Watch([]string{namespace})) // once per namespace
+
+	var lock sync.Mutex
+	var received []*corev1.Pod
+	called := make(chan bool, 1)
+	unsubscribe := stream.Subscribe(namespace, events.Reasons, func(_ context.Context, pod *corev1.Pod) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, pod)
+		select {
+		case called <- true:
+		default:
+		}
+	})
+	if suite.unsubscribe {
+		unsubscribe()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go stream.Run(ctx, &wg)
+
+	select {
+	case <-called:
+	case <-time.After(200 * time.Millisecond):
+	}
+	cancel()
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	return received
+}
+
+func (suite *TestStreamSuite) TestFeedsPodsToSubscribers() {
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&suite.pod, nil)
+	assert.DeepEqual(suite.t, suite.run(""), []*corev1.Pod{&suite.pod})
+}
+
+func (suite *TestStreamSuite) TestFeedsPodsOfWatchedNamespace() {
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&suite.pod, nil)
+	assert.DeepEqual(suite.t, suite.run("default"), []*corev1.Pod{&suite.pod})
+}
+
+// only watches Events of the namespace, like RBAC limited to it allows
+func (suite *TestStreamSuite) TestIgnoresOtherNamespaces() {
+	assert.Equal(suite.t, len(suite.run("other")), 0)
+}
+
+func (suite *TestStreamSuite) TestFeedsPodsOfAllReasons() {
+	for _, reason := range []string{"FailedScheduling", "Unhealthy", "FailedMount"} {
+		suite.event.Reason = reason
+		suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&suite.pod, nil)
+		assert.DeepEqual(suite.t, suite.run(""), []*corev1.Pod{&suite.pod})
+	}
+}
+
+func (suite *TestStreamSuite) TestRetriesPodsItCouldNotGet() {
+	gomock.InOrder(
+		suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(nil, errors.New("Foo")),
+		suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&suite.pod, nil),
+	)
+	assert.DeepEqual(suite.t, suite.run(""), []*corev1.Pod{&suite.pod})
+}
+
+func (suite *TestStreamSuite) TestIgnoresOtherReasons() {
+	suite.event.Reason = "Pulled"
+	assert.Equal(suite.t, len(suite.run("")), 0)
+}
+
+func (suite *TestStreamSuite) TestIgnoresOtherKinds() {
+	suite.event.InvolvedObject.Kind = "Node"
+	assert.Equal(suite.t, len(suite.run("")), 0)
+}
+
+func (suite *TestStreamSuite) TestIgnoresOldEvents() {
+	suite.event.LastTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	assert.Equal(suite.t, len(suite.run("")), 0)
+}
+
+func (suite *TestStreamSuite) TestDoesNotCrashWhenGetFails() {
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(nil, errors.New("Foo")).AnyTimes()
+	assert.Equal(suite.t, len(suite.run("")), 0)
+}
+
+func (suite *TestStreamSuite) TestStopsFeedingUnsubscribed() {
+	suite.unsubscribe = true
+	assert.Equal(suite.t, len(suite.run("")), 0)
+}
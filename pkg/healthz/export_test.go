@@ -0,0 +1,8 @@
+package healthz
+
+import "time"
+
+// lets tests move time forward
+func (h *Health) UseClock(now func() time.Time) {
+	h.now = now
+}
@@ -1,17 +1,177 @@
 package healthz
 
 import (
+	"fmt"
 	httpmux "github.com/google/cadvisor/http/mux"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-//TODO: add more checks as needed
-func handleHealthz(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ok"))
+// Liveness and readiness of the app, a nil Health is always live and ready
+// - live: every loop ticked within 2x the interval it expects until its next tick
+// - ready: every check passes, e.g. caches are synced and the api-server can be reached
+// statuses do not change either, they tell humans on /readyz what the replica is doing, like whether it leads
+type Health struct {
+	prefix string // of the names of loops and checks, "cluster/" for the components of one of several clusters
+	*state
 }
 
-func RegisterHandler(mux httpmux.Mux) error {
-	mux.HandleFunc("/healthz", handleHealthz)
+type state struct {
+	lock   sync.Mutex
+	loops  map[string]loop
+	checks map[string]func() error
+	status map[string]func() string
+	now    func() time.Time
+}
+
+type loop struct {
+	ticked time.Time
+	next   time.Duration
+}
+
+func NewHealth() *Health {
+	return &Health{state: &state{loops: map[string]loop{}, checks: map[string]func() error{}, status: map[string]func() string{}, now: time.Now}}
+}
+
+// the same Health for the components of one of several clusters, their loops and checks are named "cluster/name"
+func (h *Health) ForCluster(cluster string) *Health {
+	if h == nil || cluster == "" {
+		return h
+	}
+	return &Health{prefix: h.prefix + cluster + "/", state: h.state}
+}
+
+// the loop processed a tick and expects the next one within next
+func (h *Health) Tick(name string, next time.Duration) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.loops[h.prefix+name] = loop{ticked: h.now(), next: next}
+}
+
+// check is called on every readiness probe, it should be fast
+func (h *Health) AddReadyCheck(name string, check func() error) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.checks[h.prefix+name] = check
+}
+
+// status is called on every readiness probe and shown after the outcome, it should be fast
+func (h *Health) AddStatus(name string, status func() string) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.status[h.prefix+name] = status
+}
+
+// ready once all informers synced their caches
+func (h *Health) AddSyncCheck(name string, hasSynced ...func() bool) {
+	h.AddReadyCheck(name, func() error {
+		for _, synced := range hasSynced {
+			if !synced() {
+				return fmt.Errorf("cache not synced")
+			}
+		}
+		return nil
+	})
+}
+
+// forget the loop and check of a stopped component
+func (h *Health) Remove(name string) {
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.loops, h.prefix+name)
+	delete(h.checks, h.prefix+name)
+	delete(h.status, h.prefix+name)
+}
+
+// one problem per stuck loop, empty when live
+func (h *Health) Live() []string {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var problems []string
+	for name, loop := range h.loops {
+		if since := h.now().Sub(loop.ticked); since > 2*loop.next {
+			problems = append(problems, fmt.Sprintf("%s: no tick for %v, expected every %v", name, since.Round(time.Second), loop.next))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// one problem per failed check, empty when ready
+func (h *Health) Ready() []string {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	checks := make(map[string]func() error, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.lock.Unlock()
+
+	// checks can be slow, so run them without holding the lock
+	var problems []string
+	for name, check := range checks {
+		if err := check(); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// one "name: status" line per status
+func (h *Health) Status() []string {
+	if h == nil {
+		return nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var lines []string
+	for name, status := range h.status {
+		lines = append(lines, name+": "+status())
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// statuses follow the outcome on their own lines
+func respond(w http.ResponseWriter, problems []string, status int, statuses []string) {
+	lines := append([]string{"ok"}, statuses...)
+	if len(problems) > 0 {
+		w.WriteHeader(status)
+		lines = append(problems, statuses...)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.Write([]byte(strings.Join(lines, "\n")))
+}
+
+// /healthz for liveness and /readyz for readiness probes
+func RegisterHandler(mux httpmux.Mux, health *Health) error {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, health.Live(), http.StatusInternalServerError, nil)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, health.Ready(), http.StatusServiceUnavailable, health.Status())
+	})
 	return nil
 }
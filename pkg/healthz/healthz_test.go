@@ -0,0 +1,77 @@
+package healthz_test
+
+import (
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/healthz"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestLiveUntilLoopMissesTwoTicks(t *testing.T) {
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	health := healthz.NewHealth()
+	health.UseClock(func() time.Time { return now })
+	assert.Equal(t, len(health.Live()), 0)
+
+	health.Tick("OldPodDeleter", time.Minute)
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, len(health.Live()), 0)
+
+	now = now.Add(time.Second)
+	assert.DeepEqual(t, health.Live(), []string{"OldPodDeleter: no tick for 2m1s, expected every 1m0s"})
+
+	health.Tick("OldPodDeleter", time.Hour)
+	assert.Equal(t, len(health.Live()), 0)
+
+	now = now.Add(3 * time.Hour)
+	health.Remove("OldPodDeleter")
+	assert.Equal(t, len(health.Live()), 0)
+}
+
+func TestReadyWhenAllChecksPass(t *testing.T) {
+	health := healthz.NewHealth()
+	assert.Equal(t, len(health.Ready()), 0)
+
+	synced := false
+	health.AddSyncCheck("nodes", func() bool { return synced })
+	health.AddReadyCheck("api-server", func() error { return errors.New("connection refused") })
+	assert.DeepEqual(t, health.Ready(), []string{"api-server: connection refused", "nodes: cache not synced"})
+
+	synced = true
+	health.Remove("api-server")
+	assert.Equal(t, len(health.Ready()), 0)
+}
+
+func TestNamesChecksOfClusters(t *testing.T) {
+	health := healthz.NewHealth()
+	health.AddReadyCheck("api-server", func() error { return nil })
+	prod := health.ForCluster("prod")
+	prod.AddReadyCheck("api-server", func() error { return errors.New("connection refused") })
+	assert.DeepEqual(t, health.Ready(), []string{"prod/api-server: connection refused"})
+
+	prod.Remove("api-server")
+	assert.Equal(t, len(health.Ready()), 0)
+	assert.Equal(t, health.ForCluster(""), health)
+}
+
+func TestNilHealthIsLiveAndReady(t *testing.T) {
+	var health *healthz.Health
+	health.Tick("OldPodDeleter", time.Minute)
+	health.AddSyncCheck("nodes", func() bool { return false })
+	assert.Equal(t, len(health.Live()), 0)
+	assert.Equal(t, len(health.Ready()), 0)
+}
+
+func TestStatusDoesNotChangeReadiness(t *testing.T) {
+	health := healthz.NewHealth()
+	leading := "false"
+	health.ForCluster("prod").AddStatus("leader", func() string { return leading })
+	health.AddStatus("mode", func() string { return "observing" })
+	assert.Equal(t, len(health.Ready()), 0)
+	assert.DeepEqual(t, health.Status(), []string{"mode: observing", "prod/leader: false"})
+
+	leading = "true"
+	health.Remove("mode")
+	assert.DeepEqual(t, health.Status(), []string{"prod/leader: true"})
+}
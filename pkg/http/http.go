@@ -2,35 +2,53 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"github.com/aksgithub/kube_remediator/pkg/healthz"
-	"github.com/aksgithub/kube_remediator/pkg/metrics"
 	"go.uber.org/zap"
 	"net/http"
+	"net/http/pprof"
 	"sync"
 	"time"
 )
 
 type Server struct {
-	logger *zap.Logger
+	logger   *zap.Logger
+	port     int
+	health   *healthz.Health
+	handlers map[string]http.Handler
 }
 
-func NewServer(logger *zap.Logger) *Server {
-	return &Server{logger: logger}
+func NewServer(logger *zap.Logger, port int, health *healthz.Health) *Server {
+	return &Server{logger: logger, port: port, health: health, handlers: map[string]http.Handler{}}
 }
 
-// allow checking from the outside if the app is still running
-// eventually this should show if the remediators are working
-// maybe later also for /metrics
+// serve more endpoints, must be called before Serve
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.handlers[pattern] = handler
+}
+
+// profiles of net/http/pprof on /debug/pprof/, they can be expensive so only when enabled
+func (s *Server) HandlePprof() {
+	s.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	s.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	s.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	s.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	s.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+}
+
+// allow checking from the outside if the app and its remediators are working and scraping metrics
 func (s *Server) Serve(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	s.logger.Info("Starting")
+	s.logger.Info("Starting", zap.Int("port", s.port))
 
 	//register handler
 	mux := http.NewServeMux()
-	healthz.RegisterHandler(mux)
-	metrics.RegisterHandler(mux)
-	srv := &http.Server{Addr: ":8080", Handler: mux}
+	healthz.RegisterHandler(mux, s.health)
+	for pattern, handler := range s.handlers {
+		mux.Handle(pattern, handler)
+	}
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", s.port), Handler: mux}
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil {
@@ -2,7 +2,10 @@ package http_test
 
 import (
 	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/healthz"
 	remediator_http "github.com/aksgithub/kube_remediator/pkg/http"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 	"gotest.tools/assert"
@@ -56,7 +59,12 @@ func (suite *TestHttpServerSuite) TestServer() {
 	ctx, cancel := context.WithCancel(suite.ctx)
 	var wg sync.WaitGroup
 	wg.Add(1)
-	go remediator_http.NewServer(suite.logger).Serve(ctx, &wg)
+	health := healthz.NewHealth()
+	server := remediator_http.NewServer(suite.logger, 8080, health)
+	server.Handle("/log/level", zap.NewAtomicLevel())
+	server.HandlePprof()
+	server.Handle("/metrics", metrics.Handler())
+	go server.Serve(ctx, &wg)
 
 	time.Sleep(100 * time.Millisecond) // wait for http server to get ready
 
@@ -64,6 +72,18 @@ func (suite *TestHttpServerSuite) TestServer() {
 	assert.Equal(suite.t, status, 200)
 	status, _ = suite.httpGet("http://localhost:8080/metrics")
 	assert.Equal(suite.t, status, 200)
+	status, _ = suite.httpGet("http://localhost:8080/readyz")
+	assert.Equal(suite.t, status, 200)
+	status, _ = suite.httpGet("http://localhost:8080/debug/pprof/heap")
+	assert.Equal(suite.t, status, 200)
+	status, body := suite.httpGet("http://localhost:8080/log/level")
+	assert.Equal(suite.t, status, 200)
+	assert.Equal(suite.t, body, "{\"level\":\"info\"}\n")
+
+	health.AddReadyCheck("api-server", func() error { return errors.New("connection refused") })
+	status, body = suite.httpGet("http://localhost:8080/readyz")
+	assert.Equal(suite.t, status, 503)
+	assert.Equal(suite.t, body, "api-server: connection refused")
 
 	cancel()
 	wg.Wait()
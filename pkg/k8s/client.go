@@ -1,35 +1,389 @@
 package k8s
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"go.uber.org/zap"
+	"io/ioutil"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	apiv1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport"
+	utilexec "k8s.io/client-go/util/exec"
+	"net/http"
 	"os"
 	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// every call is canceled with its context, so shutdown does not wait for a slow api-server
 type ClientInterface interface {
-	GetPods(namespace string, options metav1.ListOptions) (*apiv1.PodList, error)
-	DeletePod(pod *apiv1.Pod) error
+	GetPods(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.PodList, error)
+	GetPod(ctx context.Context, namespace string, name string) (*apiv1.Pod, error)
+	DeletePod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error
+	EvictPod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error
+	AnnotatePod(ctx context.Context, pod *apiv1.Pod, annotations map[string]*string) error
+	PatchPod(ctx context.Context, pod *apiv1.Pod, patchType types.PatchType, patch []byte) error
+	GetPodLogs(ctx context.Context, pod *apiv1.Pod, options *apiv1.PodLogOptions) (string, error)
+	ExecPod(ctx context.Context, pod *apiv1.Pod, container string, command []string, stdin []byte) (string, error)
+	GetPodDisruptionBudgets(ctx context.Context, namespace string) (*policyv1beta1.PodDisruptionBudgetList, error)
+	GetEvents(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.EventList, error)
 	NewSharedInformerFactory(ns string) (informers.SharedInformerFactory, error)
+	NewDynamicSharedInformerFactory(ns string) (dynamicinformer.DynamicSharedInformerFactory, error)
+	NewLeaseLock(namespace string, name string, identity string) (resourcelock.Interface, error)
+	GetNodes(ctx context.Context, options metav1.ListOptions) (*apiv1.NodeList, error)
+	GetNode(ctx context.Context, name string) (*apiv1.Node, error)
+	CordonNode(ctx context.Context, node *apiv1.Node) error
+	UncordonNode(ctx context.Context, node *apiv1.Node) error
+	GetOwner(ctx context.Context, namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error)
+	AnnotateOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, annotations map[string]*string) error
+	PatchOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, patchType types.PatchType, patch []byte) error
+	GetScale(ctx context.Context, namespace string, owner metav1.OwnerReference) (*autoscalingv1.Scale, error)
+	UpdateScale(ctx context.Context, namespace string, owner metav1.OwnerReference, scale *autoscalingv1.Scale) error
+	GetConfigMap(ctx context.Context, namespace string, name string) (*apiv1.ConfigMap, error)
+	SetConfigMapData(ctx context.Context, namespace string, name string, data map[string]string) error
+}
+
+const (
+	ContentTypeProtobuf = "protobuf"
+	ContentTypeJSON     = "json"
+
+	protobufMediaType = "application/vnd.kubernetes.protobuf"
+)
+
+// changes are attributed to this manager in the managedFields of objects, server-side apply makes it own the applied fields
+const FieldManager = "kube-remediator"
+
+// like kube-remediator/v1.2.3 (linux/amd64) OldPodDeleter, so the audit log and the metrics of the api-server
+// attribute calls to the version and component that made them, "" component leaves it out
+func UserAgent(version string, component string) string {
+	userAgent := fmt.Sprintf("%s/%s (%s/%s)", FieldManager, version, goruntime.GOOS, goruntime.GOARCH)
+	if component != "" {
+		userAgent += " " + component
+	}
+	return userAgent
 }
 
 type Client struct {
-	logger    *zap.Logger
-	clientSet *kubernetes.Clientset
+	logger         *zap.Logger
+	config         *restclient.Config
+	clientSet      *kubernetes.Clientset
+	dynamicClient  dynamic.Interface
+	restMapper     *restmapper.DeferredDiscoveryRESTMapper
+	timeout        time.Duration
+	serviceAccount string // changes in a namespace are made as this service account of it, "" means as the client
+
+	lock          sync.Mutex
+	actors        map[string]*kubernetes.Clientset // impersonating serviceAccount, by namespace
+	policyV1beta1 bool                             // the api-server does not serve policy/v1 yet
+	evictions     string                           // the version of policy Evictions the api-server takes, "" until asked
+}
+
+// the typed clients of this client-go do not take a context, so requests are built like they build them
+func (c *Client) core() restclient.Interface {
+	return c.clientSet.CoreV1().RESTClient()
+}
+
+// changes in a namespace are made as its service account when one is configured, so the audit log of the
+// api-server names a per-namespace identity and RBAC limits it to the namespace, cluster scoped changes use the client
+func (c *Client) actor(namespace string) (*kubernetes.Clientset, error) {
+	if c.serviceAccount == "" || namespace == "" {
+		return c.clientSet, nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if actor, ok := c.actors[namespace]; ok {
+		return actor, nil
+	}
+	actor, err := kubernetes.NewForConfig(c.actorConfig(namespace))
+	if err != nil {
+		return nil, err // untested section
+	}
+	c.actors[namespace] = actor
+	return actor, nil
+}
+
+// the config of the actor of the namespace, for calls the typed clients can not make like exec
+func (c *Client) actorConfig(namespace string) *restclient.Config {
+	if c.serviceAccount == "" || namespace == "" {
+		return c.config
+	}
+	config := restclient.CopyConfig(c.config)
+	config.Impersonate = restclient.ImpersonationConfig{
+		UserName: "system:serviceaccount:" + namespace + ":" + c.serviceAccount,
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace},
+	}
+	config.RateLimiter = c.core().GetRateLimiter() // one budget for all namespaces
+	return config
+}
+
+// ctx limited to the timeout of a single call, the returned cancel has to be called when the call is done
+func (c *Client) call(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
 }
 
-func (c *Client) GetPods(namespace string, options metav1.ListOptions) (*apiv1.PodList, error) {
-	return c.clientSet.CoreV1().Pods(namespace).List(options)
+// options.Limit returns a page, the next one is requested with the Continue of the returned list, see ListPodPages
+func (c *Client) GetPods(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.PodList, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	pods := &apiv1.PodList{}
+	err := c.core().Get().Context(ctx).Namespace(namespace).Resource("pods").
+		VersionedParams(&options, scheme.ParameterCodec).Do().Into(pods)
+	return pods, err
 }
 
-func (c *Client) DeletePod(pod *apiv1.Pod) error {
-	return c.clientSet.CoreV1().Pods(pod.ObjectMeta.Namespace).Delete(pod.ObjectMeta.Name, &metav1.DeleteOptions{})
+// call fn with every page of the Pods as it arrives, so a large cluster is not listed in one huge response,
+// options.Limit is the size of a page, 0 lists all Pods at once
+func ListPodPages(ctx context.Context, client ClientInterface, namespace string, options metav1.ListOptions, fn func(*apiv1.PodList)) error {
+	for {
+		page, err := client.GetPods(ctx, namespace, options)
+		if err != nil {
+			return err
+		}
+		fn(page)
+		if page.ListMeta.Continue == "" {
+			return nil
+		}
+		options.Continue = page.ListMeta.Continue
+	}
+}
+
+func (c *Client) GetPod(ctx context.Context, namespace string, name string) (*apiv1.Pod, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	pod := &apiv1.Pod{}
+	err := c.core().Get().Context(ctx).Namespace(namespace).Resource("pods").Name(name).Do().Into(pod)
+	return pod, err
+}
+
+// nil options use the api-server defaults
+func (c *Client) DeletePod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error {
+	options = dryRunOptions(ctx, options)
+	actor, err := c.actor(pod.ObjectMeta.Namespace)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	return actor.CoreV1().RESTClient().Delete().Context(ctx).Namespace(pod.ObjectMeta.Namespace).Resource("pods").Name(pod.ObjectMeta.Name).
+		Body(options).Do().Error()
+}
+
+// delete the Pod via the Eviction subresource so PodDisruptionBudgets are honored,
+// fails with an *EvictionBlockedError when one of them does not allow it,
+// a policy/v1 Eviction since Kubernetes 1.22, policy/v1beta1 before, v1beta1 is gone since 1.25
+func (c *Client) EvictPod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error {
+	actor, err := c.actor(pod.ObjectMeta.Namespace)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	version, err := c.evictionVersion(ctx)
+	if err != nil {
+		return err
+	}
+	// this client-go has no policy/v1 types, the fields of both are the same
+	eviction := &policyv1beta1.Eviction{
+		TypeMeta:      metav1.TypeMeta{APIVersion: version, Kind: "Eviction"},
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.ObjectMeta.Name, Namespace: pod.ObjectMeta.Namespace},
+		DeleteOptions: dryRunOptions(ctx, options),
+	}
+	body, err := json.Marshal(eviction)
+	if err != nil {
+		return err // untested section
+	}
+	return evictionError(actor.CoreV1().RESTClient().Post().Context(ctx).Namespace(pod.ObjectMeta.Namespace).Resource("pods").
+		Name(pod.ObjectMeta.Name).SubResource("eviction").SetHeader("Content-Type", runtime.ContentTypeJSON).Body(body).Do().Error())
+}
+
+// policy/v1 unless the api-server is older than Kubernetes 1.22, asked once
+func (c *Client) evictionVersion(ctx context.Context) (string, error) {
+	c.lock.Lock()
+	known := c.evictions
+	c.lock.Unlock()
+	if known != "" {
+		return known, nil
+	}
+	body, err := c.clientSet.Discovery().RESTClient().Get().Context(ctx).AbsPath("/version").Do().Raw()
+	if err != nil {
+		return "", err
+	}
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", err // untested section
+	}
+	// some providers add a suffix, like "22+"
+	major, _ := strconv.Atoi(strings.TrimRight(info.Major, "+"))
+	minor, _ := strconv.Atoi(strings.TrimRight(info.Minor, "+"))
+	known = "policy/v1"
+	if major == 1 && minor < 22 {
+		c.logger.Info("policy/v1 Evictions are not taken, using policy/v1beta1", zap.String("version", info.GitVersion))
+		known = "policy/v1beta1"
+	}
+	c.lock.Lock()
+	c.evictions = known
+	c.lock.Unlock()
+	return known, nil
+}
+
+// set annotations, nil values remove them
+func (c *Client) AnnotatePod(ctx context.Context, pod *apiv1.Pod, annotations map[string]*string) error {
+	patch, err := annotationsPatch(annotations)
+	if err != nil {
+		return err
+	}
+	return c.PatchPod(ctx, pod, types.MergePatchType, patch)
+}
+
+// change only the fields in patch, like the pod-deletion-cost annotation or finalizers,
+// without the conflicts of updating the whole Pod
+func (c *Client) PatchPod(ctx context.Context, pod *apiv1.Pod, patchType types.PatchType, patch []byte) error {
+	actor, err := c.actor(pod.ObjectMeta.Namespace)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	return change(ctx, actor.CoreV1().RESTClient().Patch(patchType).Context(ctx).Namespace(pod.ObjectMeta.Namespace).Resource("pods").
+		Name(pod.ObjectMeta.Name)).Body(patch).Do().Error()
+}
+
+// at most 64KiB, so a container logging huge lines does not blow up memory
+func (c *Client) GetPodLogs(ctx context.Context, pod *apiv1.Pod, options *apiv1.PodLogOptions) (string, error) {
+	limit := int64(64 * 1024)
+	options = options.DeepCopy()
+	options.LimitBytes = &limit
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	logs, err := c.clientSet.CoreV1().Pods(pod.ObjectMeta.Namespace).GetLogs(pod.ObjectMeta.Name, options).Context(ctx).
+		Timeout(10 * time.Second).Do().Raw()
+	return string(logs), err
+}
+
+// stdout and stderr of command run in the container with stdin, an *ExitError when it exited non-zero, the
+// remotecommand of this client-go can not be cancelled, a call cancelled with ctx leaves the command running
+func (c *Client) ExecPod(ctx context.Context, pod *apiv1.Pod, container string, command []string, stdin []byte) (string, error) {
+	actor, err := c.actor(pod.ObjectMeta.Namespace)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	request := actor.CoreV1().RESTClient().Post().Namespace(pod.ObjectMeta.Namespace).Resource("pods").Name(pod.ObjectMeta.Name).
+		SubResource("exec").VersionedParams(&apiv1.PodExecOptions{
+		Container: container, Command: command, Stdin: true, Stdout: true, Stderr: true,
+	}, scheme.ParameterCodec)
+	// the streams are not made by the actor's client, only with its config
+	executor, err := remotecommand.NewSPDYExecutor(c.actorConfig(pod.ObjectMeta.Namespace), http.MethodPost, request.URL())
+	if err != nil {
+		return "", err // untested section
+	}
+	output := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Stream(remotecommand.StreamOptions{Stdin: bytes.NewReader(stdin), Stdout: output, Stderr: output})
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return output.String(), ctx.Err()
+	}
+	if exit, ok := err.(utilexec.ExitError); ok {
+		return output.String(), &ExitError{Code: exit.ExitStatus(), Output: output.String()}
+	}
+	return output.String(), err
+}
+
+// stdout and stderr are written concurrently
+type syncBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.String()
+}
+
+// from policy/v1, or policy/v1beta1 on clusters before Kubernetes 1.21, v1beta1 is gone since 1.25,
+// the fields are the same in both, an empty selector selects all Pods of the namespace like in v1
+func (c *Client) GetPodDisruptionBudgets(ctx context.Context, namespace string) (*policyv1beta1.PodDisruptionBudgetList, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	pdbs := &policyv1beta1.PodDisruptionBudgetList{}
+	c.lock.Lock()
+	v1beta1 := c.policyV1beta1
+	c.lock.Unlock()
+	if !v1beta1 {
+		// this client-go has no policy/v1 types, the JSON of v1 decodes into v1beta1 ones
+		body, err := c.clientSet.Discovery().RESTClient().Get().Context(ctx).AbsPath("/apis/policy/v1").Namespace(namespace).
+			Resource("poddisruptionbudgets").SetHeader("Accept", runtime.ContentTypeJSON).Do().Raw()
+		if !errors.IsNotFound(err) {
+			if err != nil {
+				return pdbs, err
+			}
+			return pdbs, json.Unmarshal(body, pdbs)
+		}
+		c.logger.Info("policy/v1 is not served, using policy/v1beta1 for PodDisruptionBudgets")
+		c.lock.Lock()
+		c.policyV1beta1 = true
+		c.lock.Unlock()
+	}
+	err := c.clientSet.PolicyV1beta1().RESTClient().Get().Context(ctx).Namespace(namespace).Resource("poddisruptionbudgets").
+		Do().Into(pdbs)
+	for i := range pdbs.Items {
+		// v1beta1 selects no Pods with an empty selector
+		if selector := pdbs.Items[i].Spec.Selector; selector != nil && len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+			pdbs.Items[i].Spec.Selector = nil
+		}
+	}
+	return pdbs, err
+}
+
+func (c *Client) GetEvents(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.EventList, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	events := &apiv1.EventList{}
+	err := c.core().Get().Context(ctx).Namespace(namespace).Resource("events").
+		VersionedParams(&options, scheme.ParameterCodec).Do().Into(events)
+	return events, err
 }
 
 func (c *Client) NewSharedInformerFactory(ns string) (informers.SharedInformerFactory, error) {
@@ -37,32 +391,351 @@ func (c *Client) NewSharedInformerFactory(ns string) (informers.SharedInformerFa
 	return factory, nil
 }
 
-func newClientSet() (*kubernetes.Clientset, error) {
+// informers for resources without a typed client, like custom resources
+func (c *Client) NewDynamicSharedInformerFactory(ns string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, 0, ns, nil), nil
+}
+
+// the Lease replicas hold while they are the leader, identity names this replica in it
+func (c *Client) NewLeaseLock(namespace string, name string, identity string) (resourcelock.Interface, error) {
+	return &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Client:     c.clientSet.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}, nil
+}
+
+// fails when the api-server can not be reached or is not healthy, used for readiness
+func (c *Client) Ping() error {
+	return c.clientSet.Discovery().RESTClient().Get().AbsPath("/version").Timeout(5 * time.Second).Do().Error()
+}
+
+func (c *Client) GetNodes(ctx context.Context, options metav1.ListOptions) (*apiv1.NodeList, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	nodes := &apiv1.NodeList{}
+	err := c.core().Get().Context(ctx).Resource("nodes").VersionedParams(&options, scheme.ParameterCodec).Do().Into(nodes)
+	return nodes, err
+}
+
+// the current Node, fetched from the api-server and not from a cache
+func (c *Client) GetNode(ctx context.Context, name string) (*apiv1.Node, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	node := &apiv1.Node{}
+	err := c.core().Get().Context(ctx).Resource("nodes").Name(name).Do().Into(node)
+	return node, err
+}
+
+// no new Pods are scheduled to the Node, the ones running on it stay, see DrainNode
+func (c *Client) CordonNode(ctx context.Context, node *apiv1.Node) error {
+	return c.setUnschedulable(ctx, node, true)
+}
+
+// Pods are scheduled to the Node again
+func (c *Client) UncordonNode(ctx context.Context, node *apiv1.Node) error {
+	return c.setUnschedulable(ctx, node, false)
+}
+
+func (c *Client) setUnschedulable(ctx context.Context, node *apiv1.Node, unschedulable bool) error {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	return change(ctx, c.core().Patch(types.StrategicMergePatchType).Context(ctx).Resource("nodes").Name(node.ObjectMeta.Name)).
+		Body([]byte(patch)).Do().Error()
+}
+
+// owners can be of any kind, including custom resources of operators
+func (c *Client) GetOwner(ctx context.Context, namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	path, err := c.ownerPath(namespace, owner)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	// decoded as JSON below, so ask for it even when the client prefers protobuf
+	body, err := c.clientSet.Discovery().RESTClient().Get().Context(ctx).AbsPath(path).SetHeader("Accept", runtime.ContentTypeJSON).
+		Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	object := &unstructured.Unstructured{}
+	return object, object.UnmarshalJSON(body)
+}
+
+// set annotations, nil values remove them
+func (c *Client) AnnotateOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, annotations map[string]*string) error {
+	patch, err := annotationsPatch(annotations)
+	if err != nil {
+		return err
+	}
+	return c.PatchOwner(ctx, namespace, owner, types.MergePatchType, patch)
+}
+
+// custom resources do not support strategic merge patches, use a merge patch for owners of any kind
+func (c *Client) PatchOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, patchType types.PatchType, patch []byte) error {
+	path, err := c.ownerPath(namespace, owner)
+	if err != nil {
+		return err
+	}
+	actor, err := c.actor(namespace)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	return change(ctx, actor.Discovery().RESTClient().Patch(patchType).Context(ctx).AbsPath(path)).
+		Body(patch).Do().Error()
+}
+
+// replicas of a Deployment, ReplicaSet, StatefulSet or any other kind with a scale subresource
+func (c *Client) GetScale(ctx context.Context, namespace string, owner metav1.OwnerReference) (*autoscalingv1.Scale, error) {
+	path, err := c.ownerPath(namespace, owner)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	// decoded as JSON below, so ask for it even when the client prefers protobuf
+	body, err := c.clientSet.Discovery().RESTClient().Get().Context(ctx).AbsPath(path, "scale").
+		SetHeader("Accept", runtime.ContentTypeJSON).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	scale := &autoscalingv1.Scale{}
+	return scale, json.Unmarshal(body, scale)
+}
+
+// set scale.Spec.Replicas of a scale from GetScale, fails with a conflict when the replicas changed since,
+// so we never undo what someone else just did
+func (c *Client) UpdateScale(ctx context.Context, namespace string, owner metav1.OwnerReference, scale *autoscalingv1.Scale) error {
+	path, err := c.ownerPath(namespace, owner)
+	if err != nil {
+		return err
+	}
+	versioned := *scale
+	versioned.TypeMeta = metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"}
+	body, err := json.Marshal(&versioned)
+	if err != nil {
+		return err // untested section
+	}
+	actor, err := c.actor(namespace)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	return change(ctx, actor.Discovery().RESTClient().Put().Context(ctx).AbsPath(path, "scale")).
+		SetHeader("Content-Type", runtime.ContentTypeJSON).Body(body).Do().Error()
+}
+
+func (c *Client) GetConfigMap(ctx context.Context, namespace string, name string) (*apiv1.ConfigMap, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	configMap := &apiv1.ConfigMap{}
+	err := c.core().Get().Context(ctx).Namespace(namespace).Resource("configmaps").Name(name).Do().Into(configMap)
+	return configMap, err
+}
+
+// sets the keys of data and keeps the others, creates the ConfigMap when there is none yet, for state of our own,
+// so it is made as the client and never a dry run
+func (c *Client) SetConfigMapData(ctx context.Context, namespace string, name string, data map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err // untested section
+	}
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	err = c.core().Patch(types.MergePatchType).Context(ctx).Namespace(namespace).Resource("configmaps").Name(name).
+		Param("fieldManager", FieldManager).Body(patch).Do().Error()
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	configMap := &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: data}
+	return c.core().Post().Context(ctx).Namespace(namespace).Resource("configmaps").
+		Param("fieldManager", FieldManager).Body(configMap).Do().Error()
+}
+
+type dryRunKey struct{}
+
+// changes made with the returned context are authorized, validated and admitted by the api-server and its webhooks,
+// PodDisruptionBudgets are checked, but nothing is persisted, reads are unaffected
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// a change attributed to FieldManager, only a dry run with a context of WithDryRun
+func change(ctx context.Context, request *restclient.Request) *restclient.Request {
+	request = request.Param("fieldManager", FieldManager)
+	if IsDryRun(ctx) {
+		request = request.Param("dryRun", metav1.DryRunAll)
+	}
+	return request
+}
+
+// a copy of options that is only a dry run with a context of WithDryRun, nil options use the api-server defaults
+func dryRunOptions(ctx context.Context, options *metav1.DeleteOptions) *metav1.DeleteOptions {
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+	if !IsDryRun(ctx) {
+		return options
+	}
+	options = options.DeepCopy()
+	options.DryRun = []string{metav1.DryRunAll}
+	return options
+}
+
+func annotationsPatch(annotations map[string]*string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+}
+
+// the dynamic client of this client-go does not take a context either, so owners are requested by their path
+func (c *Client) ownerPath(namespace string, owner metav1.OwnerReference) (string, error) {
+	groupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return "", err
+	}
+	groupKind := schema.GroupKind{Group: groupVersion.Group, Kind: owner.Kind}
+	mapping, err := c.restMapper.RESTMapping(groupKind, groupVersion.Version)
+	if meta.IsNoMatchError(err) {
+		c.restMapper.Reset() // kind could have been installed since discovery was cached
+		mapping, err = c.restMapper.RESTMapping(groupKind, groupVersion.Version)
+	}
+	if err != nil {
+		return "", err
+	}
+	resource := mapping.Resource
+	path := "/apis/" + resource.Group + "/" + resource.Version
+	if resource.Group == "" {
+		path = "/api/" + resource.Version
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameRoot {
+		path += "/namespaces/" + namespace
+	}
+	return path + "/" + resource.Resource + "/" + owner.Name, nil
+}
+
+type ClientOptions struct {
+	// of a program embedding kube-remediator, like the one of its manager, excludes Kubeconfig, Context and InCluster,
+	// nil means they and the environment decide, the other options apply to a copy of it
+	Config     *restclient.Config
+	Kubeconfig string        // "" means the own cluster when running in one, else $KUBECONFIG or ~/.kube/config
+	Context    string        // of the kubeconfig, "" means its current context
+	InCluster  bool          // the service account of the Pod even when $KUBECONFIG is set, excludes Kubeconfig and Context
+	QPS        float32       // 0 means the one of Config or the client-go default of 5
+	Burst      int           // 0 means the one of Config or the client-go default of 10
+	Timeout    time.Duration // of each call, 0 means only the context of the caller limits it
+	// ContentTypeJSON only sends and accepts JSON, "" or ContentTypeProtobuf use protobuf for built-in kinds
+	ContentType string
+	UserAgent   string // "" means the client-go default, see UserAgent
+
+	// who the calls are made as, so the audit log of the api-server attributes them
+	TokenFile      string                         // replaces the credentials of the kubeconfig or Pod, re-read when rotated
+	Impersonate    restclient.ImpersonationConfig // of every call, "" UserName means none
+	ServiceAccount string                         // changes in a namespace impersonate this service account of it
+}
+
+// a given Config first, then InCluster, then an explicit kubeconfig or context, then the own cluster when running in
+// one, the environment only decides when nothing was asked for
+func newConfig(options ClientOptions) (*restclient.Config, error) {
 	var err error
 	var config *restclient.Config
-	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
-		kubeconfig := os.Getenv("KUBECONFIG")
+	explicit := options.Kubeconfig != "" || options.Context != ""
+	if options.Config != nil && (options.InCluster || explicit) {
+		return nil, fmt.Errorf("a given config can not be combined with the in-cluster config, a kubeconfig or context")
+	}
+	if options.InCluster && explicit {
+		return nil, fmt.Errorf("the in-cluster config can not be combined with a kubeconfig or context")
+	}
+	if options.Config != nil {
+		config = restclient.CopyConfig(options.Config) // the caller keeps using theirs
+	} else if !options.InCluster && (explicit || os.Getenv("KUBERNETES_SERVICE_HOST") == "") {
+		kubeconfig := options.Kubeconfig
+		if kubeconfig == "" {
+			kubeconfig = os.Getenv("KUBECONFIG")
+		}
 		if kubeconfig == "" {
 			kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
 		}
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+			&clientcmd.ConfigOverrides{CurrentContext: options.Context},
+		).ClientConfig()
 	} else {
-		// Reads config when in cluster
+		// the service account token and CA mounted into the Pod
 		config, err = rest.InClusterConfig()
 	}
-
 	if err != nil {
 		return nil, err
 	}
-
-	return kubernetes.NewForConfig(config)
+	if options.Config == nil || options.QPS != 0 {
+		config.QPS = options.QPS
+	}
+	if options.Config == nil || options.Burst != 0 {
+		config.Burst = options.Burst
+	}
+	if options.UserAgent != "" {
+		config.UserAgent = options.UserAgent
+	}
+	// smaller and faster to decode than JSON, which matters when listing and watching all Pods,
+	// custom resources only speak JSON so the api-server falls back to it for them
+	if options.ContentType != ContentTypeJSON {
+		config.ContentType = protobufMediaType
+		config.AcceptContentTypes = protobufMediaType + "," + runtime.ContentTypeJSON
+	}
+	if options.TokenFile != "" {
+		token, err := ioutil.ReadFile(options.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		// other credentials would be used before the token
+		config.BearerToken, config.BearerTokenFile = "", ""
+		config.Username, config.Password = "", ""
+		config.CertFile, config.CertData, config.KeyFile, config.KeyData = "", nil, "", nil
+		config.AuthProvider, config.ExecProvider = nil, nil
+		// this client-go does not re-read BearerTokenFile, rotated tokens like projected ones need it
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			refreshing, _ := transport.NewBearerAuthWithRefreshRoundTripper(strings.TrimSpace(string(token)), options.TokenFile, rt)
+			return refreshing // only fails without a token
+		})
+	}
+	if options.Impersonate.UserName != "" {
+		config.Impersonate = options.Impersonate
+	}
+	return config, nil
 }
 
-func NewClient(logger *zap.Logger) (*Client, error) {
-	clientSet, err := newClientSet()
+func NewClient(logger *zap.Logger, options ClientOptions) (*Client, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
+	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientSet.Discovery()))
 
-	return &Client{clientSet: clientSet, logger: logger}, err
+	return &Client{
+		logger:         logger,
+		config:         config,
+		clientSet:      clientSet,
+		dynamicClient:  dynamicClient,
+		restMapper:     restMapper,
+		timeout:        options.Timeout,
+		serviceAccount: options.ServiceAccount,
+		actors:         map[string]*kubernetes.Clientset{},
+	}, nil
 }
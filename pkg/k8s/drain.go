@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"strings"
+)
+
+// set by the kubelet on the api-server copy of static Pods
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// taints that keep new Pods off a Node, set while it is drained or about to be removed
+var drainingTaints = []string{"node.kubernetes.io/unschedulable", "ToBeDeletedByClusterAutoscaler"}
+
+// cordoned, drained or about to be removed by the cluster autoscaler, Pods there are going away anyway
+func IsNodeDraining(node *apiv1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		for _, draining := range drainingTaints {
+			if taint.Key == draining {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cordon the Node and evict its Pods like kubectl drain --ignore-daemonsets, without waiting for them to terminate,
+// Pods of DaemonSets and static Pods stay since they would come right back, evictions that fail do not stop the
+// others and are returned together, so a drain blocked by a PodDisruptionBudget can simply be retried
+func DrainNode(ctx context.Context, client ClientInterface, node *apiv1.Node, options *metav1.DeleteOptions) error {
+	if !node.Spec.Unschedulable {
+		if err := client.CordonNode(ctx, node); err != nil {
+			return err
+		}
+	}
+	var pods []apiv1.Pod
+	err := ListPodPages(ctx, client, "", metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.ObjectMeta.Name).String(),
+	}, func(list *apiv1.PodList) {
+		pods = append(pods, list.Items...)
+	})
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for i := range pods {
+		pod := &pods[i]
+		if staysOnDrainedNode(pod) {
+			continue
+		}
+		if err := client.EvictPod(ctx, pod, options); err != nil && !errors.IsNotFound(err) {
+			failed = append(failed, fmt.Sprintf("%s/%s: %v", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("draining Node %s: %s", node.ObjectMeta.Name, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func staysOnDrainedNode(pod *apiv1.Pod) bool {
+	if _, ok := pod.ObjectMeta.Annotations[mirrorPodAnnotation]; ok {
+		return true
+	}
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.Kind == "DaemonSet"
+}
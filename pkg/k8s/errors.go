@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"strings"
+)
+
+// what went wrong with an api call, so callers can react to it without knowing status codes
+type ErrorClass string
+
+const (
+	ErrorNone      ErrorClass = ""
+	ErrorNotFound  ErrorClass = "NotFound"  // the object is gone, deleting it is not needed anymore
+	ErrorConflict  ErrorClass = "Conflict"  // it changed since it was read, look at it again
+	ErrorForbidden ErrorClass = "Forbidden" // RBAC does not allow it, retrying does not help until the deployment is fixed
+	ErrorThrottled ErrorClass = "Throttled" // the api-server is overloaded, retrying later helps
+	ErrorTimeout   ErrorClass = "Timeout"   // no answer in time, retrying later helps
+	ErrorOther     ErrorClass = "Other"
+)
+
+// class of an error returned by the client, an *EvictionBlockedError is Other since it is about the Pod, not the call
+func Classify(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorNone
+	case errors.IsNotFound(err):
+		return ErrorNotFound
+	case errors.IsConflict(err):
+		return ErrorConflict
+	case errors.IsForbidden(err) || errors.IsUnauthorized(err):
+		return ErrorForbidden
+	case errors.IsTooManyRequests(err):
+		return ErrorThrottled
+	case errors.IsTimeout(err) || errors.IsServerTimeout(err) || isTimeout(err):
+		return ErrorTimeout
+	}
+	return ErrorOther
+}
+
+// retrying later can succeed, Forbidden and Other need a human
+func (c ErrorClass) Temporary() bool {
+	return c == ErrorConflict || c == ErrorThrottled || c == ErrorTimeout
+}
+
+// the call timed out or the client rate limiter gave up waiting before the call was made
+func isTimeout(err error) bool {
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() { // also *url.Error
+		return true
+	}
+	return strings.Contains(err.Error(), context.DeadlineExceeded.Error())
+}
+
+// a command run by ExecPod exited non-zero, it ran, so this is about the container, not the call
+type ExitError struct {
+	Code   int
+	Output string // stdout and stderr
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with %d: %s", e.Code, strings.TrimSpace(e.Output))
+}
+
+// cause the api-server attaches to evictions blocked by a PodDisruptionBudget since kubernetes 1.15
+const disruptionBudgetCause metav1.CauseType = "DisruptionBudget"
+
+// an eviction refused because it would violate a PodDisruptionBudget, retrying later can succeed
+type EvictionBlockedError struct {
+	PodDisruptionBudget string // empty when the api-server does not name it
+	Message             string
+}
+
+func (e *EvictionBlockedError) Error() string {
+	if e.PodDisruptionBudget == "" {
+		return "eviction blocked: " + e.Message
+	}
+	return fmt.Sprintf("eviction blocked by PodDisruptionBudget %s: %s", e.PodDisruptionBudget, e.Message)
+}
+
+// the api-server answers both a blocked eviction and its own overload with 429,
+// only the first is about the Pod, the other is a failed call like any other
+func evictionError(err error) error {
+	status, ok := err.(errors.APIStatus)
+	if !ok || !errors.IsTooManyRequests(err) {
+		return err
+	}
+	details := status.Status().Details
+	if details != nil {
+		for _, cause := range details.Causes {
+			if cause.Type == disruptionBudgetCause {
+				return &EvictionBlockedError{PodDisruptionBudget: budgetName(cause.Message), Message: status.Status().Message}
+			}
+		}
+	}
+	if strings.Contains(status.Status().Message, "disruption budget") {
+		return &EvictionBlockedError{Message: status.Status().Message}
+	}
+	return err
+}
+
+// "The disruption budget foo needs 2 healthy pods and has 1 currently"
+func budgetName(message string) string {
+	fields := strings.Fields(message)
+	if len(fields) < 4 || strings.Join(fields[:3], " ") != "The disruption budget" {
+		return ""
+	}
+	return fields[3]
+}
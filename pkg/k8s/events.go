@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sort"
+	"time"
+)
+
+// events of this Pod, not of an earlier one with the same name, oldest first
+func GetPodEvents(ctx context.Context, client ClientInterface, pod *apiv1.Pod) ([]apiv1.Event, error) {
+	return getEvents(ctx, client, pod.ObjectMeta.Namespace, fields.AndSelectors(
+		fields.OneTermEqualSelector("involvedObject.kind", "Pod"),
+		fields.OneTermEqualSelector("involvedObject.name", pod.ObjectMeta.Name),
+		fields.OneTermEqualSelector("involvedObject.uid", string(pod.ObjectMeta.UID)),
+	))
+}
+
+// events of the Node, oldest first, the kubelet reports them with the name of the Node as uid and in the
+// default namespace, so they are matched by name in all namespaces
+func GetNodeEvents(ctx context.Context, client ClientInterface, node *apiv1.Node) ([]apiv1.Event, error) {
+	return getEvents(ctx, client, "", fields.AndSelectors(
+		fields.OneTermEqualSelector("involvedObject.kind", "Node"),
+		fields.OneTermEqualSelector("involvedObject.name", node.ObjectMeta.Name),
+	))
+}
+
+// the api-server keeps events for an hour by default, so these are the recent ones
+func getEvents(ctx context.Context, client ClientInterface, namespace string, selector fields.Selector) ([]apiv1.Event, error) {
+	events, err := client.GetEvents(ctx, namespace, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return EventLastSeen(&events.Items[i]).Before(EventLastSeen(&events.Items[j]))
+	})
+	return events.Items, nil
+}
+
+// when the event last happened, newer components only set the event time or a series
+func EventLastSeen(event *apiv1.Event) time.Time {
+	if event.Series != nil {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
+
+// how often it happened, repeated events are reported once with a count
+func EventCount(event *apiv1.Event) int32 {
+	if event.Series != nil {
+		return event.Series.Count
+	}
+	if event.Count == 0 {
+		return 1
+	}
+	return event.Count
+}
+
+// how often events with the reason, like BackOff or FailedMount, were reported since then, an event last seen
+// after since counts with all its repetitions even when some of them happened before
+func CountEvents(events []apiv1.Event, reason string, since time.Time) int32 {
+	var count int32
+	for i := range events {
+		if events[i].Reason == reason && !EventLastSeen(&events[i]).Before(since) {
+			count += EventCount(&events[i])
+		}
+	}
+	return count
+}
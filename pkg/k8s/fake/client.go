@@ -0,0 +1,615 @@
+// In-memory k8s.ClientInterface for unit tests of remediators, so they can run against Pods, Nodes and owners
+// without an api-server or setting up a mock for every call
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// a change the client made, like evict default/api-xyz
+type Action struct {
+	Verb      string // delete, evict, patch, exec, cordon, uncordon, patchOwner or updateScale
+	Kind      string
+	Namespace string // "" for Nodes
+	Name      string
+	DryRun    bool // made with k8s.WithDryRun, checked like the real change but nothing changed
+}
+
+func (a Action) String() string {
+	action := a.Verb + " " + a.Kind + " " + a.Namespace + "/" + a.Name
+	if a.Namespace == "" {
+		action = a.Verb + " " + a.Kind + " " + a.Name
+	}
+	if a.DryRun {
+		action += " (dry run)"
+	}
+	return action
+}
+
+// Keeps Pods, Nodes, Events and PodDisruptionBudgets in a client-go fake clientset and owners in a fake dynamic
+// client, informers of the client watch them, so changes made through either show up like they would in a cluster
+type Client struct {
+	clientSet     *kubefake.Clientset
+	dynamicClient *dynamicfake.FakeDynamicClient
+
+	lock     sync.Mutex
+	logs     map[string]string     // by namespace/pod/container, with /previous for the previous run
+	execs    map[string]execResult // by namespace/pod/container
+	stdins   map[string][]string   // by namespace/pod/container, oldest first
+	failures map[string]error      // by method
+	actions  []Action
+}
+
+var _ k8s.ClientInterface = &Client{}
+
+// objects are the Pods, Nodes, Events, PodDisruptionBudgets or any other built-in kind the client starts with,
+// owners like ReplicaSets are added with AddOwner
+func NewClient(objects ...runtime.Object) *Client {
+	return &Client{
+		clientSet:     kubefake.NewSimpleClientset(objects...),
+		dynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		logs:          map[string]string{},
+		execs:         map[string]execResult{},
+		stdins:        map[string][]string{},
+		failures:      map[string]error{},
+	}
+}
+
+// to add, change or remove objects like other controllers would, and to see every call made to it
+func (c *Client) ClientSet() *kubefake.Clientset {
+	return c.clientSet
+}
+
+// owners of Pods of any kind, like ReplicaSet or a custom resource, see ReplicaSet and Deployment
+func (c *Client) AddOwner(owner *unstructured.Unstructured) error {
+	resource, err := ownerResource(metav1.OwnerReference{APIVersion: owner.GetAPIVersion(), Kind: owner.GetKind()})
+	if err != nil {
+		return err
+	}
+	_, err = c.dynamicClient.Resource(resource).Namespace(owner.GetNamespace()).Create(owner, metav1.CreateOptions{})
+	return err
+}
+
+// what GetPodLogs returns for the container, previous for its run before the last restart
+func (c *Client) SetLogs(namespace string, pod string, container string, previous bool, logs string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.logs[logsKey(namespace, pod, container, previous)] = logs
+}
+
+type execResult struct {
+	output string
+	code   int
+}
+
+// what ExecPod returns for commands in the container, an *k8s.ExitError when code is not 0
+func (c *Client) SetExecResult(namespace string, pod string, container string, output string, code int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.execs[logsKey(namespace, pod, container, false)] = execResult{output: output, code: code}
+}
+
+// stdin of every ExecPod call for the container, oldest first
+func (c *Client) ExecInputs(namespace string, pod string, container string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string(nil), c.stdins[logsKey(namespace, pod, container, false)]...)
+}
+
+// every call of the method, like "EvictPod", fails with err until it is called again with nil
+func (c *Client) Fail(method string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err == nil {
+		delete(c.failures, method)
+	} else {
+		c.failures[method] = err
+	}
+}
+
+// changes made so far, oldest first, failed ones are not included
+func (c *Client) Actions() []Action {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]Action(nil), c.actions...)
+}
+
+// injected with Fail, calls ignore their ctx so remediators can be run once with a canceled one like in the tests here
+func (c *Client) failure(method string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.failures[method]
+}
+
+func (c *Client) record(ctx context.Context, verb string, kind string, namespace string, name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.actions = append(c.actions, Action{Verb: verb, Kind: kind, Namespace: namespace, Name: name, DryRun: k8s.IsDryRun(ctx)})
+}
+
+// sorted by namespace and name, options.Limit pages them with the index of the next Pod as continue token
+func (c *Client) GetPods(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.PodList, error) {
+	if err := c.failure("GetPods"); err != nil {
+		return nil, err
+	}
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.clientSet.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err // untested section
+	}
+	pods := &apiv1.PodList{}
+	for i := range list.Items {
+		if fieldSelector.Matches(k8s.PodFields(&list.Items[i])) {
+			pods.Items = append(pods.Items, list.Items[i])
+		}
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		a, b := pods.Items[i].ObjectMeta, pods.Items[j].ObjectMeta
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	start := 0
+	if options.Continue != "" {
+		if start, err = strconv.Atoi(options.Continue); err != nil || start > len(pods.Items) {
+			return nil, errors.NewBadRequest("invalid continue token " + options.Continue)
+		}
+	}
+	pods.Items = pods.Items[start:]
+	if options.Limit > 0 && int64(len(pods.Items)) > options.Limit {
+		pods.Items = pods.Items[:options.Limit]
+		pods.ListMeta.Continue = strconv.Itoa(start + int(options.Limit))
+	}
+	return pods, nil
+}
+
+func (c *Client) GetPod(ctx context.Context, namespace string, name string) (*apiv1.Pod, error) {
+	if err := c.failure("GetPod"); err != nil {
+		return nil, err
+	}
+	return c.clientSet.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+}
+
+// preconditions on uid and resourceVersion fail with a conflict like on the api-server, a dry run only checks them
+func (c *Client) DeletePod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error {
+	if err := c.failure("DeletePod"); err != nil {
+		return err
+	}
+	if err := c.deletePod(ctx, pod, options); err != nil {
+		return err
+	}
+	c.record(ctx, "delete", "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	return nil
+}
+
+func (c *Client) deletePod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error {
+	pods := c.clientSet.CoreV1().Pods(pod.ObjectMeta.Namespace)
+	current, err := pods.Get(pod.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if options != nil && options.Preconditions != nil {
+		preconditions := options.Preconditions
+		if preconditions.UID != nil && *preconditions.UID != current.ObjectMeta.UID {
+			return conflict(pod, fmt.Sprintf("UID in precondition: %s, UID in object meta: %s", *preconditions.UID, current.ObjectMeta.UID))
+		}
+		if preconditions.ResourceVersion != nil && *preconditions.ResourceVersion != current.ObjectMeta.ResourceVersion {
+			return conflict(pod, fmt.Sprintf("ResourceVersion in precondition: %s, ResourceVersion in object meta: %s",
+				*preconditions.ResourceVersion, current.ObjectMeta.ResourceVersion))
+		}
+	}
+	if k8s.IsDryRun(ctx) {
+		return nil
+	}
+	return pods.Delete(pod.ObjectMeta.Name, options)
+}
+
+func conflict(pod *apiv1.Pod, message string) error {
+	return errors.NewConflict(schema.GroupResource{Resource: "pods"}, pod.ObjectMeta.Name, fmt.Errorf("Precondition failed: %s", message))
+}
+
+// deletes the Pod when every PodDisruptionBudget selecting it allows a disruption and takes that disruption,
+// otherwise fails with an *k8s.EvictionBlockedError, a dry run takes nothing
+func (c *Client) EvictPod(ctx context.Context, pod *apiv1.Pod, options *metav1.DeleteOptions) error {
+	if err := c.failure("EvictPod"); err != nil {
+		return err
+	}
+	current, err := c.clientSet.CoreV1().Pods(pod.ObjectMeta.Namespace).Get(pod.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	budgets := c.clientSet.PolicyV1beta1().PodDisruptionBudgets(pod.ObjectMeta.Namespace)
+	list, err := budgets.List(metav1.ListOptions{})
+	if err != nil {
+		return err // untested section
+	}
+	var selecting []*policyv1beta1.PodDisruptionBudget
+	for i := range list.Items {
+		budget := &list.Items[i]
+		selector := labels.Everything()
+		if budget.Spec.Selector != nil {
+			if selector, err = metav1.LabelSelectorAsSelector(budget.Spec.Selector); err != nil {
+				return err // untested section
+			}
+		}
+		if !selector.Matches(labels.Set(current.ObjectMeta.Labels)) {
+			continue
+		}
+		if budget.Status.PodDisruptionsAllowed <= 0 {
+			return &k8s.EvictionBlockedError{
+				PodDisruptionBudget: budget.ObjectMeta.Name,
+				Message:             "Cannot evict pod as it would violate the pod's disruption budget.",
+			}
+		}
+		selecting = append(selecting, budget)
+	}
+	if err := c.deletePod(ctx, pod, options); err != nil {
+		return err
+	}
+	for _, budget := range selecting {
+		if k8s.IsDryRun(ctx) {
+			continue
+		}
+		budget.Status.PodDisruptionsAllowed--
+		if _, err := budgets.UpdateStatus(budget); err != nil {
+			return err // untested section
+		}
+	}
+	c.record(ctx, "evict", "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	return nil
+}
+
+// set annotations, nil values remove them
+func (c *Client) AnnotatePod(ctx context.Context, pod *apiv1.Pod, annotations map[string]*string) error {
+	return c.PatchPod(ctx, pod, types.MergePatchType, annotationsPatch(annotations))
+}
+
+// JSON, merge and strategic merge patches, a dry run only checks the Pod exists
+func (c *Client) PatchPod(ctx context.Context, pod *apiv1.Pod, patchType types.PatchType, patch []byte) error {
+	if err := c.failure("PatchPod"); err != nil {
+		return err
+	}
+	pods := c.clientSet.CoreV1().Pods(pod.ObjectMeta.Namespace)
+	var err error
+	if k8s.IsDryRun(ctx) {
+		_, err = pods.Get(pod.ObjectMeta.Name, metav1.GetOptions{})
+	} else {
+		_, err = pods.Patch(pod.ObjectMeta.Name, patchType, patch)
+	}
+	if err != nil {
+		return err
+	}
+	c.record(ctx, "patch", "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	return nil
+}
+
+// set with SetLogs, "" for containers without logs
+func (c *Client) GetPodLogs(ctx context.Context, pod *apiv1.Pod, options *apiv1.PodLogOptions) (string, error) {
+	if err := c.failure("GetPodLogs"); err != nil {
+		return "", err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.logs[logsKey(pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, options.Container, options.Previous)], nil
+}
+
+// set with SetExecResult, commands in other containers succeed without output
+func (c *Client) ExecPod(ctx context.Context, pod *apiv1.Pod, container string, command []string, stdin []byte) (string, error) {
+	if err := c.failure("ExecPod"); err != nil {
+		return "", err
+	}
+	c.record(ctx, "exec", "Pod", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := logsKey(pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, container, false)
+	c.stdins[key] = append(c.stdins[key], string(stdin))
+	result := c.execs[key]
+	if result.code != 0 {
+		return result.output, &k8s.ExitError{Code: result.code, Output: result.output}
+	}
+	return result.output, nil
+}
+
+func logsKey(namespace string, pod string, container string, previous bool) string {
+	key := namespace + "/" + pod + "/" + container
+	if previous {
+		key += "/previous"
+	}
+	return key
+}
+
+func (c *Client) GetPodDisruptionBudgets(ctx context.Context, namespace string) (*policyv1beta1.PodDisruptionBudgetList, error) {
+	if err := c.failure("GetPodDisruptionBudgets"); err != nil {
+		return nil, err
+	}
+	return c.clientSet.PolicyV1beta1().PodDisruptionBudgets(namespace).List(metav1.ListOptions{})
+}
+
+// field selectors on the involved object, reason and type, like k8s.GetPodEvents uses
+func (c *Client) GetEvents(ctx context.Context, namespace string, options metav1.ListOptions) (*apiv1.EventList, error) {
+	if err := c.failure("GetEvents"); err != nil {
+		return nil, err
+	}
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.clientSet.CoreV1().Events(namespace).List(metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err // untested section
+	}
+	events := &apiv1.EventList{}
+	for _, event := range list.Items {
+		if fieldSelector.Matches(eventFields(&event)) {
+			events.Items = append(events.Items, event)
+		}
+	}
+	return events, nil
+}
+
+func eventFields(event *apiv1.Event) fields.Set {
+	return fields.Set{
+		"metadata.name":                  event.ObjectMeta.Name,
+		"metadata.namespace":             event.ObjectMeta.Namespace,
+		"involvedObject.kind":            event.InvolvedObject.Kind,
+		"involvedObject.namespace":       event.InvolvedObject.Namespace,
+		"involvedObject.name":            event.InvolvedObject.Name,
+		"involvedObject.uid":             string(event.InvolvedObject.UID),
+		"involvedObject.apiVersion":      event.InvolvedObject.APIVersion,
+		"involvedObject.resourceVersion": event.InvolvedObject.ResourceVersion,
+		"involvedObject.fieldPath":       event.InvolvedObject.FieldPath,
+		"reason":                         event.Reason,
+		"source":                         event.Source.Component,
+		"type":                           event.Type,
+	}
+}
+
+func (c *Client) NewSharedInformerFactory(ns string) (informers.SharedInformerFactory, error) {
+	if err := c.failure("NewSharedInformerFactory"); err != nil {
+		return nil, err
+	}
+	return informers.NewSharedInformerFactoryWithOptions(c.clientSet, 0, informers.WithNamespace(ns)), nil
+}
+
+// informers of owners and custom resources added with AddOwner
+func (c *Client) NewDynamicSharedInformerFactory(ns string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	if err := c.failure("NewDynamicSharedInformerFactory"); err != nil {
+		return nil, err
+	}
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamicClient, 0, ns, nil), nil
+}
+
+func (c *Client) NewLeaseLock(namespace string, name string, identity string) (resourcelock.Interface, error) {
+	if err := c.failure("NewLeaseLock"); err != nil {
+		return nil, err
+	}
+	return &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Client:     c.clientSet.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}, nil
+}
+
+func (c *Client) GetNodes(ctx context.Context, options metav1.ListOptions) (*apiv1.NodeList, error) {
+	if err := c.failure("GetNodes"); err != nil {
+		return nil, err
+	}
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.clientSet.CoreV1().Nodes().List(metav1.ListOptions{LabelSelector: options.LabelSelector})
+	if err != nil {
+		return nil, err // untested section
+	}
+	nodes := &apiv1.NodeList{}
+	for _, node := range list.Items {
+		if fieldSelector.Matches(fields.Set{"metadata.name": node.ObjectMeta.Name, "spec.unschedulable": strconv.FormatBool(node.Spec.Unschedulable)}) {
+			nodes.Items = append(nodes.Items, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (c *Client) GetNode(ctx context.Context, name string) (*apiv1.Node, error) {
+	if err := c.failure("GetNode"); err != nil {
+		return nil, err
+	}
+	return c.clientSet.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+}
+
+func (c *Client) CordonNode(ctx context.Context, node *apiv1.Node) error {
+	if err := c.failure("CordonNode"); err != nil {
+		return err
+	}
+	return c.setUnschedulable(ctx, node, true, "cordon")
+}
+
+func (c *Client) UncordonNode(ctx context.Context, node *apiv1.Node) error {
+	if err := c.failure("UncordonNode"); err != nil {
+		return err
+	}
+	return c.setUnschedulable(ctx, node, false, "uncordon")
+}
+
+// a dry run only checks the Node exists
+func (c *Client) setUnschedulable(ctx context.Context, node *apiv1.Node, unschedulable bool, verb string) error {
+	nodes := c.clientSet.CoreV1().Nodes()
+	var err error
+	if k8s.IsDryRun(ctx) {
+		_, err = nodes.Get(node.ObjectMeta.Name, metav1.GetOptions{})
+	} else {
+		patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+		_, err = nodes.Patch(node.ObjectMeta.Name, types.StrategicMergePatchType, []byte(patch))
+	}
+	if err != nil {
+		return err
+	}
+	c.record(ctx, verb, "Node", "", node.ObjectMeta.Name)
+	return nil
+}
+
+// added with AddOwner, cluster scoped owners like the Node of a static Pod are found without the namespace
+func (c *Client) GetOwner(ctx context.Context, namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	if err := c.failure("GetOwner"); err != nil {
+		return nil, err
+	}
+	return c.getOwner(namespace, owner)
+}
+
+func (c *Client) getOwner(namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	resource, err := ownerResource(owner)
+	if err != nil {
+		return nil, err
+	}
+	object, err := c.dynamicClient.Resource(resource).Namespace(namespace).Get(owner.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if clusterScoped, clusterErr := c.dynamicClient.Resource(resource).Get(owner.Name, metav1.GetOptions{}); clusterErr == nil {
+			return clusterScoped, nil
+		}
+	}
+	return object, err
+}
+
+// set annotations, nil values remove them
+func (c *Client) AnnotateOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, annotations map[string]*string) error {
+	return c.PatchOwner(ctx, namespace, owner, types.MergePatchType, annotationsPatch(annotations))
+}
+
+// JSON and merge patches, owners are unstructured so strategic merge patches are not supported,
+// a dry run only checks the owner exists
+func (c *Client) PatchOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, patchType types.PatchType, patch []byte) error {
+	if err := c.failure("PatchOwner"); err != nil {
+		return err
+	}
+	resource, err := ownerResource(owner)
+	if err != nil {
+		return err
+	}
+	owners := c.dynamicClient.Resource(resource).Namespace(namespace)
+	if k8s.IsDryRun(ctx) {
+		_, err = owners.Get(owner.Name, metav1.GetOptions{})
+	} else {
+		_, err = owners.Patch(owner.Name, patchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	c.record(ctx, "patchOwner", owner.Kind, namespace, owner.Name)
+	return nil
+}
+
+// from spec.replicas and status.replicas of the owner, its resourceVersion is the one of the scale
+func (c *Client) GetScale(ctx context.Context, namespace string, owner metav1.OwnerReference) (*autoscalingv1.Scale, error) {
+	if err := c.failure("GetScale"); err != nil {
+		return nil, err
+	}
+	object, err := c.getOwner(namespace, owner)
+	if err != nil {
+		return nil, err
+	}
+	replicas, _, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	current, _, _ := unstructured.NestedInt64(object.Object, "status", "replicas")
+	return &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Name: object.GetName(), Namespace: object.GetNamespace(), ResourceVersion: object.GetResourceVersion()},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: int32(replicas)},
+		Status:     autoscalingv1.ScaleStatus{Replicas: int32(current)},
+	}, nil
+}
+
+// sets spec.replicas of the owner, a conflict when the owner changed since the scale was read, also in a dry run
+func (c *Client) UpdateScale(ctx context.Context, namespace string, owner metav1.OwnerReference, scale *autoscalingv1.Scale) error {
+	if err := c.failure("UpdateScale"); err != nil {
+		return err
+	}
+	object, err := c.getOwner(namespace, owner)
+	if err != nil {
+		return err
+	}
+	resource, _ := ownerResource(owner) // getOwner already parsed it
+	if scale.ObjectMeta.ResourceVersion != "" && scale.ObjectMeta.ResourceVersion != object.GetResourceVersion() {
+		return errors.NewConflict(resource.GroupResource(), owner.Name, fmt.Errorf("the object has been modified"))
+	}
+	if err := unstructured.SetNestedField(object.Object, int64(scale.Spec.Replicas), "spec", "replicas"); err != nil {
+		return err // untested section
+	}
+	if !k8s.IsDryRun(ctx) {
+		if _, err := c.dynamicClient.Resource(resource).Namespace(object.GetNamespace()).Update(object, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	c.record(ctx, "updateScale", owner.Kind, namespace, owner.Name)
+	return nil
+}
+
+// like ReplicaSet -> replicasets, kinds whose plural is not guessed right need a Kind that is
+func (c *Client) GetConfigMap(ctx context.Context, namespace string, name string) (*apiv1.ConfigMap, error) {
+	if err := c.failure("GetConfigMap"); err != nil {
+		return nil, err
+	}
+	return c.clientSet.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+}
+
+// not an Action, like the real client it is our own state and never a dry run
+func (c *Client) SetConfigMapData(ctx context.Context, namespace string, name string, data map[string]string) error {
+	if err := c.failure("SetConfigMapData"); err != nil {
+		return err
+	}
+	configMaps := c.clientSet.CoreV1().ConfigMaps(namespace)
+	configMap, err := configMaps.Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(&apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: data})
+		return err
+	}
+	if err != nil {
+		return err // untested section
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	for key, value := range data {
+		configMap.Data[key] = value
+	}
+	_, err = configMaps.Update(configMap)
+	return err
+}
+
+func ownerResource(owner metav1.OwnerReference) (schema.GroupVersionResource, error) {
+	groupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	resource, _ := meta.UnsafeGuessKindToResource(groupVersion.WithKind(owner.Kind))
+	return resource, nil
+}
+
+func annotationsPatch(annotations map[string]*string) []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	return patch
+}
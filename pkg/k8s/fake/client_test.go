@@ -0,0 +1,191 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"gotest.tools/assert"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+	"time"
+)
+
+func names(pods []apiv1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.ObjectMeta.Name)
+	}
+	return names
+}
+
+func TestListsPodsInPagesMatchingSelectors(t *testing.T) {
+	onNode := fake.CrashLoopingPod("default", "c", 6)
+	onNode.Spec.NodeName = "node-1"
+	client := fake.NewClient(fake.Pod("default", "b"), onNode, fake.Pod("default", "a"), fake.Pod("other", "d"))
+	ctx := context.Background()
+
+	var pages [][]string
+	err := k8s.ListPodPages(ctx, client, "default", metav1.ListOptions{Limit: 2}, func(page *apiv1.PodList) {
+		pages = append(pages, names(page.Items))
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pages, [][]string{{"a", "b"}, {"c"}})
+
+	pods, err := client.GetPods(ctx, "", metav1.ListOptions{FieldSelector: "spec.nodeName=node-1"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(pods.Items), []string{"c"})
+}
+
+func TestEvictionsTakeDisruptionsOfBudget(t *testing.T) {
+	budget := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &intstr.IntOrString{IntVal: 1},
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		},
+		Status: policyv1beta1.PodDisruptionBudgetStatus{PodDisruptionsAllowed: 1},
+	}
+	a, b := fake.Pod("default", "a"), fake.Pod("default", "b")
+	a.ObjectMeta.Labels = map[string]string{"app": "api"}
+	b.ObjectMeta.Labels = map[string]string{"app": "api"}
+	client := fake.NewClient(a, b, budget)
+	ctx := context.Background()
+
+	assert.NilError(t, client.EvictPod(ctx, a, nil))
+	err := client.EvictPod(ctx, b, nil)
+	blocked, ok := err.(*k8s.EvictionBlockedError)
+	assert.Assert(t, ok, err)
+	assert.Equal(t, blocked.PodDisruptionBudget, "api")
+	_, err = client.GetPod(ctx, "default", "a")
+	assert.Equal(t, k8s.Classify(err), k8s.ErrorNotFound)
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "evict", Kind: "Pod", Namespace: "default", Name: "a"}})
+}
+
+func TestDeleteChecksPreconditions(t *testing.T) {
+	client := fake.NewClient(fake.FailedPod("default", "a", "OutOfcpu"))
+	pod := fake.FailedPod("default", "a", "OutOfcpu")
+	ctx := context.Background()
+
+	replaced := types.UID("replaced")
+	err := client.DeletePod(ctx, pod, &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &replaced}})
+	assert.Assert(t, apierrors.IsConflict(err), err)
+	assert.Equal(t, len(client.Actions()), 0)
+
+	uid := pod.ObjectMeta.UID
+	assert.NilError(t, client.DeletePod(ctx, pod, &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}))
+	assert.Equal(t, client.Actions()[0].String(), "delete Pod default/a")
+}
+
+func TestDryRunsChangeNothing(t *testing.T) {
+	pod := fake.Pod("default", "a")
+	client := fake.NewClient(pod, fake.Node("node-1"))
+	ctx := k8s.WithDryRun(context.Background())
+
+	assert.NilError(t, client.DeletePod(ctx, pod, nil))
+	assert.NilError(t, client.CordonNode(ctx, fake.Node("node-1")))
+	_, err := client.GetPod(ctx, "default", "a")
+	assert.NilError(t, err)
+	node, err := client.GetNode(ctx, "node-1")
+	assert.NilError(t, err)
+	assert.Equal(t, node.Spec.Unschedulable, false)
+	assert.Equal(t, client.Actions()[0].String(), "delete Pod default/a (dry run)")
+
+	replaced := types.UID("replaced")
+	err = client.DeletePod(ctx, pod, &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &replaced}})
+	assert.Assert(t, apierrors.IsConflict(err), err)
+}
+
+func TestFailsInjectedMethods(t *testing.T) {
+	client := fake.NewClient(fake.Node("node-1"))
+	ctx := context.Background()
+	node, err := client.GetNode(ctx, "node-1")
+	assert.NilError(t, err)
+
+	client.Fail("CordonNode", errors.New("nodes is forbidden"))
+	assert.Error(t, client.CordonNode(ctx, node), "nodes is forbidden")
+	client.Fail("CordonNode", nil)
+	assert.NilError(t, client.CordonNode(ctx, node))
+	node, err = client.GetNode(ctx, "node-1")
+	assert.NilError(t, err)
+	assert.Equal(t, node.Spec.Unschedulable, true)
+}
+
+func TestExecsWithSetResults(t *testing.T) {
+	pod := fake.Pod("default", "api-5d8f-x2x")
+	client := fake.NewClient(pod)
+	ctx := context.Background()
+	client.SetExecResult("default", "api-5d8f-x2x", "sidecar", "busy", 1)
+
+	output, err := client.ExecPod(ctx, pod, "sidecar", []string{"/drain"}, []byte("{}"))
+	assert.Equal(t, output, "busy")
+	assert.Error(t, err, "command exited with 1: busy")
+	assert.Equal(t, err.(*k8s.ExitError).Code, 1)
+	output, err = client.ExecPod(ctx, pod, "app", []string{"true"}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, output, "")
+	assert.DeepEqual(t, client.ExecInputs("default", "api-5d8f-x2x", "sidecar"), []string{"{}"})
+}
+
+func TestPatchesAndScalesOwners(t *testing.T) {
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	pod := fake.OwnedBy(fake.Pod("default", "api-5d8f-x2x"), replicaSet)
+	client := fake.NewClient(pod)
+	assert.NilError(t, client.AddOwner(replicaSet))
+	ctx := context.Background()
+
+	owner, err := k8s.GetTopOwner(ctx, client, pod)
+	assert.NilError(t, err)
+	assert.Equal(t, owner.Key(), "default/ReplicaSet/api-5d8f")
+
+	reference := pod.ObjectMeta.OwnerReferences[0]
+	attempts := "1"
+	assert.NilError(t, client.AnnotateOwner(ctx, "default", reference, map[string]*string{"kube-remediator/remediations": &attempts}))
+	object, err := client.GetOwner(ctx, "default", reference)
+	assert.NilError(t, err)
+	assert.Equal(t, object.GetAnnotations()["kube-remediator/remediations"], "1")
+
+	scale, err := client.GetScale(ctx, "default", reference)
+	assert.NilError(t, err)
+	assert.Equal(t, scale.Spec.Replicas, int32(3))
+	scale.Spec.Replicas = 0
+	assert.NilError(t, client.UpdateScale(ctx, "default", reference, scale))
+	scale, err = client.GetScale(ctx, "default", reference)
+	assert.NilError(t, err)
+	assert.Equal(t, scale.Spec.Replicas, int32(0))
+}
+
+func TestInformersSeeChanges(t *testing.T) {
+	client := fake.NewClient()
+	stop := make(chan struct{})
+	defer close(stop)
+	pods := k8s.NewPodCache(client, stop, false)
+	assert.NilError(t, pods.Watch([]string{""}))
+	assert.Assert(t, pods.WaitForSync(stop, []string{""}))
+
+	_, err := client.ClientSet().CoreV1().Pods("default").Create(fake.CompletedPod("default", "job-x", 25*time.Hour))
+	assert.NilError(t, err)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cached, err := pods.ListPods([]string{""}, labels.Everything(), fields.Everything())
+		assert.NilError(t, err)
+		if len(cached) == 1 {
+			assert.Equal(t, cached[0].Status.Phase, apiv1.PodSucceeded)
+			pod, err := pods.GetPod("default", "job-x")
+			assert.NilError(t, err)
+			assert.Equal(t, pod, cached[0])
+			_, err = pods.GetPod("default", "job-y")
+			assert.Equal(t, k8s.Classify(err), k8s.ErrorNotFound)
+			return
+		}
+		assert.Assert(t, time.Now().Before(deadline), "Pod not cached")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,131 @@
+package fake
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"time"
+)
+
+// a Running Pod with a single ready container "app", created an hour ago and owned by nothing, see OwnedBy
+func Pod(namespace string, name string) *apiv1.Pod {
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	return &apiv1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			UID:               types.UID(namespace + "/" + name),
+			ResourceVersion:   "1",
+			CreationTimestamp: started,
+		},
+		Spec: apiv1.PodSpec{
+			Containers:    []apiv1.Container{{Name: "app", Image: "app:1"}},
+			RestartPolicy: apiv1.RestartPolicyAlways,
+		},
+		Status: apiv1.PodStatus{
+			Phase:     apiv1.PodRunning,
+			StartTime: &started,
+			Conditions: []apiv1.PodCondition{
+				{Type: apiv1.PodReady, Status: apiv1.ConditionTrue},
+			},
+			ContainerStatuses: []apiv1.ContainerStatus{{
+				Name:  "app",
+				Image: "app:1",
+				Ready: true,
+				State: apiv1.ContainerState{Running: &apiv1.ContainerStateRunning{StartedAt: started}},
+			}},
+		},
+	}
+}
+
+// a Pod whose container "app" waits in CrashLoopBackOff after it exited with an error restarts times
+func CrashLoopingPod(namespace string, name string, restarts int32) *apiv1.Pod {
+	pod := Pod(namespace, name)
+	pod.Status.Conditions[0].Status = apiv1.ConditionFalse
+	status := &pod.Status.ContainerStatuses[0]
+	status.Ready = false
+	status.RestartCount = restarts
+	status.State = apiv1.ContainerState{Waiting: &apiv1.ContainerStateWaiting{
+		Reason:  "CrashLoopBackOff",
+		Message: "back-off 5m0s restarting failed container=app pod=" + name,
+	}}
+	status.LastTerminationState = apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{
+		ExitCode: 1,
+		Reason:   "Error",
+	}}
+	return pod
+}
+
+// a Pod the kubelet failed, reason is like OutOfcpu, OutOfmemory or Evicted
+func FailedPod(namespace string, name string, reason string) *apiv1.Pod {
+	pod := Pod(namespace, name)
+	pod.Status.Phase = apiv1.PodFailed
+	pod.Status.Reason = reason
+	pod.Status.Message = "Pod " + reason
+	pod.Status.Conditions = nil
+	pod.Status.ContainerStatuses = nil
+	return pod
+}
+
+// a Pod whose container "app" exited successfully, created age ago
+func CompletedPod(namespace string, name string, age time.Duration) *apiv1.Pod {
+	pod := Pod(namespace, name)
+	pod.ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+	pod.Spec.RestartPolicy = apiv1.RestartPolicyNever
+	pod.Status.Phase = apiv1.PodSucceeded
+	pod.Status.Conditions[0].Status = apiv1.ConditionFalse
+	status := &pod.Status.ContainerStatuses[0]
+	status.Ready = false
+	status.State = apiv1.ContainerState{Terminated: &apiv1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}
+	return pod
+}
+
+// make owner the controller of the Pod, returns the Pod
+func OwnedBy(pod *apiv1.Pod, owner *unstructured.Unstructured) *apiv1.Pod {
+	controller := true
+	pod.ObjectMeta.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: owner.GetAPIVersion(),
+		Kind:       owner.GetKind(),
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+		Controller: &controller,
+	}}
+	return pod
+}
+
+// a ReplicaSet with all replicas ready, to add with AddOwner
+func ReplicaSet(namespace string, name string, replicas int32) *unstructured.Unstructured {
+	return workload("ReplicaSet", namespace, name, replicas)
+}
+
+// a Deployment with all replicas ready, to add with AddOwner
+func Deployment(namespace string, name string, replicas int32) *unstructured.Unstructured {
+	return workload("Deployment", namespace, name, replicas)
+}
+
+func workload(kind string, namespace string, name string, replicas int32) *unstructured.Unstructured {
+	owner := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(replicas)},
+		"status": map[string]interface{}{"replicas": int64(replicas), "readyReplicas": int64(replicas)},
+	}}
+	owner.SetAPIVersion("apps/v1")
+	owner.SetKind(kind)
+	owner.SetNamespace(namespace)
+	owner.SetName(name)
+	owner.SetUID(types.UID(kind + "/" + namespace + "/" + name))
+	owner.SetResourceVersion("1")
+	return owner
+}
+
+// a Ready Node that Pods can be scheduled to
+func Node(name string) *apiv1.Node {
+	return &apiv1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name), ResourceVersion: "1"},
+		Status: apiv1.NodeStatus{
+			Conditions: []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: apiv1.ConditionTrue}},
+		},
+	}
+}
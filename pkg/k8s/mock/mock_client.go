@@ -5,10 +5,17 @@
 package mock_k8s
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
+	v10 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
+	v1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	types "k8s.io/apimachinery/pkg/types"
+	dynamicinformer "k8s.io/client-go/dynamic/dynamicinformer"
 	informers "k8s.io/client-go/informers"
+	resourcelock "k8s.io/client-go/tools/leaderelection/resourcelock"
 	reflect "reflect"
 )
 
@@ -36,32 +43,149 @@ func (m *MockClientInterface) EXPECT() *MockClientInterfaceMockRecorder {
 }
 
 // GetPods mocks base method
-func (m *MockClientInterface) GetPods(namespace string, options metav1.ListOptions) (*v1.PodList, error) {
+func (m *MockClientInterface) GetPods(ctx context.Context, namespace string, options metav1.ListOptions) (*v1.PodList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPods", namespace)
+	ret := m.ctrl.Call(m, "GetPods", ctx, namespace)
 	ret0, _ := ret[0].(*v1.PodList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPods indicates an expected call of GetPods
-func (mr *MockClientInterfaceMockRecorder) GetPods(namespace interface{}) *gomock.Call {
+func (mr *MockClientInterfaceMockRecorder) GetPods(ctx, namespace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPods", reflect.TypeOf((*MockClientInterface)(nil).GetPods), namespace)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPods", reflect.TypeOf((*MockClientInterface)(nil).GetPods), ctx, namespace)
+}
+
+// GetPod mocks base method
+func (m *MockClientInterface) GetPod(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPod", ctx, namespace, name)
+	ret0, _ := ret[0].(*v1.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPod indicates an expected call of GetPod
+func (mr *MockClientInterfaceMockRecorder) GetPod(ctx, namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPod", reflect.TypeOf((*MockClientInterface)(nil).GetPod), ctx, namespace, name)
 }
 
 // DeletePod mocks base method
-func (m *MockClientInterface) DeletePod(pod *v1.Pod) error {
+func (m *MockClientInterface) DeletePod(ctx context.Context, pod *v1.Pod, options *metav1.DeleteOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePod", pod)
+	ret := m.ctrl.Call(m, "DeletePod", ctx, pod, options)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePod indicates an expected call of DeletePod
-func (mr *MockClientInterfaceMockRecorder) DeletePod(pod interface{}) *gomock.Call {
+func (mr *MockClientInterfaceMockRecorder) DeletePod(ctx, pod, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePod", reflect.TypeOf((*MockClientInterface)(nil).DeletePod), ctx, pod, options)
+}
+
+// EvictPod mocks base method
+func (m *MockClientInterface) EvictPod(ctx context.Context, pod *v1.Pod, options *metav1.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictPod", ctx, pod, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictPod indicates an expected call of EvictPod
+func (mr *MockClientInterfaceMockRecorder) EvictPod(ctx, pod, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictPod", reflect.TypeOf((*MockClientInterface)(nil).EvictPod), ctx, pod, options)
+}
+
+// AnnotatePod mocks base method
+func (m *MockClientInterface) AnnotatePod(ctx context.Context, pod *v1.Pod, annotations map[string]*string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotatePod", ctx, pod, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotatePod indicates an expected call of AnnotatePod
+func (mr *MockClientInterfaceMockRecorder) AnnotatePod(ctx, pod, annotations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotatePod", reflect.TypeOf((*MockClientInterface)(nil).AnnotatePod), ctx, pod, annotations)
+}
+
+// PatchPod mocks base method
+func (m *MockClientInterface) PatchPod(ctx context.Context, pod *v1.Pod, patchType types.PatchType, patch []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchPod", ctx, pod, patchType, patch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchPod indicates an expected call of PatchPod
+func (mr *MockClientInterfaceMockRecorder) PatchPod(ctx, pod, patchType, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchPod", reflect.TypeOf((*MockClientInterface)(nil).PatchPod), ctx, pod, patchType, patch)
+}
+
+// GetPodLogs mocks base method
+func (m *MockClientInterface) GetPodLogs(ctx context.Context, pod *v1.Pod, options *v1.PodLogOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPodLogs", ctx, pod, options)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPodLogs indicates an expected call of GetPodLogs
+func (mr *MockClientInterfaceMockRecorder) GetPodLogs(ctx, pod, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodLogs", reflect.TypeOf((*MockClientInterface)(nil).GetPodLogs), ctx, pod, options)
+}
+
+// ExecPod mocks base method
+func (m *MockClientInterface) ExecPod(ctx context.Context, pod *v1.Pod, container string, command []string, stdin []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecPod", ctx, pod, container, command, stdin)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecPod indicates an expected call of ExecPod
+func (mr *MockClientInterfaceMockRecorder) ExecPod(ctx, pod, container, command, stdin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecPod", reflect.TypeOf((*MockClientInterface)(nil).ExecPod), ctx, pod, container, command, stdin)
+}
+
+// GetPodDisruptionBudgets mocks base method
+func (m *MockClientInterface) GetPodDisruptionBudgets(ctx context.Context, namespace string) (*v1beta1.PodDisruptionBudgetList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPodDisruptionBudgets", ctx, namespace)
+	ret0, _ := ret[0].(*v1beta1.PodDisruptionBudgetList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPodDisruptionBudgets indicates an expected call of GetPodDisruptionBudgets
+func (mr *MockClientInterfaceMockRecorder) GetPodDisruptionBudgets(ctx, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodDisruptionBudgets", reflect.TypeOf((*MockClientInterface)(nil).GetPodDisruptionBudgets), ctx, namespace)
+}
+
+// GetEvents mocks base method
+func (m *MockClientInterface) GetEvents(ctx context.Context, namespace string, options metav1.ListOptions) (*v1.EventList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", ctx, namespace, options)
+	ret0, _ := ret[0].(*v1.EventList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents
+func (mr *MockClientInterfaceMockRecorder) GetEvents(ctx, namespace, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePod", reflect.TypeOf((*MockClientInterface)(nil).DeletePod), pod)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockClientInterface)(nil).GetEvents), ctx, namespace, options)
 }
 
 // NewSharedInformerFactory mocks base method
@@ -78,3 +202,192 @@ func (mr *MockClientInterfaceMockRecorder) NewSharedInformerFactory(ns interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSharedInformerFactory", reflect.TypeOf((*MockClientInterface)(nil).NewSharedInformerFactory), ns)
 }
+
+// NewDynamicSharedInformerFactory mocks base method
+func (m *MockClientInterface) NewDynamicSharedInformerFactory(ns string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewDynamicSharedInformerFactory", ns)
+	ret0, _ := ret[0].(dynamicinformer.DynamicSharedInformerFactory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewDynamicSharedInformerFactory indicates an expected call of NewDynamicSharedInformerFactory
+func (mr *MockClientInterfaceMockRecorder) NewDynamicSharedInformerFactory(ns interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDynamicSharedInformerFactory", reflect.TypeOf((*MockClientInterface)(nil).NewDynamicSharedInformerFactory), ns)
+}
+
+// NewLeaseLock mocks base method
+func (m *MockClientInterface) NewLeaseLock(namespace, name, identity string) (resourcelock.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewLeaseLock", namespace, name, identity)
+	ret0, _ := ret[0].(resourcelock.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewLeaseLock indicates an expected call of NewLeaseLock
+func (mr *MockClientInterfaceMockRecorder) NewLeaseLock(namespace, name, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewLeaseLock", reflect.TypeOf((*MockClientInterface)(nil).NewLeaseLock), namespace, name, identity)
+}
+
+// GetNodes mocks base method
+func (m *MockClientInterface) GetNodes(ctx context.Context, options metav1.ListOptions) (*v1.NodeList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodes", ctx, options)
+	ret0, _ := ret[0].(*v1.NodeList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNodes indicates an expected call of GetNodes
+func (mr *MockClientInterfaceMockRecorder) GetNodes(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodes", reflect.TypeOf((*MockClientInterface)(nil).GetNodes), ctx, options)
+}
+
+// CordonNode mocks base method
+func (m *MockClientInterface) CordonNode(ctx context.Context, node *v1.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CordonNode", ctx, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CordonNode indicates an expected call of CordonNode
+func (mr *MockClientInterfaceMockRecorder) CordonNode(ctx, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CordonNode", reflect.TypeOf((*MockClientInterface)(nil).CordonNode), ctx, node)
+}
+
+// GetNode mocks base method
+func (m *MockClientInterface) GetNode(ctx context.Context, name string) (*v1.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNode", ctx, name)
+	ret0, _ := ret[0].(*v1.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNode indicates an expected call of GetNode
+func (mr *MockClientInterfaceMockRecorder) GetNode(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNode", reflect.TypeOf((*MockClientInterface)(nil).GetNode), ctx, name)
+}
+
+// UncordonNode mocks base method
+func (m *MockClientInterface) UncordonNode(ctx context.Context, node *v1.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UncordonNode", ctx, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UncordonNode indicates an expected call of UncordonNode
+func (mr *MockClientInterfaceMockRecorder) UncordonNode(ctx, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UncordonNode", reflect.TypeOf((*MockClientInterface)(nil).UncordonNode), ctx, node)
+}
+
+// GetOwner mocks base method
+func (m *MockClientInterface) GetOwner(ctx context.Context, namespace string, owner metav1.OwnerReference) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwner", ctx, namespace, owner)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwner indicates an expected call of GetOwner
+func (mr *MockClientInterfaceMockRecorder) GetOwner(ctx, namespace, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwner", reflect.TypeOf((*MockClientInterface)(nil).GetOwner), ctx, namespace, owner)
+}
+
+// AnnotateOwner mocks base method
+func (m *MockClientInterface) AnnotateOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, annotations map[string]*string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotateOwner", ctx, namespace, owner, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotateOwner indicates an expected call of AnnotateOwner
+func (mr *MockClientInterfaceMockRecorder) AnnotateOwner(ctx, namespace, owner, annotations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotateOwner", reflect.TypeOf((*MockClientInterface)(nil).AnnotateOwner), ctx, namespace, owner, annotations)
+}
+
+// PatchOwner mocks base method
+func (m *MockClientInterface) PatchOwner(ctx context.Context, namespace string, owner metav1.OwnerReference, patchType types.PatchType, patch []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchOwner", ctx, namespace, owner, patchType, patch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchOwner indicates an expected call of PatchOwner
+func (mr *MockClientInterfaceMockRecorder) PatchOwner(ctx, namespace, owner, patchType, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchOwner", reflect.TypeOf((*MockClientInterface)(nil).PatchOwner), ctx, namespace, owner, patchType, patch)
+}
+
+// GetScale mocks base method
+func (m *MockClientInterface) GetScale(ctx context.Context, namespace string, owner metav1.OwnerReference) (*v10.Scale, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScale", ctx, namespace, owner)
+	ret0, _ := ret[0].(*v10.Scale)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScale indicates an expected call of GetScale
+func (mr *MockClientInterfaceMockRecorder) GetScale(ctx, namespace, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScale", reflect.TypeOf((*MockClientInterface)(nil).GetScale), ctx, namespace, owner)
+}
+
+// UpdateScale mocks base method
+func (m *MockClientInterface) UpdateScale(ctx context.Context, namespace string, owner metav1.OwnerReference, scale *v10.Scale) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScale", ctx, namespace, owner, scale)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScale indicates an expected call of UpdateScale
+func (mr *MockClientInterfaceMockRecorder) UpdateScale(ctx, namespace, owner, scale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScale", reflect.TypeOf((*MockClientInterface)(nil).UpdateScale), ctx, namespace, owner, scale)
+}
+
+// GetConfigMap mocks base method
+func (m *MockClientInterface) GetConfigMap(ctx context.Context, namespace, name string) (*v1.ConfigMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigMap", ctx, namespace, name)
+	ret0, _ := ret[0].(*v1.ConfigMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigMap indicates an expected call of GetConfigMap
+func (mr *MockClientInterfaceMockRecorder) GetConfigMap(ctx, namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigMap", reflect.TypeOf((*MockClientInterface)(nil).GetConfigMap), ctx, namespace, name)
+}
+
+// SetConfigMapData mocks base method
+func (m *MockClientInterface) SetConfigMapData(ctx context.Context, namespace, name string, data map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetConfigMapData", ctx, namespace, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetConfigMapData indicates an expected call of SetConfigMapData
+func (mr *MockClientInterfaceMockRecorder) SetConfigMapData(ctx, namespace, name, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfigMapData", reflect.TypeOf((*MockClientInterface)(nil).SetConfigMapData), ctx, namespace, name, data)
+}
@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watched copy of all Namespaces, so matching Pods against Namespace labels does not hit the api-server
+type NamespaceCache struct {
+	informer cache.SharedIndexInformer
+	lister   listersv1.NamespaceLister
+}
+
+func NewNamespaceCache(client ClientInterface) (*NamespaceCache, error) {
+	informerFactory, err := client.NewSharedInformerFactory("")
+	if err != nil {
+		return nil, err
+	}
+	namespaces := informerFactory.Core().V1().Namespaces()
+	return &NamespaceCache{informer: namespaces.Informer(), lister: namespaces.Lister()}, nil
+}
+
+// start watching and wait until all Namespaces are cached, false when stopped before that
+func (c *NamespaceCache) Start(stop <-chan struct{}) bool {
+	go c.informer.Run(stop)
+	return cache.WaitForCacheSync(stop, c.informer.HasSynced)
+}
+
+func (c *NamespaceCache) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+func (c *NamespaceCache) GetNamespace(name string) (*apiv1.Namespace, error) {
+	return c.lister.Get(name)
+}
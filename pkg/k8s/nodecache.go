@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watched copy of all Nodes, so looking up the Node of every Pod does not hit the api-server
+type NodeCache struct {
+	informer cache.SharedIndexInformer
+	lister   listersv1.NodeLister
+}
+
+// slim caches Nodes without what remediators never look at, see SlimNode
+func NewNodeCache(client ClientInterface, slim bool) (*NodeCache, error) {
+	informerFactory, err := client.NewSharedInformerFactory("")
+	if err != nil {
+		return nil, err
+	}
+	var informer cache.SharedIndexInformer
+	if slim {
+		informer = informerFactory.InformerFor(&apiv1.Node{}, newSlimNodeInformer)
+	} else {
+		informer = informerFactory.Core().V1().Nodes().Informer()
+	}
+	return &NodeCache{informer: informer, lister: listersv1.NewNodeLister(informer.GetIndexer())}, nil
+}
+
+// start watching and wait until all Nodes are cached, false when stopped before that
+func (c *NodeCache) Start(stop <-chan struct{}) bool {
+	go c.informer.Run(stop)
+	return cache.WaitForCacheSync(stop, c.informer.HasSynced)
+}
+
+func (c *NodeCache) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+func (c *NodeCache) GetNode(name string) (*apiv1.Node, error) {
+	return c.lister.Get(name)
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// chains are short, Pod -> ReplicaSet -> Deployment or Pod -> Job -> CronJob, longer ones are cycles
+const maxOwnerChain = 10
+
+// one controller above a Pod, fetched as it is now
+type Owner struct {
+	Reference metav1.OwnerReference
+	Object    *unstructured.Unstructured
+}
+
+// namespace/kind/name, the same for every Pod of the owner
+func (o Owner) Key() string {
+	return o.Object.GetNamespace() + "/" + o.Reference.Kind + "/" + o.Reference.Name
+}
+
+// the controller that recreates the object, the first owner when none is marked as controller, nil when it has none
+func controllerOf(object metav1.Object) *metav1.OwnerReference {
+	if owner := metav1.GetControllerOf(object); owner != nil {
+		return owner
+	}
+	if owners := object.GetOwnerReferences(); len(owners) > 0 {
+		return &owners[0]
+	}
+	return nil
+}
+
+// the controllers above the object, its own first and the top-level workload last, like ReplicaSet then Deployment,
+// empty when it has none, an owner that is already gone ends the chain since the ones above it can not be found
+func GetOwnerChain(ctx context.Context, client ClientInterface, object metav1.Object) ([]Owner, error) {
+	var chain []Owner
+	namespace, name := object.GetNamespace(), object.GetName()
+	for reference := controllerOf(object); reference != nil; reference = controllerOf(object) {
+		if len(chain) == maxOwnerChain {
+			return nil, fmt.Errorf("owners of %s/%s form a cycle or are nested more than %d deep", namespace, name, maxOwnerChain)
+		}
+		owner, err := client.GetOwner(ctx, namespace, *reference)
+		if Classify(err) == ErrorNotFound {
+			return chain, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, Owner{Reference: *reference, Object: owner})
+		object = owner
+	}
+	return chain, nil
+}
+
+// the workload users deploy and change, like the Deployment of a Pod, nil when the object has no owner
+func GetTopOwner(ctx context.Context, client ClientInterface, object metav1.Object) (*Owner, error) {
+	chain, err := GetOwnerChain(ctx, client, object)
+	if err != nil || len(chain) == 0 {
+		return nil, err
+	}
+	return &chain[len(chain)-1], nil
+}
@@ -0,0 +1,220 @@
+package k8s
+
+import (
+	"fmt"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+)
+
+const nodeNameIndex = "spec.nodeName"
+
+type PodHandler func(pod *apiv1.Pod)
+
+type podSubscription struct {
+	handler PodHandler
+	lock    sync.RWMutex // held while the handler runs
+	stopped bool
+}
+
+// Watched copy of the Pods in the namespaces remediators asked for, shared by them so every Pod is watched once
+// instead of once per remediator and scans do not list all Pods from the api-server
+type PodCache struct {
+	client ClientInterface
+	stop   <-chan struct{}
+	slim   bool // cache Pods slimmed by SlimPod
+
+	lock          sync.RWMutex
+	informers     map[string]cache.SharedIndexInformer // by namespace, "" watches all of them
+	subscriptions map[string][]*podSubscription        // by namespace of the informer
+}
+
+// stop ends all watches, the cache outlives the remediators using it when the config is reloaded,
+// slim caches Pods without what remediators never look at, see SlimPod
+func NewPodCache(client ClientInterface, stop <-chan struct{}, slim bool) *PodCache {
+	return &PodCache{
+		client:        client,
+		stop:          stop,
+		slim:          slim,
+		informers:     map[string]cache.SharedIndexInformer{},
+		subscriptions: map[string][]*podSubscription{},
+	}
+}
+
+// start watching the namespaces that are not watched yet, "" means all of them,
+// Pods of a namespace are only cached once even when several remediators watch it
+func (c *PodCache) Watch(namespaces []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, namespace := range namespaces {
+		if _, ok := c.informers[namespace]; ok {
+			continue
+		}
+		informerFactory, err := c.client.NewSharedInformerFactory(namespace)
+		if err != nil {
+			return err
+		}
+		var informer cache.SharedIndexInformer
+		if c.slim {
+			informer = informerFactory.InformerFor(&apiv1.Pod{}, newSlimPodInformer(namespace))
+		} else {
+			informer = informerFactory.Core().V1().Pods().Informer()
+		}
+		err = informer.AddIndexers(cache.Indexers{nodeNameIndex: func(obj interface{}) ([]string, error) {
+			return []string{obj.(*apiv1.Pod).Spec.NodeName}, nil
+		}})
+		if err != nil {
+			return err // untested section
+		}
+		watched := namespace
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) { c.notify(watched, newObj.(*apiv1.Pod)) },
+		})
+		c.informers[namespace] = informer
+		go informer.Run(c.stop)
+	}
+	return nil
+}
+
+// wait until the Pods of the namespaces are cached, false when stopped before that
+func (c *PodCache) WaitForSync(stop <-chan struct{}, namespaces []string) bool {
+	var synced []cache.InformerSynced
+	c.lock.RLock()
+	for _, namespace := range namespaces {
+		if informer, ok := c.informers[namespace]; ok {
+			synced = append(synced, informer.HasSynced)
+		}
+	}
+	c.lock.RUnlock()
+	return cache.WaitForCacheSync(stop, synced...)
+}
+
+// true once the Pods of all watched namespaces are cached
+func (c *PodCache) HasSynced() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	for _, informer := range c.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// call handler with the new Pod whenever a Pod in the watched namespace changes, returns a func that stops
+// calling the handler, it returns once a running call finished so the caller can clean up after it
+func (c *PodCache) Subscribe(namespace string, handler PodHandler) func() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	added := &podSubscription{handler: handler}
+	c.subscriptions[namespace] = append(c.subscriptions[namespace], added)
+	return func() { c.unsubscribe(namespace, added) }
+}
+
+func (c *PodCache) unsubscribe(namespace string, removed *podSubscription) {
+	c.lock.Lock()
+	var kept []*podSubscription
+	for _, subscription := range c.subscriptions[namespace] {
+		if subscription != removed {
+			kept = append(kept, subscription)
+		}
+	}
+	if len(kept) == 0 {
+		delete(c.subscriptions, namespace)
+	} else {
+		c.subscriptions[namespace] = kept
+	}
+	c.lock.Unlock()
+
+	removed.lock.Lock()
+	removed.stopped = true
+	removed.lock.Unlock()
+}
+
+func (c *PodCache) notify(namespace string, pod *apiv1.Pod) {
+	c.lock.RLock()
+	subscriptions := c.subscriptions[namespace]
+	c.lock.RUnlock()
+	for _, subscription := range subscriptions {
+		subscription.lock.RLock()
+		if !subscription.stopped {
+			subscription.handler(pod)
+		}
+		subscription.lock.RUnlock()
+	}
+}
+
+// Pods of the watched namespaces matching both selectors, like listing them with the selectors would return,
+// they are shared with the cache and must not be changed
+func (c *PodCache) ListPods(namespaces []string, labelSelector labels.Selector, fieldSelector fields.Selector) ([]*apiv1.Pod, error) {
+	var pods []*apiv1.Pod
+	for _, namespace := range namespaces {
+		informer, err := c.informer(namespace)
+		if err != nil {
+			return nil, err
+		}
+		var objects []interface{}
+		if node, ok := fieldSelector.RequiresExactMatch(nodeNameIndex); ok {
+			objects, err = informer.GetIndexer().ByIndex(nodeNameIndex, node) // Pods of a Node without scanning all
+		} else {
+			objects = informer.GetIndexer().List()
+		}
+		if err != nil {
+			return nil, err // untested section
+		}
+		for _, object := range objects {
+			pod := object.(*apiv1.Pod)
+			if labelSelector.Matches(labels.Set(pod.ObjectMeta.Labels)) && fieldSelector.Matches(PodFields(pod)) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+// the cached Pod, a NotFound error when it is not cached, like getting it from the api-server would return,
+// it is shared with the cache and must not be changed
+func (c *PodCache) GetPod(namespace string, name string) (*apiv1.Pod, error) {
+	informer, err := c.informer(namespace)
+	if err != nil {
+		if informer, err = c.informer(""); err != nil {
+			return nil, fmt.Errorf("Pods in namespace %q are not watched", namespace)
+		}
+	}
+	object, exists, err := informer.GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err // untested section
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(apiv1.Resource("pods"), name)
+	}
+	return object.(*apiv1.Pod), nil
+}
+
+func (c *PodCache) informer(namespace string) (cache.SharedIndexInformer, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	informer, ok := c.informers[namespace]
+	if !ok {
+		return nil, fmt.Errorf("Pods in namespace %q are not watched", namespace)
+	}
+	return informer, nil
+}
+
+// the fields the api-server supports in field selectors of Pods
+func PodFields(pod *apiv1.Pod) fields.Set {
+	return fields.Set{
+		"metadata.name":            pod.ObjectMeta.Name,
+		"metadata.namespace":       pod.ObjectMeta.Namespace,
+		"spec.nodeName":            pod.Spec.NodeName,
+		"spec.restartPolicy":       string(pod.Spec.RestartPolicy),
+		"spec.schedulerName":       pod.Spec.SchedulerName,
+		"spec.serviceAccountName":  pod.Spec.ServiceAccountName,
+		"status.phase":             string(pod.Status.Phase),
+		"status.podIP":             pod.Status.PodIP,
+		"status.nominatedNodeName": pod.Status.NominatedNodeName,
+	}
+}
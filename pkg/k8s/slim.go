@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"time"
+)
+
+// kubectl apply keeps the whole applied object in it, often the largest part of the metadata
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// drops what remediators never look at from a cached Pod: the managed fields, the last applied configuration,
+// the volumes and all of the containers but their names and images, the status stays complete
+func SlimPod(pod *apiv1.Pod) {
+	slimMeta(&pod.ObjectMeta)
+	pod.Spec.Volumes = nil
+	pod.Spec.InitContainers = slimContainers(pod.Spec.InitContainers)
+	pod.Spec.Containers = slimContainers(pod.Spec.Containers)
+}
+
+// drops the managed fields, the last applied configuration and the images of a cached Node,
+// a Node lists every image it pulled which adds up to most of its size
+func SlimNode(node *apiv1.Node) {
+	slimMeta(&node.ObjectMeta)
+	node.Status.Images = nil
+}
+
+func slimMeta(object *metav1.ObjectMeta) {
+	object.ManagedFields = nil
+	if _, ok := object.Annotations[lastAppliedAnnotation]; ok {
+		annotations := make(map[string]string, len(object.Annotations)-1)
+		for key, value := range object.Annotations {
+			if key != lastAppliedAnnotation {
+				annotations[key] = value
+			}
+		}
+		object.Annotations = annotations
+	}
+}
+
+func slimContainers(containers []apiv1.Container) []apiv1.Container {
+	if containers == nil {
+		return nil
+	}
+	slim := make([]apiv1.Container, len(containers))
+	for i, container := range containers {
+		slim[i] = apiv1.Container{Name: container.Name, Image: container.Image}
+	}
+	return slim
+}
+
+// informer of the Pods in the namespace, "" for all of them, that caches them slimmed by SlimPod
+func newSlimPodInformer(namespace string) func(kubernetes.Interface, time.Duration) cache.SharedIndexInformer {
+	return func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		pods := client.CoreV1().Pods(namespace)
+		return newSlimInformer(&cache.ListWatch{
+			ListFunc:  func(options metav1.ListOptions) (runtime.Object, error) { return pods.List(options) },
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) { return pods.Watch(options) },
+		}, &apiv1.Pod{}, resync, func(object runtime.Object) {
+			if pod, ok := object.(*apiv1.Pod); ok {
+				SlimPod(pod)
+			}
+		})
+	}
+}
+
+// informer of all Nodes that caches them slimmed by SlimNode
+func newSlimNodeInformer(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+	nodes := client.CoreV1().Nodes()
+	return newSlimInformer(&cache.ListWatch{
+		ListFunc:  func(options metav1.ListOptions) (runtime.Object, error) { return nodes.List(options) },
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) { return nodes.Watch(options) },
+	}, &apiv1.Node{}, resync, func(object runtime.Object) {
+		if node, ok := object.(*apiv1.Node); ok {
+			SlimNode(node)
+		}
+	})
+}
+
+// this client-go can neither watch only metadata nor transform objects before they are cached,
+// so they are slimmed as they are listed and watched, before the informer stores them
+func newSlimInformer(listWatch *cache.ListWatch, object runtime.Object, resync time.Duration, slim func(runtime.Object)) cache.SharedIndexInformer {
+	list, watchFunc := listWatch.ListFunc, listWatch.WatchFunc
+	listWatch.ListFunc = func(options metav1.ListOptions) (runtime.Object, error) {
+		result, err := list(options)
+		if err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(result) // pointers to the items, slimming changes the list
+		if err != nil {
+			return nil, err // untested section
+		}
+		for _, item := range items {
+			slim(item)
+		}
+		return result, nil
+	}
+	listWatch.WatchFunc = func(options metav1.ListOptions) (watch.Interface, error) {
+		watcher, err := watchFunc(options)
+		if err != nil {
+			return nil, err
+		}
+		return watch.Filter(watcher, func(event watch.Event) (watch.Event, bool) {
+			slim(event.Object) // errors come as a Status which is left alone
+			return event, true
+		}), nil
+	}
+	return cache.NewSharedIndexInformer(listWatch, object, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
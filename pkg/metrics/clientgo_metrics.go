@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+	"net/url"
+	"time"
+)
+
+// api calls of all clients and work queues, as client-go reports them, to see how much we ask of the api-server
+type ClientGo_Metrics struct {
+	logger          *zap.Logger
+	request_latency *prometheus.HistogramVec
+	request_count   *prometheus.CounterVec
+
+	queue_depth           *prometheus.GaugeVec
+	queue_adds            *prometheus.CounterVec
+	queue_latency         *prometheus.HistogramVec
+	queue_work_duration   *prometheus.HistogramVec
+	queue_unfinished_work *prometheus.GaugeVec
+	queue_longest_running *prometheus.GaugeVec
+	queue_retries         *prometheus.CounterVec
+}
+
+func NewClientGoMetrics(logger *zap.Logger) *ClientGo_Metrics {
+	queueLabels := []string{"name"}
+	return &ClientGo_Metrics{
+		logger: logger,
+		request_latency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "rest_client_request_duration_seconds",
+				Help:    "Latency of api-server requests by verb and URL, names in the URL are replaced by placeholders",
+				Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+			},
+			[]string{"verb", "url"},
+		),
+		request_count: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rest_client_requests_total",
+				Help: "Total number of api-server requests by status code, method and host",
+			},
+			[]string{"code", "method", "host"},
+		),
+		queue_depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "workqueue_depth",
+			Help: "Number of items waiting in the work queue",
+		}, queueLabels),
+		queue_adds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workqueue_adds_total",
+			Help: "Total number of items added to the work queue",
+		}, queueLabels),
+		queue_latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "workqueue_queue_duration_seconds",
+			Help:    "How long items waited in the work queue before being processed",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, queueLabels),
+		queue_work_duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "workqueue_work_duration_seconds",
+			Help:    "How long processing an item of the work queue took",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, queueLabels),
+		queue_unfinished_work: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "workqueue_unfinished_work_seconds",
+			Help: "Seconds items in progress have been processed for, growing values mean stuck processing",
+		}, queueLabels),
+		queue_longest_running: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "workqueue_longest_running_processor_seconds",
+			Help: "Seconds the longest running item of the work queue has been processed for",
+		}, queueLabels),
+		queue_retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workqueue_retries_total",
+			Help: "Total number of items added to the work queue again after failing",
+		}, queueLabels),
+	}
+}
+
+// client-go takes the first metrics it is given for the whole process, so only the first Register reports,
+// queues created before it are not reported
+func (c *ClientGo_Metrics) Register() {
+	Registry.MustRegister(
+		c.request_latency, c.request_count,
+		c.queue_depth, c.queue_adds, c.queue_latency, c.queue_work_duration,
+		c.queue_unfinished_work, c.queue_longest_running, c.queue_retries,
+	)
+	clientmetrics.Register(requestLatency{c.request_latency}, requestResult{c.request_count})
+	workqueue.SetProvider(queueMetricsProvider{c})
+}
+
+func (c *ClientGo_Metrics) UnRegister() {
+	Registry.Unregister(c.request_latency)
+	Registry.Unregister(c.request_count)
+	Registry.Unregister(c.queue_depth)
+	Registry.Unregister(c.queue_adds)
+	Registry.Unregister(c.queue_latency)
+	Registry.Unregister(c.queue_work_duration)
+	Registry.Unregister(c.queue_unfinished_work)
+	Registry.Unregister(c.queue_longest_running)
+	Registry.Unregister(c.queue_retries)
+}
+
+type requestLatency struct {
+	histogram *prometheus.HistogramVec
+}
+
+// u has placeholders for names and namespaces, the query only adds label values that differ in their parameters
+func (r requestLatency) Observe(verb string, u url.URL, latency time.Duration) {
+	u.RawQuery = ""
+	r.histogram.WithLabelValues(verb, u.String()).Observe(latency.Seconds())
+}
+
+type requestResult struct {
+	counter *prometheus.CounterVec
+}
+
+func (r requestResult) Increment(code string, method string, host string) {
+	r.counter.WithLabelValues(code, method, host).Inc()
+}
+
+// metrics of queues created with a name, the deprecated ones client-go still asks for are not reported
+type queueMetricsProvider struct {
+	metrics *ClientGo_Metrics
+}
+
+func (p queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.metrics.queue_depth.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.metrics.queue_adds.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return p.metrics.queue_latency.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return p.metrics.queue_work_duration.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.metrics.queue_unfinished_work.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.metrics.queue_longest_running.WithLabelValues(name)
+}
+
+func (p queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.metrics.queue_retries.WithLabelValues(name)
+}
+
+func (queueMetricsProvider) NewDeprecatedDepthMetric(name string) workqueue.GaugeMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedAddsMetric(name string) workqueue.CounterMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedLatencyMetric(name string) workqueue.SummaryMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedLongestRunningProcessorMicrosecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (queueMetricsProvider) NewDeprecatedRetriesMetric(name string) workqueue.CounterMetric {
+	return noopMetric{}
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Set(float64)     {}
+func (noopMetric) Observe(float64) {}
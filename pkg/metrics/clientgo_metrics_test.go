@@ -0,0 +1,37 @@
+package metrics_test
+
+import (
+	"bytes"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportsRequestsAndQueuesOfClientGo(t *testing.T) {
+	clientGo := metrics.NewClientGoMetrics(zap.NewNop())
+	clientGo.Register()
+	defer clientGo.UnRegister()
+
+	u, err := url.Parse("https://api-server:443/api/v1/namespaces/%7Bnamespace%7D/pods?limit=%7Bvalue%7D")
+	assert.NilError(t, err)
+	clientmetrics.RequestLatency.Observe("GET", *u, time.Second)
+	clientmetrics.RequestResult.Increment("200", "GET", "api-server:443")
+	queue := workqueue.NewNamed("events")
+	defer queue.ShutDown()
+	queue.Add("default/foo")
+
+	var out bytes.Buffer
+	assert.NilError(t, metrics.Push(metrics.PushConfig{Stdout: true}, &out))
+	text := out.String()
+
+	assert.Assert(t, strings.Contains(text, `rest_client_request_duration_seconds_sum{url="https://api-server:443/api/v1/namespaces/%7Bnamespace%7D/pods",verb="GET"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, `rest_client_requests_total{code="200",host="api-server:443",method="GET"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, `workqueue_adds_total{name="events"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, `workqueue_depth{name="events"} 1`+"\n"))
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type Leader_Metrics struct {
+	logger  *zap.Logger
+	cluster string // "" when there is only the one cluster
+	leader  *prometheus.GaugeVec
+}
+
+func NewLeaderMetrics(logger *zap.Logger) *Leader_Metrics {
+	return &Leader_Metrics{
+		logger: logger,
+		leader: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "leader",
+				Help: "1 while this replica is the leader and remediates, 0 while it only watches",
+			},
+			[]string{"cluster"},
+		),
+	}
+}
+
+func (c *Leader_Metrics) Register() {
+	Registry.MustRegister(c.leader)
+}
+
+func (c *Leader_Metrics) UnRegister() {
+	Registry.Unregister(c.leader)
+}
+
+// the same metrics labeled with another cluster
+func (c *Leader_Metrics) ForCluster(cluster string) *Leader_Metrics {
+	if c == nil {
+		return nil
+	}
+	forCluster := *c
+	forCluster.cluster = cluster
+	return &forCluster
+}
+
+// a nil Leader_Metrics records nothing
+func (c *Leader_Metrics) SetLeader(leading bool) {
+	if c == nil {
+		return
+	}
+	value := 0.0
+	if leading {
+		value = 1
+	}
+	labels, tags := clusterLabels(c.cluster, nil)
+	c.leader.With(labels).Set(value)
+	StatsD.Gauge("leader", value, tags)
+}
@@ -1,15 +1,37 @@
 package metrics
 
 import (
-	httpmux "github.com/google/cadvisor/http/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
 )
 
 type Metrics interface {
 	RegisterMetrics()
 }
 
-func RegisterHandler(mux httpmux.Mux) error {
-	mux.Handle("/metrics", promhttp.Handler())
-	return nil
+// Registry all metrics of kube-remediator are registered with and /metrics serves
+var Registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	return registry
+}
+
+// serves Registry for Prometheus to scrape
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// labels of a metric of one cluster, StatsD only gets the cluster when there are several clusters
+func clusterLabels(cluster string, labels map[string]string) (prometheus.Labels, map[string]string) {
+	withCluster := prometheus.Labels{"cluster": cluster}
+	for name, value := range labels {
+		withCluster[name] = value
+	}
+	if cluster == "" {
+		return withCluster, labels
+	}
+	return withCluster, withCluster
 }
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Where the final metrics go when the process exits, so one-shot runs that are never scraped still report them
+type PushConfig struct {
+	URL    string `mapstructure:"url"` // Pushgateway, "" means no push
+	Job    string `mapstructure:"job"`
+	Stdout bool   `mapstructure:"stdout"` // write them in the OpenMetrics text format
+}
+
+func DefaultPushConfig() PushConfig {
+	return PushConfig{Job: "kube-remediator"}
+}
+
+func (c PushConfig) Validate() error {
+	if c.URL != "" && c.Job == "" {
+		return fmt.Errorf("metrics.push.job is required when pushing to a Pushgateway")
+	}
+	return nil
+}
+
+// called at exit, the Pushgateway gets all metrics of Registry replacing those the job pushed before
+func Push(config PushConfig, out io.Writer) error {
+	if config.Stdout {
+		if err := WriteOpenMetrics(out); err != nil {
+			return err
+		}
+	}
+	if config.URL == "" {
+		return nil
+	}
+	return push.New(config.URL, config.Job).Gatherer(Registry).Push()
+}
+
+// all metrics of Registry in the OpenMetrics text format, https://openmetrics.io
+func WriteOpenMetrics(out io.Writer) error {
+	families, err := Registry.Gather()
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(out)
+	for _, family := range families {
+		writeFamily(writer, family)
+	}
+	writer.WriteString("# EOF\n")
+	return writer.Flush()
+}
+
+func writeFamily(w *bufio.Writer, family *dto.MetricFamily) {
+	name := family.GetName()
+	kind := "unknown"
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		kind = "counter"
+		name = strings.TrimSuffix(name, "_total") // the family is named without it, samples with it
+	case dto.MetricType_GAUGE:
+		kind = "gauge"
+	case dto.MetricType_HISTOGRAM:
+		kind = "histogram"
+	case dto.MetricType_SUMMARY:
+		kind = "summary"
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+	if help := family.GetHelp(); help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escape(help, false))
+	}
+
+	for _, metric := range family.GetMetric() {
+		labels := metric.GetLabel()
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			writeSample(w, name+"_total", labels, "", "", metric.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			writeSample(w, name, labels, "", "", metric.GetGauge().GetValue())
+		case dto.MetricType_HISTOGRAM:
+			histogram := metric.GetHistogram()
+			for _, bucket := range histogram.GetBucket() {
+				writeSample(w, name+"_bucket", labels, "le", formatFloat(bucket.GetUpperBound()), float64(bucket.GetCumulativeCount()))
+			}
+			writeSample(w, name+"_bucket", labels, "le", "+Inf", float64(histogram.GetSampleCount()))
+			writeSample(w, name+"_count", labels, "", "", float64(histogram.GetSampleCount()))
+			writeSample(w, name+"_sum", labels, "", "", histogram.GetSampleSum())
+		case dto.MetricType_SUMMARY:
+			summary := metric.GetSummary()
+			for _, quantile := range summary.GetQuantile() {
+				writeSample(w, name, labels, "quantile", formatFloat(quantile.GetQuantile()), quantile.GetValue())
+			}
+			writeSample(w, name+"_count", labels, "", "", float64(summary.GetSampleCount()))
+			writeSample(w, name+"_sum", labels, "", "", summary.GetSampleSum())
+		default:
+			writeSample(w, name, labels, "", "", metric.GetUntyped().GetValue())
+		}
+	}
+}
+
+// extra is a label only this sample has, like le of histogram buckets
+func writeSample(w *bufio.Writer, name string, labels []*dto.LabelPair, extraName string, extraValue string, value float64) {
+	var pairs []string
+	for _, label := range labels {
+		pairs = append(pairs, label.GetName()+`="`+escape(label.GetValue(), true)+`"`)
+	}
+	sort.Strings(pairs)
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	w.WriteString(name)
+	if len(pairs) > 0 {
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escape(value string, quoted bool) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	if quoted {
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+	return value
+}
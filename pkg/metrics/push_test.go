@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"bytes"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritesOpenMetrics(t *testing.T) {
+	remediations := metrics.NewRemediationMetrics(zap.NewNop())
+	remediations.Register()
+	defer remediations.UnRegister()
+	remediations.UpdateRemediationCount("OldPodDeleter", "default", "Old \"pod\"", "evicted", metrics.ResultSuccess)
+	remediations.ObserveScanDuration("OldPodDeleter", 2*time.Second)
+	remediations.ForCluster("prod").UpdateRemediationCount("OldPodDeleter", "default", "Old", "evicted", metrics.ResultSuccess)
+
+	var out bytes.Buffer
+	assert.NilError(t, metrics.Push(metrics.PushConfig{Stdout: true}, &out))
+	text := out.String()
+
+	assert.Assert(t, strings.Contains(text, "# TYPE remediations counter\n"))
+	assert.Assert(t, strings.Contains(text, `remediations_total{action="evicted",cluster="",namespace="default",reason="Old \"pod\"",remediator="OldPodDeleter",result="success"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, `remediations_total{action="evicted",cluster="prod",namespace="default",reason="Old",remediator="OldPodDeleter",result="success"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, "# TYPE scan_duration_seconds histogram\n"))
+	assert.Assert(t, strings.Contains(text, `scan_duration_seconds_bucket{cluster="",remediator="OldPodDeleter",le="+Inf"} 1`+"\n"))
+	assert.Assert(t, strings.Contains(text, `scan_duration_seconds_sum{cluster="",remediator="OldPodDeleter"} 2`+"\n"))
+	assert.Assert(t, strings.HasSuffix(text, "\n# EOF\n"))
+}
+
+func TestPushesToPushgateway(t *testing.T) {
+	paths, bodies := make(chan string, 1), make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NilError(t, err)
+		paths <- r.Method + " " + r.URL.Path
+		bodies <- string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	assert.NilError(t, metrics.Push(metrics.PushConfig{URL: server.URL, Job: "kube-remediator"}, &out))
+	assert.Equal(t, <-paths, "PUT /metrics/job/kube-remediator")
+	assert.Assert(t, len(<-bodies) > 0)
+	assert.Equal(t, out.Len(), 0)
+}
+
+func TestFailsWhenPushgatewayFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	assert.ErrorContains(t, metrics.Push(metrics.PushConfig{URL: server.URL, Job: "kube-remediator"}, ioutil.Discard), "500")
+}
+
+func TestPushesNothingByDefault(t *testing.T) {
+	var out bytes.Buffer
+	assert.NilError(t, metrics.Push(metrics.DefaultPushConfig(), &out))
+	assert.Equal(t, out.Len(), 0)
+}
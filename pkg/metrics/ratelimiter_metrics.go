@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type RateLimiter_Metrics struct {
+	logger          *zap.Logger
+	throttled_count prometheus.Counter
+	queued          prometheus.Gauge
+}
+
+func NewRateLimiterMetrics(logger *zap.Logger) *RateLimiter_Metrics {
+	return &RateLimiter_Metrics{
+		logger: logger,
+		throttled_count: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remediations_throttled",
+			Help: "Total number of remediations delayed to the next window by the rate limiter",
+		}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "remediations_queued",
+			Help: "Number of remediations waiting for the next window of the rate limiter",
+		}),
+	}
+}
+
+func (c *RateLimiter_Metrics) Register() {
+	Registry.MustRegister(c.throttled_count, c.queued)
+}
+
+func (c *RateLimiter_Metrics) UnRegister() {
+	Registry.Unregister(c.throttled_count)
+	Registry.Unregister(c.queued)
+}
+
+func (c *RateLimiter_Metrics) UpdateThrottledCount() {
+	c.throttled_count.Inc()
+	StatsD.Count("remediations_throttled", 1, nil)
+}
+
+func (c *RateLimiter_Metrics) SetQueued(queued int) {
+	c.queued.Set(float64(queued))
+	StatsD.Gauge("remediations_queued", float64(queued), nil)
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"time"
+)
+
+// results of a remediation
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+	ResultSkipped = "skipped" // a safety check like the kill switch, cooldown or approval held it back
+	ResultDryRun  = "dry-run" // dry running or observing
+)
+
+// What all remediators did, labeled so dashboards can break it down per cluster, remediator, team namespace and problem
+type Remediation_Metrics struct {
+	logger             *zap.Logger
+	cluster            string // "" when there is only the one cluster
+	remediations_count *prometheus.CounterVec
+	latency            *prometheus.HistogramVec
+	scan_duration      *prometheus.HistogramVec
+	list_duration      *prometheus.HistogramVec
+	api_errors_count   *prometheus.CounterVec
+	panics_count       *prometheus.CounterVec
+}
+
+func NewRemediationMetrics(logger *zap.Logger) *Remediation_Metrics {
+	return &Remediation_Metrics{
+		logger: logger,
+		remediations_count: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "remediations",
+				Help: "Total number of Pods and Nodes kube-remediator acted on or would have acted on",
+			},
+			[]string{"cluster", "remediator", "namespace", "reason", "action", "result"},
+		),
+		latency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "remediation_latency_seconds",
+				Help:    "Time from first seeing a Pod unhealthy to deleting or evicting it",
+				Buckets: prometheus.ExponentialBuckets(1, 4, 9), // 1s to 18h
+			},
+			[]string{"cluster", "remediator"},
+		),
+		scan_duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "scan_duration_seconds",
+				Help:    "How long scans for unhealthy Pods or Nodes took",
+				Buckets: prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms to 20s
+			},
+			[]string{"cluster", "remediator"},
+		),
+		list_duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "list_duration_seconds",
+				Help:    "How long listing Pods from the API server took",
+				Buckets: prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms to 20s
+			},
+			[]string{"cluster", "remediator"},
+		),
+		api_errors_count: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "api_errors",
+				Help: "Total number of failed API server calls of remediators by class, Forbidden means RBAC needs fixing",
+			},
+			[]string{"cluster", "remediator", "class"},
+		),
+		panics_count: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "panics",
+				Help: "Total number of panics kube-remediator recovered from by where, the remediator was restarted when in run",
+			},
+			[]string{"cluster", "remediator", "in"},
+		),
+	}
+}
+
+func (c *Remediation_Metrics) Register() {
+	Registry.MustRegister(c.remediations_count, c.latency, c.scan_duration, c.list_duration, c.api_errors_count, c.panics_count)
+}
+
+func (c *Remediation_Metrics) UnRegister() {
+	Registry.Unregister(c.remediations_count)
+	Registry.Unregister(c.latency)
+	Registry.Unregister(c.scan_duration)
+	Registry.Unregister(c.list_duration)
+	Registry.Unregister(c.api_errors_count)
+	Registry.Unregister(c.panics_count)
+}
+
+// the same metrics labeled with another cluster
+func (c *Remediation_Metrics) ForCluster(cluster string) *Remediation_Metrics {
+	if c == nil {
+		return nil
+	}
+	forCluster := *c
+	forCluster.cluster = cluster
+	return &forCluster
+}
+
+// a nil Remediation_Metrics counts nothing, namespace is empty for Nodes
+func (c *Remediation_Metrics) UpdateRemediationCount(remediator string, namespace string, reason string, action string, result string) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{
+		"remediator": remediator,
+		"namespace":  namespace,
+		"reason":     reason,
+		"action":     action,
+		"result":     result,
+	})
+	c.remediations_count.With(labels).Inc()
+	StatsD.Count("remediations", 1, tags)
+}
+
+func (c *Remediation_Metrics) ObserveLatency(remediator string, latency time.Duration) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"remediator": remediator})
+	c.latency.With(labels).Observe(latency.Seconds())
+	StatsD.Timing("remediation_latency", latency, tags)
+}
+
+func (c *Remediation_Metrics) ObserveScanDuration(remediator string, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"remediator": remediator})
+	c.scan_duration.With(labels).Observe(duration.Seconds())
+	StatsD.Timing("scan_duration", duration, tags)
+}
+
+func (c *Remediation_Metrics) ObserveListDuration(remediator string, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"remediator": remediator})
+	c.list_duration.With(labels).Observe(duration.Seconds())
+	StatsD.Timing("list_duration", duration, tags)
+}
+
+// class is the k8s.ErrorClass of the failed call
+func (c *Remediation_Metrics) UpdateAPIErrorCount(remediator string, class string) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"remediator": remediator, "class": class})
+	c.api_errors_count.With(labels).Inc()
+	StatsD.Count("api_errors", 1, tags)
+}
+
+// in is what panicked: run, handler or action
+func (c *Remediation_Metrics) UpdatePanicCount(remediator string, in string) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"remediator": remediator, "in": in})
+	c.panics_count.With(labels).Inc()
+	StatsD.Count("panics", 1, tags)
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Snapshot of the last report, replaced when the next one is made
+type Report_Metrics struct {
+	logger            *zap.Logger
+	actions           prometheus.Gauge
+	failures          prometheus.Gauge
+	namespace_actions *prometheus.GaugeVec
+	workload_actions  *prometheus.GaugeVec
+}
+
+func NewReportMetrics(logger *zap.Logger) *Report_Metrics {
+	return &Report_Metrics{
+		logger: logger,
+		actions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "report_actions",
+			Help: "Pods and Nodes acted on in the period of the last report",
+		}),
+		failures: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "report_failures",
+			Help: "Actions that failed in the period of the last report",
+		}),
+		namespace_actions: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "report_namespace_actions",
+				Help: "Actions per namespace in the period of the last report",
+			},
+			[]string{"namespace"},
+		),
+		workload_actions: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "report_workload_actions",
+				Help: "Actions on the top offending workloads in the period of the last report",
+			},
+			[]string{"workload"},
+		),
+	}
+}
+
+func (c *Report_Metrics) Register() {
+	Registry.MustRegister(c.actions, c.failures, c.namespace_actions, c.workload_actions)
+}
+
+func (c *Report_Metrics) UnRegister() {
+	Registry.Unregister(c.actions)
+	Registry.Unregister(c.failures)
+	Registry.Unregister(c.namespace_actions)
+	Registry.Unregister(c.workload_actions)
+}
+
+// namespaces and workloads missing from the report are dropped, a nil Report_Metrics records nothing
+func (c *Report_Metrics) SetReport(actions int, failures int, namespaces map[string]int, workloads map[string]int) {
+	if c == nil {
+		return
+	}
+	c.actions.Set(float64(actions))
+	c.failures.Set(float64(failures))
+	StatsD.Gauge("report_actions", float64(actions), nil)
+	StatsD.Gauge("report_failures", float64(failures), nil)
+
+	c.namespace_actions.Reset()
+	for namespace, count := range namespaces {
+		c.namespace_actions.With(prometheus.Labels{"namespace": namespace}).Set(float64(count))
+		StatsD.Gauge("report_namespace_actions", float64(count), map[string]string{"namespace": namespace})
+	}
+	c.workload_actions.Reset()
+	for workload, count := range workloads {
+		c.workload_actions.With(prometheus.Labels{"workload": workload}).Set(float64(count))
+		StatsD.Gauge("report_workload_actions", float64(count), map[string]string{"workload": workload})
+	}
+}
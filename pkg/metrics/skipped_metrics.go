@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+type Skipped_Metrics struct {
+	logger        *zap.Logger
+	cluster       string // "" when there is only the one cluster
+	skipped_count *prometheus.CounterVec
+}
+
+func NewSkippedMetrics(logger *zap.Logger) *Skipped_Metrics {
+	return &Skipped_Metrics{
+		logger: logger,
+		skipped_count: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "remediations_skipped",
+				Help: "Total number of unhealthy Pods not remediated on purpose",
+			},
+			[]string{"cluster", "reason"},
+		),
+	}
+}
+
+func (c *Skipped_Metrics) Register() {
+	Registry.MustRegister(c.skipped_count)
+}
+
+func (c *Skipped_Metrics) UnRegister() {
+	Registry.Unregister(c.skipped_count)
+}
+
+// the same metrics labeled with another cluster
+func (c *Skipped_Metrics) ForCluster(cluster string) *Skipped_Metrics {
+	if c == nil {
+		return nil
+	}
+	forCluster := *c
+	forCluster.cluster = cluster
+	return &forCluster
+}
+
+// a nil Skipped_Metrics counts nothing
+func (c *Skipped_Metrics) UpdateSkippedCount(reason string) {
+	if c == nil {
+		return
+	}
+	labels, tags := clusterLabels(c.cluster, map[string]string{"reason": reason})
+	c.skipped_count.With(labels).Inc()
+	StatsD.Count("remediations_skipped", 1, tags)
+}
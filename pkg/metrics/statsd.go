@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sends counters, gauges and timings to a StatsD server or Datadog agent over UDP
+type StatsDConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Address   string   `mapstructure:"address"`   // host:port of the agent
+	Prefix    string   `mapstructure:"prefix"`    // prepended to every metric name
+	DogStatsD bool     `mapstructure:"dogStatsD"` // send labels as tags, plain StatsD has no tags so they are dropped
+	Tags      []string `mapstructure:"tags"`      // added to every metric, "env:prod"
+}
+
+// every metric is also sent here, nil means StatsD is disabled
+var StatsD *StatsDClient
+
+type StatsDClient struct {
+	logger    *zap.Logger
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+	tags      []string
+}
+
+func DefaultStatsDConfig() StatsDConfig {
+	return StatsDConfig{Address: "localhost:8125", Prefix: "kube_remediator.", DogStatsD: true, Tags: []string{}}
+}
+
+func (c StatsDConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("metrics.statsd.address: %v", err)
+	}
+	return nil
+}
+
+func NewStatsD(logger *zap.Logger, config StatsDConfig) (*StatsDClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	// UDP does not connect, so this only fails when the address can not be resolved
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDClient{logger: logger, conn: conn, prefix: config.Prefix, dogStatsD: config.DogStatsD, tags: config.Tags}, nil
+}
+
+// a nil StatsDClient sends nothing
+func (s *StatsDClient) Count(name string, value int64, tags map[string]string) {
+	s.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+func (s *StatsDClient) Gauge(name string, value float64, tags map[string]string) {
+	s.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+func (s *StatsDClient) Timing(name string, duration time.Duration, tags map[string]string) {
+	s.send(name, strconv.FormatFloat(duration.Seconds()*1000, 'f', -1, 64), "ms", tags)
+}
+
+func (s *StatsDClient) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// one datagram per metric, "prefix.name:value|type|#tag:value,..."
+func (s *StatsDClient) send(name string, value string, kind string, tags map[string]string) {
+	if s == nil {
+		return
+	}
+	line := s.prefix + name + ":" + value + "|" + kind
+	if s.dogStatsD {
+		if formatted := s.formatTags(tags); len(formatted) > 0 {
+			line += "|#" + strings.Join(formatted, ",")
+		}
+	}
+	if _, err := s.conn.Write([]byte(line)); err != nil {
+		s.logger.Warn("Error sending to StatsD", zap.String("metric", name), zap.Error(err))
+	}
+}
+
+// sorted so the same labels make the same line, empty values are left out
+func (s *StatsDClient) formatTags(tags map[string]string) []string {
+	formatted := append([]string{}, s.tags...)
+	var keys []string
+	for key, value := range tags {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		formatted = append(formatted, key+":"+tagReplacer.Replace(tags[key]))
+	}
+	return formatted
+}
+
+// separators of the DogStatsD format, reasons of Node problems are comma separated
+var tagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_")
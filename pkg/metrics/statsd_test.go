@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func listen(t *testing.T) (net.PacketConn, func() string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	return conn, func() string {
+		buffer := make([]byte, 1024)
+		assert.NilError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buffer)
+		assert.NilError(t, err)
+		return string(buffer[:n])
+	}
+}
+
+func TestSendsDogStatsDWithTags(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+	config := metrics.DefaultStatsDConfig()
+	config.Enabled = true
+	config.Address = conn.LocalAddr().String()
+	config.Tags = []string{"env:prod"}
+	statsD, err := metrics.NewStatsD(zap.NewNop(), config)
+	assert.NilError(t, err)
+	defer statsD.Close()
+
+	statsD.Count("remediations", 1, map[string]string{"remediator": "NodeProblemRemediator", "namespace": "", "reason": "KernelDeadlock,ReadonlyFilesystem"})
+	assert.Equal(t, read(), "kube_remediator.remediations:1|c|#env:prod,reason:KernelDeadlock_ReadonlyFilesystem,remediator:NodeProblemRemediator")
+	statsD.Timing("scan_duration", 1500*time.Microsecond, nil)
+	assert.Equal(t, read(), "kube_remediator.scan_duration:1.5|ms|#env:prod")
+	statsD.Gauge("remediations_queued", 3, nil)
+	assert.Equal(t, read(), "kube_remediator.remediations_queued:3|g|#env:prod")
+}
+
+func TestSendsPlainStatsDWithoutTags(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+	statsD, err := metrics.NewStatsD(zap.NewNop(), metrics.StatsDConfig{Enabled: true, Address: conn.LocalAddr().String()})
+	assert.NilError(t, err)
+	defer statsD.Close()
+
+	statsD.Count("remediations", 2, map[string]string{"remediator": "OldPodDeleter"})
+	assert.Equal(t, read(), "remediations:2|c")
+}
+
+func TestEmitsRemediationMetrics(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+	var err error
+	metrics.StatsD, err = metrics.NewStatsD(zap.NewNop(), metrics.StatsDConfig{Enabled: true, Address: conn.LocalAddr().String(), DogStatsD: true})
+	assert.NilError(t, err)
+	defer func() {
+		metrics.StatsD.Close()
+		metrics.StatsD = nil
+	}()
+
+	metrics.NewRemediationMetrics(zap.NewNop()).UpdateRemediationCount("OldPodDeleter", "default", "Old", "evicted", metrics.ResultSuccess)
+	assert.Equal(t, read(), "remediations:1|c|#action:evicted,namespace:default,reason:Old,remediator:OldPodDeleter,result:success")
+	metrics.NewSkippedMetrics(zap.NewNop()).UpdateSkippedCount("static-pod")
+	assert.Equal(t, read(), "remediations_skipped:1|c|#reason:static-pod")
+	metrics.NewSkippedMetrics(zap.NewNop()).ForCluster("prod").UpdateSkippedCount("static-pod")
+	assert.Equal(t, read(), "remediations_skipped:1|c|#cluster:prod,reason:static-pod")
+}
+
+func TestRejectsInvalidAddress(t *testing.T) {
+	_, err := metrics.NewStatsD(zap.NewNop(), metrics.StatsDConfig{Enabled: true, Address: "localhost"})
+	assert.ErrorContains(t, err, "metrics.statsd.address")
+}
+
+func TestNilStatsDSendsNothing(t *testing.T) {
+	var statsD *metrics.StatsDClient
+	statsD.Count("remediations", 1, nil)
+	assert.NilError(t, statsD.Close())
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how bad an event is, most severe first
+const (
+	SeverityError   = "error"   // an action failed
+	SeverityWarning = "warning" // the kill switch was engaged or released
+	SeverityInfo    = "info"    // everything else
+)
+
+var severities = []string{SeverityError, SeverityWarning, SeverityInfo}
+
+// the Severity of the event, or else error for failed actions, warning for the kill switch, info for everything else
+func Severity(event Event) string {
+	switch {
+	case event.Severity != "":
+		return event.Severity
+	case event.Outcome == "error":
+		return SeverityError
+	case event.Type == EventKillSwitch:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// which events go to a notifier, empty means all
+type RouteConfig struct {
+	MinSeverity string   `mapstructure:"minSeverity"` // least severe events sent
+	Namespaces  []string `mapstructure:"namespaces"`  // events without namespace, like of Nodes, always match
+	Remediators []string `mapstructure:"remediators"` // events without remediator, like of the kill switch, always match
+}
+
+func (r RouteConfig) Matches(event Event) bool {
+	if r.MinSeverity != "" && index(severities, Severity(event)) > index(severities, strings.ToLower(r.MinSeverity)) {
+		return false
+	}
+	if event.Object.Namespace != "" && len(r.Namespaces) > 0 && !containsFold(r.Namespaces, event.Object.Namespace) {
+		return false
+	}
+	if event.Remediator != "" && len(r.Remediators) > 0 && !containsFold(r.Remediators, event.Remediator) {
+		return false
+	}
+	return true
+}
+
+// how a notifier gets events to its endpoint, the same for all of them
+type DeliveryConfig struct {
+	Route      RouteConfig   `mapstructure:"route"`
+	RateLimit  int           `mapstructure:"rateLimit"`  // events per minute, more are dropped, 0 means unlimited
+	Retries    int           `mapstructure:"retries"`    // after the first attempt failed
+	RetryDelay time.Duration `mapstructure:"retryDelay"` // doubled after each retry
+}
+
+func DefaultDeliveryConfig() DeliveryConfig {
+	return DeliveryConfig{Retries: 3, RetryDelay: time.Second}
+}
+
+// name is the key of the notifier in notifications, like webhook
+func (c DeliveryConfig) Validate(name string) error {
+	if c.Route.MinSeverity != "" && !contains(severities, strings.ToLower(c.Route.MinSeverity)) {
+		return fmt.Errorf("notifications.%s.route.minSeverity must be one of %v, got %q", name, severities, c.Route.MinSeverity)
+	}
+	if c.RateLimit < 0 {
+		return fmt.Errorf("notifications.%s.rateLimit must not be negative, got %v", name, c.RateLimit)
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("notifications.%s.retries must not be negative, got %v", name, c.Retries)
+	}
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("notifications.%s.retryDelay must not be negative, got %v", name, c.RetryDelay)
+	}
+	return nil
+}
+
+// a failure worth trying again, like a timeout or a 5xx response
+type retryableError struct {
+	error
+}
+
+func retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err}
+}
+
+// attempts until it succeeded, failed with an error that is not retryable or used up the retries
+func (c DeliveryConfig) retry(attempt func() error) error {
+	delay := c.RetryDelay
+	for i := 0; ; i++ {
+		err := attempt()
+		if retry, ok := err.(retryableError); ok {
+			if i < c.Retries {
+				time.Sleep(delay)
+				delay *= 2
+				continue
+			}
+			return retry.error
+		}
+		return err
+	}
+}
+
+// POSTs the body to the url, with retries when the request failed or the server had a problem, not when it
+// rejected the body, service names the endpoint in errors
+func (c DeliveryConfig) post(client *http.Client, url string, headers map[string]string, body []byte, service string) error {
+	return c.retry(func() error {
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return retryable(err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("%s responded with %s", service, response.Status)
+		if response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests {
+			return retryable(err)
+		}
+		return err
+	})
+}
+
+// A notifier with its own Queue, so every endpoint gets events at its own pace, that only takes the events of
+// its route and drops those above its rate limit
+type Sink struct {
+	*Queue
+	config DeliveryConfig
+
+	lock   sync.Mutex
+	window time.Time // the minute events are counted for the rate limit
+	taken  int
+}
+
+func NewSink(logger *zap.Logger, config DeliveryConfig, size int, send func(Event) error) *Sink {
+	return &Sink{Queue: NewQueue(logger, size, send), config: config}
+}
+
+func (s *Sink) Notify(event Event) {
+	if !s.config.Route.Matches(event) {
+		return
+	}
+	if !s.allow(event.Time) {
+		s.logger.Warn("Dropping notification, rate limit reached", zap.String("remediator", event.Remediator))
+		return
+	}
+	s.Queue.Notify(event)
+}
+
+func (s *Sink) allow(now time.Time) bool {
+	if s.config.RateLimit == 0 {
+		return true
+	}
+	if now.IsZero() {
+		now = time.Now() // untested section
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if window := now.Truncate(time.Minute); !window.Equal(s.window) {
+		s.window = window
+		s.taken = 0
+	}
+	if s.taken >= s.config.RateLimit {
+		return false
+	}
+	s.taken++
+	return true
+}
+
+func index(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return len(values) // untested section
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how events of a severity are mailed
+const (
+	EmailImmediate = "immediate" // one mail per event
+	EmailDigest    = "digest"    // collected into one mail per digestInterval
+	EmailNone      = "none"
+)
+
+var emailModes = []string{EmailImmediate, EmailDigest, EmailNone}
+
+// Mails events through an SMTP server
+type EmailConfig struct {
+	Enabled        bool              `mapstructure:"enabled"`
+	Host           string            `mapstructure:"host"`
+	Port           int               `mapstructure:"port"`
+	Username       string            `mapstructure:"username"` // "" means no authentication
+	Password       string            `mapstructure:"password"`
+	From           string            `mapstructure:"from"`
+	To             []string          `mapstructure:"to"`
+	Severities     map[string]string `mapstructure:"severities"` // mode per severity, missing severities are not mailed
+	DigestInterval time.Duration     `mapstructure:"digestInterval"`
+	Template       string            `mapstructure:"template"` // text/template of the subject and digest lines
+
+	DeliveryConfig `mapstructure:",squash"`
+}
+
+type Email struct {
+	logger   *zap.Logger
+	config   EmailConfig
+	template *Template
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	lock    sync.Mutex
+	pending []Event
+}
+
+func DefaultEmailConfig() EmailConfig {
+	return EmailConfig{
+		Port:           587,
+		Severities:     map[string]string{"error": EmailImmediate, "warning": EmailImmediate, "info": EmailDigest},
+		DigestInterval: time.Hour,
+		Template:       DefaultTemplate,
+		DeliveryConfig: DefaultDeliveryConfig(),
+	}
+}
+
+func (c EmailConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Host == "" || c.From == "" || len(c.To) == 0 {
+		return fmt.Errorf("notifications.email.host, from and to are required when email is enabled")
+	}
+	for severity, mode := range c.Severities {
+		if !contains(severities, strings.ToLower(severity)) {
+			return fmt.Errorf("notifications.email.severities: unknown severity %q, use error, warning or info", severity)
+		}
+		if !contains(emailModes, mode) {
+			return fmt.Errorf("notifications.email.severities.%s must be one of %v, got %q", severity, emailModes, mode)
+		}
+	}
+	if c.DigestInterval <= 0 {
+		return fmt.Errorf("notifications.email.digestInterval must be positive, got %v", c.DigestInterval)
+	}
+	if _, err := ParseTemplate("email", c.Template); err != nil {
+		return err
+	}
+	return c.DeliveryConfig.Validate("email")
+}
+
+func NewEmail(logger *zap.Logger, config EmailConfig) (*Email, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	tmpl, err := ParseTemplate("email", config.Template)
+	if err != nil {
+		return nil, err // untested section
+	}
+	return &Email{logger: logger, config: config, template: tmpl, sendMail: smtp.SendMail}, nil
+}
+
+// mail immediately or remember for the digest, depending on the severity, reports are mailed right away
+func (e *Email) Send(event Event) error {
+	if event.Type == EventReport { // always mailed, it already is a digest
+		return e.mail(event.Report.Title(), strings.Replace(event.Report.String(), "\n", "\r\n", -1)+"\r\n")
+	}
+	switch e.mode(Severity(event)) {
+	case EmailImmediate:
+		summary, err := e.template.Render(event)
+		if err != nil {
+			return err
+		}
+		body := summary + "\r\n"
+		if event.Diagnostics != "" {
+			body += "Diagnostics: " + event.Diagnostics + "\r\n"
+		}
+		if len(event.Events) > 0 {
+			body += "\r\nRecent events:\r\n" + strings.Join(event.Events, "\r\n") + "\r\n"
+		}
+		if event.Logs != nil {
+			body += "\r\nLast logs of " + event.Logs.Container + ":\r\n" + strings.Replace(event.Logs.Tail, "\n", "\r\n", -1)
+		}
+		return e.mail(summary, body)
+	case EmailDigest:
+		e.lock.Lock()
+		e.pending = append(e.pending, event)
+		e.lock.Unlock()
+	}
+	return nil
+}
+
+// sends a digest every digestInterval and a last one when stopped
+func (e *Email) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(e.config.DigestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			e.logDigestError(e.SendDigest())
+			return
+		}
+		e.logDigestError(e.SendDigest())
+	}
+}
+
+// one mail of all events since the last digest, grouped by namespace, nothing when there were none
+func (e *Email) SendDigest() error {
+	e.lock.Lock()
+	events := e.pending
+	e.pending = nil
+	e.lock.Unlock()
+	if len(events) == 0 {
+		return nil
+	}
+
+	byNamespace := map[string][]Event{}
+	var namespaces []string
+	for _, event := range events {
+		namespace := event.Object.Namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+		if byNamespace[namespace] == nil {
+			namespaces = append(namespaces, namespace)
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], event)
+	}
+	sort.Strings(namespaces)
+
+	var body bytes.Buffer
+	for _, namespace := range namespaces {
+		fmt.Fprintf(&body, "%s:\r\n", namespace)
+		for _, event := range byNamespace[namespace] {
+			summary, err := e.template.Render(event)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&body, "  %s %s\r\n", event.Time.UTC().Format(time.RFC3339), summary)
+		}
+		body.WriteString("\r\n")
+	}
+	subject := fmt.Sprintf("kube-remediator: %d events in the last %v", len(events), e.config.DigestInterval)
+	return e.mail(subject, body.String())
+}
+
+func (e *Email) mode(severity string) string {
+	for name, mode := range e.config.Severities {
+		if strings.EqualFold(name, severity) { // viper lowercases keys
+			return mode
+		}
+	}
+	return EmailNone
+}
+
+func (e *Email) mail(subject string, body string) error {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(body)
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	return e.config.retry(func() error {
+		err := e.sendMail(addr, auth, e.config.From, e.config.To, message.Bytes())
+		if rejected, ok := err.(*textproto.Error); ok && rejected.Code >= 500 { // permanent, like an unknown recipient
+			return rejected
+		}
+		return retryable(err)
+	})
+}
+
+func (e *Email) logDigestError(err error) {
+	if err != nil {
+		e.logger.Warn("Error sending digest", zap.Error(err))
+	}
+}
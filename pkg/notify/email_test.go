@@ -0,0 +1,183 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mail struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+	msg  string
+}
+
+func email(t *testing.T, change func(*notify.EmailConfig)) (*notify.Email, *[]mail) {
+	config := notify.DefaultEmailConfig()
+	config.Enabled = true
+	config.Host = "smtp.example.com"
+	config.From = "kube-remediator@example.com"
+	config.To = []string{"oncall@example.com", "team@example.com"}
+	config.RetryDelay = time.Millisecond
+	if change != nil {
+		change(&config)
+	}
+	email, err := notify.NewEmail(zap.NewNop(), config)
+	assert.NilError(t, err)
+
+	var mails []mail
+	var lock sync.Mutex
+	email.UseSendMail(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		lock.Lock()
+		defer lock.Unlock()
+		mails = append(mails, mail{addr: addr, auth: a, from: from, to: to, msg: string(msg)})
+		return nil
+	})
+	return email, &mails
+}
+
+func event(namespace string, name string, outcome string) notify.Event {
+	return notify.Event{
+		Time:       time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC),
+		Type:       notify.EventRemediation,
+		Remediator: "CrashLoopBackOffRescheduler",
+		Object:     audit.ObjectRef{Kind: "Pod", Namespace: namespace, Name: name},
+		Reason:     "CrashLoopBackOff",
+		Action:     "evicted",
+		Outcome:    outcome,
+	}
+}
+
+func TestMailsErrorsImmediately(t *testing.T) {
+	email, mails := email(t, nil)
+
+	failed := event("payments", "api", "error")
+	failed.Detail = "boom"
+	assert.NilError(t, email.Send(failed))
+	assert.Equal(t, len(*mails), 1)
+	sent := (*mails)[0]
+	assert.Equal(t, sent.addr, "smtp.example.com:587")
+	assert.Equal(t, sent.auth, nil)
+	assert.Equal(t, sent.from, "kube-remediator@example.com")
+	assert.DeepEqual(t, sent.to, []string{"oncall@example.com", "team@example.com"})
+	assert.Assert(t, strings.HasPrefix(sent.msg, "From: kube-remediator@example.com\r\nTo: oncall@example.com, team@example.com\r\n"+
+		"Subject: kube-remediator failed to remediate api in payments (CrashLoopBackOff): boom\r\n"), sent.msg)
+	assert.Assert(t, strings.HasSuffix(sent.msg, "\r\n\r\nkube-remediator failed to remediate api in payments (CrashLoopBackOff): boom\r\n"), sent.msg)
+}
+
+func TestMailsLogsOfContainer(t *testing.T) {
+	email, mails := email(t, nil)
+
+	failed := event("payments", "api", "error")
+	failed.Logs = &audit.ContainerLogs{Container: "app", Tail: "starting\npanic: boom\n"}
+	assert.NilError(t, email.Send(failed))
+	assert.Assert(t, strings.HasSuffix((*mails)[0].msg, "(CrashLoopBackOff)\r\n\r\nLast logs of app:\r\nstarting\r\npanic: boom\r\n"), (*mails)[0].msg)
+}
+
+func TestMailsActionsInDigestGroupedByNamespace(t *testing.T) {
+	email, mails := email(t, nil)
+
+	assert.NilError(t, email.Send(event("web", "frontend", "success")))
+	assert.NilError(t, email.Send(event("payments", "api", "success")))
+	assert.NilError(t, email.Send(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"})) // immediate warning
+	assert.NilError(t, email.Send(event("payments", "worker", "success")))
+	assert.Equal(t, len(*mails), 1)
+
+	assert.NilError(t, email.SendDigest())
+	assert.Equal(t, len(*mails), 2)
+	digest := (*mails)[1].msg
+	assert.Assert(t, strings.Contains(digest, "Subject: kube-remediator: 3 events in the last 1h0m0s\r\n"), digest)
+	assert.Assert(t, strings.HasSuffix(digest, "\r\n\r\n"+
+		"payments:\r\n"+
+		"  2019-07-01T12:00:00Z kube-remediator evicted api in payments (CrashLoopBackOff)\r\n"+
+		"  2019-07-01T12:00:00Z kube-remediator evicted worker in payments (CrashLoopBackOff)\r\n"+
+		"\r\n"+
+		"web:\r\n"+
+		"  2019-07-01T12:00:00Z kube-remediator evicted frontend in web (CrashLoopBackOff)\r\n"+
+		"\r\n"), digest)
+
+	assert.NilError(t, email.SendDigest()) // nothing new
+	assert.Equal(t, len(*mails), 2)
+}
+
+func TestIgnoresSeveritiesNotConfigured(t *testing.T) {
+	email, mails := email(t, func(config *notify.EmailConfig) {
+		config.Severities = map[string]string{"error": notify.EmailDigest, "warning": notify.EmailNone}
+		config.Username = "user"
+	})
+
+	assert.NilError(t, email.Send(event("web", "frontend", "success")))
+	assert.NilError(t, email.Send(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"}))
+	assert.NilError(t, email.Send(event("web", "frontend", "error")))
+	assert.NilError(t, email.SendDigest())
+	assert.Equal(t, len(*mails), 1)
+	assert.Assert(t, strings.Contains((*mails)[0].msg, "Subject: kube-remediator: 1 events"))
+	assert.Assert(t, (*mails)[0].auth != nil)
+}
+
+func TestSendsLastDigestWhenStopped(t *testing.T) {
+	email, mails := email(t, nil)
+	assert.NilError(t, email.Send(event("web", "frontend", "success")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go email.Run(ctx, &wg)
+	cancel()
+	wg.Wait()
+	assert.Equal(t, len(*mails), 1)
+}
+
+func TestReturnsMailErrors(t *testing.T) {
+	email, _ := email(t, nil)
+	attempts := 0
+	email.UseSendMail(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		attempts++
+		return errors.New("connection refused")
+	})
+	assert.ErrorContains(t, email.Send(event("web", "frontend", "error")), "connection refused")
+	assert.Equal(t, attempts, 4)
+}
+
+func TestDoesNotRetryRejectedMails(t *testing.T) {
+	email, _ := email(t, nil)
+	attempts := 0
+	email.UseSendMail(func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		attempts++
+		return &textproto.Error{Code: 550, Msg: "no such user"}
+	})
+	assert.ErrorContains(t, email.Send(event("web", "frontend", "error")), "no such user")
+	assert.Equal(t, attempts, 1)
+}
+
+func TestMailsWithTemplate(t *testing.T) {
+	email, mails := email(t, func(config *notify.EmailConfig) { config.Template = "[{{.Severity | upper}}] {{.Object.Name}}" })
+	failed := event("web", "frontend", "error")
+	failed.Severity = notify.SeverityError
+	assert.NilError(t, email.Send(failed))
+	assert.Assert(t, strings.Contains((*mails)[0].msg, "Subject: [ERROR] frontend\r\n"), (*mails)[0].msg)
+}
+
+func TestRejectsInvalidEmailConfig(t *testing.T) {
+	config := notify.DefaultEmailConfig()
+	config.Enabled = true
+	assert.ErrorContains(t, config.Validate(), "host, from and to are required")
+
+	config.Host, config.From, config.To = "smtp.example.com", "a@example.com", []string{"b@example.com"}
+	config.Severities = map[string]string{"fatal": notify.EmailImmediate}
+	assert.ErrorContains(t, config.Validate(), "unknown severity")
+
+	config.Severities = map[string]string{"error": "hourly"}
+	assert.ErrorContains(t, config.Validate(), "severities.error must be one of")
+}
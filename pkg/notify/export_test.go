@@ -0,0 +1,15 @@
+package notify
+
+import (
+	"net/smtp"
+	"time"
+)
+
+func (e *Email) UseSendMail(sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error) {
+	e.sendMail = sendMail
+}
+
+func (r *Reporter) UseClock(now func() time.Time) {
+	r.now = now
+	r.reset()
+}
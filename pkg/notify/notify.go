@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// types of events
+const (
+	EventRemediation = "remediation" // a Pod or Node was remediated or remediating it failed
+	EventKillSwitch  = "killSwitch"  // the kill switch was engaged or released
+	EventReport      = "report"      // summary of the actions of a period
+	EventWatchdog    = "watchdog"    // a remediator stopped making progress or recovered
+)
+
+// something humans want to hear about
+type Event struct {
+	Time        time.Time            `json:"time"`
+	Type        string               `json:"type"`
+	Severity    string               `json:"severity,omitempty"` // see Severity
+	Remediator  string               `json:"remediator,omitempty"`
+	Cluster     string               `json:"cluster,omitempty"` // "" when there is only the one cluster
+	Object      audit.ObjectRef      `json:"object"`
+	Owner       string               `json:"owner,omitempty"`       // namespace/kind/name of the owner of the Pod, "" for Nodes and Pods without owner
+	Reason      string               `json:"reason,omitempty"`      // the detected problem
+	Action      string               `json:"action"`                // deleted, evicted or cordoned, engaged or released for the kill switch, stuck or recovered for the watchdog
+	Outcome     string               `json:"outcome,omitempty"`     // success or error
+	Detail      string               `json:"detail,omitempty"`      // the error
+	Restarts    int32                `json:"restarts,omitempty"`    // of all containers of the Pod
+	Logs        *audit.ContainerLogs `json:"logs,omitempty"`        // of the failing container, when captured
+	Events      []string             `json:"events,omitempty"`      // recent Warning events of the Pod, newest last, when captured
+	Diagnostics string               `json:"diagnostics,omitempty"` // where the diagnostics bundle was stored
+	Report      *Report              `json:"report,omitempty"`      // only for reports
+}
+
+type Notifier interface {
+	Notify(Event)
+}
+
+// sends events to all of them, empty sends nothing, each Sink picks the events of its route
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	event.Severity = Severity(event)
+	for _, notifier := range n {
+		notifier.Notify(event)
+	}
+}
+
+// labels the events of components that do not know what cluster they watch, like the kill switch
+type ClusterNotifier struct {
+	Cluster  string
+	Notifier Notifier
+}
+
+func (c ClusterNotifier) Notify(event Event) {
+	event.Cluster = c.Cluster
+	c.Notifier.Notify(event)
+}
+
+// the owner or else the object, with the cluster in front when there are several
+func workload(event Event) string {
+	name := event.Owner
+	if name == "" {
+		name = subject(event)
+	}
+	if event.Cluster != "" {
+		name = event.Cluster + ": " + name
+	}
+	return name
+}
+
+// Delivers events in the background so slow or broken endpoints do not hold up remediation,
+// events that do not fit into the queue are dropped
+type Queue struct {
+	logger *zap.Logger
+	send   func(Event) error
+	events chan Event
+}
+
+func NewQueue(logger *zap.Logger, size int, send func(Event) error) *Queue {
+	return &Queue{logger: logger, send: send, events: make(chan Event, size)}
+}
+
+// events waiting to be sent
+func (q *Queue) Len() int {
+	return len(q.events)
+}
+
+func (q *Queue) Notify(event Event) {
+	select {
+	case q.events <- event:
+	default:
+		q.logger.Warn("Dropping notification, queue is full", zap.String("remediator", event.Remediator))
+	}
+}
+
+// sends what is still queued when ctx is done before returning, like the events of the last remediations
+func (q *Queue) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer q.logger.Info("Stopping", zap.String("reason", "Signal"))
+	q.logger.Info("Starting")
+
+	for {
+		select {
+		case event := <-q.events:
+			q.sendLogged(event)
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-q.events:
+					q.sendLogged(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *Queue) sendLogged(event Event) {
+	if err := q.send(event); err != nil {
+		q.logger.Warn("Error sending notification", zap.String("remediator", event.Remediator), zap.Error(err))
+	}
+}
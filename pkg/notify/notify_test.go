@@ -0,0 +1,133 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	events []notify.Event
+}
+
+func (r *recorder) Notify(event notify.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestNotifiesAllAndSetsTimeAndSeverity(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	notify.Notifiers{first, second}.Notify(notify.Event{Action: "deleted"})
+	assert.Equal(t, len(first.events), 1)
+	assert.Equal(t, len(second.events), 1)
+	assert.Assert(t, time.Since(first.events[0].Time) < time.Minute)
+	assert.Equal(t, first.events[0].Severity, notify.SeverityInfo)
+
+	var nobody notify.Notifiers
+	nobody.Notify(notify.Event{}) // does not panic
+}
+
+func TestLabelsEventsWithCluster(t *testing.T) {
+	sent := &recorder{}
+	notify.ClusterNotifier{Cluster: "prod", Notifier: sent}.Notify(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"})
+	assert.Equal(t, sent.events[0].Cluster, "prod")
+}
+
+func TestQueueSendsInBackground(t *testing.T) {
+	sent := make(chan notify.Event, 2)
+	queue := notify.NewQueue(zap.NewNop(), 2, func(event notify.Event) error {
+		sent <- event
+		return errors.New("logged")
+	})
+	queue.Notify(notify.Event{Action: "deleted"})
+	queue.Notify(notify.Event{Action: "evicted"})
+	queue.Notify(notify.Event{Action: "dropped"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go queue.Run(ctx, &wg)
+	assert.Equal(t, (<-sent).Action, "deleted")
+	assert.Equal(t, (<-sent).Action, "evicted")
+	cancel()
+	wg.Wait()
+	assert.Equal(t, len(sent), 0)
+}
+
+func TestQueueSendsWhatIsQueuedWhenStopped(t *testing.T) {
+	var sent []notify.Event
+	queue := notify.NewQueue(zap.NewNop(), 2, func(event notify.Event) error {
+		sent = append(sent, event)
+		return nil
+	})
+	queue.Notify(notify.Event{Action: "deleted"})
+	queue.Notify(notify.Event{Action: "evicted"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	queue.Run(ctx, &wg)
+	assert.Equal(t, len(sent), 2)
+	assert.Equal(t, queue.Len(), 0)
+}
+
+func TestRoutesBySeverityNamespaceAndRemediator(t *testing.T) {
+	route := notify.RouteConfig{MinSeverity: "Warning", Namespaces: []string{"payments"}, Remediators: []string{"crashloopbackoffrescheduler"}}
+	failed := notify.Event{Type: notify.EventRemediation, Remediator: "CrashLoopBackOffRescheduler", Outcome: "error"}
+	failed.Object.Namespace = "payments"
+	assert.Equal(t, route.Matches(failed), true)
+
+	succeeded := failed
+	succeeded.Outcome = "success"
+	assert.Equal(t, route.Matches(succeeded), false)
+
+	elsewhere := failed
+	elsewhere.Object.Namespace = "default"
+	assert.Equal(t, route.Matches(elsewhere), false)
+
+	other := failed
+	other.Remediator = "OldPodDeleter"
+	assert.Equal(t, route.Matches(other), false)
+
+	assert.Equal(t, route.Matches(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"}), true)
+}
+
+func TestSinkDropsEventsOutsideRouteOrAboveRateLimit(t *testing.T) {
+	var sent []notify.Event
+	sink := notify.NewSink(zap.NewNop(), notify.DeliveryConfig{Route: notify.RouteConfig{MinSeverity: "error"}, RateLimit: 2}, 10, func(event notify.Event) error {
+		sent = append(sent, event)
+		return nil
+	})
+	minute := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i, outcome := range []string{"error", "success", "error", "error"} {
+		sink.Notify(notify.Event{Time: minute.Add(time.Duration(i) * time.Second), Outcome: outcome})
+	}
+	sink.Notify(notify.Event{Time: minute.Add(time.Minute), Outcome: "error"})
+	assert.Equal(t, sink.Len(), 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go sink.Run(ctx, &wg)
+	for sink.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+	assert.Equal(t, sent[2].Time, minute.Add(time.Minute))
+}
+
+func TestRejectsInvalidDelivery(t *testing.T) {
+	config := notify.DefaultDeliveryConfig()
+	config.Route.MinSeverity = "fatal"
+	assert.ErrorContains(t, config.Validate("slack"), "notifications.slack.route.minSeverity must be one of")
+
+	config = notify.DefaultDeliveryConfig()
+	config.RateLimit = -1
+	assert.ErrorContains(t, config.Validate("slack"), "notifications.slack.rateLimit must not be negative")
+}
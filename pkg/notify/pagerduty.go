@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+var pagerDutySeverities = []string{"critical", "error", "warning", "info"}
+
+// Triggers PagerDuty incidents through the Events API v2 when remediation keeps failing
+type PagerDutyConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	RoutingKey          string        `mapstructure:"routingKey"` // integration key of the service
+	URL                 string        `mapstructure:"url"`
+	Severity            string        `mapstructure:"severity"`
+	FailuresPerWorkload int           `mapstructure:"failuresPerWorkload"` // failed remediations in a row of one workload that trigger
+	ErrorRate           float64       `mapstructure:"errorRate"`           // share of failed actions of a remediator that triggers, 0 means never
+	MinActions          int           `mapstructure:"minActions"`          // actions within window before errorRate is checked
+	Window              time.Duration `mapstructure:"window"`
+
+	DeliveryConfig `mapstructure:",squash"`
+}
+
+type PagerDuty struct {
+	config PagerDutyConfig
+	client *http.Client
+
+	lock      sync.Mutex
+	failures  map[string]int        // failed remediations in a row per workload
+	actions   map[string][]pdAction // recent actions per remediator
+	incidents map[string]bool       // triggered and not resolved, by dedup key
+}
+
+type pdAction struct {
+	time   time.Time
+	failed bool
+}
+
+func DefaultPagerDutyConfig() PagerDutyConfig {
+	return PagerDutyConfig{
+		URL:                 DefaultPagerDutyURL,
+		Severity:            "error",
+		FailuresPerWorkload: 3,
+		ErrorRate:           0.5,
+		MinActions:          5,
+		Window:              time.Hour,
+		DeliveryConfig:      DefaultDeliveryConfig(),
+	}
+}
+
+func (c PagerDutyConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.RoutingKey == "" {
+		return fmt.Errorf("notifications.pagerDuty.routingKey is required when pagerDuty is enabled")
+	}
+	if c.URL == "" {
+		return fmt.Errorf("notifications.pagerDuty.url is required when pagerDuty is enabled")
+	}
+	if !contains(pagerDutySeverities, c.Severity) {
+		return fmt.Errorf("notifications.pagerDuty.severity must be one of %v, got %q", pagerDutySeverities, c.Severity)
+	}
+	if c.FailuresPerWorkload < 1 {
+		return fmt.Errorf("notifications.pagerDuty.failuresPerWorkload must be at least 1, got %v", c.FailuresPerWorkload)
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("notifications.pagerDuty.errorRate must be between 0 and 1, got %v", c.ErrorRate)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("notifications.pagerDuty.window must be positive, got %v", c.Window)
+	}
+	return c.DeliveryConfig.Validate("pagerDuty")
+}
+
+func NewPagerDuty(config PagerDutyConfig) (*PagerDuty, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &PagerDuty{
+		config:    config,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		failures:  map[string]int{},
+		actions:   map[string][]pdAction{},
+		incidents: map[string]bool{},
+	}, nil
+}
+
+// triggers when a workload failed too often in a row or the remediator fails too often,
+// resolves once the workload was remediated or the error rate dropped
+func (p *PagerDuty) Send(event Event) error {
+	if event.Type != EventRemediation {
+		return nil
+	}
+	var errs []error
+	for _, message := range p.evaluate(event) {
+		if err := p.post(message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Events API v2 messages for what changed
+func (p *PagerDuty) evaluate(event Event) []map[string]interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var messages []map[string]interface{}
+	failed := event.Outcome == "error"
+
+	workload := workload(event)
+	workloadKey := "kube-remediator/workload/" + workload
+	if failed {
+		p.failures[workload]++
+		if p.failures[workload] >= p.config.FailuresPerWorkload && !p.incidents[workloadKey] {
+			summary := fmt.Sprintf("kube-remediator failed to remediate %s %d times in a row: %s", workload, p.failures[workload], event.Detail)
+			messages = append(messages, p.trigger(workloadKey, summary, event))
+		}
+	} else {
+		delete(p.failures, workload)
+		if p.incidents[workloadKey] {
+			messages = append(messages, p.resolve(workloadKey))
+		}
+	}
+
+	if p.config.ErrorRate == 0 {
+		return messages
+	}
+	remediatorKey := "kube-remediator/remediator/" + event.Remediator
+	actions := append(p.actions[event.Remediator], pdAction{time: event.Time, failed: failed})
+	for len(actions) > 0 && event.Time.Sub(actions[0].time) > p.config.Window {
+		actions = actions[1:]
+	}
+	p.actions[event.Remediator] = actions
+	failures := 0
+	for _, action := range actions {
+		if action.failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(actions))
+	switch {
+	case len(actions) >= p.config.MinActions && rate >= p.config.ErrorRate && !p.incidents[remediatorKey]:
+		summary := fmt.Sprintf("kube-remediator %s failed %d of %d actions within %v", event.Remediator, failures, len(actions), p.config.Window)
+		messages = append(messages, p.trigger(remediatorKey, summary, event))
+	case rate < p.config.ErrorRate && p.incidents[remediatorKey]:
+		messages = append(messages, p.resolve(remediatorKey))
+	}
+	return messages
+}
+
+func (p *PagerDuty) trigger(key string, summary string, event Event) map[string]interface{} {
+	p.incidents[key] = true
+	details := map[string]string{
+		"object": event.Object.Kind + " " + event.Object.Namespace + "/" + event.Object.Name,
+		"reason": event.Reason,
+		"action": event.Action,
+		"error":  event.Detail,
+	}
+	if event.Cluster != "" {
+		details["cluster"] = event.Cluster
+	}
+	return map[string]interface{}{
+		"routing_key":  p.config.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    key,
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"source":         "kube-remediator",
+			"severity":       p.config.Severity,
+			"component":      event.Remediator,
+			"custom_details": details,
+		},
+	}
+}
+
+func (p *PagerDuty) resolve(key string) map[string]interface{} {
+	delete(p.incidents, key)
+	return map[string]interface{}{
+		"routing_key":  p.config.RoutingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	}
+}
+
+func (p *PagerDuty) post(message map[string]interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err // untested section
+	}
+	return p.config.post(p.client, p.config.URL, map[string]string{"Content-Type": "application/json"}, body, "pagerduty")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
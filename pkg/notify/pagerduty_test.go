@@ -0,0 +1,177 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pagerDutyMessage struct {
+	RoutingKey  string `json:"routing_key"`
+	EventAction string `json:"event_action"`
+	DedupKey    string `json:"dedup_key"`
+	Payload     struct {
+		Summary  string `json:"summary"`
+		Severity string `json:"severity"`
+	} `json:"payload"`
+}
+
+func pagerDutyServer(t *testing.T) (*httptest.Server, *[]pagerDutyMessage) {
+	var messages []pagerDutyMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message pagerDutyMessage
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&message))
+		messages = append(messages, message)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return server, &messages
+}
+
+func pagerDuty(t *testing.T, url string, change func(*notify.PagerDutyConfig)) *notify.PagerDuty {
+	config := notify.DefaultPagerDutyConfig()
+	config.Enabled = true
+	config.RoutingKey = "key"
+	config.URL = url
+	config.ErrorRate = 0
+	config.RetryDelay = time.Millisecond
+	if change != nil {
+		change(&config)
+	}
+	pagerDuty, err := notify.NewPagerDuty(config)
+	assert.NilError(t, err)
+	return pagerDuty
+}
+
+func remediation(owner string, outcome string) notify.Event {
+	return notify.Event{
+		Time:       time.Now(),
+		Type:       notify.EventRemediation,
+		Remediator: "CrashLoopBackOffRescheduler",
+		Object:     audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "foo"},
+		Owner:      owner,
+		Action:     "evicted",
+		Outcome:    outcome,
+		Detail:     "boom",
+	}
+}
+
+func TestTriggersWhenWorkloadFailsRepeatedlyAndResolvesWhenRemediated(t *testing.T) {
+	server, messages := pagerDutyServer(t)
+	defer server.Close()
+	pagerDuty := pagerDuty(t, server.URL, nil)
+
+	for i := 0; i < 4; i++ {
+		assert.NilError(t, pagerDuty.Send(remediation("default/ReplicaSet/foo", "error")))
+	}
+	assert.NilError(t, pagerDuty.Send(remediation("default/ReplicaSet/other", "error")))
+	assert.Equal(t, len(*messages), 1)
+	trigger := (*messages)[0]
+	assert.Equal(t, trigger.EventAction, "trigger")
+	assert.Equal(t, trigger.RoutingKey, "key")
+	assert.Equal(t, trigger.DedupKey, "kube-remediator/workload/default/ReplicaSet/foo")
+	assert.Equal(t, trigger.Payload.Severity, "error")
+	assert.Equal(t, trigger.Payload.Summary, "kube-remediator failed to remediate default/ReplicaSet/foo 3 times in a row: boom")
+
+	assert.NilError(t, pagerDuty.Send(remediation("default/ReplicaSet/foo", "success")))
+	assert.NilError(t, pagerDuty.Send(remediation("default/ReplicaSet/foo", "success")))
+	assert.Equal(t, len(*messages), 2)
+	assert.Equal(t, (*messages)[1].EventAction, "resolve")
+	assert.Equal(t, (*messages)[1].DedupKey, trigger.DedupKey)
+}
+
+func TestSuccessResetsFailuresInARow(t *testing.T) {
+	server, messages := pagerDutyServer(t)
+	defer server.Close()
+	pagerDuty := pagerDuty(t, server.URL, nil)
+
+	for _, outcome := range []string{"error", "error", "success", "error", "error"} {
+		assert.NilError(t, pagerDuty.Send(remediation("default/ReplicaSet/foo", outcome)))
+	}
+	assert.Equal(t, len(*messages), 0)
+}
+
+func TestCountsFailuresOfEachCluster(t *testing.T) {
+	server, messages := pagerDutyServer(t)
+	defer server.Close()
+	pagerDuty := pagerDuty(t, server.URL, nil)
+
+	for _, cluster := range []string{"prod", "staging", "prod", "staging", "prod"} {
+		failed := remediation("default/ReplicaSet/foo", "error")
+		failed.Cluster = cluster
+		assert.NilError(t, pagerDuty.Send(failed))
+	}
+	assert.Equal(t, len(*messages), 1)
+	assert.Equal(t, (*messages)[0].DedupKey, "kube-remediator/workload/prod: default/ReplicaSet/foo")
+}
+
+func TestTriggersWhenErrorRateOfRemediatorIsExceeded(t *testing.T) {
+	server, messages := pagerDutyServer(t)
+	defer server.Close()
+	pagerDuty := pagerDuty(t, server.URL, func(config *notify.PagerDutyConfig) {
+		config.FailuresPerWorkload = 100
+		config.ErrorRate = 0.5
+		config.MinActions = 4
+	})
+
+	// each failure is a different workload
+	for i, outcome := range []string{"success", "error", "success", "error"} {
+		assert.NilError(t, pagerDuty.Send(remediation(string(rune('a'+i)), outcome)))
+	}
+	assert.Equal(t, len(*messages), 1)
+	assert.Equal(t, (*messages)[0].DedupKey, "kube-remediator/remediator/CrashLoopBackOffRescheduler")
+	assert.Equal(t, (*messages)[0].Payload.Summary, "kube-remediator CrashLoopBackOffRescheduler failed 2 of 4 actions within 1h0m0s")
+
+	assert.NilError(t, pagerDuty.Send(remediation("e", "success")))
+	assert.Equal(t, len(*messages), 2)
+	assert.Equal(t, (*messages)[1].EventAction, "resolve")
+}
+
+func TestForgetsActionsOutsideOfWindow(t *testing.T) {
+	server, messages := pagerDutyServer(t)
+	defer server.Close()
+	pagerDuty := pagerDuty(t, server.URL, func(config *notify.PagerDutyConfig) {
+		config.FailuresPerWorkload = 100
+		config.ErrorRate = 0.5
+		config.MinActions = 2
+	})
+
+	old := remediation("a", "error")
+	old.Time = time.Now().Add(-2 * time.Hour)
+	assert.NilError(t, pagerDuty.Send(old))
+	assert.NilError(t, pagerDuty.Send(remediation("b", "success")))
+	assert.NilError(t, pagerDuty.Send(remediation("c", "success")))
+	assert.Equal(t, len(*messages), 0)
+}
+
+func TestIgnoresKillSwitch(t *testing.T) {
+	pagerDuty := pagerDuty(t, "http://localhost:0", func(config *notify.PagerDutyConfig) { config.FailuresPerWorkload = 1 })
+	assert.NilError(t, pagerDuty.Send(notify.Event{Type: notify.EventKillSwitch, Outcome: "error"}))
+}
+
+func TestFailsWhenPagerDutyIsUnreachable(t *testing.T) {
+	pagerDuty := pagerDuty(t, "http://localhost:0", func(config *notify.PagerDutyConfig) { config.FailuresPerWorkload = 1 })
+	assert.ErrorContains(t, pagerDuty.Send(remediation("a", "error")), "localhost:0")
+}
+
+func TestRejectsInvalidPagerDutyConfig(t *testing.T) {
+	config := notify.DefaultPagerDutyConfig()
+	config.Enabled = true
+	assert.ErrorContains(t, config.Validate(), "routingKey is required")
+
+	config.RoutingKey = "key"
+	config.Severity = "fatal"
+	assert.ErrorContains(t, config.Validate(), "severity must be one of")
+
+	config.Severity = "error"
+	config.ErrorRate = 2
+	assert.ErrorContains(t, config.Validate(), "errorRate must be between 0 and 1")
+
+	config.ErrorRate = 0.5
+	config.FailuresPerWorkload = 0
+	assert.ErrorContains(t, config.Validate(), "failuresPerWorkload must be at least 1")
+}
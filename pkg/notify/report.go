@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Summarizes the actions of a period for the notifiers and as metrics
+type ReportConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Interval     time.Duration `mapstructure:"interval"`     // period of each report
+	TopWorkloads int           `mapstructure:"topWorkloads"` // workloads with the most actions listed
+}
+
+// what happened in a period, sent as an Event of type report
+type Report struct {
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Actions      int            `json:"actions"`
+	Failures     int            `json:"failures"`
+	TopWorkloads []Count        `json:"topWorkloads"` // most actions first
+	Namespaces   map[string]int `json:"namespaces"`   // actions per namespace, "" for Nodes
+	Reasons      map[string]int `json:"reasons"`      // actions per detected problem
+	Errors       map[string]int `json:"errors"`       // failures per error
+}
+
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type Reporter struct {
+	logger    *zap.Logger
+	notifiers Notifiers
+	metrics   *metrics.Report_Metrics
+	top       int
+	now       func() time.Time
+
+	lock      sync.Mutex
+	start     time.Time
+	actions   int
+	failures  int
+	workloads map[string]int
+	counts    map[string]map[string]int // namespaces, reasons and errors
+}
+
+func DefaultReportConfig() ReportConfig {
+	return ReportConfig{Interval: 24 * time.Hour, TopWorkloads: 10}
+}
+
+func (c ReportConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("report.interval must be positive, got %v", c.Interval)
+	}
+	if c.TopWorkloads < 0 {
+		return fmt.Errorf("report.topWorkloads must not be negative, got %d", c.TopWorkloads)
+	}
+	return nil
+}
+
+// reports are sent to notifiers and set on metrics
+func NewReporter(logger *zap.Logger, config ReportConfig, notifiers Notifiers, metrics *metrics.Report_Metrics) *Reporter {
+	r := &Reporter{logger: logger, notifiers: notifiers, metrics: metrics, top: config.TopWorkloads, now: time.Now}
+	r.reset()
+	return r
+}
+
+// counts remediations, other events are not reported
+func (r *Reporter) Notify(event Event) {
+	if event.Type != EventRemediation {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.actions++
+	r.workloads[workload(event)]++
+	r.counts["namespaces"][event.Object.Namespace]++
+	r.counts["reasons"][event.Reason]++
+	if event.Outcome == "error" {
+		r.failures++
+		r.counts["errors"][event.Detail]++
+	}
+}
+
+// sends a report every interval, the actions of the last partial period are not reported
+func (r *Reporter) Run(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+	defer r.logger.Info("Stopping", zap.String("reason", "Signal"))
+	r.logger.Info("Starting", zap.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Send()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// report the actions since the last report and start over, the metrics are updated even when nothing happened
+func (r *Reporter) Send() {
+	report := r.report()
+	workloads := map[string]int{}
+	for _, workload := range report.TopWorkloads {
+		workloads[workload.Name] = workload.Count
+	}
+	r.metrics.SetReport(report.Actions, report.Failures, report.Namespaces, workloads)
+	if report.Actions == 0 {
+		r.logger.Info("Nothing to report")
+		return
+	}
+	r.notifiers.Notify(Event{Time: report.End, Type: EventReport, Action: "reported", Report: &report})
+}
+
+func (r *Reporter) report() Report {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	report := Report{
+		Start:      r.start,
+		End:        r.now().UTC(),
+		Actions:    r.actions,
+		Failures:   r.failures,
+		Namespaces: r.counts["namespaces"],
+		Reasons:    r.counts["reasons"],
+		Errors:     r.counts["errors"],
+	}
+	report.TopWorkloads = sortedCounts(r.workloads)
+	if len(report.TopWorkloads) > r.top {
+		report.TopWorkloads = report.TopWorkloads[:r.top]
+	}
+	r.reset()
+	return report
+}
+
+func (r *Reporter) reset() {
+	r.start = r.now().UTC()
+	r.actions, r.failures = 0, 0
+	r.workloads = map[string]int{}
+	r.counts = map[string]map[string]int{"namespaces": {}, "reasons": {}, "errors": {}}
+}
+
+// most first, equal counts by name
+func sortedCounts(counts map[string]int) []Count {
+	sorted := []Count{}
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func (r *Report) Title() string {
+	return fmt.Sprintf("kube-remediator report: %d actions, %d failed in the last %v", r.Actions, r.Failures, r.End.Sub(r.Start).Round(time.Minute))
+}
+
+// title and one section per breakdown, used by the default Slack template and email
+func (r *Report) String() string {
+	var text strings.Builder
+	text.WriteString(r.Title())
+	section := func(title string, counts []Count) {
+		if len(counts) == 0 {
+			return
+		}
+		text.WriteString("\n" + title + ":")
+		for _, count := range counts {
+			name := count.Name
+			if name == "" {
+				name = "(cluster)"
+			}
+			fmt.Fprintf(&text, "\n  %s: %d", name, count.Count)
+		}
+	}
+	section("Top workloads", r.TopWorkloads)
+	section("Namespaces", sortedCounts(r.Namespaces))
+	section("Reasons", sortedCounts(r.Reasons))
+	section("Errors", sortedCounts(r.Errors))
+	return text.String()
+}
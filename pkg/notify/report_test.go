@@ -0,0 +1,121 @@
+package notify_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reporter(t *testing.T, top int) (*notify.Reporter, *recorder, *time.Time) {
+	config := notify.DefaultReportConfig()
+	config.Enabled = true
+	config.TopWorkloads = top
+	sent := &recorder{}
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	reporter := notify.NewReporter(zap.NewNop(), config, notify.Notifiers{sent}, nil)
+	reporter.UseClock(func() time.Time { return now })
+	return reporter, sent, &now
+}
+
+func TestReportsActionsOfThePeriod(t *testing.T) {
+	reporter, sent, now := reporter(t, 2)
+	for _, name := range []string{"api-1", "api-2", "api-3"} {
+		action := event("payments", name, "success")
+		action.Owner = "payments/ReplicaSet/api"
+		reporter.Notify(action)
+	}
+	failed := event("shop", "web-1", "error")
+	failed.Owner, failed.Detail = "shop/ReplicaSet/web", "forbidden"
+	reporter.Notify(failed)
+	reporter.Notify(notify.Event{Type: notify.EventRemediation, Object: audit.ObjectRef{Kind: "Node", Name: "node-1"}, Reason: "KernelDeadlock", Outcome: "success"})
+	reporter.Notify(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"})
+	*now = now.Add(24 * time.Hour)
+	reporter.Send()
+
+	assert.Equal(t, len(sent.events), 1)
+	assert.Equal(t, sent.events[0].Type, notify.EventReport)
+	report := sent.events[0].Report
+	assert.Equal(t, report.End.Sub(report.Start), 24*time.Hour)
+	assert.Equal(t, report.Actions, 5)
+	assert.Equal(t, report.Failures, 1)
+	assert.DeepEqual(t, report.TopWorkloads, []notify.Count{{Name: "payments/ReplicaSet/api", Count: 3}, {Name: "Node/node-1", Count: 1}})
+	assert.DeepEqual(t, report.Namespaces, map[string]int{"payments": 3, "shop": 1, "": 1})
+	assert.DeepEqual(t, report.Reasons, map[string]int{"CrashLoopBackOff": 4, "KernelDeadlock": 1})
+	assert.DeepEqual(t, report.Errors, map[string]int{"forbidden": 1})
+	assert.Equal(t, report.String(), "kube-remediator report: 5 actions, 1 failed in the last 24h0m0s\n"+
+		"Top workloads:\n  payments/ReplicaSet/api: 3\n  Node/node-1: 1\n"+
+		"Namespaces:\n  payments: 3\n  (cluster): 1\n  shop: 1\n"+
+		"Reasons:\n  CrashLoopBackOff: 4\n  KernelDeadlock: 1\n"+
+		"Errors:\n  forbidden: 1")
+
+	// starts over
+	*now = now.Add(time.Hour)
+	reporter.Notify(event("payments", "api-4", "success"))
+	reporter.Send()
+	assert.Equal(t, sent.events[1].Report.Actions, 1)
+	assert.Equal(t, sent.events[1].Report.End.Sub(sent.events[1].Report.Start), time.Hour)
+}
+
+func TestDoesNotReportQuietPeriods(t *testing.T) {
+	reporter, sent, _ := reporter(t, 10)
+	reporter.Send()
+	assert.Equal(t, len(sent.events), 0)
+}
+
+func TestSetsReportMetrics(t *testing.T) {
+	reportMetrics := metrics.NewReportMetrics(zap.NewNop())
+	reportMetrics.Register()
+	defer reportMetrics.UnRegister()
+	reporter := notify.NewReporter(zap.NewNop(), notify.DefaultReportConfig(), nil, reportMetrics)
+	action := event("payments", "api-1", "success")
+	action.Owner = "payments/ReplicaSet/api"
+	reporter.Notify(action)
+	reporter.Send()
+
+	families, err := metrics.Registry.Gather()
+	assert.NilError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if strings.HasPrefix(family.GetName(), "report_") {
+				values[family.GetName()] += metric.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.DeepEqual(t, values, map[string]float64{
+		"report_actions": 1, "report_failures": 0, "report_namespace_actions": 1, "report_workload_actions": 1,
+	})
+}
+
+func TestPostsAndMailsReports(t *testing.T) {
+	report := &notify.Report{
+		Start: time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC), End: time.Date(2019, 7, 2, 12, 0, 0, 0, time.UTC),
+		Actions: 2, Namespaces: map[string]int{"payments": 2},
+	}
+	reported := notify.Event{Type: notify.EventReport, Action: "reported", Report: report}
+
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+	assert.NilError(t, slack.Send(reported))
+	assert.Equal(t, (*messages)[0]["text"], "kube-remediator report: 2 actions, 0 failed in the last 24h0m0s\nNamespaces:\n  payments: 2")
+
+	email, mails := email(t, func(config *notify.EmailConfig) { config.Severities = map[string]string{} })
+	assert.NilError(t, email.Send(reported))
+	assert.Equal(t, len(*mails), 1)
+	assert.Assert(t, strings.Contains((*mails)[0].msg, "Subject: kube-remediator report: 2 actions, 0 failed in the last 24h0m0s\r\n"))
+	assert.Assert(t, strings.HasSuffix((*mails)[0].msg, "\r\n\r\nkube-remediator report: 2 actions, 0 failed in the last 24h0m0s\r\nNamespaces:\r\n  payments: 2\r\n"))
+}
+
+func TestRejectsInvalidReportConfig(t *testing.T) {
+	assert.ErrorContains(t, notify.ReportConfig{Enabled: true}.Validate(), "report.interval must be positive")
+	assert.ErrorContains(t, notify.ReportConfig{Enabled: true, Interval: time.Hour, TopWorkloads: -1}.Validate(), "report.topWorkloads")
+	assert.NilError(t, notify.ReportConfig{}.Validate())
+}
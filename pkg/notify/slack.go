@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Posts a message to a Slack incoming webhook
+type SlackConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	WebhookURL  string            `mapstructure:"webhookURL"`
+	Channel     string            `mapstructure:"channel"`     // "" uses the channel of the webhook
+	Template    string            `mapstructure:"template"`    // text/template of an Event
+	Remediators map[string]string `mapstructure:"remediators"` // channel per remediator, missing use channel
+
+	DeliveryConfig `mapstructure:",squash"`
+}
+
+type Slack struct {
+	config   SlackConfig
+	template *Template
+	client   *http.Client
+}
+
+func DefaultSlackConfig() SlackConfig {
+	return SlackConfig{Template: DefaultTemplate, DeliveryConfig: DefaultDeliveryConfig()}
+}
+
+func (c SlackConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.WebhookURL == "" {
+		return fmt.Errorf("notifications.slack.webhookURL is required when slack is enabled")
+	}
+	if _, err := ParseTemplate("slack", c.Template); err != nil {
+		return err
+	}
+	return c.DeliveryConfig.Validate("slack")
+}
+
+func NewSlack(config SlackConfig) (*Slack, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	tmpl, err := ParseTemplate("slack", config.Template)
+	if err != nil {
+		return nil, err // untested section
+	}
+	return &Slack{config: config, template: tmpl, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (s *Slack) Send(event Event) error {
+	rendered, err := s.template.Render(event)
+	if err != nil {
+		return err
+	}
+	text := bytes.NewBufferString(rendered)
+	if len(event.Events) > 0 {
+		fmt.Fprintf(text, "\nRecent events:\n```%s```", strings.Join(event.Events, "\n"))
+	}
+	if event.Logs != nil {
+		fmt.Fprintf(text, "\nLast logs of %s:\n```%s```", event.Logs.Container, event.Logs.Tail)
+	}
+	if event.Diagnostics != "" {
+		fmt.Fprintf(text, "\nDiagnostics: %s", event.Diagnostics)
+	}
+	message := map[string]string{"text": text.String()}
+	if channel := s.channel(event.Remediator); channel != "" {
+		message["channel"] = channel
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err // untested section
+	}
+	return s.config.post(s.client, s.config.WebhookURL, map[string]string{"Content-Type": "application/json"}, body, "slack")
+}
+
+func (s *Slack) channel(remediator string) string {
+	for name, channel := range s.config.Remediators {
+		if strings.EqualFold(name, remediator) { // viper lowercases keys
+			return channel
+		}
+	}
+	return s.config.Channel
+}
@@ -0,0 +1,143 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// webhook that records posted messages
+func slackServer(t *testing.T, status int) (*httptest.Server, *[]map[string]string) {
+	var messages []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]string
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&message))
+		messages = append(messages, message)
+		w.WriteHeader(status)
+	}))
+	return server, &messages
+}
+
+func slackConfig(url string) notify.SlackConfig {
+	config := notify.DefaultSlackConfig()
+	config.Enabled = true
+	config.WebhookURL = url
+	return config
+}
+
+var deleted = notify.Event{
+	Type:       notify.EventRemediation,
+	Remediator: "CrashLoopBackOffRescheduler",
+	Object:     audit.ObjectRef{Kind: "Pod", Namespace: "payments", Name: "payments-api-xyz"},
+	Reason:     "CrashLoopBackOff",
+	Action:     "deleted",
+	Outcome:    "success",
+	Restarts:   12,
+}
+
+func TestPostsMessage(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+
+	assert.NilError(t, slack.Send(deleted))
+	assert.DeepEqual(t, *messages, []map[string]string{
+		{"text": "kube-remediator deleted payments-api-xyz in payments (CrashLoopBackOff, 12 restarts)"},
+	})
+}
+
+func TestPostsLogsOfContainer(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+
+	withLogs := deleted
+	withLogs.Logs = &audit.ContainerLogs{Container: "api", Tail: "panic: boom\n"}
+	assert.NilError(t, slack.Send(withLogs))
+	assert.Equal(t, (*messages)[0]["text"],
+		"kube-remediator deleted payments-api-xyz in payments (CrashLoopBackOff, 12 restarts)\nLast logs of api:\n```panic: boom\n```")
+}
+
+func TestPostsEventsOfPod(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+
+	withEvents := deleted
+	withEvents.Events = []string{"Unhealthy (x3): Liveness probe failed", "BackOff (x12): Back-off restarting failed container"}
+	assert.NilError(t, slack.Send(withEvents))
+	assert.Equal(t, (*messages)[0]["text"],
+		"kube-remediator deleted payments-api-xyz in payments (CrashLoopBackOff, 12 restarts)\nRecent events:\n"+
+			"```Unhealthy (x3): Liveness probe failed\nBackOff (x12): Back-off restarting failed container```")
+}
+
+func TestPostsErrorsAndKillSwitch(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+
+	failed := deleted
+	failed.Outcome, failed.Detail, failed.Restarts = "error", "boom", 0
+	assert.NilError(t, slack.Send(failed))
+	assert.NilError(t, slack.Send(notify.Event{Type: notify.EventKillSwitch, Action: "engaged"}))
+	assert.Equal(t, (*messages)[0]["text"], "kube-remediator failed to remediate payments-api-xyz in payments (CrashLoopBackOff): boom")
+	assert.Equal(t, (*messages)[1]["text"], "kube-remediator kill switch engaged")
+}
+
+func TestPostsClusterOfEvent(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+
+	inCluster := deleted
+	inCluster.Cluster = "prod-eu"
+	assert.NilError(t, slack.Send(inCluster))
+	assert.Equal(t, (*messages)[0]["text"], "[prod-eu] kube-remediator deleted payments-api-xyz in payments (CrashLoopBackOff, 12 restarts)")
+}
+
+func TestRoutesToChannelOfRemediator(t *testing.T) {
+	server, messages := slackServer(t, http.StatusOK)
+	defer server.Close()
+	config := slackConfig(server.URL)
+	config.Channel = "#kube-remediator"
+	config.Remediators = map[string]string{"crashloopbackoffrescheduler": "#payments"}
+	config.Template = "{{.Remediator}}"
+	slack, err := notify.NewSlack(config)
+	assert.NilError(t, err)
+
+	assert.NilError(t, slack.Send(deleted))
+	assert.NilError(t, slack.Send(notify.Event{Remediator: "OldPodDeleter"}))
+	assert.DeepEqual(t, *messages, []map[string]string{
+		{"channel": "#payments", "text": "CrashLoopBackOffRescheduler"},
+		{"channel": "#kube-remediator", "text": "OldPodDeleter"},
+	})
+}
+
+func TestFailsWhenSlackRejects(t *testing.T) {
+	server, _ := slackServer(t, http.StatusNotFound)
+	defer server.Close()
+	slack, err := notify.NewSlack(slackConfig(server.URL))
+	assert.NilError(t, err)
+	assert.ErrorContains(t, slack.Send(deleted), "404")
+}
+
+func TestRejectsInvalidSlackConfig(t *testing.T) {
+	_, err := notify.NewSlack(slackConfig(""))
+	assert.ErrorContains(t, err, "webhookURL is required")
+
+	config := slackConfig("http://example.com")
+	config.Template = "{{.Missing"
+	_, err = notify.NewSlack(config)
+	assert.ErrorContains(t, err, "notifications.slack.template")
+
+	assert.NilError(t, notify.SlackConfig{}.Validate()) // disabled
+}
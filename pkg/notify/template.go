@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// one line about the event, like "kube-remediator deleted payments-api-xyz in payments (CrashLoopBackOff, 12 restarts)"
+const DefaultTemplate = `{{with .Cluster}}[{{.}}] {{end}}{{if eq .Type "report"}}{{.Report}}` +
+	`{{else if eq .Type "killSwitch"}}kube-remediator kill switch {{.Action}}` +
+	`{{else if eq .Type "watchdog"}}kube-remediator {{.Remediator}} {{.Action}}{{with .Detail}}: {{.}}{{end}}` +
+	`{{else}}kube-remediator {{if eq .Outcome "error"}}failed to remediate{{else}}{{.Action}}{{end}} {{.Object.Name}}` +
+	`{{with .Object.Namespace}} in {{.}}{{end}} ({{.Reason}}{{if .Restarts}}, {{.Restarts}} restarts{{end}})` +
+	`{{with .Detail}}: {{.}}{{end}}{{end}}`
+
+var defaultTemplate = MustParseTemplate("default", DefaultTemplate)
+
+// text/template of an Event, for the notifiers that send text
+type Template struct {
+	template *template.Template
+}
+
+// name is the key of the notifier in notifications, like slack
+func ParseTemplate(name string, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"upper": strings.ToUpper,
+		"join":  strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("notifications.%s.template: %v", name, err)
+	}
+	return &Template{template: tmpl}, nil
+}
+
+func MustParseTemplate(name string, text string) *Template {
+	tmpl, err := ParseTemplate(name, text)
+	if err != nil {
+		panic(err) // untested section
+	}
+	return tmpl
+}
+
+func (t *Template) Render(event Event) (string, error) {
+	var text bytes.Buffer
+	if err := t.template.Execute(&text, event); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// one line about the event, with the default template
+func Summary(event Event) string {
+	text, _ := defaultTemplate.Render(event) // only fails for broken templates
+	return text
+}
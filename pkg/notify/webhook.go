@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const SignatureHeader = "X-Kube-Remediator-Signature"
+
+// POSTs every event as a CloudEvent to a URL
+type WebhookConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	Secret  string            `mapstructure:"secret"` // signs the body with HMAC-SHA256, "" means unsigned
+
+	DeliveryConfig `mapstructure:",squash"`
+}
+
+type Webhook struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// structured mode of https://github.com/cloudevents/spec
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	Data            Event  `json:"data"`
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{DeliveryConfig: DefaultDeliveryConfig()}
+}
+
+func (c WebhookConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.URL == "" {
+		return fmt.Errorf("notifications.webhook.url is required when webhook is enabled")
+	}
+	return c.DeliveryConfig.Validate("webhook")
+}
+
+func NewWebhook(config WebhookConfig) (*Webhook, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Webhook{config: config, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// retries when the request failed or the server had a problem, not when it rejected the event
+func (w *Webhook) Send(event Event) error {
+	body, err := json.Marshal(cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              randomID(),
+		Source:          "kube-remediator",
+		Type:            "io.kube-remediator." + event.Type,
+		Subject:         subject(event),
+		Time:            event.Time.UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            event,
+	})
+	if err != nil {
+		return err // untested section
+	}
+
+	headers := map[string]string{}
+	for name, value := range w.config.Headers {
+		headers[name] = value
+	}
+	headers["Content-Type"] = "application/cloudevents+json"
+	if w.config.Secret != "" {
+		headers[SignatureHeader] = Sign(w.config.Secret, body)
+	}
+	return w.config.post(w.client, w.config.URL, headers, body, "webhook")
+}
+
+// value of the signature header, receivers compute it from the body and compare
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subject(event Event) string {
+	if event.Object.Namespace == "" {
+		return event.Object.Kind + "/" + event.Object.Name
+	}
+	return event.Object.Namespace + "/" + event.Object.Kind + "/" + event.Object.Name
+}
+
+func randomID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
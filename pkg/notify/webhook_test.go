@@ -0,0 +1,132 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// responds with the statuses in order, then 200
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, *[]webhookRequest) {
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NilError(t, err)
+		requests = append(requests, webhookRequest{header: r.Header, body: body})
+		if len(requests) <= len(statuses) {
+			w.WriteHeader(statuses[len(requests)-1])
+		}
+	}))
+	return server, &requests
+}
+
+func webhook(t *testing.T, url string, change func(*notify.WebhookConfig)) *notify.Webhook {
+	config := notify.DefaultWebhookConfig()
+	config.Enabled = true
+	config.URL = url
+	config.RetryDelay = time.Millisecond
+	if change != nil {
+		change(&config)
+	}
+	webhook, err := notify.NewWebhook(config)
+	assert.NilError(t, err)
+	return webhook
+}
+
+var evicted = notify.Event{
+	Time:       time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC),
+	Type:       notify.EventRemediation,
+	Remediator: "CrashLoopBackOffRescheduler",
+	Object:     audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "foo"},
+	Action:     "evicted",
+	Outcome:    "success",
+}
+
+func TestPostsCloudEvent(t *testing.T) {
+	server, requests := webhookServer(t)
+	defer server.Close()
+	webhook := webhook(t, server.URL, func(config *notify.WebhookConfig) {
+		config.Headers = map[string]string{"authorization": "Bearer token"}
+	})
+
+	assert.NilError(t, webhook.Send(evicted))
+	assert.Equal(t, len(*requests), 1)
+	request := (*requests)[0]
+	assert.Equal(t, request.header.Get("Content-Type"), "application/cloudevents+json")
+	assert.Equal(t, request.header.Get("Authorization"), "Bearer token")
+	assert.Equal(t, request.header.Get(notify.SignatureHeader), "")
+
+	var cloudEvent map[string]interface{}
+	assert.NilError(t, json.Unmarshal(request.body, &cloudEvent))
+	assert.Equal(t, cloudEvent["specversion"], "1.0")
+	assert.Equal(t, cloudEvent["source"], "kube-remediator")
+	assert.Equal(t, cloudEvent["type"], "io.kube-remediator.remediation")
+	assert.Equal(t, cloudEvent["subject"], "default/Pod/foo")
+	assert.Equal(t, cloudEvent["time"], "2019-07-01T12:00:00Z")
+	assert.Equal(t, len(cloudEvent["id"].(string)), 32)
+	assert.DeepEqual(t, cloudEvent["data"], map[string]interface{}{
+		"time":       "2019-07-01T12:00:00Z",
+		"type":       "remediation",
+		"remediator": "CrashLoopBackOffRescheduler",
+		"object":     map[string]interface{}{"kind": "Pod", "namespace": "default", "name": "foo"},
+		"action":     "evicted",
+		"outcome":    "success",
+	})
+}
+
+func TestSignsBody(t *testing.T) {
+	server, requests := webhookServer(t)
+	defer server.Close()
+	webhook := webhook(t, server.URL, func(config *notify.WebhookConfig) { config.Secret = "secret" })
+
+	assert.NilError(t, webhook.Send(evicted))
+	request := (*requests)[0]
+	assert.Equal(t, request.header.Get(notify.SignatureHeader), notify.Sign("secret", request.body))
+	assert.Equal(t, notify.Sign("secret", []byte("body")), "sha256=dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355")
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	server, requests := webhookServer(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	defer server.Close()
+
+	assert.NilError(t, webhook(t, server.URL, nil).Send(evicted))
+	assert.Equal(t, len(*requests), 3)
+}
+
+func TestGivesUpAfterRetries(t *testing.T) {
+	server, requests := webhookServer(t, 503, 503, 503)
+	defer server.Close()
+
+	err := webhook(t, server.URL, func(config *notify.WebhookConfig) { config.Retries = 2 }).Send(evicted)
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, len(*requests), 3)
+}
+
+func TestDoesNotRetryRejectedEvents(t *testing.T) {
+	server, requests := webhookServer(t, http.StatusBadRequest)
+	defer server.Close()
+
+	assert.ErrorContains(t, webhook(t, server.URL, nil).Send(evicted), "400")
+	assert.Equal(t, len(*requests), 1)
+}
+
+func TestRejectsInvalidWebhookConfig(t *testing.T) {
+	config := notify.DefaultWebhookConfig()
+	config.Enabled = true
+	assert.ErrorContains(t, config.Validate(), "url is required")
+
+	config.URL = "http://example.com"
+	config.Retries = -1
+	assert.ErrorContains(t, config.Validate(), "retries must not be negative")
+}
@@ -0,0 +1,219 @@
+package remediator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"strings"
+	"time"
+)
+
+const (
+	ActionDelete  = "delete"
+	ActionEvict   = "evict"
+	ActionRestart = "restart"
+	ActionScale   = "scale"
+	ActionCordon  = "cordon"
+	ActionNotify  = "notify"
+)
+
+// what a remediator does about a Pod once it decided to, the default of the remediator unless Policy.Actions
+// has another one for the reason or the remediator
+type Action interface {
+	Name() string // as configured, like "evict"
+	Done() string // the action of metrics, audit records and notifications, like "evicted"
+
+	// act on the Pod, recording and notifying the outcome
+	Run(ctx context.Context, p *Base, pod v1.Pod, reason string)
+
+	// what the api-server says about the action, ctx asks for a dry run so nothing is changed
+	DryRun(ctx context.Context, p *Base, pod *v1.Pod) error
+}
+
+var actions = map[string]Action{}
+
+func init() {
+	for _, action := range []Action{deleteAction{}, evictAction{}, restartAction{}, scaleAction{}, cordonAction{}, notifyAction{}} {
+		actions[action.Name()] = action
+	}
+}
+
+// the names Policy.Actions can use, in the order they are documented
+func ActionNames() []string {
+	return []string{ActionDelete, ActionEvict, ActionRestart, ActionScale, ActionCordon, ActionNotify}
+}
+
+type deleteAction struct{}
+
+func (deleteAction) Name() string { return ActionDelete }
+func (deleteAction) Done() string { return "deleted" }
+
+func (deleteAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	p.tryDeletePod(ctx, pod, reason)
+}
+
+func (deleteAction) DryRun(ctx context.Context, p *Base, pod *v1.Pod) error {
+	return p.client.DeletePod(ctx, pod, p.deleteOptions(pod))
+}
+
+// honors PodDisruptionBudgets, deletes the Pod when evictions were blocked too often
+type evictAction struct{}
+
+func (evictAction) Name() string { return ActionEvict }
+func (evictAction) Done() string { return "evicted" }
+
+func (evictAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	p.tryEvictPod(ctx, pod, reason)
+}
+
+func (evictAction) DryRun(ctx context.Context, p *Base, pod *v1.Pod) error {
+	return p.client.EvictPod(ctx, pod, p.deleteOptions(pod))
+}
+
+// like kubectl rollout restart of the Deployment, StatefulSet or DaemonSet of the Pod, all its Pods are replaced
+// at the pace of its rollout strategy
+type restartAction struct{}
+
+func (restartAction) Name() string { return ActionRestart }
+func (restartAction) Done() string { return "restarted" }
+
+func (a restartAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	p.tryChangeOwner(ctx, pod, reason, a.Done(), a.restart)
+}
+
+func (a restartAction) DryRun(ctx context.Context, p *Base, pod *v1.Pod) error {
+	return p.changeOwner(ctx, pod, a.restart)
+}
+
+func (restartAction) restart(ctx context.Context, client k8s.ClientInterface, namespace string, owner *k8s.Owner) error {
+	switch owner.Reference.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+	default:
+		return fmt.Errorf("can not restart a %s", owner.Reference.Kind)
+	}
+	patch, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]string{
+			"kubectl.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
+		}},
+	}}})
+	if err != nil {
+		return err // untested section
+	}
+	return client.PatchOwner(ctx, namespace, owner.Reference, types.MergePatchType, patch)
+}
+
+// scales the owner of the Pod to 0 replicas, so a workload that keeps failing stops until a human looks at it
+type scaleAction struct{}
+
+func (scaleAction) Name() string { return ActionScale }
+func (scaleAction) Done() string { return "scaled" }
+
+func (a scaleAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	p.tryChangeOwner(ctx, pod, reason, a.Done(), a.scale)
+}
+
+func (a scaleAction) DryRun(ctx context.Context, p *Base, pod *v1.Pod) error {
+	return p.changeOwner(ctx, pod, a.scale)
+}
+
+func (scaleAction) scale(ctx context.Context, client k8s.ClientInterface, namespace string, owner *k8s.Owner) error {
+	scale, err := client.GetScale(ctx, namespace, owner.Reference)
+	if err != nil {
+		return err
+	}
+	scale.Spec.Replicas = 0
+	return client.UpdateScale(ctx, namespace, owner.Reference, scale)
+}
+
+// cordons the Node of the Pod, for problems that point to the Node rather than the app
+type cordonAction struct{}
+
+func (cordonAction) Name() string { return ActionCordon }
+func (cordonAction) Done() string { return "cordoned" }
+
+func (cordonAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	if pod.Spec.NodeName == "" {
+		p.recordResult(ctx, podEvent(&pod, reason, "cordoned"), fmt.Errorf("Pod is not scheduled to a Node"))
+		return
+	}
+	p.tryCordonNode(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: pod.Spec.NodeName}}, reason)
+}
+
+func (cordonAction) DryRun(ctx context.Context, p *Base, pod *v1.Pod) error {
+	return p.client.CordonNode(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: pod.Spec.NodeName}})
+}
+
+// only tells humans, with the logs and events of the Pod, changing nothing
+type notifyAction struct{}
+
+func (notifyAction) Name() string { return ActionNotify }
+func (notifyAction) Done() string { return "notified" }
+
+func (notifyAction) Run(ctx context.Context, p *Base, pod v1.Pod, reason string) {
+	event := podEvent(&pod, reason, "notified")
+	if !p.gatherEvidence(ctx, &pod, &event) {
+		return
+	}
+	p.logger.Info("Notifying about Pod", podInfo(&pod)...)
+	p.recordResult(ctx, event, nil)
+}
+
+func (notifyAction) DryRun(context.Context, *Base, *v1.Pod) error {
+	return nil
+}
+
+// the action for the reason, or the remediator, in Policy.Actions, fallback when there is none
+func (p *Base) action(reason string, fallback string) Action {
+	name := fallback
+	for _, key := range []string{p.policy.Remediator, reason} { // the reason wins
+		for configured, action := range p.policy.Actions {
+			if key != "" && strings.EqualFold(configured, key) {
+				name = action
+			}
+		}
+	}
+	if action, ok := actions[strings.ToLower(name)]; ok {
+		return action
+	}
+	return actions[fallback] // untested section, Policy.Validate rejects unknown actions
+}
+
+type ownerChange func(ctx context.Context, client k8s.ClientInterface, namespace string, owner *k8s.Owner) error
+
+// change the top owner of the Pod, like its Deployment
+func (p *Base) changeOwner(ctx context.Context, pod *v1.Pod, change ownerChange) error {
+	owner, err := k8s.GetTopOwner(ctx, p.client, pod)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return fmt.Errorf("Pod has no owner")
+	}
+	return change(ctx, p.client, pod.ObjectMeta.Namespace, owner)
+}
+
+func (p *Base) tryChangeOwner(ctx context.Context, pod v1.Pod, reason string, done string, change ownerChange) {
+	info := append(podInfo(&pod), zap.String("owner", ownerKey(&pod)))
+	event := podEvent(&pod, reason, done)
+	if !p.gatherEvidence(ctx, &pod, &event) {
+		return
+	}
+
+	p.logger.Info("Changing owner of Pod", append(info, zap.String("action", done))...)
+	_, call := p.policy.Tracer.Start(ctx, "change owner", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	err := p.changeOwner(ctx, &pod, change)
+	call.SetError(err)
+	call.End()
+	p.recordResult(ctx, event, err)
+	if err != nil {
+		p.callFailed("Error changing owner", info, err)
+		return
+	}
+	p.stampOwner(ctx, &pod, reason, done)
+}
@@ -0,0 +1,102 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sync"
+	"testing"
+)
+
+// a Pod of Deployment api that ran out of memory on node-1, remediated once by FailedPodRescheduler with the actions
+func remediateWithActions(t *testing.T, actions map[string]string) (*fake.Client, *audit.History) {
+	deployment := fake.Deployment("default", "api", 3)
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	controller := true
+	replicaSet.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: "apps/v1", Kind: "Deployment", Name: "api", UID: deployment.GetUID(), Controller: &controller,
+	}})
+	pod := fake.OwnedBy(fake.FailedPod("default", "api-5d8f-x2x", "OutOfmemory"), replicaSet)
+	pod.Spec.NodeName = "node-1"
+	client := fake.NewClient(pod, fake.Node("node-1"))
+	assert.NilError(t, client.AddOwner(deployment))
+	assert.NilError(t, client.AddOwner(replicaSet))
+
+	history := audit.NewHistory(10)
+	rescheduler := remediator.FailedPodRescheduler{}
+	policy := &remediator.Policy{Remediator: "FailedPodRescheduler", Actions: actions, History: history}
+	assert.NilError(t, rescheduler.Setup(zap.NewNop(), client, policy))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	rescheduler.Run(ctx, &wg)
+	return client, history
+}
+
+func TestDeletesByDefault(t *testing.T) {
+	client, history := remediateWithActions(t, nil)
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "api-5d8f-x2x"}})
+	assert.Equal(t, history.Recent("", 0)[0].Action, "deleted")
+}
+
+func TestRunsActionConfiguredForReason(t *testing.T) {
+	client, history := remediateWithActions(t, map[string]string{"FailedPodRescheduler": "notify", "outofmemory": "restart"})
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "patchOwner", Kind: "Deployment", Namespace: "default", Name: "api"}})
+	deployment, err := client.GetOwner(context.Background(), "default", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"})
+	assert.NilError(t, err)
+	restartedAt, _, _ := unstructured.NestedString(deployment.Object, "spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt")
+	assert.Assert(t, restartedAt != "")
+	assert.Equal(t, history.Recent("", 0)[0].Action, "restarted")
+}
+
+func TestRunsActionConfiguredForRemediator(t *testing.T) {
+	client, history := remediateWithActions(t, map[string]string{"failedpodrescheduler": "notify"})
+	assert.Equal(t, len(client.Actions()), 0)
+	record := history.Recent("", 0)[0]
+	assert.Equal(t, record.Action, "notified")
+	assert.Equal(t, record.Outcome, "success")
+}
+
+func TestScalesOwnerToZero(t *testing.T) {
+	client, _ := remediateWithActions(t, map[string]string{"OutOfmemory": "scale"})
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "updateScale", Kind: "Deployment", Namespace: "default", Name: "api"}})
+	scale, err := client.GetScale(context.Background(), "default", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"})
+	assert.NilError(t, err)
+	assert.Equal(t, scale.Spec.Replicas, int32(0))
+}
+
+func TestCordonsNodeOfPod(t *testing.T) {
+	client, _ := remediateWithActions(t, map[string]string{"OutOfmemory": "cordon"})
+	node, err := client.GetNode(context.Background(), "node-1")
+	assert.NilError(t, err)
+	assert.Equal(t, node.Spec.Unschedulable, true)
+}
+
+func TestDryRunsConfiguredAction(t *testing.T) {
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	client := fake.NewClient(fake.OwnedBy(fake.FailedPod("default", "api-5d8f-x2x", "OutOfmemory"), replicaSet))
+	assert.NilError(t, client.AddOwner(replicaSet))
+	history := audit.NewHistory(10)
+	rescheduler := remediator.FailedPodRescheduler{}
+	policy := &remediator.Policy{Actions: map[string]string{"OutOfmemory": "restart"}, History: history, DryRun: true, ServerSideDryRun: true}
+	assert.NilError(t, rescheduler.Setup(zap.NewNop(), client, policy))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	rescheduler.Run(ctx, &wg)
+
+	assert.Equal(t, len(client.Actions()), 0)
+	assert.Equal(t, history.Recent("", 0)[0].Detail, "dry run rejected: can not restart a ReplicaSet")
+}
+
+func TestRejectsUnknownActions(t *testing.T) {
+	policy := remediator.Policy{Actions: map[string]string{"CrashLoopBackOff": "reboot"}}
+	assert.ErrorContains(t, policy.Validate(), `actions.CrashLoopBackOff: unknown action "reboot"`)
+}
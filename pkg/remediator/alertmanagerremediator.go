@@ -0,0 +1,156 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"strings"
+	"sync"
+)
+
+// an alert of existing alerting rules that means a Pod needs remediating, like KubePodCrashLooping
+type AlertConfig struct {
+	Alert  string            `mapstructure:"alert"`  // alertname, also the reason of actions, metrics and audit records
+	Labels map[string]string `mapstructure:"labels"` // the alert must also have, like severity: critical
+	Action string            `mapstructure:"action"` // "" evicts, actions can override it by alertname
+}
+
+type AlertmanagerConfig struct {
+	Alerts         []AlertConfig `mapstructure:"alerts"`         // the first matching one decides
+	NamespaceLabel string        `mapstructure:"namespaceLabel"` // of the Pod the alert is about
+	PodLabel       string        `mapstructure:"podLabel"`
+	ClusterLabel   string        `mapstructure:"clusterLabel"` // with several clusters, alerts are for the one it names
+}
+
+func DefaultAlertmanagerConfig() AlertmanagerConfig {
+	return AlertmanagerConfig{Alerts: []AlertConfig{}, NamespaceLabel: "namespace", PodLabel: "pod", ClusterLabel: "cluster"}
+}
+
+func (c AlertmanagerConfig) Validate() error {
+	if c.NamespaceLabel == "" || c.PodLabel == "" {
+		return fmt.Errorf("namespaceLabel and podLabel are required")
+	}
+	for i, alert := range c.Alerts {
+		if alert.Alert == "" {
+			return fmt.Errorf("alerts[%d]: alert is required", i)
+		}
+		if _, ok := actions[strings.ToLower(alert.Action)]; alert.Action != "" && !ok {
+			return fmt.Errorf("alerts[%d]: unknown action %q, use %s", i, alert.Action, strings.Join(ActionNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// Remediates the Pods that firing alerts of Alertmanager are about, so existing alerting rules can drive remediations
+// instead of detecting the same problems again, the alerts come from the receiver on /api/v1/alerts
+type AlertmanagerRemediator struct {
+	Base
+	Config AlertmanagerConfig
+}
+
+func init() {
+	Register("AlertmanagerRemediator", func(configs Configs) Remediator {
+		return &AlertmanagerRemediator{Config: configs.AlertmanagerRemediator}
+	})
+}
+
+func (p *AlertmanagerRemediator) Name() string {
+	return "AlertmanagerRemediator"
+}
+
+func (p *AlertmanagerRemediator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// nothing is sent to --once, simulate or a replica without the receiver
+	if len(p.Config.Alerts) == 0 || p.policy.Alerts == nil || p.policy.Once {
+		p.logger.Debug("No alerts or no receiver, stopping")
+		return
+	}
+	p.logStartAndStop(func() {
+		alerts, stop := p.policy.Alerts.subscribe(p.policy.Leader.Leading)
+		defer stop() // the receiver outlives us when reloading config
+		for {
+			select {
+			case received := <-alerts:
+				p.remediateAlerts(ctx, received)
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// remediates the Pod of each firing alert that matches, once per Pod
+func (p *AlertmanagerRemediator) remediateAlerts(ctx context.Context, alerts []Alert) {
+	ctx, span := p.policy.Tracer.Start(ctx, "alerts", tracing.KindInternal, map[string]string{"remediator": p.policy.Remediator})
+	defer span.End()
+
+	seen := map[string]bool{}
+	for _, alert := range alerts {
+		matching := p.matching(alert)
+		if matching == nil {
+			continue
+		}
+		namespace, name := alert.Labels[p.Config.NamespaceLabel], alert.Labels[p.Config.PodLabel]
+		info := []zap.Field{zap.String("alert", matching.Alert), zap.String("namespace", namespace), zap.String("pod", name)}
+		if namespace == "" || name == "" {
+			p.logger.Info("Skipping alert, not about a Pod", info...)
+			continue
+		}
+		if seen[namespace+"/"+name] {
+			continue
+		}
+		seen[namespace+"/"+name] = true
+
+		pod, err := p.client.GetPod(ctx, namespace, name)
+		if k8s.Classify(err) == k8s.ErrorNotFound {
+			p.logger.Info("Skipping alert, Pod already gone", info...)
+			continue
+		}
+		if err != nil {
+			p.callFailed("Error getting Pod of alert", info, err)
+			continue
+		}
+		if !p.passes(nil, pod) {
+			continue
+		}
+		action := strings.ToLower(matching.Action)
+		if action == "" {
+			action = ActionEvict
+		}
+		// the alert said so, confirming before acting only checks the Pod was not replaced
+		p.remediatePod(ctx, *pod, matching.Alert, action, func(*v1.Pod) bool { return true })
+	}
+}
+
+// the first configured alert the firing alert matches, nil when none does or it is for another cluster
+func (p *AlertmanagerRemediator) matching(alert Alert) *AlertConfig {
+	if alert.Status != AlertFiring {
+		return nil
+	}
+	if p.policy.Cluster != "" && alert.Labels[p.Config.ClusterLabel] != p.policy.Cluster {
+		return nil
+	}
+	labels := make(map[string]string, len(alert.Labels)) // viper lowercases the label names of the config
+	for name, value := range alert.Labels {
+		labels[strings.ToLower(name)] = value
+	}
+	for i, config := range p.Config.Alerts {
+		if alert.Labels["alertname"] != config.Alert {
+			continue
+		}
+		matches := true
+		for name, value := range config.Labels {
+			if labels[strings.ToLower(name)] != value {
+				matches = false
+			}
+		}
+		if matches {
+			return &p.Config.Alerts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Pods api-5d8f-x2x and web-7c9b-k4m of ReplicaSets, alerts for them are evicted by KubePodCrashLooping with severity
+// critical and deleted by KubePodNotReady
+func startAlertmanagerRemediator(t *testing.T, cluster string) (*remediator.Alerts, *fake.Client, *audit.History, func()) {
+	api, web := fake.ReplicaSet("default", "api-5d8f", 3), fake.ReplicaSet("default", "web-7c9b", 3)
+	client := fake.NewClient(fake.OwnedBy(fake.Pod("default", "api-5d8f-x2x"), api), fake.OwnedBy(fake.Pod("default", "web-7c9b-k4m"), web))
+	assert.NilError(t, client.AddOwner(api))
+	assert.NilError(t, client.AddOwner(web))
+
+	config := remediator.DefaultAlertmanagerConfig()
+	config.Alerts = []remediator.AlertConfig{
+		{Alert: "KubePodCrashLooping", Labels: map[string]string{"severity": "critical"}},
+		{Alert: "KubePodNotReady", Action: "delete"},
+	}
+	alerts := remediator.NewAlerts()
+	history := audit.NewHistory(10)
+	alertmanagerRemediator := &remediator.AlertmanagerRemediator{Config: config}
+	policy := &remediator.Policy{Remediator: "AlertmanagerRemediator", Cluster: cluster, Alerts: alerts, History: history}
+	assert.NilError(t, alertmanagerRemediator.Setup(zap.NewNop(), client, policy))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go alertmanagerRemediator.Run(ctx, &wg)
+	for i := 0; i < 100 && alerts.Deliver(nil) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return alerts, client, history, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func alert(name string, status string, labels map[string]string) remediator.Alert {
+	alert := remediator.Alert{Status: status, Labels: map[string]string{"alertname": name, "namespace": "default"}}
+	for label, value := range labels {
+		alert.Labels[label] = value
+	}
+	return alert
+}
+
+func waitForRecords(history *audit.History, count int) []audit.Record {
+	for i := 0; i < 100 && len(history.Recent("", 0)) < count; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return history.Recent("", 0)
+}
+
+func TestRemediatesPodsOfFiringAlerts(t *testing.T) {
+	alerts, client, history, stop := startAlertmanagerRemediator(t, "")
+	defer stop()
+
+	assert.Equal(t, alerts.Deliver([]remediator.Alert{
+		alert("KubePodCrashLooping", "resolved", map[string]string{"pod": "api-5d8f-x2x", "severity": "critical"}),
+		alert("KubePodCrashLooping", "firing", map[string]string{"pod": "api-5d8f-x2x", "severity": "warning"}),
+		alert("KubePodCrashLooping", "firing", map[string]string{"pod": "api-5d8f-x2x", "Severity": "critical"}),
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "web-7c9b-k4m"}),
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "web-7c9b-k4m", "container": "sidecar"}), // once per Pod
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "gone"}),
+		alert("KubePodNotReady", "firing", nil),
+		alert("KubeNodeNotReady", "firing", map[string]string{"pod": "web-7c9b-k4m"}),
+	}), 1)
+
+	records := waitForRecords(history, 2)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[1].Reason, "KubePodCrashLooping")
+	assert.Equal(t, records[1].Action, "evicted")
+	assert.Equal(t, records[0].Reason, "KubePodNotReady")
+	assert.Equal(t, records[0].Action, "deleted")
+	assert.DeepEqual(t, client.Actions(), []fake.Action{
+		{Verb: "evict", Kind: "Pod", Namespace: "default", Name: "api-5d8f-x2x"},
+		{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "web-7c9b-k4m"},
+	})
+}
+
+func TestOnlyRemediatesAlertsOfItsCluster(t *testing.T) {
+	alerts, client, history, stop := startAlertmanagerRemediator(t, "staging")
+	defer stop()
+
+	alerts.Deliver([]remediator.Alert{
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "api-5d8f-x2x", "cluster": "production"}),
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "web-7c9b-k4m"}),
+		alert("KubePodNotReady", "firing", map[string]string{"pod": "web-7c9b-k4m", "cluster": "staging"}),
+	})
+	records := waitForRecords(history, 1)
+	assert.Equal(t, len(records), 1)
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "web-7c9b-k4m"}})
+}
+
+func TestDeliversAlertsOnlyToRunningRemediators(t *testing.T) {
+	var none *remediator.Alerts
+	assert.Equal(t, none.Deliver([]remediator.Alert{alert("KubePodNotReady", "firing", nil)}), 0)
+	assert.Equal(t, remediator.NewAlerts().Deliver([]remediator.Alert{alert("KubePodNotReady", "firing", nil)}), 0)
+
+	alerts, _, _, stop := startAlertmanagerRemediator(t, "")
+	stop()
+	assert.Equal(t, alerts.Deliver([]remediator.Alert{alert("KubePodNotReady", "firing", nil)}), 0)
+}
+
+func TestRejectsInvalidAlerts(t *testing.T) {
+	config := remediator.DefaultAlertmanagerConfig()
+	config.Alerts = []remediator.AlertConfig{{Action: "evict"}}
+	assert.ErrorContains(t, config.Validate(), "alerts[0]: alert is required")
+	config.Alerts = []remediator.AlertConfig{{Alert: "KubePodCrashLooping", Action: "reboot"}}
+	assert.ErrorContains(t, config.Validate(), `alerts[0]: unknown action "reboot"`)
+	config.Alerts, config.PodLabel = nil, ""
+	assert.ErrorContains(t, config.Validate(), "namespaceLabel and podLabel are required")
+}
@@ -0,0 +1,73 @@
+package remediator
+
+import (
+	"sync"
+	"time"
+)
+
+// an alert of an Alertmanager webhook, https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type Alert struct {
+	Status      string            `json:"status"` // firing or resolved
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+const AlertFiring = "firing"
+
+// Hands the alerts Alertmanager sends to every running AlertmanagerRemediator, shared by all clusters and kept across
+// config reloads, a nil Alerts hands out nothing
+type Alerts struct {
+	lock        sync.Mutex
+	subscribers []*alertSubscriber
+}
+
+type alertSubscriber struct {
+	alerts  chan []Alert
+	leading func() bool
+}
+
+func NewAlerts() *Alerts {
+	return &Alerts{}
+}
+
+// hands the alerts to the remediators of clusters this replica leads, returns how many took them, those still busy
+// with many earlier alerts miss them, Alertmanager sends firing alerts again every repeat_interval
+func (a *Alerts) Deliver(alerts []Alert) int {
+	if a == nil {
+		return 0
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	took := 0
+	for _, subscriber := range a.subscribers {
+		if !subscriber.leading() {
+			continue
+		}
+		select {
+		case subscriber.alerts <- alerts:
+			took++
+		default:
+		}
+	}
+	return took
+}
+
+// alerts for a remediator until the returned stop is called, leading is whether it would act on them
+func (a *Alerts) subscribe(leading func() bool) (<-chan []Alert, func()) {
+	subscriber := &alertSubscriber{alerts: make(chan []Alert, 100), leading: leading}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.subscribers = append(a.subscribers, subscriber)
+	return subscriber.alerts, func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		for i := range a.subscribers {
+			if a.subscribers[i] == subscriber {
+				a.subscribers = append(a.subscribers[:i:i], a.subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package remediator
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"time"
+)
+
+// Human in the loop: instead of acting, unhealthy Pods get annotated with the time approval was requested and are
+// only remediated once a human annotates them as approved, approvals of expired requests are discarded
+type Approval struct {
+	RequestAnnotation string // set by us, RFC3339 time of the request
+	ApproveAnnotation string // set to "true" by a human
+	Expiry            time.Duration
+}
+
+// whether the Pod may be remediated, otherwise the annotations to set to (re-)request approval, nil when waiting
+// a nil Approval always grants
+func (a *Approval) evaluate(pod *v1.Pod, now time.Time) (bool, map[string]*string) {
+	if a == nil {
+		return true, nil
+	}
+	annotations := pod.ObjectMeta.Annotations
+	requested, err := time.Parse(time.RFC3339, annotations[a.RequestAnnotation])
+	if err != nil || now.Sub(requested) > a.Expiry {
+		requestedAt := now.UTC().Format(time.RFC3339)
+		return false, map[string]*string{
+			a.RequestAnnotation: &requestedAt,
+			a.ApproveAnnotation: nil, // approvals are for a single request
+		}
+	}
+	return annotations[a.ApproveAnnotation] == "true", nil
+}
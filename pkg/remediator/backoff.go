@@ -0,0 +1,116 @@
+package remediator
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type backoffState struct {
+	attempts    int
+	lastAttempt time.Time
+	lastSeen    time.Time // last time the owner had an unhealthy Pod
+}
+
+// Waits exponentially longer between remediations of the same owner (1m, 5m, 25m ...), so permanently broken
+// workloads do not get deleted on every run, starting over once the owner had no unhealthy Pods for a while
+type Backoff struct {
+	initial    time.Duration
+	factor     float64
+	max        time.Duration
+	resetAfter time.Duration
+
+	lock   sync.Mutex
+	owners map[string]*backoffState
+}
+
+func NewBackoff(initial time.Duration, factor float64, max time.Duration, resetAfter time.Duration) *Backoff {
+	return &Backoff{
+		initial:    initial,
+		factor:     factor,
+		max:        max,
+		resetAfter: resetAfter,
+		owners:     map[string]*backoffState{},
+	}
+}
+
+// how long to wait before remediating the owner again, 0 when it can be remediated now
+// also records that the owner has unhealthy Pods, a nil Backoff never waits
+func (b *Backoff) Wait(owner string) time.Duration {
+	if b == nil || owner == "" {
+		return 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	state, ok := b.owners[owner]
+	if !ok {
+		return 0
+	}
+	if now.Sub(state.lastSeen) > b.resetAfter { // was healthy in between
+		delete(b.owners, owner)
+		return 0
+	}
+	state.lastSeen = now
+
+	wait := state.lastAttempt.Add(b.delay(state.attempts)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// record a remediation of the owner
+func (b *Backoff) Attempt(owner string) {
+	if b == nil || owner == "" {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	for key, state := range b.owners {
+		if now.Sub(state.lastSeen) > b.resetAfter {
+			delete(b.owners, key)
+		}
+	}
+
+	state, ok := b.owners[owner]
+	if !ok {
+		state = &backoffState{}
+		b.owners[owner] = state
+	}
+	state.attempts++
+	state.lastAttempt = now
+	state.lastSeen = now
+}
+
+type BackoffState struct {
+	Attempts    int       `json:"attempts"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// owners that are backing off, for /debug/state
+func (b *Backoff) State() map[string]BackoffState {
+	state := map[string]BackoffState{}
+	if b == nil {
+		return state
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for owner, s := range b.owners {
+		state[owner] = BackoffState{Attempts: s.attempts, LastAttempt: s.lastAttempt, LastSeen: s.lastSeen}
+	}
+	return state
+}
+
+// delay after the given number of attempts
+func (b *Backoff) delay(attempts int) time.Duration {
+	delay := float64(b.initial) * math.Pow(b.factor, float64(attempts-1))
+	if delay > float64(b.max) {
+		return b.max
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,45 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestBackoffNeverWaitsWhenNil(t *testing.T) {
+	var backoff *remediator.Backoff
+	backoff.Attempt("default/ReplicaSet/foo")
+	assert.Equal(t, backoff.Wait("default/ReplicaSet/foo"), time.Duration(0))
+}
+
+func TestBackoffWaitsExponentiallyLonger(t *testing.T) {
+	backoff := remediator.NewBackoff(10*time.Millisecond, 5, time.Hour, time.Hour)
+	assert.Equal(t, backoff.Wait("default/ReplicaSet/foo"), time.Duration(0))
+
+	backoff.Attempt("default/ReplicaSet/foo")
+	wait := backoff.Wait("default/ReplicaSet/foo")
+	assert.Assert(t, wait > 0 && wait <= 10*time.Millisecond)
+	assert.Equal(t, backoff.Wait("default/ReplicaSet/bar"), time.Duration(0))
+
+	time.Sleep(wait)
+	assert.Equal(t, backoff.Wait("default/ReplicaSet/foo"), time.Duration(0))
+	backoff.Attempt("default/ReplicaSet/foo")
+	wait = backoff.Wait("default/ReplicaSet/foo")
+	assert.Assert(t, wait > 10*time.Millisecond && wait <= 50*time.Millisecond)
+}
+
+func TestBackoffIsCappedAtMax(t *testing.T) {
+	backoff := remediator.NewBackoff(time.Minute, 5, 2*time.Minute, time.Hour)
+	backoff.Attempt("default/ReplicaSet/foo")
+	backoff.Attempt("default/ReplicaSet/foo")
+	assert.Assert(t, backoff.Wait("default/ReplicaSet/foo") <= 2*time.Minute)
+}
+
+func TestBackoffStartsOverOnceHealthy(t *testing.T) {
+	backoff := remediator.NewBackoff(time.Hour, 5, time.Hour, 10*time.Millisecond)
+	backoff.Attempt("default/ReplicaSet/foo")
+	assert.Assert(t, backoff.Wait("default/ReplicaSet/foo") > 0)
+	time.Sleep(20 * time.Millisecond) // no unhealthy Pods seen
+	assert.Equal(t, backoff.Wait("default/ReplicaSet/foo"), time.Duration(0))
+}
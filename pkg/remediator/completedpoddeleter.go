@@ -2,7 +2,9 @@ package remediator
 
 import (
 	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sync"
 	"time"
@@ -10,6 +12,23 @@ import (
 
 type CompletedPodDeleter struct {
 	Base
+	filters FilterChain
+}
+
+func init() {
+	Register("CompletedPodDeleter", func(Configs) Remediator { return &CompletedPodDeleter{} })
+}
+
+func (p *CompletedPodDeleter) Name() string {
+	return "CompletedPodDeleter"
+}
+
+func (p *CompletedPodDeleter) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	p.filters = FilterChain{
+		{Name: "completed", Matches: func(pod *v1.Pod) bool { return pod.Status.Phase == v1.PodSucceeded }},
+		AgeFilter(24 * time.Hour), // could delete pods that ran a long time early, but good enough for now
+	}
+	return p.Base.Setup(logger, client, policy)
 }
 
 func (p *CompletedPodDeleter) Run(ctx context.Context, wg *sync.WaitGroup) {
@@ -18,22 +37,17 @@ func (p *CompletedPodDeleter) Run(ctx context.Context, wg *sync.WaitGroup) {
 	p.reconcileEvery(ctx, p.deleteCompletedPods, 1*time.Hour)
 }
 
-func (p *CompletedPodDeleter) deleteCompletedPods() {
+func (p *CompletedPodDeleter) deleteCompletedPods(ctx context.Context) {
 	p.logger.Info("Running")
 
-	// get completed pods
-	pods, err := p.client.GetPods("", metav1.ListOptions{FieldSelector: "status.phase=Succeeded"})
-	if err != nil {
-		p.logger.Error("Error getting pod list: ", zap.Error(err))
-		return
-	}
+	// get completed pods that are too old
+	pods := p.listPods(ctx, p.policy.Namespaces.ListNamespaces(), p.policy.listOptions(metav1.ListOptions{FieldSelector: "status.phase=Succeeded"}), p.isOldCompleted)
 
-	// delete those that are too old (could delete pods that ran a long time early, but good enough for now)
-	cutoff := time.Now().Add(-24 * time.Hour)
-	for _, pod := range pods.Items {
-		if pod.ObjectMeta.CreationTimestamp.Time.After(cutoff) {
-			continue
-		}
-		p.deletePod(pod)
+	for _, pod := range pods {
+		p.remediatePod(ctx, pod, "Completed", ActionDelete, p.isOldCompleted)
 	}
 }
+
+func (p *CompletedPodDeleter) isOldCompleted(pod *v1.Pod) bool {
+	return p.passes(p.filters, pod)
+}
@@ -3,6 +3,8 @@ package remediator_test
 import (
 	"context"
 	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
 	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
 	"github.com/aksgithub/kube_remediator/pkg/remediator"
 	"github.com/golang/mock/gomock"
@@ -10,6 +12,7 @@ import (
 	"go.uber.org/zap"
 	"gotest.tools/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sync"
 	"testing"
@@ -22,6 +25,7 @@ type TestCompletedPodDeleterSuite struct {
 	mockController *gomock.Controller
 	mockClient     *mock_k8s.MockClientInterface
 	pods           []corev1.Pod
+	policy         remediator.Policy
 	t              *testing.T
 }
 
@@ -33,6 +37,7 @@ func (suite *TestCompletedPodDeleterSuite) SetupTest() {
 	suite.logger, _ = zap.NewDevelopment()
 	suite.mockController = gomock.NewController(suite.t)
 	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
 	suite.pods = []corev1.Pod{{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              "foo",
@@ -51,7 +56,7 @@ func (suite *TestCompletedPodDeleterSuite) TeardownTest() {
 
 func (suite *TestCompletedPodDeleterSuite) run() {
 	completedPodDeleter := remediator.CompletedPodDeleter{}
-	err := completedPodDeleter.Setup(suite.logger, suite.mockClient)
+	err := completedPodDeleter.Setup(suite.logger, suite.mockClient, &suite.policy)
 	assert.Equal(suite.t, err, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -64,24 +69,107 @@ func (suite *TestCompletedPodDeleterSuite) run() {
 }
 
 func (suite *TestCompletedPodDeleterSuite) TestDeleteCompletedPods() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
 	suite.run()
 }
 
+func (suite *TestCompletedPodDeleterSuite) TestDeletesWithDeleteOptions() {
+	gracePeriod := int64(0)
+	suite.policy.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], &metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+		Preconditions:      &metav1.Preconditions{UID: &suite.pods[0].ObjectMeta.UID},
+	}).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCompletedPodDeleterSuite) TestDeletesWithResourceVersionPrecondition() {
+	suite.policy.PreconditionResourceVersion = true
+	suite.pods[0].ObjectMeta.ResourceVersion = "42"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &suite.pods[0].ObjectMeta.UID,
+			ResourceVersion: &suite.pods[0].ObjectMeta.ResourceVersion,
+		},
+	}).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCompletedPodDeleterSuite) TestDoesNotCrashWhenPodWasReplaced() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(
+		apierrors.NewConflict(corev1.Resource("pods"), suite.pods[0].ObjectMeta.Name, errors.New("uid mismatch")),
+	)
+	suite.run()
+}
+
+func (suite *TestCompletedPodDeleterSuite) TestCountsPodThatIsAlreadyGoneAsDeleted() {
+	suite.policy.History = audit.NewHistory(10)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(
+		apierrors.NewNotFound(corev1.Resource("pods"), suite.pods[0].ObjectMeta.Name),
+	)
+	suite.run()
+	assert.Equal(suite.t, suite.policy.History.Recent("", 0)[0].Outcome, "success")
+}
+
+func (suite *TestCompletedPodDeleterSuite) TestCountsForbiddenDeleteAsError() {
+	suite.policy.History = audit.NewHistory(10)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(
+		apierrors.NewForbidden(corev1.Resource("pods"), suite.pods[0].ObjectMeta.Name, errors.New("RBAC")),
+	)
+	suite.run()
+	assert.Equal(suite.t, suite.policy.History.Recent("", 0)[0].Outcome, "error")
+}
+
 func (suite *TestCompletedPodDeleterSuite) TestKeepsNewPods() {
 	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-23 * time.Hour))
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestCompletedPodDeleterSuite) TestDoesNotCrashWhenListFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(nil, errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, errors.New("Foo"))
 	suite.run()
 }
 
 func (suite *TestCompletedPodDeleterSuite) TestDoesNotCrashWhenDeleteFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("Foo"))
 	suite.run()
 }
+
+func TestCompletedPodDeleterWithFakeClient(t *testing.T) {
+	client := fake.NewClient(
+		fake.CompletedPod("default", "job-old", 25*time.Hour),
+		fake.CompletedPod("default", "job-new", time.Hour),
+		fake.Pod("default", "api"),
+	)
+	completedPodDeleter := remediator.CompletedPodDeleter{}
+	assert.NilError(t, completedPodDeleter.Setup(zap.NewNop(), client, &remediator.Policy{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	completedPodDeleter.Run(ctx, &wg)
+
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "job-old"}})
+}
+
+func TestCompletedPodDeleterIgnoresMinReadyReplicas(t *testing.T) {
+	job := fake.ReplicaSet("default", "job", 1)
+	client := fake.NewClient(fake.OwnedBy(fake.CompletedPod("default", "job-old", 25*time.Hour), job))
+	completedPodDeleter := remediator.CompletedPodDeleter{}
+	assert.NilError(t, completedPodDeleter.Setup(zap.NewNop(), client, &remediator.Policy{MinReadyReplicas: 2}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	completedPodDeleter.Run(ctx, &wg)
+
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "job-old"}})
+}
@@ -0,0 +1,128 @@
+package remediator
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Changes made at runtime through the control API, shared by all remediators and kept across config reloads, lost
+// when the process restarts. A nil Controls changes nothing.
+type Controls struct {
+	lock      sync.Mutex
+	pausedAll bool
+	paused    map[string]bool            // by lowercase remediator
+	dryRun    *bool                      // overrides Policy.DryRun, nil keeps it
+	scans     map[string][]chan struct{} // of the running scan loops, by remediator
+}
+
+func NewControls() *Controls {
+	return &Controls{paused: map[string]bool{}, scans: map[string][]chan struct{}{}}
+}
+
+// "" pauses all remediators
+func (c *Controls) Pause(remediator string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if remediator == "" {
+		c.pausedAll = true
+		return
+	}
+	c.paused[strings.ToLower(remediator)] = true
+}
+
+// "" resumes all remediators, also those paused on their own
+func (c *Controls) Resume(remediator string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if remediator == "" {
+		c.pausedAll = false
+		c.paused = map[string]bool{}
+		return
+	}
+	delete(c.paused, strings.ToLower(remediator))
+}
+
+func (c *Controls) Paused(remediator string) bool {
+	if c == nil {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.pausedAll || c.paused[strings.ToLower(remediator)]
+}
+
+// whether all are paused and the remediators paused on their own, sorted
+func (c *Controls) PausedRemediators() (bool, []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var paused []string
+	for name := range c.paused {
+		paused = append(paused, name)
+	}
+	sort.Strings(paused)
+	return c.pausedAll, paused
+}
+
+// nil goes back to the config
+func (c *Controls) SetDryRun(dryRun *bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.dryRun = dryRun
+}
+
+// nil when the config decides
+func (c *Controls) DryRun() *bool {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.dryRun
+}
+
+// starts a scan of the remediator, "" of all, returns the remediators that will scan, sorted, those busy scanning
+// scan again right after
+func (c *Controls) Scan(remediator string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var started []string
+	for name, loops := range c.scans {
+		if remediator != "" && !strings.EqualFold(name, remediator) {
+			continue
+		}
+		for _, loop := range loops {
+			select {
+			case loop <- struct{}{}:
+			default: // one is already waiting
+			}
+		}
+		if len(loops) > 0 {
+			started = append(started, name)
+		}
+	}
+	sort.Strings(started)
+	return started
+}
+
+// scans requested for the remediator until the returned stop is called, nil and a no-op without Controls
+func (c *Controls) scanRequests(remediator string) (<-chan struct{}, func()) {
+	if c == nil {
+		return nil, func() {}
+	}
+	loop := make(chan struct{}, 1)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.scans[remediator] = append(c.scans[remediator], loop)
+	return loop, func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		loops := c.scans[remediator]
+		for i := range loops {
+			if loops[i] == loop {
+				c.scans[remediator] = append(loops[:i:i], loops[i+1:]...)
+				break
+			}
+		}
+	}
+}
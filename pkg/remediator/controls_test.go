@@ -0,0 +1,99 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// a Pod of a ReplicaSet that ran out of memory, remediated once by FailedPodRescheduler with the controls
+func remediateWithControls(t *testing.T, controls *remediator.Controls, policy remediator.Policy) (*fake.Client, *audit.History) {
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	client := fake.NewClient(fake.OwnedBy(fake.FailedPod("default", "api-5d8f-x2x", "OutOfmemory"), replicaSet))
+	assert.NilError(t, client.AddOwner(replicaSet))
+	history := audit.NewHistory(10)
+	policy.History, policy.Controls = history, controls
+	rescheduler := remediator.FailedPodRescheduler{}
+	assert.NilError(t, rescheduler.Setup(zap.NewNop(), client, &policy))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	rescheduler.Run(ctx, &wg)
+	return client, history
+}
+
+func TestPausesRemediators(t *testing.T) {
+	controls := remediator.NewControls()
+	controls.Pause("failedpodrescheduler")
+	assert.Equal(t, controls.Paused("FailedPodRescheduler"), true)
+	assert.Equal(t, controls.Paused("OldPodDeleter"), false)
+	client, history := remediateWithControls(t, controls, remediator.Policy{Remediator: "FailedPodRescheduler"})
+	assert.Equal(t, len(client.Actions()), 0)
+	assert.Equal(t, history.Recent("", 0)[0].Detail, "paused")
+
+	controls.Resume("FailedPodRescheduler")
+	controls.Pause("")
+	all, paused := controls.PausedRemediators()
+	assert.Equal(t, all, true)
+	assert.Equal(t, len(paused), 0)
+	assert.Equal(t, controls.Paused("OldPodDeleter"), true)
+
+	controls.Resume("")
+	client, _ = remediateWithControls(t, controls, remediator.Policy{Remediator: "FailedPodRescheduler"})
+	assert.Equal(t, len(client.Actions()), 1)
+}
+
+func TestOverridesDryRun(t *testing.T) {
+	controls := remediator.NewControls()
+	on, off := true, false
+	controls.SetDryRun(&on)
+	client, history := remediateWithControls(t, controls, remediator.Policy{})
+	assert.Equal(t, len(client.Actions()), 0)
+	assert.Equal(t, history.Recent("", 0)[0].DryRun, true)
+
+	controls.SetDryRun(&off)
+	client, _ = remediateWithControls(t, controls, remediator.Policy{DryRun: true})
+	assert.Equal(t, len(client.Actions()), 1)
+
+	controls.SetDryRun(nil)
+	client, _ = remediateWithControls(t, controls, remediator.Policy{DryRun: true})
+	assert.Equal(t, len(client.Actions()), 0)
+}
+
+func TestScansWhenRequested(t *testing.T) {
+	replicaSet := fake.ReplicaSet("default", "api", 1)
+	client := fake.NewClient()
+	assert.NilError(t, client.AddOwner(replicaSet))
+	controls := remediator.NewControls()
+	deleter := remediator.OldPodDeleter{}
+	assert.NilError(t, deleter.Setup(zap.NewNop(), client, &remediator.Policy{Remediator: "OldPodDeleter", Controls: controls}))
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go deleter.Run(ctx, &wg)
+	defer wg.Wait()
+	defer cancel()
+
+	// the first scan found nothing, the next one is an hour away
+	old := fake.OwnedBy(fake.Pod("default", "api"), replicaSet)
+	old.ObjectMeta.CreationTimestamp.Time = time.Now().Add(-25 * time.Hour)
+	old.ObjectMeta.Labels = map[string]string{"kube-remediator/OldPodDeleter": "true"}
+	_, err := client.ClientSet().CoreV1().Pods("default").Create(old)
+	assert.NilError(t, err)
+	for len(controls.Scan("oldpoddeleter")) == 0 { // until the scan loop runs
+		time.Sleep(time.Millisecond)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(client.Actions()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "evict", Kind: "Pod", Namespace: "default", Name: "api"}})
+	assert.Equal(t, len(controls.Scan("FailedPodRescheduler")), 0)
+}
@@ -0,0 +1,96 @@
+package remediator
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sync"
+	"time"
+)
+
+// Remembers when the owners of Pods were last remediated, so a Deployment with many crashing replicas
+// does not get all of them deleted at once
+type Cooldown struct {
+	duration time.Duration
+
+	lock sync.Mutex
+	last map[string]time.Time // owner key -> last remediation
+}
+
+func NewCooldown(duration time.Duration) *Cooldown {
+	return &Cooldown{duration: duration, last: map[string]time.Time{}}
+}
+
+// owner was remediated recently, a nil Cooldown is never active
+func (c *Cooldown) Active(owner string) bool {
+	if c == nil || owner == "" {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return time.Since(c.last[owner]) < c.duration
+}
+
+// start the cooldown for the owner unless it is already active
+func (c *Cooldown) TryStart(owner string) bool {
+	if c == nil || owner == "" {
+		return true
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	for key, last := range c.last {
+		if now.Sub(last) >= c.duration {
+			delete(c.last, key)
+		}
+	}
+	if _, active := c.last[owner]; active {
+		return false
+	}
+	c.last[owner] = now
+	return true
+}
+
+// end the cooldown TryStart started for the owner, when it was not remediated after all
+func (c *Cooldown) Cancel(owner string) {
+	if c == nil || owner == "" {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.last, owner)
+}
+
+// owners still in cooldown and when they were remediated, for /debug/state
+func (c *Cooldown) State() map[string]time.Time {
+	state := map[string]time.Time{}
+	if c == nil {
+		return state
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for owner, last := range c.last {
+		if time.Since(last) < c.duration {
+			state[owner] = last
+		}
+	}
+	return state
+}
+
+// controller that will recreate the Pod, nil when there is none
+func ownerReference(pod *v1.Pod) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil && len(pod.ObjectMeta.OwnerReferences) > 0 {
+		owner = &pod.ObjectMeta.OwnerReferences[0]
+	}
+	return owner
+}
+
+// namespace/kind/name of the controller that will recreate the Pod, "" when there is none
+func ownerKey(pod *v1.Pod) string {
+	owner := ownerReference(pod)
+	if owner == nil {
+		return ""
+	}
+	return pod.ObjectMeta.Namespace + "/" + owner.Kind + "/" + owner.Name
+}
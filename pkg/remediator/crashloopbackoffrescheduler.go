@@ -2,132 +2,252 @@ package remediator
 
 import (
 	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/events"
 	"github.com/aksgithub/kube_remediator/pkg/k8s"
-	"github.com/aksgithub/kube_remediator/pkg/metrics"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
+	"k8s.io/apimachinery/pkg/labels"
 	"sync"
+	"time"
 )
 
-// TODO: this cannot be global since we have multiple remediator in this package ... folder can be set though but should
-// still not be here
-var CONFIG_FILE = "config/crash_loop_back_off_rescheduler.json"
+type CrashLoopBackOffConfig struct {
+	Annotation        string                `mapstructure:"annotation"` // "false" opts the Pod out
+	FailureThreshold  int32                 `mapstructure:"failureThreshold"`
+	IncludeNamespaces []string              `mapstructure:"includeNamespaces"`
+	ExcludeNamespaces []string              `mapstructure:"excludeNamespaces"`
+	LabelSelector     string                `mapstructure:"labelSelector"`
+	NodeCorrelation   NodeCorrelationConfig `mapstructure:"nodeCorrelation"`
+}
+
+// many unrelated owners crashing on the same Node points to the Node, not the apps
+type NodeCorrelationConfig struct {
+	MinOwners int  `mapstructure:"minOwners"` // 0 disables the guard
+	Cordon    bool `mapstructure:"cordon"`
+}
+
+func DefaultCrashLoopBackOffConfig() CrashLoopBackOffConfig {
+	return CrashLoopBackOffConfig{
+		Annotation:        "kube-remediator/CrashLoopBackOffRemediator",
+		FailureThreshold:  5,
+		IncludeNamespaces: []string{},
+		ExcludeNamespaces: []string{},
+	}
+}
+
+func (c CrashLoopBackOffConfig) Validate() error {
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("failureThreshold must be at least 1, got %d", c.FailureThreshold)
+	}
+	if c.NodeCorrelation.MinOwners != 0 && c.NodeCorrelation.MinOwners < 2 {
+		return fmt.Errorf("nodeCorrelation.minOwners must be 0 or at least 2, got %d", c.NodeCorrelation.MinOwners)
+	}
+	if _, err := NewNamespaceFilter(c.IncludeNamespaces, c.ExcludeNamespaces); err != nil {
+		return err
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("labelSelector: %v", err)
+	}
+	return nil
+}
 
 type PodFilter struct {
 	annotation       string
 	failureThreshold int32
-	namespace        string
+	namespaces       *NamespaceFilter
+	labelSelector    labels.Selector
 }
 
 type CrashLoopBackOffRescheduler struct {
 	Base
-	filter          PodFilter
-	informerFactory informers.SharedInformerFactory
-	metrics         *metrics.CrashLoopBackOff_Metrics
+	Config     CrashLoopBackOffConfig
+	filter     PodFilter
+	filters    FilterChain
+	namespaces []string
+	stream     *events.Stream
+}
+
+func init() {
+	Register("CrashLoopBackOffRescheduler", func(configs Configs) Remediator {
+		return &CrashLoopBackOffRescheduler{Config: configs.CrashLoopBackOffRescheduler}
+	})
 }
 
-func (p *CrashLoopBackOffRescheduler) Setup(logger *zap.Logger, client k8s.ClientInterface) error {
-	logger.Info("Reading config", zap.String("file", CONFIG_FILE))
-	viper.SetConfigFile(CONFIG_FILE)
-	viper.SetConfigType("json")
-	viper.SetDefault("annotation", "kube-remediator/CrashLoopBackOffRemediator")
-	viper.SetDefault("failureThreshold", 5)
-	viper.SetDefault("namespace", "")
+func (p *CrashLoopBackOffRescheduler) Name() string {
+	return "CrashLoopBackOffRescheduler"
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return err // untested section
+func (p *CrashLoopBackOffRescheduler) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	if err := p.Config.Validate(); err != nil {
+		return err
+	}
+	logger.Sugar().Infof("Config %+v", p.Config) // TODO: prefer using zap.Map or something like that
+	namespaces, err := NewNamespaceFilter(p.Config.IncludeNamespaces, p.Config.ExcludeNamespaces)
+	if err != nil {
+		return err
+	}
+	labelSelector, err := labels.Parse(p.Config.LabelSelector)
+	if err != nil {
+		return err
 	}
-
-	logger.Sugar().Infof("Config %v", viper.AllSettings()) // TODO: prefer using zap.Map or something like that
 	filter := PodFilter{
-		annotation:       viper.GetString("annotation"),
-		failureThreshold: viper.GetInt32("failureThreshold"),
-		namespace:        viper.GetString("namespace"),
+		annotation:       p.Config.Annotation,
+		failureThreshold: p.Config.FailureThreshold,
+		namespaces:       namespaces,
+		labelSelector:    labelSelector,
 	}
 
-	metrics := metrics.NewCrashLoopBackOffMetrics(logger)
-	metrics.Register()
-
-	informerFactory, err := client.NewSharedInformerFactory(filter.namespace)
-	if err != nil {
-		return err // untested section
+	// only watch some namespaces when possible
+	listNamespaces := filter.namespaces.ListNamespaces()
+	if listNamespaces[0] == "" {
+		listNamespaces = policy.Namespaces.ListNamespaces()
 	}
-	p.informerFactory = informerFactory
 	p.filter = filter
-	p.metrics = metrics
-	p.logger = logger
-	p.client = client
-	return nil
+	p.namespaces = listNamespaces
+	p.filters = FilterChain{
+		{Name: "namespace", Matches: func(pod *v1.Pod) bool { return filter.namespaces.Matches(pod.ObjectMeta.Namespace) }},
+		{Name: "labels", Matches: func(pod *v1.Pod) bool { return filter.labelSelector.Matches(labels.Set(pod.ObjectMeta.Labels)) }},
+		AnnotationFilter(filter.annotation), // opted out
+		// not 100% reliable because Pod could toggle between Terminated with Error and Waiting with CrashLoopBackOff
+		ThresholdFilter("CrashLoopBackOff", func(namespace string) int32 {
+			return p.policy.failureThreshold(namespace, filter.failureThreshold)
+		}),
+		OwnerFilter(), // assuming Pod has owner reference of kind Controller
+	}
+	p.checksReadyReplicas = true // the Pod may be the last one of its owner still serving some requests
+	return p.Base.Setup(logger, client, policy)
+}
+
+// react to BackOff events instead of watching all pods
+func (p *CrashLoopBackOffRescheduler) UseEventStream(stream *events.Stream) {
+	p.stream = stream
 }
 
 func (p *CrashLoopBackOffRescheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	p.logStartAndStop(func() {
-		// Check for any CrashLoopBackOff Pods first
-		p.reschedulePods()
-
-		informer := p.informerFactory.Core().V1().Pods().Informer()
+	if p.policy.Once { // the scan finds what watching would
+		p.reconcileEvery(ctx, p.reschedulePods, 5*time.Minute)
+		return
+	}
 
-		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-			UpdateFunc: p.rescheduleIfNecessary,
+	if p.stream != nil {
+		p.logStartAndStop(func() {
+			// Check for any CrashLoopBackOff Pods first
+			if !p.policy.Reconcile.WaitForStart(ctx) {
+				return
+			}
+			p.scan(ctx, p.reschedulePods)
+			// the workers of the stream get the Pods from the api-server and handle them
+			stop, ok := p.subscribeEvents(p.stream, p.namespaces, []string{events.ReasonBackOff}, p.reschedule)
+			if !ok {
+				return
+			}
+			defer stop() // the stream outlives us when reloading config
+			<-ctx.Done()
 		})
-		informer.Run(ctx.Done())
+		return
+	}
 
-		<-ctx.Done()
-		p.metrics.UnRegister()
+	pods := p.podCache(ctx)
+	queue := p.startPodQueue(pods, p.reschedule)
+	defer queue.Stop() // after the handlers below no longer add to it
+	stop, ok := p.watchPods(ctx, pods, p.namespaces, func(pod *v1.Pod) {
+		if p.shouldReschedule(pod) {
+			queue.Add(pod)
+		}
 	})
+	if !ok {
+		return
+	}
+	defer stop() // the Pod cache outlives us when reloading config
+
+	// scans of the cache catch Pods whose update we missed or that were skipped before
+	p.reconcileEvery(ctx, p.reschedulePods, 5*time.Minute)
 }
 
-func (p *CrashLoopBackOffRescheduler) reschedulePods() {
+func (p *CrashLoopBackOffRescheduler) reschedulePods(ctx context.Context) {
 	p.logger.Info("Running")
-	for _, pod := range *p.getCrashLoopBackOffPods() {
-		p.rescheduleIfNecessary(nil, &pod)
+	pods := *p.getCrashLoopBackOffPods(ctx)
+	correlated := p.correlatedNodes(pods)
+	for node := range correlated {
+		p.cordonCorrelatedNode(ctx, node)
+	}
+	for _, pod := range pods {
+		if !p.onCorrelatedNode(&pod, correlated) {
+			p.remediatePod(ctx, pod, "CrashLoopBackOff", ActionEvict, p.shouldReschedule)
+		}
 	}
 }
 
-func (p *CrashLoopBackOffRescheduler) rescheduleIfNecessary(oldObj, newObj interface{}) {
-	pod := newObj.(*v1.Pod)
-	if p.shouldReschedule(pod) {
-		p.deletePod(*pod)
+func (p *CrashLoopBackOffRescheduler) reschedule(ctx context.Context, pod *v1.Pod) {
+	if !p.shouldReschedule(pod) {
+		return
 	}
+	if p.Config.NodeCorrelation.MinOwners > 0 && pod.Spec.NodeName != "" {
+		unhealthyPods := p.cachedPods(ctx, p.namespaces, metav1.ListOptions{FieldSelector: "spec.nodeName=" + pod.Spec.NodeName}, p.shouldReschedule)
+		if p.onCorrelatedNode(pod, p.correlatedNodes(unhealthyPods)) {
+			p.cordonCorrelatedNode(ctx, pod.Spec.NodeName)
+			return
+		}
+	}
+	p.remediatePod(ctx, *pod, "CrashLoopBackOff", ActionEvict, p.shouldReschedule)
 }
 
-func (p *CrashLoopBackOffRescheduler) getCrashLoopBackOffPods() *[]v1.Pod {
-	pods, err := p.client.GetPods(p.filter.namespace, metav1.ListOptions{})
-	if err != nil {
-		p.logger.Error("Error getting pod list: ", zap.Error(err))
-		return &[]v1.Pod{}
+// Nodes where at least minOwners different owners have crashing Pods
+func (p *CrashLoopBackOffRescheduler) correlatedNodes(pods []v1.Pod) map[string]int {
+	correlated := map[string]int{}
+	if p.Config.NodeCorrelation.MinOwners <= 0 {
+		return correlated
 	}
-	var unhealthyPods []v1.Pod
-	for _, pod := range pods.Items {
-		if p.shouldReschedule(&pod) {
-			unhealthyPods = append(unhealthyPods, pod)
+	owners := map[string]map[string]bool{} // node -> owner keys
+	for i := range pods {
+		node := pods[i].Spec.NodeName
+		if node == "" {
+			continue
+		}
+		if owners[node] == nil {
+			owners[node] = map[string]bool{}
 		}
+		owners[node][ownerKey(&pods[i])] = true
 	}
+	for node, nodeOwners := range owners {
+		if len(nodeOwners) >= p.Config.NodeCorrelation.MinOwners {
+			correlated[node] = len(nodeOwners)
+		}
+	}
+	return correlated
+}
+
+// treat the crash as a Node problem and leave the Pod alone
+func (p *CrashLoopBackOffRescheduler) onCorrelatedNode(pod *v1.Pod, correlated map[string]int) bool {
+	owners, ok := correlated[pod.Spec.NodeName]
+	if !ok {
+		return false
+	}
+	p.logger.Warn("Skipping, many owners crashing on the same Node, likely a Node problem",
+		append(podInfo(pod), zap.String("node", pod.Spec.NodeName), zap.Int("owners", owners))...)
+	p.policy.Skipped.UpdateSkippedCount("node-correlation")
+	return true
+}
+
+func (p *CrashLoopBackOffRescheduler) cordonCorrelatedNode(ctx context.Context, node string) {
+	if p.Config.NodeCorrelation.Cordon {
+		p.cordonNode(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: node}}, "CrashLoopBackOff")
+	}
+}
+
+func (p *CrashLoopBackOffRescheduler) getCrashLoopBackOffPods(ctx context.Context) *[]v1.Pod {
+	// crashing Pods are Running, or Pending while an init container crashes
+	unhealthyPods := p.cachedPods(ctx, p.namespaces, p.policy.listOptions(metav1.ListOptions{
+		LabelSelector: p.filter.labelSelector.String(),
+		FieldSelector: joinSelectors("status.phase!=Succeeded,status.phase!=Failed", p.filter.namespaces.FieldSelector()),
+	}), p.shouldReschedule)
 	return &unhealthyPods
 }
 
 func (p *CrashLoopBackOffRescheduler) shouldReschedule(pod *v1.Pod) bool {
-	return (p.filter.annotation == "" || pod.ObjectMeta.Annotations[p.filter.annotation] != "false") && // not opted-out
-		len(pod.ObjectMeta.OwnerReferences) > 0 && // Assuming Pod has owner reference of kind Controller
-		p.isPodUnhealthy(pod)
-}
-
-// This is not 100% reliable because Pod could toggle between Terminated with Error and Waiting with CrashLoopBackOff
-func (p *CrashLoopBackOffRescheduler) isPodUnhealthy(pod *v1.Pod) bool {
-	// Check if any of Containers is in CrashLoop
-	statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
-	for _, containerStatus := range statuses {
-		if containerStatus.RestartCount >= p.filter.failureThreshold {
-			if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason == "CrashLoopBackOff" {
-				return true
-			}
-		}
-	}
-	return false
+	return p.passes(p.filters, pod)
 }
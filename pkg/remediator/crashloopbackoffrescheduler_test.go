@@ -1,20 +1,40 @@
 package remediator_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"github.com/aksgithub/kube_remediator/pkg/events"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
 	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
 	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 	"gotest.tools/assert"
+	"io/ioutil"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type TestCrashLoopBackOffReschedulerSuite struct {
@@ -23,6 +43,9 @@ type TestCrashLoopBackOffReschedulerSuite struct {
 	mockController *gomock.Controller
 	mockClient     *mock_k8s.MockClientInterface
 	pods           []corev1.Pod
+	config         remediator.CrashLoopBackOffConfig
+	policy         remediator.Policy
+	slimPodCache   bool
 	t              *testing.T
 }
 
@@ -31,10 +54,11 @@ func TestSuiteCrashLoopBackOffRescheduler(t *testing.T) {
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) SetupTest() {
-	remediator.CONFIG_FILE = "../../config/crash_loop_back_off_rescheduler.json" // TODO: set on the instance or global folder
+	suite.config = remediator.DefaultCrashLoopBackOffConfig()
 	suite.logger, _ = zap.NewDevelopment()
 	suite.mockController = gomock.NewController(suite.t)
 	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
 	suite.pods = []corev1.Pod{{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -79,12 +103,16 @@ func (suite *TestCrashLoopBackOffReschedulerSuite) newInformerFactory() informer
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) run() {
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(suite.newInformerFactory(), nil)
+	suite.runWithoutInformerExpectation()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) runWithoutInformerExpectation() {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // cancel first so we can just run once and exit
 
-	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(suite.newInformerFactory(), nil)
-	crashloop := remediator.CrashLoopBackOffRescheduler{}
-	err := crashloop.Setup(suite.logger, suite.mockClient)
+	crashloop := remediator.CrashLoopBackOffRescheduler{Config: suite.config}
+	err := crashloop.Setup(suite.logger, suite.mockClient, &suite.policy)
 	assert.Equal(suite.t, err, nil)
 
 	var wg sync.WaitGroup
@@ -94,40 +122,40 @@ func (suite *TestCrashLoopBackOffReschedulerSuite) run() {
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesUnhealthyPod() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestLoopsOverAllPods() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: append(suite.pods, suite.pods...)}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil).Times(2)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, suite.pods...)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil).Times(2)
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsUnhealthyPodWithoutOwnerReference() {
 	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{}
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodBelowThreshold() {
 	suite.pods[0].Status.ContainerStatuses[0].RestartCount = 4
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesBasedOnInitContainers() {
 	suite.pods[0].Status.ContainerStatuses[0].RestartCount = 0 // make healthy
 	suite.pods[0].Status.InitContainerStatuses[0].RestartCount = 6
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsWithOtherReason() {
 	suite.pods[0].Status.ContainerStatuses[0].State.Waiting.Reason = "X"
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
@@ -135,7 +163,7 @@ func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsOptedOutUnHealthyPod
 	suite.pods[0].ObjectMeta.Annotations = map[string]string{
 		"kube-remediator/CrashLoopBackOffRemediator": "false",
 	}
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil).Times(1)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil).Times(1)
 
 	suite.run()
 }
@@ -144,19 +172,906 @@ func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesUnHealthyPodsW
 	suite.pods[0].ObjectMeta.Annotations = map[string]string{
 		"kube-remediator/CrashLoopBackOffRemediator": "true",
 	}
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil).Times(1)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil).Times(1)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil).Times(1)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil).Times(1)
 
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestDoesNotCrashWhenDeleteFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("Foo"))
 	suite.run()
 }
 
 func (suite *TestCrashLoopBackOffReschedulerSuite) TestDoesNotCrashWhenListFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(nil, errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, errors.New("Foo"))
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsInExcludedNamespaces() {
+	namespaces, err := remediator.NewNamespaceFilter([]string{}, []string{"def*"})
+	assert.Equal(suite.t, err, nil)
+	suite.policy.Namespaces = namespaces
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestOnlyWatchesSingleIncludedNamespace() {
+	namespaces, err := remediator.NewNamespaceFilter([]string{"default"}, []string{})
+	assert.Equal(suite.t, err, nil)
+	suite.policy.Namespaces = namespaces
+	suite.mockClient.EXPECT().NewSharedInformerFactory("default").Return(suite.newInformerFactory(), nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "default").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.runWithoutInformerExpectation()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestWatchesEachIncludedNamespace() {
+	suite.config.IncludeNamespaces = []string{"forbidden", "default"}
+	suite.mockClient.EXPECT().NewSharedInformerFactory("forbidden").Return(suite.newInformerFactory(), nil)
+	suite.mockClient.EXPECT().NewSharedInformerFactory("default").Return(suite.newInformerFactory(), nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "forbidden").Return(nil, errors.New("pods is forbidden"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "default").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.runWithoutInformerExpectation()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsNotMatchingLabelSelector() {
+	selector, err := labels.Parse("team=payments")
+	assert.Equal(suite.t, err, nil)
+	suite.policy.LabelSelector = selector
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsMatchingLabelSelector() {
+	selector, err := labels.Parse("team=payments")
+	assert.Equal(suite.t, err, nil)
+	suite.policy.LabelSelector = selector
+	suite.pods[0].ObjectMeta.Labels = map[string]string{"team": "payments"}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsWithOptOutAnnotation() {
+	suite.policy.OptOutAnnotation = "kube-remediator/disable"
+	suite.pods[0].ObjectMeta.Annotations = map[string]string{"kube-remediator/disable": "true"}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsWithoutOptInAnnotationInOptInMode() {
+	suite.policy.OptMode = remediator.OptIn
+	suite.policy.OptInAnnotation = "kube-remediator/enable"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsWithOptInAnnotationInOptInMode() {
+	suite.policy.OptMode = remediator.OptIn
+	suite.policy.OptInAnnotation = "kube-remediator/enable"
+	suite.pods[0].ObjectMeta.Annotations = map[string]string{"kube-remediator/enable": "true"}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsYoungerThanMinPodAge() {
+	suite.policy.MinPodAge = 10 * time.Minute
+	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsOlderThanMinPodAge() {
+	suite.policy.MinPodAge = 10 * time.Minute
+	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-15 * time.Minute))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) readyPod(name string) corev1.Pod {
+	pod := *suite.pods[0].DeepCopy()
+	pod.ObjectMeta.Name = name
+	pod.Status = corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+	return pod
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsOfOwnerWithTooFewReadyPods() {
+	suite.policy.MinReadyReplicas = 2
+	siblings := append(suite.pods, suite.readyPod("ready"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "default").Return(&corev1.PodList{Items: siblings}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsOfOwnerWithEnoughReadyPods() {
+	suite.policy.MinReadyReplicas = 2
+	siblings := append(suite.pods, suite.readyPod("ready"), suite.readyPod("ready2"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "default").Return(&corev1.PodList{Items: siblings}, nil).Times(2)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) useNamespaceOverride(config remediator.NamespaceOverrideConfig) {
+	config.Namespaces = []string{"default"}
+	overrides, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{config})
+	assert.Equal(suite.t, err, nil)
+	suite.policy.NamespaceOverrides = overrides
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestUsesNamespaceFailureThreshold() {
+	suite.useNamespaceOverride(remediator.NamespaceOverrideConfig{FailureThreshold: 10})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestOnlyLogsInDryRunNamespaces() {
+	dryRun := true
+	suite.useNamespaceOverride(remediator.NamespaceOverrideConfig{DryRun: &dryRun})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestRemediatesOnePodPerNamespaceInterval() {
+	suite.useNamespaceOverride(remediator.NamespaceOverrideConfig{Interval: time.Hour})
+	secondPod := *suite.pods[0].DeepCopy()
+	secondPod.ObjectMeta.Name = "other"
+	secondPod.ObjectMeta.OwnerReferences[0].Name = "other"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, secondPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) useNamespaceAnnotations(annotations map[string]string) {
+	clientSet := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: annotations}})
+	namespacesClient := mock_k8s.NewMockClientInterface(suite.mockController)
+	namespacesClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	namespaces, err := k8s.NewNamespaceCache(namespacesClient)
+	assert.Equal(suite.t, err, nil)
+	stop := make(chan struct{})
+	assert.Equal(suite.t, namespaces.Start(stop), true)
+	close(stop) // the cache keeps what it listed
+	suite.policy.NamespaceAnnotations = &remediator.NamespaceAnnotations{Prefix: "kube-remediator/", Namespaces: namespaces}
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsInNamespacesDisabledByAnnotation() {
+	suite.useNamespaceAnnotations(map[string]string{"kube-remediator/disabled": "true"})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestUsesNamespaceAnnotationFailureThreshold() {
+	suite.useNamespaceAnnotations(map[string]string{"kube-remediator/failure-threshold": "10"})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestNamespaceOverridesWinOverAnnotations() {
+	suite.useNamespaceAnnotations(map[string]string{"kube-remediator/failure-threshold": "10"})
+	suite.useNamespaceOverride(remediator.NamespaceOverrideConfig{FailureThreshold: 3})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestOnlyLogsInNamespacesAnnotatedForDryRun() {
+	suite.useNamespaceAnnotations(map[string]string{"kube-remediator/dry-run": "true"})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestIgnoresInvalidNamespaceAnnotations() {
+	suite.useNamespaceAnnotations(map[string]string{"kube-remediator/failure-threshold": "lots"})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsWithExcludedPriorityClass() {
+	suite.policy.ExcludedPriorityClasses = []string{"system-cluster-critical", "system-node-critical"}
+	suite.pods[0].Spec.PriorityClassName = "system-node-critical"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsWithOtherPriorityClass() {
+	suite.policy.ExcludedPriorityClasses = []string{"system-cluster-critical", "system-node-critical"}
+	suite.pods[0].Spec.PriorityClassName = "high-priority"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+// value of a registered metric with the given labels, number of observations for histograms, 0 when there is none
+func gatheredValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NilError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			if metric.GetCounter() != nil {
+				return metric.GetCounter().GetValue()
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			return metric.GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCountsRemediationResults() {
+	suite.policy.Metrics = metrics.NewRemediationMetrics(suite.logger)
+	suite.policy.Metrics.Register()
+	defer suite.policy.Metrics.UnRegister()
+	suite.policy.Remediator = "CrashLoopBackOffRescheduler"
+	otherPod := *suite.pods[0].DeepCopy()
+	otherPod.ObjectMeta.Name = "other"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, otherPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &otherPod, gomock.Any()).Return(errors.New("boom"))
+	suite.run()
+
+	labels := map[string]string{"remediator": "CrashLoopBackOffRescheduler", "namespace": "default", "reason": "CrashLoopBackOff", "action": "evicted", "result": "success"}
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations", labels), float64(1))
+	labels["result"] = "error"
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations", labels), float64(1))
+
+	remediator := map[string]string{"remediator": "CrashLoopBackOffRescheduler"}
+	assert.Equal(suite.t, gatheredValue(suite.t, "scan_duration_seconds", remediator), float64(1))
+	assert.Equal(suite.t, gatheredValue(suite.t, "list_duration_seconds", remediator), float64(1))
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediation_latency_seconds", remediator), float64(2))
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCountsDryRunsAndSkips() {
+	suite.policy.Metrics = metrics.NewRemediationMetrics(suite.logger)
+	suite.policy.Metrics.Register()
+	defer suite.policy.Metrics.UnRegister()
+	suite.policy.Skipped = metrics.NewSkippedMetrics(suite.logger)
+	suite.policy.Skipped.Register()
+	defer suite.policy.Skipped.UnRegister()
+	suite.policy.DryRun = true
+	youngPod := *suite.pods[0].DeepCopy()
+	youngPod.ObjectMeta.Name = "young"
+	youngPod.ObjectMeta.CreationTimestamp = metav1.Now()
+	suite.policy.MinPodAge = time.Hour
+	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, youngPod)}, nil)
+	suite.run()
+
+	labels := map[string]string{"namespace": "default", "reason": "CrashLoopBackOff", "action": "evicted", "result": "dry-run"}
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations", labels), float64(1))
+	labels["result"] = "skipped"
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations", labels), float64(1))
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations_skipped", map[string]string{"reason": "dry-run"}), float64(1))
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations_skipped", map[string]string{"reason": "pod-too-young"}), float64(1))
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReportsRejectedServerSideDryRun() {
+	suite.policy.Skipped = metrics.NewSkippedMetrics(suite.logger)
+	suite.policy.Skipped.Register()
+	defer suite.policy.Skipped.UnRegister()
+	var buffer bytes.Buffer
+	suite.policy.Audit = audit.NewLog(&buffer)
+	suite.policy.DryRun = true
+	suite.policy.ServerSideDryRun = true
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pod *corev1.Pod, options *metav1.DeleteOptions) error {
+			assert.Assert(suite.t, k8s.IsDryRun(ctx))
+			return errors.New(`admission webhook "pods.policy.example.com" denied the request`)
+		})
+	suite.run()
+
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations_skipped", map[string]string{"reason": "dry-run-rejected"}), float64(1))
+	var record audit.Record
+	assert.NilError(suite.t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(suite.t, record.Detail, `dry run rejected: admission webhook "pods.policy.example.com" denied the request`)
+	assert.Equal(suite.t, record.DryRun, true)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCountsPodsWithoutOwner() {
+	suite.policy.Skipped = metrics.NewSkippedMetrics(suite.logger)
+	suite.policy.Skipped.Register()
+	defer suite.policy.Skipped.UnRegister()
+	suite.pods[0].ObjectMeta.OwnerReferences = nil
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations_skipped", map[string]string{"reason": "no-owner"}), float64(1))
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestAuditsDecisions() {
+	var buffer bytes.Buffer
+	suite.policy.Audit = audit.NewLog(&buffer)
+	suite.policy.History = audit.NewHistory(10)
+	suite.policy.Remediator = "CrashLoopBackOffRescheduler"
+	suite.policy.MinPodAge = time.Hour
+	youngPod := *suite.pods[0].DeepCopy()
+	youngPod.ObjectMeta.Name = "young"
+	youngPod.ObjectMeta.CreationTimestamp = metav1.Now()
+	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, youngPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+
+	var records []audit.Record
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n") {
+		var record audit.Record
+		assert.NilError(suite.t, json.Unmarshal([]byte(line), &record))
+		record.Time = time.Time{}
+		records = append(records, record)
+	}
+	assert.DeepEqual(suite.t, records, []audit.Record{
+		{
+			Remediator: "CrashLoopBackOffRescheduler",
+			Object:     audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: suite.pods[0].ObjectMeta.Name},
+			Reason:     "CrashLoopBackOff",
+			Action:     "evicted",
+			Decision:   audit.DecisionRemediate,
+			Outcome:    "success",
+		},
+		{
+			Remediator: "CrashLoopBackOffRescheduler",
+			Object:     audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "young"},
+			Reason:     "CrashLoopBackOff",
+			Action:     "evicted",
+			Decision:   audit.DecisionSkip,
+			Outcome:    "skipped",
+			Detail:     "Pod too young",
+		},
+	})
+	recent := suite.policy.History.Recent("", 0)
+	assert.Equal(suite.t, len(recent), 2)
+	assert.Equal(suite.t, recent[0].Object.Name, "young")
+	assert.DeepEqual(suite.t, suite.policy.History.Stats()["CrashLoopBackOffRescheduler"].Outcomes, map[string]int{"success": 1, "skipped": 1})
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestNotifiesAboutActionsAndErrors() {
+	notifier := make(channelNotifier, 2)
+	suite.policy.Notifiers = notify.Notifiers{notifier}
+	suite.pods[0].Status.ContainerStatuses[0].RestartCount = 12
+	otherPod := *suite.pods[0].DeepCopy()
+	otherPod.ObjectMeta.Name = "other"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, otherPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &otherPod, gomock.Any()).Return(errors.New("boom"))
+	suite.run()
+
+	evicted, failed := <-notifier, <-notifier
+	assert.Equal(suite.t, evicted.Outcome, "success")
+	assert.Equal(suite.t, evicted.Action, "evicted")
+	assert.Equal(suite.t, evicted.Reason, "CrashLoopBackOff")
+	assert.Equal(suite.t, evicted.Restarts, int32(12))
+	assert.Equal(suite.t, failed.Outcome, "error")
+	assert.Equal(suite.t, failed.Detail, "boom")
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestLabelsEverythingWithCluster() {
+	notifier := make(channelNotifier, 1)
+	suite.policy.Notifiers = notify.Notifiers{notifier}
+	suite.policy.History = audit.NewHistory(10)
+	suite.policy.Metrics = metrics.NewRemediationMetrics(suite.logger)
+	suite.policy.Metrics.Register()
+	defer suite.policy.Metrics.UnRegister()
+	suite.policy.Metrics = suite.policy.Metrics.ForCluster("prod")
+	suite.policy.Remediator = "CrashLoopBackOffRescheduler"
+	suite.policy.Cluster = "prod"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+
+	assert.Equal(suite.t, (<-notifier).Cluster, "prod")
+	assert.Equal(suite.t, suite.policy.History.Recent("", 0)[0].Cluster, "prod")
+	labels := map[string]string{"cluster": "prod", "remediator": "CrashLoopBackOffRescheduler", "namespace": "default", "reason": "CrashLoopBackOff", "action": "evicted", "result": "success"}
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations", labels), float64(1))
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestTracesScanAndRemediation() {
+	bodies := make(chan []byte, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NilError(suite.t, err)
+		bodies <- body
+	}))
+	defer collector.Close()
+	tracer := tracing.NewTracer(suite.logger, collector.URL, nil, "kube-remediator")
+	suite.policy.Tracer = tracer
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("boom"))
+	suite.run()
+	assert.NilError(suite.t, tracer.Export())
+
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+					Attributes   []struct {
+						Key string `json:"key"`
+					} `json:"attributes"`
+					Status *struct {
+						Message string `json:"message"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	assert.NilError(suite.t, json.Unmarshal(<-bodies, &request))
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	names := map[string]string{} // span id -> name
+	for _, span := range spans {
+		names[span.SpanID] = span.Name
+	}
+	var tree []string
+	for _, span := range spans {
+		tree = append(tree, names[span.ParentSpanID]+">"+span.Name)
+	}
+	assert.DeepEqual(suite.t, tree, []string{"scan>list Pods", "decide>evict Pod", "decide>notify", "scan>decide", ">scan"})
+	assert.Equal(suite.t, spans[1].Status.Message, "boom")
+	var keys []string
+	for _, attribute := range spans[3].Attributes {
+		keys = append(keys, attribute.Key)
+	}
+	assert.DeepEqual(suite.t, keys, []string{"action", "detail", "namespace", "pod", "reason", "result"})
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsMirrorPods() {
+	suite.policy.Skipped = metrics.NewSkippedMetrics(suite.logger)
+	suite.pods[0].ObjectMeta.Annotations = map[string]string{"kubernetes.io/config.mirror": "abc"}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsStaticPodsOwnedByNodes() {
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "Node", Name: "node"}}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) ownerWithAttempts(attempts string) *unstructured.Unstructured {
+	owner := &unstructured.Unstructured{}
+	owner.SetAnnotations(map[string]string{"kube-remediator/remediations": attempts})
+	return owner
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCountsAttemptsOnOwner() {
+	suite.policy.MaxAttemptsPerOwner = 3
+	suite.policy.AttemptsAnnotation = "kube-remediator/remediations"
+	owner := suite.pods[0].ObjectMeta.OwnerReferences[0]
+	count := "3"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", owner).Return(suite.ownerWithAttempts("2"), nil)
+	suite.mockClient.EXPECT().AnnotateOwner(gomock.Any(), "default", owner, map[string]*string{"kube-remediator/remediations": &count}).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsOfOwnerAtAttemptLimit() {
+	suite.policy.MaxAttemptsPerOwner = 3
+	suite.policy.AttemptsAnnotation = "kube-remediator/remediations"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", gomock.Any()).Return(suite.ownerWithAttempts("3"), nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsWhenOwnerCannotBeRead() {
+	suite.policy.MaxAttemptsPerOwner = 3
+	suite.policy.AttemptsAnnotation = "kube-remediator/remediations"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", gomock.Any()).Return(nil, errors.New("forbidden"))
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestDoesNotCoolDownOwnerWhenAttemptCannotBeCounted() {
+	suite.policy.Cooldown = remediator.NewCooldown(time.Hour)
+	suite.policy.MaxAttemptsPerOwner = 3
+	suite.policy.AttemptsAnnotation = "kube-remediator/remediations"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", gomock.Any()).Return(suite.ownerWithAttempts("1"), nil)
+	suite.mockClient.EXPECT().AnnotateOwner(gomock.Any(), "default", gomock.Any(), gomock.Any()).Return(errors.New("conflict"))
+	suite.run()
+	assert.Equal(suite.t, len(suite.policy.Cooldown.State()), 0)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsRateLimitOfPodsNotRemediated() {
+	suite.policy.RateLimiter = remediator.NewRateLimiter(suite.logger, 1, time.Hour)
+	suite.policy.MaxAttemptsPerOwner = 3
+	suite.policy.AttemptsAnnotation = "kube-remediator/remediations"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", gomock.Any()).Return(nil, errors.New("forbidden"))
+	suite.run()
+	assert.Equal(suite.t, suite.policy.RateLimiter.State().Tokens, 1)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestStampsOwnerAfterActing() {
+	suite.policy.OwnerAnnotationPrefix = "kube-remediator/"
+	suite.policy.Remediator = "CrashLoopBackOffRescheduler"
+	owner := suite.pods[0].ObjectMeta.OwnerReferences[0]
+	previous := &unstructured.Unstructured{}
+	previous.SetAnnotations(map[string]string{"kube-remediator/action-count": "4"})
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().GetOwner(gomock.Any(), "default", owner).Return(previous, nil)
+	suite.mockClient.EXPECT().AnnotateOwner(gomock.Any(), "default", owner, gomock.Any()).DoAndReturn(
+		func(_ context.Context, namespace string, owner metav1.OwnerReference, annotations map[string]*string) error {
+			assert.Equal(suite.t, *annotations["kube-remediator/last-action"], "CrashLoopBackOffRescheduler evicted Pod "+suite.pods[0].ObjectMeta.Name+": CrashLoopBackOff")
+			stamped, err := time.Parse(time.RFC3339, *annotations["kube-remediator/last-action-time"])
+			assert.NilError(suite.t, err)
+			assert.Assert(suite.t, time.Since(stamped) < time.Minute)
+			assert.Equal(suite.t, *annotations["kube-remediator/action-count"], "5")
+			return nil
+		})
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestDoesNotStampOwnerWhenActionFailed() {
+	suite.policy.OwnerAnnotationPrefix = "kube-remediator/"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("forbidden"))
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsLogsOfFailingContainer() {
+	var buffer bytes.Buffer
+	suite.policy.Audit = audit.NewLog(&buffer)
+	notifier := make(channelNotifier, 1)
+	suite.policy.Notifiers = notify.Notifiers{notifier}
+	suite.policy.CaptureLogLines = 20
+	suite.pods[0].Status.InitContainerStatuses[0].Name = "init"
+	suite.pods[0].Status.InitContainerStatuses[0].State.Waiting = nil
+	suite.pods[0].Status.ContainerStatuses[0].Name = "app"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPodLogs(gomock.Any(), &suite.pods[0], gomock.Any()).DoAndReturn(
+		func(_ context.Context, pod *corev1.Pod, options *corev1.PodLogOptions) (string, error) {
+			assert.Equal(suite.t, options.Container, "app")
+			assert.Equal(suite.t, options.Previous, true)
+			assert.Equal(suite.t, *options.TailLines, int64(20))
+			return "panic: boom\n", nil
+		})
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+
+	logs := &audit.ContainerLogs{Container: "app", Tail: "panic: boom\n"}
+	assert.DeepEqual(suite.t, (<-notifier).Logs, logs)
+	var record audit.Record
+	assert.NilError(suite.t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.DeepEqual(suite.t, record.Logs, logs)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestNotifiesRecentWarningEvents() {
+	notifier := make(channelNotifier, 1)
+	suite.policy.Notifiers = notify.Notifiers{notifier}
+	suite.policy.CaptureEvents = 2
+	now := time.Now()
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetEvents(gomock.Any(), "default", gomock.Any()).Return(&corev1.EventList{Items: []corev1.Event{
+		{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 12, LastTimestamp: metav1.NewTime(now)},
+		{Type: "Warning", Reason: "FailedMount", Message: "secret not found", Count: 1, LastTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		{Type: "Normal", Reason: "Pulled", Message: "Container image pulled", Count: 13, LastTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+		{Type: "Warning", Reason: "Unhealthy", Message: "Liveness probe failed", Count: 3, LastTimestamp: metav1.NewTime(now.Add(-2 * time.Minute))},
+	}}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+
+	assert.DeepEqual(suite.t, (<-notifier).Events, []string{
+		"Unhealthy (x3): Liveness probe failed",
+		"BackOff (x12): Back-off restarting failed container",
+	})
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestRemediatesWhenLogsAreUnavailable() {
+	suite.policy.CaptureLogLines = 20
+	suite.pods[0].Status.InitContainerStatuses[0].Name = "init"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPodLogs(gomock.Any(), &suite.pods[0], gomock.Any()).Return("", errors.New("forbidden"))
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+// collector writing bundles below directory
+func (suite *TestCrashLoopBackOffReschedulerSuite) useDiagnostics(directory string, required bool) {
+	config := diagnostics.DefaultConfig()
+	config.Enabled, config.Directory = true, directory
+	collector, err := diagnostics.NewCollector(suite.logger, suite.mockClient, config)
+	assert.NilError(suite.t, err)
+	suite.policy.Diagnostics, suite.policy.RequireDiagnostics = collector, required
+	suite.mockClient.EXPECT().GetEvents(gomock.Any(), "default", gomock.Any()).Return(&corev1.EventList{}, nil)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestStoresDiagnosticsBeforeActing() {
+	directory, err := ioutil.TempDir("", "diagnostics")
+	assert.NilError(suite.t, err)
+	defer os.RemoveAll(directory)
+	suite.useDiagnostics(directory, true)
+	notifier := make(channelNotifier, 1)
+	suite.policy.Notifiers = notify.Notifiers{notifier}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+
+	location := (<-notifier).Diagnostics
+	assert.Assert(suite.t, strings.HasPrefix(location, filepath.Join(directory, "default", "healthyPod-")), location)
+	_, err = os.Stat(location)
+	assert.NilError(suite.t, err)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestSkipsWhenRequiredDiagnosticsAreNotStored() {
+	file, err := ioutil.TempFile("", "diagnostics")
+	assert.NilError(suite.t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+	suite.useDiagnostics(file.Name(), true) // not a directory
+	suite.policy.Skipped = metrics.NewSkippedMetrics(suite.logger)
+	suite.policy.Skipped.Register()
+	defer suite.policy.Skipped.UnRegister()
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+
+	assert.Equal(suite.t, gatheredValue(suite.t, "remediations_skipped", map[string]string{"reason": "diagnostics-not-stored"}), float64(1))
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestActsWhenOptionalDiagnosticsAreNotStored() {
+	file, err := ioutil.TempFile("", "diagnostics")
+	assert.NilError(suite.t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+	suite.useDiagnostics(file.Name(), false)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsOfOwnerKindsNotAllowed() {
+	suite.policy.OwnerKinds = []string{"ReplicaSet", "StatefulSet"}
+	suite.pods[0].ObjectMeta.OwnerReferences[0].Kind = "DaemonSet"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsOfAllowedOwnerKinds() {
+	suite.policy.OwnerKinds = []string{"ReplicaSet", "StatefulSet"}
+	suite.pods[0].ObjectMeta.OwnerReferences[0].Kind = "StatefulSet"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsStillUnhealthyWhenConfirming() {
+	suite.policy.ConfirmBeforeAction = true
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(suite.pods[0].DeepCopy(), nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsThatRecoveredWhenConfirming() {
+	suite.policy.ConfirmBeforeAction = true
+	recovered := suite.pods[0].DeepCopy()
+	recovered.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(recovered, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsThatWereReplacedWhenConfirming() {
+	suite.policy.ConfirmBeforeAction = true
+	replaced := suite.pods[0].DeepCopy()
+	replaced.ObjectMeta.UID = "new"
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(replaced, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsThatAreGoneWhenConfirming() {
+	suite.policy.ConfirmBeforeAction = true
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(nil, apierrors.NewNotFound(corev1.Resource("pods"), "healthyPod"))
+	suite.run()
+}
+
+// crashing Pods of different owners on the same Node
+func (suite *TestCrashLoopBackOffReschedulerSuite) podsOnNode(owners ...string) []corev1.Pod {
+	var pods []corev1.Pod
+	for _, owner := range owners {
+		pod := *suite.pods[0].DeepCopy()
+		pod.ObjectMeta.Name = owner + "-pod"
+		pod.ObjectMeta.OwnerReferences[0].Name = owner
+		pod.Spec.NodeName = "node"
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestKeepsPodsWhenManyOwnersCrashOnSameNode() {
+	suite.config.NodeCorrelation = remediator.NodeCorrelationConfig{MinOwners: 2, Cordon: false}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.podsOnNode("foo", "bar")}, nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCordonsNodeWhenManyOwnersCrashOnIt() {
+	suite.config.NodeCorrelation = remediator.NodeCorrelationConfig{MinOwners: 2, Cordon: true}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.podsOnNode("foo", "bar")}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, node *corev1.Node) error {
+		assert.Equal(suite.t, node.ObjectMeta.Name, "node")
+		return nil
+	})
 	suite.run()
 }
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsWhenFewOwnersCrashOnSameNode() {
+	suite.config.NodeCorrelation = remediator.NodeCorrelationConfig{MinOwners: 3, Cordon: true}
+	pods := suite.podsOnNode("foo", "bar")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &pods[1], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestFailsSetupWithInvalidConfig() {
+	suite.config.FailureThreshold = 0
+	crashloop := remediator.CrashLoopBackOffRescheduler{Config: suite.config}
+	err := crashloop.Setup(suite.logger, suite.mockClient, &suite.policy)
+	assert.ErrorContains(suite.t, err, "failureThreshold")
+}
+
+// run with the Pods in a shared cache instead of listing them, until the returned func is called
+func (suite *TestCrashLoopBackOffReschedulerSuite) runWithPodCache(pods []corev1.Pod) (*fake.Clientset, func()) {
+	var objects []runtime.Object
+	for i := range pods {
+		objects = append(objects, &pods[i])
+	}
+	clientSet := fake.NewSimpleClientset(objects...)
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.policy.Pods = k8s.NewPodCache(suite.mockClient, ctx.Done(), suite.slimPodCache)
+
+	crashloop := remediator.CrashLoopBackOffRescheduler{Config: suite.config}
+	err := crashloop.Setup(suite.logger, suite.mockClient, &suite.policy)
+	assert.Equal(suite.t, err, nil)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go crashloop.Run(ctx, &wg)
+	return clientSet, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) expectEvictions() chan string {
+	evicted := make(chan string, 10)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pod *corev1.Pod, _ *metav1.DeleteOptions) error {
+			evicted <- pod.ObjectMeta.Name
+			return nil
+		}).AnyTimes()
+	return evicted
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestScansPodCacheInsteadOfListing() {
+	evicted := suite.expectEvictions()
+	_, stop := suite.runWithPodCache(suite.pods)
+	defer stop()
+	assert.Equal(suite.t, <-evicted, "healthyPod")
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCountsReadyPodsOfOwnerInPodCache() {
+	suite.policy.MinReadyReplicas = 2
+	evicted := suite.expectEvictions()
+	_, stop := suite.runWithPodCache(append(suite.pods, suite.readyPod("ready"), suite.readyPod("ready2")))
+	defer stop()
+	assert.Equal(suite.t, <-evicted, "healthyPod") // without listing Pods
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestScansSlimPodCache() {
+	suite.slimPodCache = true
+	pod := &suite.pods[0]
+	pod.ObjectMeta.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}}
+	pod.ObjectMeta.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}", "team": "payments"}
+	pod.Spec.Volumes = []corev1.Volume{{Name: "config"}}
+	pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:1", Env: []corev1.EnvVar{{Name: "MODE", Value: "fast"}}}}
+	evicted := make(chan *corev1.Pod, 1)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pod *corev1.Pod, _ *metav1.DeleteOptions) error {
+			evicted <- pod
+			return nil
+		})
+	_, stop := suite.runWithPodCache(suite.pods)
+	defer stop()
+
+	cached := <-evicted
+	assert.Equal(suite.t, cached.ObjectMeta.Name, "healthyPod")
+	assert.Assert(suite.t, cached.ObjectMeta.ManagedFields == nil)
+	assert.DeepEqual(suite.t, cached.ObjectMeta.Annotations, map[string]string{"team": "payments"})
+	assert.Assert(suite.t, cached.Spec.Volumes == nil)
+	assert.DeepEqual(suite.t, cached.Spec.Containers, []corev1.Container{{Name: "app", Image: "app:1"}})
+	assert.DeepEqual(suite.t, cached.Status, pod.Status)
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestReschedulesPodsUpdatedInPodCache() {
+	evicted := suite.expectEvictions()
+	crashing := *suite.pods[0].DeepCopy()
+	suite.pods[0].Status.ContainerStatuses[0].RestartCount = 0
+	clientSet, stop := suite.runWithPodCache(suite.pods)
+	defer stop()
+	assert.Equal(suite.t, suite.policy.Pods.WaitForSync(nil, []string{""}), true)
+
+	_, err := clientSet.CoreV1().Pods("default").Update(&crashing)
+	assert.Equal(suite.t, err, nil)
+	assert.Equal(suite.t, <-evicted, "healthyPod")
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestCordonsNodeOfPodUpdatedInPodCache() {
+	suite.config.NodeCorrelation = remediator.NodeCorrelationConfig{MinOwners: 2, Cordon: true}
+	evicted := suite.expectEvictions()
+	cordoned := make(chan string, 1)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, node *corev1.Node) error {
+		cordoned <- node.ObjectMeta.Name
+		return nil
+	})
+	pods := suite.podsOnNode("foo", "bar")
+	crashing := *pods[0].DeepCopy()
+	pods[0].Status.ContainerStatuses[0].RestartCount = 0
+	clientSet, stop := suite.runWithPodCache(pods)
+	defer stop()
+	assert.Equal(suite.t, <-evicted, "bar-pod") // the only crashing owner on the Node when scanning
+
+	_, err := clientSet.CoreV1().Pods("default").Update(&crashing)
+	assert.Equal(suite.t, err, nil)
+	assert.Equal(suite.t, <-cordoned, "node")
+}
+
+func (suite *TestCrashLoopBackOffReschedulerSuite) TestRetriesQueuedPodsItCouldNotGet() {
+	pod := &suite.pods[0]
+	scanned := make(chan bool, 1)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").DoAndReturn(func(context.Context, string) (*corev1.PodList, error) {
+		scanned <- true
+		return &corev1.PodList{}, nil // the Pod only crashes after the scan
+	})
+	gomock.InOrder(
+		suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(nil, apierrors.NewTimeoutError("slow", 1)),
+		suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "healthyPod").Return(pod, nil),
+	)
+	evicted := suite.expectEvictions()
+	clientSet := fake.NewSimpleClientset()
+	// watched by the remediator, in all namespaces since it includes all of them
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := events.NewStream(suite.logger, suite.mockClient, ctx.Done(), 1)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	wg.Add(2)
+	go stream.Run(ctx, &wg)
+	crashloop := remediator.CrashLoopBackOffRescheduler{Config: suite.config}
+	crashloop.UseEventStream(stream)
+	assert.NilError(suite.t, crashloop.Setup(suite.logger, suite.mockClient, &suite.policy))
+	go crashloop.Run(ctx, &wg)
+	<-scanned
+	time.Sleep(100 * time.Millisecond) // subscribed to the stream
+
+	_, err := clientSet.CoreV1().Events("default").Create(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "healthyPod.backoff"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "healthyPod"},
+		Reason:         "BackOff",
+	})
+	assert.NilError(suite.t, err)
+	assert.Equal(suite.t, <-evicted, "healthyPod")
+}
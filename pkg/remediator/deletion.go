@@ -0,0 +1,45 @@
+package remediator
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
+
+type DeleteOptionsConfig struct {
+	GracePeriodSeconds *int64 `mapstructure:"gracePeriodSeconds"` // nil uses the Pods terminationGracePeriodSeconds
+	PropagationPolicy  string `mapstructure:"propagationPolicy"`  // "Orphan", "Background", "Foreground" or "" for the default
+}
+
+type DeletionConfig struct {
+	DeleteOptionsConfig `mapstructure:",squash"`
+	Remediators         map[string]DeleteOptionsConfig `mapstructure:"remediators"`
+}
+
+// DeleteOptions for a remediator, settings it does not override come from the global ones
+func (c DeletionConfig) Build(remediator string) (*metav1.DeleteOptions, error) {
+	config := c.DeleteOptionsConfig
+	for name, override := range c.Remediators {
+		if strings.EqualFold(name, remediator) { // viper lowercases keys
+			if override.GracePeriodSeconds != nil {
+				config.GracePeriodSeconds = override.GracePeriodSeconds
+			}
+			if override.PropagationPolicy != "" {
+				config.PropagationPolicy = override.PropagationPolicy
+			}
+		}
+	}
+
+	options := &metav1.DeleteOptions{GracePeriodSeconds: config.GracePeriodSeconds}
+	if config.GracePeriodSeconds != nil && *config.GracePeriodSeconds < 0 {
+		return nil, fmt.Errorf("invalid gracePeriodSeconds %d", *config.GracePeriodSeconds)
+	}
+	switch policy := metav1.DeletionPropagation(config.PropagationPolicy); policy {
+	case "":
+	case metav1.DeletePropagationOrphan, metav1.DeletePropagationBackground, metav1.DeletePropagationForeground:
+		options.PropagationPolicy = &policy
+	default:
+		return nil, fmt.Errorf("unknown propagationPolicy %q", config.PropagationPolicy)
+	}
+	return options, nil
+}
@@ -0,0 +1,46 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+func TestDeletionConfigUsesApiServerDefaults(t *testing.T) {
+	options, err := remediator.DeletionConfig{}.Build("OldPodDeleter")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, options, &metav1.DeleteOptions{})
+}
+
+func TestDeletionConfigUsesRemediatorOverrides(t *testing.T) {
+	config := remediator.DeletionConfig{
+		DeleteOptionsConfig: remediator.DeleteOptionsConfig{GracePeriodSeconds: int64Pointer(30), PropagationPolicy: "Background"},
+		Remediators: map[string]remediator.DeleteOptionsConfig{
+			"crashloopbackoffrescheduler": {GracePeriodSeconds: int64Pointer(0)},
+		},
+	}
+	background := metav1.DeletePropagationBackground
+
+	options, err := config.Build("CrashLoopBackOffRescheduler")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, options, &metav1.DeleteOptions{GracePeriodSeconds: int64Pointer(0), PropagationPolicy: &background})
+
+	options, err = config.Build("OldPodDeleter")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, options, &metav1.DeleteOptions{GracePeriodSeconds: int64Pointer(30), PropagationPolicy: &background})
+}
+
+func TestDeletionConfigFailsOnInvalidConfig(t *testing.T) {
+	for _, config := range []remediator.DeleteOptionsConfig{
+		{GracePeriodSeconds: int64Pointer(-1)},
+		{PropagationPolicy: "Sometimes"},
+	} {
+		_, err := remediator.DeletionConfig{DeleteOptionsConfig: config}.Build("OldPodDeleter")
+		assert.Assert(t, err != nil)
+	}
+}
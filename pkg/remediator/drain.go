@@ -0,0 +1,92 @@
+package remediator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Lets remediations that already started finish once shutdown began, so a Pod is not left half remediated,
+// until the timeout passed, then they are cancelled, a nil Drain cancels them with the scan that started them
+type Drain struct {
+	timeout time.Duration
+	ctx     context.Context // done once the timeout passed after Start
+	cancel  context.CancelFunc
+	start   sync.Once
+	started chan struct{} // closed by Start
+}
+
+// timeout 0 cancels in-flight remediations right away
+func NewDrain(timeout time.Duration) *Drain {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drain{timeout: timeout, ctx: ctx, cancel: cancel, started: make(chan struct{})}
+}
+
+// ctx for a remediation, with the values of ctx like its trace, that is only done when the drain timed out
+func (d *Drain) Context(ctx context.Context) context.Context {
+	if d == nil {
+		return ctx
+	}
+	return drainContext{Context: ctx, drain: d.ctx}
+}
+
+// shutdown began, in-flight remediations are cancelled after the timeout
+func (d *Drain) Start() {
+	if d == nil {
+		return
+	}
+	d.start.Do(func() {
+		close(d.started)
+		time.AfterFunc(d.timeout, d.cancel)
+	})
+}
+
+// shutdown began, no new remediation starts, a nil Drain never drains
+func (d *Drain) Draining() bool {
+	if d == nil {
+		return false
+	}
+	select {
+	case <-d.started:
+		return true
+	default:
+		return false
+	}
+}
+
+// waits for wg until the timeout passed after Start, false when it did
+func (d *Drain) Wait(wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	if d == nil {
+		<-done
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+// values of the scan, cancellation of the drain
+type drainContext struct {
+	context.Context
+	drain context.Context
+}
+
+func (c drainContext) Deadline() (time.Time, bool) {
+	return c.drain.Deadline()
+}
+
+func (c drainContext) Done() <-chan struct{} {
+	return c.drain.Done()
+}
+
+func (c drainContext) Err() error {
+	return c.drain.Err()
+}
@@ -0,0 +1,55 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type drainKey struct{}
+
+func TestDrainKeepsRemediationsGoingUntilTimeout(t *testing.T) {
+	drain := remediator.NewDrain(50 * time.Millisecond)
+	scan, stopScan := context.WithCancel(context.WithValue(context.Background(), drainKey{}, "span"))
+	ctx := drain.Context(scan)
+
+	stopScan()
+	drain.Start()
+	assert.Equal(t, drain.Draining(), true)
+	assert.NilError(t, ctx.Err())
+	assert.Equal(t, ctx.Value(drainKey{}), "span")
+
+	select {
+	case <-ctx.Done():
+		assert.Equal(t, ctx.Err(), context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("not cancelled after the drain timeout")
+	}
+}
+
+func TestDrainWaitsUntilTimeout(t *testing.T) {
+	drain := remediator.NewDrain(time.Hour)
+	assert.Equal(t, drain.Draining(), false)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go wg.Done()
+	drain.Start()
+	assert.Equal(t, drain.Wait(&wg), true)
+
+	drain = remediator.NewDrain(0)
+	wg.Add(1) // never done
+	drain.Start()
+	assert.Equal(t, drain.Wait(&wg), false)
+}
+
+func TestNilDrainCancelsWithTheScan(t *testing.T) {
+	var drain *remediator.Drain
+	scan, stopScan := context.WithCancel(context.Background())
+	stopScan()
+	drain.Start()
+	assert.Equal(t, drain.Draining(), false)
+	assert.Equal(t, drain.Context(scan).Err(), context.Canceled)
+}
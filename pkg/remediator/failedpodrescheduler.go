@@ -6,8 +6,6 @@ import (
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"strings"
 	"sync"
 	"time"
@@ -15,83 +13,72 @@ import (
 
 type FailedPodRescheduler struct {
 	Base
-	informerFactory informers.SharedInformerFactory
+	filters FilterChain
 }
 
-func (p *FailedPodRescheduler) Setup(logger *zap.Logger, client k8s.ClientInterface) error {
-	informerFactory, err := client.NewSharedInformerFactory("")
-	if err != nil {
-		return err // untested section
+func init() {
+	Register("FailedPodRescheduler", func(Configs) Remediator { return &FailedPodRescheduler{} })
+}
+
+func (p *FailedPodRescheduler) Name() string {
+	return "FailedPodRescheduler"
+}
+
+func (p *FailedPodRescheduler) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	p.filters = FilterChain{
+		{Name: "outOfResources", Matches: func(pod *v1.Pod) bool {
+			reason := strings.ToLower(pod.Status.Reason) // we saw OutOfCPU, OutOfcpu and Outofmemory
+			return pod.Status.Phase == v1.PodFailed && (reason == "outofcpu" || reason == "outofmemory")
+		}},
+		OwnerFilter(),
+		OwnerKindFilter("Job"),     // Job pods are deleted by Kubernetes
+		AgeFilter(5 * time.Minute), // keep pods for 5 mins to be able to debug and log pipeline to find out metadata
 	}
-	p.informerFactory = informerFactory
-	p.logger = logger
-	p.client = client
-	return nil
+	return p.Base.Setup(logger, client, policy)
 }
 
 func (p *FailedPodRescheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	p.logStartAndStop(func() {
-		// Check for any Failed Pods first
-		p.reschedulePods()
-		// TODO: filter failed pods here to avoid overhead
-		informer := p.informerFactory.Core().V1().Pods().Informer()
-
-		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-			UpdateFunc: p.rescheduleIfNecessary,
-		})
-		informer.Run(ctx.Done())
+	if p.policy.Once { // the scan finds what watching would
+		p.reconcileEvery(ctx, p.reschedulePods, 5*time.Minute)
+		return
+	}
 
-		<-ctx.Done()
+	pods := p.podCache(ctx)
+	queue := p.startPodQueue(pods, p.reschedule)
+	defer queue.Stop() // after the informer no longer adds to it
+	stop, ok := p.watchPods(ctx, pods, p.policy.Namespaces.ListNamespaces(), func(pod *v1.Pod) {
+		if p.shouldReschedule(pod) {
+			queue.Add(pod)
+		}
 	})
+	if !ok {
+		return
+	}
+	defer stop() // the Pod cache outlives us when reloading config
+
+	p.reconcileEvery(ctx, p.reschedulePods, 5*time.Minute)
 }
 
-func (p *FailedPodRescheduler) reschedulePods() {
+func (p *FailedPodRescheduler) reschedulePods(ctx context.Context) {
 	p.logger.Info("Reconcile")
-	for _, pod := range *p.getFailedPods() {
-		p.rescheduleIfNecessary(nil, &pod)
+	for _, pod := range *p.getFailedPods(ctx) {
+		p.reschedule(ctx, &pod)
 	}
 }
 
-func (p *FailedPodRescheduler) rescheduleIfNecessary(oldObj, newObj interface{}) {
-	pod := newObj.(*v1.Pod)
+func (p *FailedPodRescheduler) reschedule(ctx context.Context, pod *v1.Pod) {
 	if p.shouldReschedule(pod) {
-		p.deletePod(*pod)
+		p.remediatePod(ctx, *pod, pod.Status.Reason, ActionDelete, p.shouldReschedule)
 	}
 }
 
-func (p *FailedPodRescheduler) getFailedPods() *[]v1.Pod {
-	pods, err := p.client.GetPods("", metav1.ListOptions{FieldSelector: "status.phase=Failed"})
-	if err != nil {
-		p.logger.Error("Error getting pod list: ", zap.Error(err))
-		return &[]v1.Pod{}
-	}
-	return &pods.Items
+func (p *FailedPodRescheduler) getFailedPods(ctx context.Context) *[]v1.Pod {
+	pods := p.cachedPods(ctx, p.policy.Namespaces.ListNamespaces(), p.policy.listOptions(metav1.ListOptions{FieldSelector: "status.phase=Failed"}), p.shouldReschedule)
+	return &pods
 }
 
 func (p *FailedPodRescheduler) shouldReschedule(pod *v1.Pod) bool {
-	reason := strings.ToLower(pod.Status.Reason) // we saw OutOfCPU, OutOfcpu and Outofmemory
-	if pod.Status.Phase != "Failed" || (reason != "outofcpu" && reason != "outofmemory") {
-		return false
-	}
-
-	// Pods that would not be recreated need to stay
-	if len(pod.ObjectMeta.OwnerReferences) == 0 {
-		return false
-	}
-
-	// Job pods are deleted by Kubernetes
-	for _, ownerReference := range pod.ObjectMeta.OwnerReferences {
-		if ownerReference.Kind == "Job" {
-			return false
-		}
-	}
-
-	// Keep pods for 5 mins to be able to debug and log pipeline to find out metadata
-	if pod.ObjectMeta.CreationTimestamp.Time.After(time.Now().Add(-5 * time.Minute)) {
-		return false
-	}
-
-	return true
+	return p.passes(p.filters, pod)
 }
@@ -3,6 +3,7 @@ package remediator_test
 import (
 	"context"
 	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
 	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
 	"github.com/aksgithub/kube_remediator/pkg/remediator"
 	"github.com/golang/mock/gomock"
@@ -24,6 +25,7 @@ type TestFailedPodReschedulerSuite struct {
 	mockController *gomock.Controller
 	mockClient     *mock_k8s.MockClientInterface
 	pods           []corev1.Pod
+	policy         remediator.Policy
 	t              *testing.T
 }
 
@@ -35,6 +37,7 @@ func (suite *TestFailedPodReschedulerSuite) SetupTest() {
 	suite.logger, _ = zap.NewDevelopment()
 	suite.mockController = gomock.NewController(suite.t)
 	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
 	suite.pods = []corev1.Pod{{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -48,7 +51,7 @@ func (suite *TestFailedPodReschedulerSuite) SetupTest() {
 					Name: "controller",
 				},
 			},
-			CreationTimestamp: metav1.Time{time.Now().Add(-10 * time.Minute)},
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
 		},
 		Status: corev1.PodStatus{
 			Phase:  "Failed",
@@ -71,7 +74,7 @@ func (suite *TestFailedPodReschedulerSuite) run() {
 
 	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(suite.newInformerFactory(), nil)
 	r := remediator.FailedPodRescheduler{}
-	err := r.Setup(suite.logger, suite.mockClient)
+	err := r.Setup(suite.logger, suite.mockClient, &suite.policy)
 	assert.Equal(suite.t, err, nil)
 
 	var wg sync.WaitGroup
@@ -80,48 +83,74 @@ func (suite *TestFailedPodReschedulerSuite) run() {
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestReschedulesFailedPod() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestLoopsOverAllPods() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: append(suite.pods, suite.pods...)}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil).Times(2)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, suite.pods...)}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil).Times(2)
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestKeepsFailedPodWithoutOwnerReference() {
 	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{}
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestKeepsFailedPodsWhenTheyAreCleanup() {
 	suite.pods[0].ObjectMeta.OwnerReferences[0].Kind = "Job"
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestKeepsFailedPodsWithOtherReasons() {
 	suite.pods[0].Status.Reason = "fake"
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestDoesNotCrashWhenDeleteFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(errors.New("foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("foo"))
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestDoesNotCrashWhenListFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(nil, errors.New("foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, errors.New("foo"))
 	suite.run()
 }
 
 func (suite *TestFailedPodReschedulerSuite) TestDoesNotDeleteWhenPodIsNew() {
-	suite.pods[0].CreationTimestamp = metav1.Time{time.Now().Add(-4 * time.Minute)}
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.pods[0].CreationTimestamp = metav1.Time{Time: time.Now().Add(-4 * time.Minute)}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
+
+func (suite *TestFailedPodReschedulerSuite) TestScansPodCacheInsteadOfListing() {
+	running := suite.pods[0]
+	running.ObjectMeta.Name = "running"
+	running.Status.Phase = "Running"
+	clientSet := fake.NewSimpleClientset(&suite.pods[0], &running)
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	deleted := make(chan string, 10)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, pod *corev1.Pod, _ *metav1.DeleteOptions) error {
+			deleted <- pod.ObjectMeta.Name
+			return nil
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.policy.Pods = k8s.NewPodCache(suite.mockClient, ctx.Done(), false)
+
+	r := remediator.FailedPodRescheduler{}
+	err := r.Setup(suite.logger, suite.mockClient, &suite.policy)
+	assert.Equal(suite.t, err, nil)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go r.Run(ctx, &wg)
+	assert.Equal(suite.t, <-deleted, "healthyPod")
+	cancel()
+	wg.Wait()
+}
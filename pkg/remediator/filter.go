@@ -0,0 +1,176 @@
+package remediator
+
+import (
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"strings"
+	"time"
+)
+
+// a named check a Pod has to pass before a remediator acts on it
+type Filter struct {
+	Name    string
+	Matches func(*v1.Pod) bool
+	Skipped string // label of remediations_skipped counting the Pods it rejects, "" means they are not counted
+}
+
+// the checks of a remediator, a Pod has to pass all of them, in order
+type FilterChain []Filter
+
+// the first filter the Pod does not pass, nil when it passes all
+func (c FilterChain) Rejecting(pod *v1.Pod) *Filter {
+	for i := range c {
+		if !c[i].Matches(pod) {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// skips Pods opted out by annotating them with "false", "" means Pods can not opt out
+func AnnotationFilter(annotation string) Filter {
+	return Filter{Name: "annotation", Matches: func(pod *v1.Pod) bool {
+		return annotation == "" || pod.ObjectMeta.Annotations[annotation] != "false"
+	}}
+}
+
+// Pods without an owner are not recreated after deleting them
+func OwnerFilter() Filter {
+	return Filter{Name: "owner", Skipped: "no-owner", Matches: func(pod *v1.Pod) bool {
+		return len(pod.ObjectMeta.OwnerReferences) > 0
+	}}
+}
+
+// skips Pods owned by the kinds, like DaemonSet Pods that come right back on the same Node
+func OwnerKindFilter(excluded ...string) Filter {
+	return Filter{Name: "ownerKind", Matches: func(pod *v1.Pod) bool {
+		for _, ownerReference := range pod.ObjectMeta.OwnerReferences {
+			for _, kind := range excluded {
+				if strings.EqualFold(ownerReference.Kind, kind) {
+					return false
+				}
+			}
+		}
+		return true
+	}}
+}
+
+// Pods created at least minAge ago
+func AgeFilter(minAge time.Duration) Filter {
+	return Filter{Name: "age", Matches: func(pod *v1.Pod) bool {
+		return pod.ObjectMeta.CreationTimestamp.Time.Before(time.Now().Add(-minAge))
+	}}
+}
+
+// Pods on Nodes with matching labels, unscheduled Pods and those on Nodes that are not cached do not match
+func NodeFilter(nodes *k8s.NodeCache, selector labels.Selector) Filter {
+	return Filter{Name: "node", Matches: func(pod *v1.Pod) bool {
+		if pod.Spec.NodeName == "" {
+			return false
+		}
+		node, err := nodes.GetNode(pod.Spec.NodeName)
+		return err == nil && selector.Matches(labels.Set(node.ObjectMeta.Labels))
+	}}
+}
+
+// Pods with a container, or init container, that restarted at least threshold times, with waitingReason
+// only when it now waits for that reason, like CrashLoopBackOff, threshold is per namespace
+func ThresholdFilter(waitingReason string, threshold func(namespace string) int32) Filter {
+	return Filter{Name: "threshold", Matches: func(pod *v1.Pod) bool {
+		restarts := threshold(pod.ObjectMeta.Namespace)
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if status.RestartCount < restarts {
+				continue
+			}
+			if waitingReason == "" || (status.State.Waiting != nil && status.State.Waiting.Reason == waitingReason) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// filters added to the own ones of a remediator, to tune which Pods it acts on
+type FilterConfig struct {
+	Annotation        string        `mapstructure:"annotation"` // Pods annotated with "false" are skipped
+	MinAge            time.Duration `mapstructure:"minAge"`
+	RequireOwner      bool          `mapstructure:"requireOwner"`
+	ExcludeOwnerKinds []string      `mapstructure:"excludeOwnerKinds"`
+	NodeSelector      string        `mapstructure:"nodeSelector"` // labels of the Node the Pod runs on
+	MinRestarts       int32         `mapstructure:"minRestarts"`  // of any container
+}
+
+// FilterConfig by remediator
+type FiltersConfig map[string]FilterConfig
+
+func (c FiltersConfig) Validate() error {
+	for name, filter := range c {
+		if filter.MinAge < 0 {
+			return fmt.Errorf("filters.%s.minAge must not be negative, got %v", name, filter.MinAge)
+		}
+		if filter.MinRestarts < 0 {
+			return fmt.Errorf("filters.%s.minRestarts must not be negative, got %d", name, filter.MinRestarts)
+		}
+		if _, err := labels.Parse(filter.NodeSelector); err != nil {
+			return fmt.Errorf("filters.%s.nodeSelector: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// the filters of a remediator, nodes looks up the Nodes of a nodeSelector, nil when Nodes are not cached
+func (c FiltersConfig) Build(remediator string, nodes *k8s.NodeCache) (FilterChain, error) {
+	var chain FilterChain
+	for name, filter := range c {
+		if !strings.EqualFold(name, remediator) { // viper lowercases keys
+			continue
+		}
+		if filter.Annotation != "" {
+			chain = append(chain, AnnotationFilter(filter.Annotation))
+		}
+		if filter.MinAge > 0 {
+			chain = append(chain, AgeFilter(filter.MinAge))
+		}
+		if filter.RequireOwner {
+			chain = append(chain, OwnerFilter())
+		}
+		if len(filter.ExcludeOwnerKinds) > 0 {
+			chain = append(chain, OwnerKindFilter(filter.ExcludeOwnerKinds...))
+		}
+		if filter.NodeSelector != "" {
+			if nodes == nil {
+				return nil, fmt.Errorf("filters.%s.nodeSelector needs the Node cache of skipDrainingNodes", name)
+			}
+			selector, err := labels.Parse(filter.NodeSelector)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, NodeFilter(nodes, selector))
+		}
+		if filter.MinRestarts > 0 {
+			minRestarts := filter.MinRestarts
+			chain = append(chain, ThresholdFilter("", func(string) int32 { return minRestarts }))
+		}
+	}
+	return chain, nil
+}
+
+// whether the Pod passes the filters of the remediator and then Policy.Filters, rejected Pods are logged at debug
+// since most Pods are
+func (p *Base) passes(filters FilterChain, pod *v1.Pod) bool {
+	rejecting := filters.Rejecting(pod)
+	if rejecting == nil {
+		rejecting = p.policy.Filters.Rejecting(pod)
+	}
+	if rejecting == nil {
+		return true
+	}
+	p.logger.Debug("Skipping, filtered", append(podInfo(pod), zap.String("filter", rejecting.Name))...)
+	if rejecting.Skipped != "" {
+		p.policy.Skipped.UpdateSkippedCount(rejecting.Skipped)
+	}
+	return false
+}
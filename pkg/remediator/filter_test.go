@@ -0,0 +1,110 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/labels"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRejectsWithFirstFailingFilter(t *testing.T) {
+	pod := fake.CrashLoopingPod("default", "api", 3)
+	pod.ObjectMeta.Annotations = map[string]string{"example.com/remediate": "false"}
+	chain := remediator.FilterChain{
+		remediator.AgeFilter(time.Minute),
+		remediator.OwnerFilter(),
+		remediator.AnnotationFilter("example.com/remediate"),
+	}
+	assert.Equal(t, chain.Rejecting(pod).Name, "owner")
+
+	pod = fake.OwnedBy(pod, fake.ReplicaSet("default", "api", 1))
+	assert.Equal(t, chain.Rejecting(pod).Name, "annotation")
+
+	pod.ObjectMeta.Annotations = nil
+	assert.Assert(t, chain.Rejecting(pod) == nil)
+}
+
+func TestFiltersByOwnerKind(t *testing.T) {
+	filter := remediator.OwnerKindFilter("DaemonSet", "Job")
+	assert.Equal(t, filter.Matches(fake.Pod("default", "api")), true)
+	assert.Equal(t, filter.Matches(fake.OwnedBy(fake.Pod("default", "api"), fake.ReplicaSet("default", "api", 1))), true)
+	assert.Equal(t, filter.Matches(fake.OwnedBy(fake.Pod("default", "api"), fake.Deployment("default", "api", 1))), true)
+
+	job := fake.Deployment("default", "backup", 1)
+	job.SetKind("job")
+	assert.Equal(t, filter.Matches(fake.OwnedBy(fake.Pod("default", "backup"), job)), false)
+}
+
+func TestFiltersByRestarts(t *testing.T) {
+	threshold := func(string) int32 { return 5 }
+	crashLooping := remediator.ThresholdFilter("CrashLoopBackOff", threshold)
+	assert.Equal(t, crashLooping.Matches(fake.CrashLoopingPod("default", "api", 5)), true)
+	assert.Equal(t, crashLooping.Matches(fake.CrashLoopingPod("default", "api", 4)), false)
+
+	recovered := fake.CrashLoopingPod("default", "api", 7)
+	recovered.Status.ContainerStatuses[0].State = fake.Pod("default", "api").Status.ContainerStatuses[0].State
+	assert.Equal(t, crashLooping.Matches(recovered), false)
+	assert.Equal(t, remediator.ThresholdFilter("", threshold).Matches(recovered), true)
+}
+
+func TestFiltersByNodeLabels(t *testing.T) {
+	node := fake.Node("node-1")
+	node.ObjectMeta.Labels = map[string]string{"agentpool": "user"}
+	client := fake.NewClient(node)
+	nodes, err := k8s.NewNodeCache(client, false)
+	assert.NilError(t, err)
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.Assert(t, nodes.Start(stop))
+
+	filter := remediator.NodeFilter(nodes, labels.SelectorFromSet(labels.Set{"agentpool": "user"}))
+	pod := fake.Pod("default", "api")
+	assert.Equal(t, filter.Matches(pod), false) // not scheduled
+
+	pod.Spec.NodeName = "node-1"
+	assert.Equal(t, filter.Matches(pod), true)
+
+	pod.Spec.NodeName = "node-2"
+	assert.Equal(t, filter.Matches(pod), false)
+}
+
+func TestBuildsFiltersOfRemediator(t *testing.T) {
+	config := remediator.FiltersConfig{
+		"failedpodrescheduler": {MinAge: 2 * time.Hour, RequireOwner: true, MinRestarts: 1},
+		"OldPodDeleter":        {Annotation: "example.com/remediate"},
+	}
+	chain, err := config.Build("FailedPodRescheduler", nil)
+	assert.NilError(t, err)
+	names := []string{}
+	for _, filter := range chain {
+		names = append(names, filter.Name)
+	}
+	assert.DeepEqual(t, names, []string{"age", "owner", "threshold"})
+
+	_, err = remediator.FiltersConfig{"OldPodDeleter": {NodeSelector: "agentpool=user"}}.Build("OldPodDeleter", nil)
+	assert.ErrorContains(t, err, "filters.OldPodDeleter.nodeSelector needs the Node cache")
+}
+
+func TestSkipsPodsRejectedByPolicyFilters(t *testing.T) {
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	client := fake.NewClient(fake.OwnedBy(fake.FailedPod("default", "api-5d8f-x2x", "OutOfmemory"), replicaSet))
+	assert.NilError(t, client.AddOwner(replicaSet))
+	filters, err := remediator.FiltersConfig{"FailedPodRescheduler": {MinAge: 2 * time.Hour}}.Build("FailedPodRescheduler", nil)
+	assert.NilError(t, err)
+
+	rescheduler := remediator.FailedPodRescheduler{}
+	assert.NilError(t, rescheduler.Setup(zap.NewNop(), client, &remediator.Policy{Filters: filters}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	rescheduler.Run(ctx, &wg)
+
+	assert.Equal(t, len(client.Actions()), 0) // created an hour ago
+}
@@ -0,0 +1,239 @@
+package remediator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HookPhasePre  = "pre"
+	HookPhasePost = "post"
+)
+
+// called around the actions of remediators, like to snapshot state, silence alerts or ask an external system
+// whether now is a good time, either a url or a command exec'd in a container of the remediated Pod
+type HookConfig struct {
+	Name           string            `mapstructure:"name"`           // in logs and the detail of vetoes
+	URL            string            `mapstructure:"url"`            // POSTed a HookRequest, 2xx allows, 409 vetoes with the body as reason
+	Headers        map[string]string `mapstructure:"headers"`        // of the POST, like Authorization
+	Container      string            `mapstructure:"container"`      // sidecar the command runs in, only for Pods
+	Command        []string          `mapstructure:"command"`        // gets the HookRequest on stdin, 0 allows, other exit codes veto with the output as reason
+	Timeout        time.Duration     `mapstructure:"timeout"`        // 0 means 10s
+	Remediators    []string          `mapstructure:"remediators"`    // empty means all
+	IgnoreFailures bool              `mapstructure:"ignoreFailures"` // a pre hook that could not be called allows instead of vetoing
+}
+
+type HooksConfig struct {
+	Pre  []HookConfig `mapstructure:"pre"`  // in order before each action, the first veto skips the remediation
+	Post []HookConfig `mapstructure:"post"` // in order after each action with its outcome, failures are only logged
+}
+
+// what a hook is told, as JSON
+type HookRequest struct {
+	Hook       string          `json:"hook"`
+	Phase      string          `json:"phase"` // pre or post
+	Remediator string          `json:"remediator"`
+	Cluster    string          `json:"cluster,omitempty"`
+	Object     audit.ObjectRef `json:"object"`
+	Reason     string          `json:"reason"`
+	Action     string          `json:"action"`            // like evicted
+	Outcome    string          `json:"outcome,omitempty"` // success or error, post hooks only
+	Detail     string          `json:"detail,omitempty"`  // the error, post hooks only
+}
+
+// the hooks of a remediator, a nil Hooks calls none
+type Hooks struct {
+	pre    []HookConfig
+	post   []HookConfig
+	client *http.Client
+}
+
+// a hook said no, with why
+type vetoError struct {
+	reason string
+}
+
+func (e *vetoError) Error() string {
+	return e.reason
+}
+
+func (c HooksConfig) Validate() error {
+	phases := map[string][]HookConfig{HookPhasePre: c.Pre, HookPhasePost: c.Post}
+	for _, phase := range []string{HookPhasePre, HookPhasePost} {
+		names := map[string]bool{}
+		for i, hook := range phases[phase] {
+			key := fmt.Sprintf("hooks.%s[%d]", phase, i)
+			if hook.Name == "" {
+				return fmt.Errorf("%s.name is required", key)
+			}
+			if names[hook.Name] {
+				return fmt.Errorf("%s.name %q is used twice", key, hook.Name)
+			}
+			names[hook.Name] = true
+			if (hook.URL == "") == (hook.Container == "" && len(hook.Command) == 0) {
+				return fmt.Errorf("%s: set either url or container and command", key)
+			}
+			if hook.URL == "" && (hook.Container == "" || len(hook.Command) == 0) {
+				return fmt.Errorf("%s: container and command are both required", key)
+			}
+			if hook.Timeout < 0 {
+				return fmt.Errorf("%s.timeout must not be negative, got %v", key, hook.Timeout)
+			}
+			for _, name := range hook.Remediators {
+				if registered, ok := Lookup(name); !ok || !strings.EqualFold(registered, name) {
+					return fmt.Errorf("%s.remediators: unknown remediator %q, use %s", key, name, strings.Join(Names(), ", "))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Hooks of a remediator, nil when it has none
+func (c HooksConfig) Build(remediator string) *Hooks {
+	hooks := &Hooks{
+		pre:    hooksOf(c.Pre, remediator),
+		post:   hooksOf(c.Post, remediator),
+		client: &http.Client{},
+	}
+	if len(hooks.pre) == 0 && len(hooks.post) == 0 {
+		return nil
+	}
+	return hooks
+}
+
+func hooksOf(hooks []HookConfig, remediator string) []HookConfig {
+	var of []HookConfig
+	for _, hook := range hooks {
+		if len(hook.Remediators) == 0 {
+			of = append(of, hook)
+		}
+		for _, name := range hook.Remediators {
+			if strings.EqualFold(name, remediator) {
+				of = append(of, hook)
+				break
+			}
+		}
+	}
+	return of
+}
+
+// nil when the hook allows, a *vetoError when it vetoes, other errors when it could not be called,
+// exec hooks are skipped for objects that are not Pods
+func (h *Hooks) call(ctx context.Context, client k8s.ClientInterface, hook HookConfig, request HookRequest) error {
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	request.Hook = hook.Name
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err // untested section
+	}
+	if hook.URL != "" {
+		return h.send(ctx, hook, body)
+	}
+	if request.Object.Kind != "Pod" {
+		return nil
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: request.Object.Namespace, Name: request.Object.Name}}
+	_, err = client.ExecPod(ctx, pod, hook.Container, hook.Command, body)
+	if exit, ok := err.(*k8s.ExitError); ok {
+		return &vetoError{reason: strings.TrimSpace(exit.Output)}
+	}
+	return err
+}
+
+func (h *Hooks) send(ctx context.Context, hook HookConfig, body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range hook.Headers {
+		request.Header.Set(name, value)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := h.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	text, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return nil
+	case response.StatusCode == http.StatusConflict:
+		return &vetoError{reason: strings.TrimSpace(string(text))}
+	default:
+		return fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(text)))
+	}
+}
+
+// calls the pre hooks in order, why the remediation must not go ahead, "" when all of them allowed it
+func (p *Base) preHooks(ctx context.Context, object audit.ObjectRef, reason string, action string) string {
+	if p.policy.Hooks == nil {
+		return ""
+	}
+	request := HookRequest{Phase: HookPhasePre, Remediator: p.policy.Remediator, Cluster: p.policy.Cluster, Object: object, Reason: reason, Action: action}
+	for _, hook := range p.policy.Hooks.pre {
+		info := []zap.Field{zap.String("hook", hook.Name), zap.String("kind", object.Kind), zap.String("namespace", object.Namespace), zap.String("name", object.Name)}
+		err := p.callHook(ctx, hook, request)
+		if veto, ok := err.(*vetoError); ok {
+			p.logger.Info("Vetoed by hook", append(info, zap.String("veto", veto.reason))...)
+			if veto.reason == "" {
+				return "vetoed by hook: " + hook.Name
+			}
+			return "vetoed by hook: " + hook.Name + ": " + veto.reason
+		}
+		if err != nil && hook.IgnoreFailures {
+			p.logger.Warn("Hook failed, ignoring", append(info, zap.Error(err))...)
+			continue
+		}
+		if err != nil {
+			p.logger.Warn("Hook failed", append(info, zap.Error(err))...)
+			return "hook failed: " + hook.Name + ": " + err.Error()
+		}
+	}
+	return ""
+}
+
+// calls the post hooks in order with the outcome of the action, all of them also when some fail
+func (p *Base) postHooks(ctx context.Context, request HookRequest) {
+	if p.policy.Hooks == nil {
+		return
+	}
+	request.Phase = HookPhasePost
+	request.Remediator = p.policy.Remediator
+	request.Cluster = p.policy.Cluster
+	for _, hook := range p.policy.Hooks.post {
+		if err := p.callHook(ctx, hook, request); err != nil {
+			p.logger.Warn("Hook failed", zap.String("hook", hook.Name), zap.String("kind", request.Object.Kind),
+				zap.String("namespace", request.Object.Namespace), zap.String("name", request.Object.Name), zap.Error(err))
+		}
+	}
+}
+
+func (p *Base) callHook(ctx context.Context, hook HookConfig, request HookRequest) error {
+	_, span := p.policy.Tracer.Start(ctx, "hook", tracing.KindClient, map[string]string{"hook": hook.Name, "phase": request.Phase})
+	err := p.policy.Hooks.call(ctx, p.client, hook, request)
+	if _, vetoed := err.(*vetoError); !vetoed {
+		span.SetError(err)
+	}
+	span.End()
+	return err
+}
@@ -0,0 +1,160 @@
+package remediator_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// a failed Pod remediated once by FailedPodRescheduler with the hooks, the exec hook of container sidecar exits with code
+func remediateWithHooks(t *testing.T, hooks remediator.HooksConfig, code int) (*fake.Client, *audit.History) {
+	replicaSet := fake.ReplicaSet("default", "api-5d8f", 3)
+	client := fake.NewClient(fake.OwnedBy(fake.FailedPod("default", "api-5d8f-x2x", "OutOfmemory"), replicaSet))
+	assert.NilError(t, client.AddOwner(replicaSet))
+	client.SetExecResult("default", "api-5d8f-x2x", "sidecar", "busy\n", code)
+
+	history := audit.NewHistory(10)
+	rescheduler := remediator.FailedPodRescheduler{}
+	policy := &remediator.Policy{
+		Remediator: "FailedPodRescheduler",
+		History:    history,
+		Hooks:      hooks.Build("FailedPodRescheduler"),
+		Drain:      remediator.NewDrain(time.Hour), // actions outlive the cancelled scan, like while shutting down
+	}
+	assert.NilError(t, rescheduler.Setup(zap.NewNop(), client, policy))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	var wg sync.WaitGroup
+	wg.Add(1)
+	rescheduler.Run(ctx, &wg)
+	return client, history
+}
+
+// answers with status and body, sends what it was told to requests
+func hookServer(status int, body string) (*httptest.Server, chan remediator.HookRequest) {
+	requests := make(chan remediator.HookRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request remediator.HookRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests <- request
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	return server, requests
+}
+
+func TestCallsHooksAroundActions(t *testing.T) {
+	server, requests := hookServer(http.StatusOK, "")
+	defer server.Close()
+	client, history := remediateWithHooks(t, remediator.HooksConfig{
+		Pre:  []remediator.HookConfig{{Name: "snapshot", URL: server.URL}},
+		Post: []remediator.HookConfig{{Name: "alerts", URL: server.URL}},
+	}, 0)
+
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "delete", Kind: "Pod", Namespace: "default", Name: "api-5d8f-x2x"}})
+	assert.Equal(t, history.Recent("", 0)[0].Outcome, "success")
+	pre := <-requests
+	assert.DeepEqual(t, pre, remediator.HookRequest{
+		Hook: "snapshot", Phase: "pre", Remediator: "FailedPodRescheduler", Reason: "OutOfmemory", Action: "deleted",
+		Object: audit.ObjectRef{Kind: "Pod", Namespace: "default", Name: "api-5d8f-x2x", UID: pre.Object.UID},
+	})
+	post := <-requests
+	assert.Equal(t, post.Hook, "alerts")
+	assert.Equal(t, post.Phase, "post")
+	assert.Equal(t, post.Outcome, "success")
+}
+
+func TestSkipsWhenHookVetoes(t *testing.T) {
+	server, requests := hookServer(http.StatusConflict, "change freeze\n")
+	defer server.Close()
+	client, history := remediateWithHooks(t, remediator.HooksConfig{
+		Pre:  []remediator.HookConfig{{Name: "freeze", URL: server.URL}},
+		Post: []remediator.HookConfig{{Name: "alerts", URL: server.URL}},
+	}, 0)
+
+	assert.Equal(t, len(client.Actions()), 0)
+	record := history.Recent("", 0)[0]
+	assert.Equal(t, record.Outcome, "skipped")
+	assert.Equal(t, record.Detail, "vetoed by hook: freeze: change freeze")
+	assert.Equal(t, len(requests), 1) // no post hook without an action
+}
+
+func TestFailingHookVetoesUnlessIgnored(t *testing.T) {
+	server, _ := hookServer(http.StatusInternalServerError, "oops")
+	defer server.Close()
+	client, history := remediateWithHooks(t, remediator.HooksConfig{
+		Pre: []remediator.HookConfig{{Name: "freeze", URL: server.URL}},
+	}, 0)
+	assert.Equal(t, len(client.Actions()), 0)
+	assert.Equal(t, history.Recent("", 0)[0].Detail, "hook failed: freeze: 500 Internal Server Error: oops")
+
+	client, _ = remediateWithHooks(t, remediator.HooksConfig{
+		Pre: []remediator.HookConfig{{Name: "freeze", URL: server.URL, IgnoreFailures: true}},
+	}, 0)
+	assert.Equal(t, len(client.Actions()), 1)
+}
+
+func TestFailingHookTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	client, history := remediateWithHooks(t, remediator.HooksConfig{
+		Pre: []remediator.HookConfig{{Name: "slow", URL: server.URL, Timeout: 10 * time.Millisecond}},
+	}, 0)
+	assert.Equal(t, len(client.Actions()), 0)
+	assert.Assert(t, strings.HasPrefix(history.Recent("", 0)[0].Detail, "hook failed: slow: "), history.Recent("", 0)[0].Detail)
+}
+
+func TestExecHookVetoesWithExitCode(t *testing.T) {
+	hooks := remediator.HooksConfig{Pre: []remediator.HookConfig{{Name: "drain", Container: "sidecar", Command: []string{"/drain"}}}}
+	client, history := remediateWithHooks(t, hooks, 1)
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "exec", Kind: "Pod", Namespace: "default", Name: "api-5d8f-x2x"}})
+	assert.Equal(t, history.Recent("", 0)[0].Detail, "vetoed by hook: drain: busy")
+
+	var request remediator.HookRequest
+	stdin := client.ExecInputs("default", "api-5d8f-x2x", "sidecar")
+	assert.Equal(t, len(stdin), 1)
+	assert.NilError(t, json.Unmarshal([]byte(stdin[0]), &request))
+	assert.Equal(t, request.Phase, "pre")
+	assert.Equal(t, request.Action, "deleted")
+
+	client, _ = remediateWithHooks(t, hooks, 0)
+	assert.Equal(t, len(client.Actions()), 2) // exec and delete
+}
+
+func TestHooksOnlyForTheirRemediators(t *testing.T) {
+	hooks := remediator.HooksConfig{Pre: []remediator.HookConfig{{Name: "drain", Container: "sidecar", Command: []string{"/drain"}, Remediators: []string{"OldPodDeleter"}}}}
+	assert.Assert(t, hooks.Build("FailedPodRescheduler") == nil)
+	assert.Assert(t, hooks.Build("oldpoddeleter") != nil)
+}
+
+func TestRejectsInvalidHooks(t *testing.T) {
+	for _, c := range []struct {
+		hook remediator.HookConfig
+		err  string
+	}{
+		{remediator.HookConfig{URL: "http://localhost"}, "hooks.pre[0].name is required"},
+		{remediator.HookConfig{Name: "a"}, "hooks.pre[0]: set either url or container and command"},
+		{remediator.HookConfig{Name: "a", URL: "http://localhost", Command: []string{"true"}}, "hooks.pre[0]: set either url or container and command"},
+		{remediator.HookConfig{Name: "a", Container: "sidecar"}, "hooks.pre[0]: container and command are both required"},
+		{remediator.HookConfig{Name: "a", URL: "http://localhost", Timeout: -time.Second}, "hooks.pre[0].timeout must not be negative"},
+		{remediator.HookConfig{Name: "a", URL: "http://localhost", Remediators: []string{"crash"}}, `hooks.pre[0].remediators: unknown remediator "crash"`},
+	} {
+		assert.ErrorContains(t, remediator.HooksConfig{Pre: []remediator.HookConfig{c.hook}}.Validate(), c.err)
+	}
+	twice := remediator.HookConfig{Name: "a", URL: "http://localhost"}
+	assert.ErrorContains(t, remediator.HooksConfig{Post: []remediator.HookConfig{twice, twice}}.Validate(), `hooks.post[1].name "a" is used twice`)
+}
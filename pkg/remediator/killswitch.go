@@ -0,0 +1,114 @@
+package remediator
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+	"sync/atomic"
+)
+
+// Pauses all remediators while a key is set in a ConfigMap, so incident responders can stop us without a restart:
+// kubectl create configmap kube-remediator-killswitch --from-literal=paused=true
+type KillSwitch struct {
+	logger    *zap.Logger
+	namespace string
+	name      string
+	key       string
+	informer  cache.SharedIndexInformer
+	engaged   int32
+	notifier  notify.Notifier
+}
+
+func NewKillSwitch(logger *zap.Logger, client k8s.ClientInterface, namespace string, name string, key string) (*KillSwitch, error) {
+	informerFactory, err := client.NewSharedInformerFactory(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &KillSwitch{
+		logger:    logger,
+		namespace: namespace,
+		name:      name,
+		key:       key,
+		informer:  informerFactory.Core().V1().ConfigMaps().Informer(),
+	}, nil
+}
+
+// tell humans when the kill switch is engaged or released
+func (k *KillSwitch) UseNotifier(notifier notify.Notifier) {
+	k.notifier = notifier
+}
+
+// a nil KillSwitch is never engaged
+func (k *KillSwitch) Engaged() bool {
+	return k != nil && atomic.LoadInt32(&k.engaged) == 1
+}
+
+func (k *KillSwitch) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer k.logger.Info("Stopping", zap.String("reason", "Signal"))
+	k.logger.Info("Starting")
+
+	k.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    k.update,
+		UpdateFunc: func(oldObj, newObj interface{}) { k.update(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if configMap, ok := obj.(*v1.ConfigMap); ok && configMap.ObjectMeta.Name == k.name {
+				k.set(false)
+			}
+		},
+	})
+	k.informer.Run(ctx.Done())
+}
+
+// false when stopped before the ConfigMap was read, for runs that scan once right away, a nil KillSwitch is synced
+func (k *KillSwitch) WaitForSync(stop <-chan struct{}) bool {
+	if k == nil {
+		return true
+	}
+	if !cache.WaitForCacheSync(stop, k.informer.HasSynced) {
+		return false
+	}
+	// handlers hear of the ConfigMap a little after the cache has it
+	if obj, exists, err := k.informer.GetStore().GetByKey(k.namespace + "/" + k.name); err == nil && exists {
+		k.update(obj)
+	}
+	return true
+}
+
+func (k *KillSwitch) update(obj interface{}) {
+	configMap, ok := obj.(*v1.ConfigMap)
+	if !ok || configMap.ObjectMeta.Name != k.name {
+		return
+	}
+	value, set := configMap.Data[k.key]
+	k.set(set && value != "" && value != "false")
+}
+
+func (k *KillSwitch) set(engaged bool) {
+	var value int32
+	if engaged {
+		value = 1
+	}
+	if atomic.SwapInt32(&k.engaged, value) == value {
+		return
+	}
+	event := notify.Event{Type: notify.EventKillSwitch, Object: audit.ObjectRef{Kind: "ConfigMap", Name: k.name}}
+	if engaged {
+		k.logger.Warn("Kill switch engaged, pausing all remediation", zap.String("configMap", k.name))
+		event.Action = "engaged"
+	} else {
+		k.logger.Info("Kill switch released, resuming remediation", zap.String("configMap", k.name))
+		event.Action = "released"
+	}
+	if k.notifier != nil {
+		k.notifier.Notify(event)
+	}
+}
@@ -0,0 +1,140 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+func killSwitchConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-remediator-killswitch", Namespace: "default"},
+		Data:       data,
+	}
+}
+
+// running KillSwitch watching the fake clientset, stopped by cancelling the context
+func runKillSwitch(t *testing.T, ctx context.Context, wg *sync.WaitGroup, clientSet kubernetes.Interface, notifier notify.Notifier) *remediator.KillSwitch {
+	logger, _ := zap.NewDevelopment()
+	mockClient := mock_k8s.NewMockClientInterface(gomock.NewController(t))
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0, informers.WithNamespace("default"))
+	mockClient.EXPECT().NewSharedInformerFactory("default").Return(informerFactory, nil)
+
+	killSwitch, err := remediator.NewKillSwitch(logger, mockClient, "default", "kube-remediator-killswitch", "paused")
+	assert.Equal(t, err, nil)
+	if notifier != nil {
+		killSwitch.UseNotifier(notifier)
+	}
+	wg.Add(1)
+	go killSwitch.Run(ctx, wg)
+	return killSwitch
+}
+
+func waitForKillSwitch(killSwitch *remediator.KillSwitch, engaged bool) bool {
+	for i := 0; i < 100 && killSwitch.Engaged() != engaged; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return killSwitch.Engaged() == engaged
+}
+
+func TestKillSwitchIsNeverEngagedWhenNil(t *testing.T) {
+	var killSwitch *remediator.KillSwitch
+	assert.Equal(t, killSwitch.Engaged(), false)
+}
+
+func TestKillSwitchEngagesWhileKeyIsSet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	clientSet := fake.NewSimpleClientset()
+	killSwitch := runKillSwitch(t, ctx, &wg, clientSet, nil)
+	assert.Equal(t, killSwitch.Engaged(), false)
+
+	configMaps := clientSet.CoreV1().ConfigMaps("default")
+	_, err := configMaps.Create(killSwitchConfigMap(map[string]string{"paused": "true"}))
+	assert.Equal(t, err, nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, true))
+
+	_, err = configMaps.Update(killSwitchConfigMap(map[string]string{"paused": "false"}))
+	assert.Equal(t, err, nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, false))
+
+	_, err = configMaps.Update(killSwitchConfigMap(map[string]string{"paused": "yes"}))
+	assert.Equal(t, err, nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, true))
+
+	assert.Equal(t, configMaps.Delete("kube-remediator-killswitch", &metav1.DeleteOptions{}), nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, false))
+}
+
+func TestKillSwitchIsEngagedOnceSynced(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	clientSet := fake.NewSimpleClientset(killSwitchConfigMap(map[string]string{"paused": "true"}))
+	killSwitch := runKillSwitch(t, ctx, &wg, clientSet, nil)
+	assert.Assert(t, killSwitch.WaitForSync(ctx.Done()))
+	assert.Equal(t, killSwitch.Engaged(), true) // right away, not when the handler is called
+
+	var none *remediator.KillSwitch
+	assert.Assert(t, none.WaitForSync(ctx.Done()))
+}
+
+func TestKillSwitchIgnoresOtherConfigMaps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	other := killSwitchConfigMap(map[string]string{"paused": "true"})
+	other.ObjectMeta.Name = "other"
+	killSwitch := runKillSwitch(t, ctx, &wg, fake.NewSimpleClientset(other), nil)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, killSwitch.Engaged(), false)
+}
+
+type channelNotifier chan notify.Event
+
+func (c channelNotifier) Notify(event notify.Event) {
+	c <- event
+}
+
+func TestKillSwitchNotifiesWhenEngagedAndReleased(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	notifier := make(channelNotifier, 2)
+	clientSet := fake.NewSimpleClientset()
+	killSwitch := runKillSwitch(t, ctx, &wg, clientSet, notifier)
+
+	configMaps := clientSet.CoreV1().ConfigMaps("default")
+	_, err := configMaps.Create(killSwitchConfigMap(map[string]string{"paused": "true"}))
+	assert.Equal(t, err, nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, true))
+	assert.Equal(t, configMaps.Delete("kube-remediator-killswitch", &metav1.DeleteOptions{}), nil)
+	assert.Assert(t, waitForKillSwitch(killSwitch, false))
+
+	engaged, released := <-notifier, <-notifier
+	assert.Equal(t, engaged.Type, notify.EventKillSwitch)
+	assert.Equal(t, engaged.Action, "engaged")
+	assert.Equal(t, engaged.Object.Name, "kube-remediator-killswitch")
+	assert.Equal(t, released.Action, "released")
+}
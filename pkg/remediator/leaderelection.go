@@ -0,0 +1,143 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/leaderelection"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Lease that elects which of several replicas remediates
+type LeaderElectionConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Namespace     string        `mapstructure:"namespace"`
+	Lease         string        `mapstructure:"lease"`
+	LeaseDuration time.Duration `mapstructure:"leaseDuration"` // others take over this long after the leader last renewed
+	RenewDeadline time.Duration `mapstructure:"renewDeadline"` // the leader stops remediating when it could not renew within this
+	RetryPeriod   time.Duration `mapstructure:"retryPeriod"`   // between attempts to acquire or renew the Lease
+}
+
+func DefaultLeaderElectionConfig() LeaderElectionConfig {
+	return LeaderElectionConfig{
+		Namespace:     "default",
+		Lease:         "kube-remediator",
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+// the leader must give up before others take over, renewing is retried with jitter within the deadline
+func (c LeaderElectionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Lease == "" {
+		return fmt.Errorf("leaderElection.lease is required when leaderElection is enabled")
+	}
+	if c.RetryPeriod <= 0 {
+		return fmt.Errorf("leaderElection.retryPeriod must be positive, got %v", c.RetryPeriod)
+	}
+	if float64(c.RenewDeadline) <= leaderelection.JitterFactor*float64(c.RetryPeriod) {
+		return fmt.Errorf("leaderElection.renewDeadline must be more than %v times retryPeriod, got %v", leaderelection.JitterFactor, c.RenewDeadline)
+	}
+	if c.LeaseDuration <= c.RenewDeadline {
+		return fmt.Errorf("leaderElection.leaseDuration must be more than renewDeadline, got %v", c.LeaseDuration)
+	}
+	return nil
+}
+
+// Lets only one of several replicas remediate, the others keep watching so their caches are warm
+// when they take over after the Lease of the leader expired or it released the Lease when stopping
+type LeaderElection struct {
+	logger  *zap.Logger
+	lease   string
+	elector *leaderelection.LeaderElector
+	leading int32
+	leader  atomic.Value // identity of the last observed leader
+	metrics *metrics.Leader_Metrics
+}
+
+// identity names this replica in the Lease, usually the Pod name
+func NewLeaderElection(logger *zap.Logger, client k8s.ClientInterface, config LeaderElectionConfig, identity string) (*LeaderElection, error) {
+	lock, err := client.NewLeaseLock(config.Namespace, config.Lease, identity)
+	if err != nil {
+		return nil, err
+	}
+	l := &LeaderElection{logger: logger, lease: config.Lease}
+	l.leader.Store("")
+	l.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: config.LeaseDuration,
+		RenewDeadline: config.RenewDeadline,
+		RetryPeriod:   config.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { l.set(true) },
+			OnStoppedLeading: func() { l.set(false) },
+			OnNewLeader: func(leader string) {
+				l.leader.Store(leader)
+				logger.Info("Leader elected", zap.String("lease", config.Lease), zap.String("leader", leader))
+			},
+		},
+		// remediators stop with the same context, so the next leader can start right away
+		ReleaseOnCancel: true,
+		Name:            config.Lease,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// report leadership as a metric
+func (l *LeaderElection) UseMetrics(metrics *metrics.Leader_Metrics) {
+	l.metrics = metrics
+	metrics.SetLeader(l.Leading())
+}
+
+// a nil LeaderElection always leads, so a single replica does not need a Lease
+func (l *LeaderElection) Leading() bool {
+	return l == nil || atomic.LoadInt32(&l.leading) == 1
+}
+
+// for /readyz, followers are ready too so they can take over
+func (l *LeaderElection) Status() string {
+	if l.Leading() {
+		return "leading"
+	}
+	if leader := l.leader.Load().(string); leader != "" {
+		return "following " + leader
+	}
+	return "no leader yet"
+}
+
+// campaign for the Lease until stopped, after losing it this replica follows and campaigns again
+func (l *LeaderElection) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer l.logger.Info("Stopping", zap.String("reason", "Signal"))
+	l.logger.Info("Starting")
+	for ctx.Err() == nil {
+		l.elector.Run(ctx)
+	}
+}
+
+func (l *LeaderElection) set(leading bool) {
+	var value int32
+	if leading {
+		value = 1
+	}
+	l.metrics.SetLeader(leading)
+	if atomic.SwapInt32(&l.leading, value) == value {
+		return
+	}
+	if leading {
+		l.logger.Info("Became leader, remediating", zap.String("lease", l.lease))
+	} else {
+		l.logger.Warn("Lost leadership, only watching", zap.String("lease", l.lease))
+	}
+}
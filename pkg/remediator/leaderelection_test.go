@@ -0,0 +1,107 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Lease held by another replica that renewed it just now
+func heldLease(holder string) *coordinationv1.Lease {
+	duration := int32(60)
+	now := metav1.NewMicroTime(time.Now())
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-remediator", Namespace: "default"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+}
+
+// running LeaderElection for the Lease in the fake clientset, stopped by cancelling the context
+func runLeaderElection(t *testing.T, ctx context.Context, wg *sync.WaitGroup, clientSet kubernetes.Interface) *remediator.LeaderElection {
+	logger, _ := zap.NewDevelopment()
+	mockClient := mock_k8s.NewMockClientInterface(gomock.NewController(t))
+	mockClient.EXPECT().NewLeaseLock("default", "kube-remediator", "replica-1").Return(&resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: "default", Name: "kube-remediator"},
+		Client:     clientSet.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: "replica-1"},
+	}, nil)
+
+	config := remediator.DefaultLeaderElectionConfig()
+	config.Enabled = true
+	config.LeaseDuration, config.RenewDeadline, config.RetryPeriod = time.Second, 500*time.Millisecond, 10*time.Millisecond
+	leader, err := remediator.NewLeaderElection(logger, mockClient, config, "replica-1")
+	assert.Equal(t, err, nil)
+	wg.Add(1)
+	go leader.Run(ctx, wg)
+	return leader
+}
+
+func waitForLeading(leader *remediator.LeaderElection) bool {
+	for i := 0; i < 100 && !leader.Leading(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return leader.Leading()
+}
+
+func TestLeaderElectionAlwaysLeadsWhenNil(t *testing.T) {
+	var leader *remediator.LeaderElection
+	assert.Equal(t, leader.Leading(), true)
+	assert.Equal(t, leader.Status(), "leading")
+}
+
+func TestLeadsWhenLeaseIsFree(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	clientSet := fake.NewSimpleClientset()
+	leader := runLeaderElection(t, ctx, &wg, clientSet)
+	assert.Assert(t, waitForLeading(leader))
+	assert.Equal(t, leader.Status(), "leading")
+
+	lease, err := clientSet.CoordinationV1().Leases("default").Get("kube-remediator", metav1.GetOptions{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *lease.Spec.HolderIdentity, "replica-1")
+}
+
+func TestFollowsWhileLeaseIsHeld(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	leader := runLeaderElection(t, ctx, &wg, fake.NewSimpleClientset(heldLease("replica-2")))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, leader.Leading(), false)
+	assert.Equal(t, leader.Status(), "following replica-2")
+}
+
+func TestTakesOverExpiredLease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	lease := heldLease("replica-2")
+	renewed := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	lease.Spec.RenewTime = &renewed
+	leader := runLeaderElection(t, ctx, &wg, fake.NewSimpleClientset(lease))
+	assert.Assert(t, waitForLeading(leader))
+}
@@ -0,0 +1,199 @@
+package remediator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Recurring time of day on some days of the week, end before start means it ends the next day
+type Window struct {
+	Days  map[time.Weekday]bool
+	Start time.Duration // since midnight
+	End   time.Duration
+}
+
+type WindowConfig struct {
+	Days  string `mapstructure:"days"`  // "Mon-Fri", "Sat,Sun", "" for every day
+	Start string `mapstructure:"start"` // "22:00"
+	End   string `mapstructure:"end"`   // "06:00"
+}
+
+type ScheduleConfig struct {
+	TimeZone  string         `mapstructure:"timeZone"`
+	Windows   []WindowConfig `mapstructure:"windows"`
+	Blackouts []WindowConfig `mapstructure:"blackouts"`
+}
+
+type MaintenanceConfig struct {
+	ScheduleConfig `mapstructure:",squash"`
+	Remediators    map[string]ScheduleConfig `mapstructure:"remediators"`
+	Namespaces     map[string]ScheduleConfig `mapstructure:"namespaces"`
+}
+
+// When remediators may act, outside of it they still detect and log but do not act
+type Schedule struct {
+	Location  *time.Location
+	Windows   []Window // only act inside these, none means always
+	Blackouts []Window // never act inside these
+}
+
+// Schedule of a single remediator with per namespace overrides
+type Maintenance struct {
+	Default    *Schedule
+	Namespaces map[string]*Schedule
+}
+
+func ParseWindow(config WindowConfig) (Window, error) {
+	window := Window{Days: map[time.Weekday]bool{}}
+	var err error
+	if window.Start, err = parseTimeOfDay(config.Start); err != nil {
+		return window, err
+	}
+	if window.End, err = parseTimeOfDay(config.End); err != nil {
+		return window, err
+	}
+
+	if strings.TrimSpace(config.Days) == "" {
+		for _, day := range weekdays {
+			window.Days[day] = true
+		}
+		return window, nil
+	}
+	for _, part := range strings.Split(config.Days, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, err := parseWeekday(bounds[0])
+		if err != nil {
+			return window, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseWeekday(bounds[1]); err != nil {
+				return window, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			window.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return window, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if len(name) >= 3 {
+		if day, ok := weekdays[name[:3]]; ok {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", value)
+}
+
+func (w Window) Contains(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	day := t.Weekday()
+	switch {
+	case w.Start == w.End: // all day
+		return w.Days[day]
+	case w.Start < w.End:
+		return w.Days[day] && sinceMidnight >= w.Start && sinceMidnight < w.End
+	default: // over midnight
+		yesterday := (day + 6) % 7
+		return (w.Days[day] && sinceMidnight >= w.Start) || (w.Days[yesterday] && sinceMidnight < w.End)
+	}
+}
+
+func ParseSchedule(config ScheduleConfig) (*Schedule, error) {
+	location, err := time.LoadLocation(config.TimeZone) // "" is UTC
+	if err != nil {
+		return nil, err
+	}
+	schedule := &Schedule{Location: location}
+	for _, windowConfig := range config.Windows {
+		window, err := ParseWindow(windowConfig)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Windows = append(schedule.Windows, window)
+	}
+	for _, windowConfig := range config.Blackouts {
+		window, err := ParseWindow(windowConfig)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Blackouts = append(schedule.Blackouts, window)
+	}
+	return schedule, nil
+}
+
+// a nil Schedule always allows
+func (s *Schedule) Allows(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	t = t.In(s.Location)
+	for _, blackout := range s.Blackouts {
+		if blackout.Contains(t) {
+			return false
+		}
+	}
+	if len(s.Windows) == 0 {
+		return true
+	}
+	for _, window := range s.Windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Maintenance for a remediator, using its own schedule when configured and the global one otherwise
+func (c MaintenanceConfig) Build(remediator string) (*Maintenance, error) {
+	scheduleConfig := c.ScheduleConfig
+	for name, config := range c.Remediators {
+		if strings.EqualFold(name, remediator) { // viper lowercases keys
+			scheduleConfig = config
+		}
+	}
+
+	var err error
+	maintenance := &Maintenance{Namespaces: map[string]*Schedule{}}
+	if maintenance.Default, err = ParseSchedule(scheduleConfig); err != nil {
+		return nil, err
+	}
+	for namespace, config := range c.Namespaces {
+		if maintenance.Namespaces[namespace], err = ParseSchedule(config); err != nil {
+			return nil, err
+		}
+	}
+	return maintenance, nil
+}
+
+// a nil Maintenance always allows
+func (m *Maintenance) Allows(namespace string, t time.Time) bool {
+	if m == nil {
+		return true
+	}
+	if schedule, ok := m.Namespaces[namespace]; ok {
+		return schedule.Allows(t)
+	}
+	return m.Default.Allows(t)
+}
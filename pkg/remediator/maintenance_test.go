@@ -0,0 +1,95 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/stretchr/testify/suite"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+type TestMaintenanceSuite struct {
+	suite.Suite
+	config remediator.MaintenanceConfig
+	t      *testing.T
+}
+
+func TestSuiteMaintenance(t *testing.T) {
+	suite.Run(t, &TestMaintenanceSuite{t: t})
+}
+
+func (suite *TestMaintenanceSuite) SetupTest() {
+	suite.config = remediator.MaintenanceConfig{
+		ScheduleConfig: remediator.ScheduleConfig{
+			Windows: []remediator.WindowConfig{{Days: "Mon-Fri", Start: "22:00", End: "06:00"}},
+		},
+	}
+}
+
+// 2019-09-02 is a Monday
+func at(day int, hour int) time.Time {
+	return time.Date(2019, 9, day, hour, 0, 0, 0, time.UTC)
+}
+
+func (suite *TestMaintenanceSuite) build(remediatorName string) *remediator.Maintenance {
+	maintenance, err := suite.config.Build(remediatorName)
+	assert.Equal(suite.t, err, nil)
+	return maintenance
+}
+
+func (suite *TestMaintenanceSuite) TestAllowsEverythingWhenNil() {
+	var maintenance *remediator.Maintenance
+	assert.Equal(suite.t, maintenance.Allows("default", at(2, 12)), true)
+}
+
+func (suite *TestMaintenanceSuite) TestAllowsInsideWindowsOverMidnight() {
+	maintenance := suite.build("OldPodDeleter")
+	assert.Equal(suite.t, maintenance.Allows("default", at(2, 23)), true) // Monday night
+	assert.Equal(suite.t, maintenance.Allows("default", at(3, 5)), true)  // Tuesday morning
+	assert.Equal(suite.t, maintenance.Allows("default", at(3, 12)), false)
+	assert.Equal(suite.t, maintenance.Allows("default", at(7, 5)), true)   // Saturday morning after Friday
+	assert.Equal(suite.t, maintenance.Allows("default", at(7, 23)), false) // Saturday night
+	assert.Equal(suite.t, maintenance.Allows("default", at(2, 5)), false)  // Monday morning after Sunday
+}
+
+func (suite *TestMaintenanceSuite) TestDeniesInsideBlackouts() {
+	suite.config.Windows = nil
+	suite.config.Blackouts = []remediator.WindowConfig{{Days: "Mon-Fri", Start: "09:00", End: "17:00"}}
+	maintenance := suite.build("OldPodDeleter")
+	assert.Equal(suite.t, maintenance.Allows("default", at(2, 12)), false)
+	assert.Equal(suite.t, maintenance.Allows("default", at(2, 18)), true)
+	assert.Equal(suite.t, maintenance.Allows("default", at(1, 12)), true) // Sunday
+}
+
+func (suite *TestMaintenanceSuite) TestUsesTimeZone() {
+	suite.config.TimeZone = "America/New_York"
+	maintenance := suite.build("OldPodDeleter")
+	assert.Equal(suite.t, maintenance.Allows("default", at(3, 8)), true)   // 04:00 in New York
+	assert.Equal(suite.t, maintenance.Allows("default", at(3, 23)), false) // 19:00 in New York
+}
+
+func (suite *TestMaintenanceSuite) TestUsesRemediatorAndNamespaceOverrides() {
+	suite.config.Remediators = map[string]remediator.ScheduleConfig{"oldpoddeleter": {}}
+	suite.config.Namespaces = map[string]remediator.ScheduleConfig{
+		"payments": {Windows: []remediator.WindowConfig{{Days: "Sat,Sun", Start: "00:00", End: "00:00"}}},
+	}
+	assert.Equal(suite.t, suite.build("OldPodDeleter").Allows("default", at(3, 12)), true)
+	assert.Equal(suite.t, suite.build("CompletedPodDeleter").Allows("default", at(3, 12)), false)
+	assert.Equal(suite.t, suite.build("OldPodDeleter").Allows("payments", at(3, 12)), false)
+	assert.Equal(suite.t, suite.build("OldPodDeleter").Allows("payments", at(7, 12)), true)
+}
+
+func (suite *TestMaintenanceSuite) TestFailsOnInvalidConfig() {
+	for _, window := range []remediator.WindowConfig{
+		{Days: "Mon-Foo", Start: "22:00", End: "06:00"},
+		{Days: "Mon", Start: "25:00", End: "06:00"},
+		{Days: "Mon", Start: "22:00", End: ""},
+	} {
+		suite.config.Windows = []remediator.WindowConfig{window}
+		_, err := suite.config.Build("OldPodDeleter")
+		assert.Assert(suite.t, err != nil)
+	}
+	suite.config.TimeZone = "Mars/Olympus"
+	_, err := suite.config.Build("OldPodDeleter")
+	assert.Assert(suite.t, err != nil)
+}
@@ -0,0 +1,46 @@
+package remediator
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"strconv"
+)
+
+// Settings namespace owners set as annotations on their Namespace, so they can tune remediation without access to
+// the config. Namespace overrides from the config win over them, invalid values are ignored.
+type NamespaceAnnotations struct {
+	// "kube-remediator/" reads kube-remediator/disabled, kube-remediator/failure-threshold and kube-remediator/dry-run
+	Prefix string
+
+	Namespaces *k8s.NamespaceCache
+}
+
+// value of the annotation on the namespace, "" when it is not set or the namespace is unknown
+func (a *NamespaceAnnotations) get(namespace string, name string) string {
+	if a == nil || a.Namespaces == nil {
+		return ""
+	}
+	ns, err := a.Namespaces.GetNamespace(namespace)
+	if err != nil {
+		return ""
+	}
+	return ns.ObjectMeta.Annotations[a.Prefix+name]
+}
+
+// no remediator acts in the namespace
+func (a *NamespaceAnnotations) disabled(namespace string) bool {
+	return a.get(namespace, "disabled") == "true"
+}
+
+// restarts before CrashLoopBackOffRescheduler acts, 0 when not set
+func (a *NamespaceAnnotations) failureThreshold(namespace string) int32 {
+	threshold, err := strconv.ParseInt(a.get(namespace, "failure-threshold"), 10, 32)
+	if err != nil || threshold < 1 {
+		return 0
+	}
+	return int32(threshold)
+}
+
+// only turns dry run on, namespace owners must not make remediators act when the config says not to
+func (a *NamespaceAnnotations) dryRun(namespace string) bool {
+	return a.get(namespace, "dry-run") == "true"
+}
@@ -0,0 +1,127 @@
+package remediator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// glob like "team-*" or regex wrapped in slashes like "/^team-[a-z]+$/"
+type namespacePattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+func (p namespacePattern) matches(namespace string) bool {
+	if p.regexp != nil {
+		return p.regexp.MatchString(namespace)
+	}
+	matched, _ := path.Match(p.glob, namespace) // pattern was validated when parsing
+	return matched
+}
+
+// a single namespace name without wildcards
+func (p namespacePattern) plain() bool {
+	return p.regexp == nil && !strings.ContainsAny(p.glob, `*?[\`)
+}
+
+// Namespaces remediators act in, everything matching an include pattern (or everything when there are none) that
+// does not match an exclude pattern
+type NamespaceFilter struct {
+	include []namespacePattern
+	exclude []namespacePattern
+}
+
+func NewNamespaceFilter(include []string, exclude []string) (*NamespaceFilter, error) {
+	filter := &NamespaceFilter{}
+	var err error
+	if filter.include, err = parseNamespacePatterns(include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = parseNamespacePatterns(exclude); err != nil {
+		return nil, err
+	}
+	// an include that is always excluded is a mistake, nothing would be remediated in it
+	for _, pattern := range filter.include {
+		if pattern.plain() {
+			for _, excluded := range filter.exclude {
+				if excluded.matches(pattern.glob) {
+					return nil, fmt.Errorf("namespace %q is both included and excluded", pattern.glob)
+				}
+			}
+		}
+	}
+	return filter, nil
+}
+
+func parseNamespacePatterns(values []string) ([]namespacePattern, error) {
+	var patterns []namespacePattern
+	for _, value := range values {
+		if len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			compiled, err := regexp.Compile(value[1 : len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace regex %q: %v", value, err)
+			}
+			patterns = append(patterns, namespacePattern{regexp: compiled})
+		} else {
+			if _, err := path.Match(value, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace glob %q: %v", value, err)
+			}
+			patterns = append(patterns, namespacePattern{glob: value})
+		}
+	}
+	return patterns, nil
+}
+
+// a nil NamespaceFilter matches everything
+func (f *NamespaceFilter) Matches(namespace string) bool {
+	if f == nil {
+		return true
+	}
+	for _, pattern := range f.exclude {
+		if pattern.matches(namespace) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if pattern.matches(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludes the plain excluded namespaces, so the api-server already leaves their Pods out of lists,
+// "" when there are none
+func (f *NamespaceFilter) FieldSelector() string {
+	if f == nil {
+		return ""
+	}
+	var selectors []string
+	for _, exclude := range f.exclude {
+		if exclude.plain() {
+			selectors = append(selectors, "metadata.namespace!="+exclude.glob)
+		}
+	}
+	return strings.Join(selectors, ",")
+}
+
+// namespaces to list/watch Pods in, [""] for all namespaces unless only plain namespaces are included,
+// so remediators also work with RBAC in just a few namespaces
+func (f *NamespaceFilter) ListNamespaces() []string {
+	if f == nil || len(f.include) == 0 {
+		return []string{""}
+	}
+	var namespaces []string
+	for _, include := range f.include {
+		if !include.plain() {
+			return []string{""}
+		}
+		namespaces = append(namespaces, include.glob)
+	}
+	return namespaces
+}
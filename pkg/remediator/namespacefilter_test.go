@@ -0,0 +1,70 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestNamespaceFilterMatchesEverythingWhenNil(t *testing.T) {
+	var filter *remediator.NamespaceFilter
+	assert.Equal(t, filter.Matches("kube-system"), true)
+	assert.DeepEqual(t, filter.ListNamespaces(), []string{""})
+}
+
+func TestNamespaceFilterMatchesGlobsAndRegex(t *testing.T) {
+	filter, err := remediator.NewNamespaceFilter([]string{"team-*", "/^app-[0-9]+$/"}, []string{"team-infra"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, filter.Matches("team-a"), true)
+	assert.Equal(t, filter.Matches("app-12"), true)
+	assert.Equal(t, filter.Matches("app-x"), false)
+	assert.Equal(t, filter.Matches("team-infra"), false)
+	assert.Equal(t, filter.Matches("kube-system"), false)
+	assert.DeepEqual(t, filter.ListNamespaces(), []string{""})
+}
+
+func TestNamespaceFilterOnlyExcludes(t *testing.T) {
+	filter, err := remediator.NewNamespaceFilter([]string{}, []string{"kube-*"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, filter.Matches("default"), true)
+	assert.Equal(t, filter.Matches("kube-system"), false)
+}
+
+func TestNamespaceFilterListsSinglePlainNamespace(t *testing.T) {
+	filter, err := remediator.NewNamespaceFilter([]string{"default"}, []string{})
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, filter.ListNamespaces(), []string{"default"})
+}
+
+func TestNamespaceFilterListsEachPlainNamespace(t *testing.T) {
+	filter, err := remediator.NewNamespaceFilter([]string{"team-a", "team-b"}, []string{})
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, filter.ListNamespaces(), []string{"team-a", "team-b"})
+
+	filter, err = remediator.NewNamespaceFilter([]string{"team-a", "app-*"}, []string{})
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, filter.ListNamespaces(), []string{""})
+}
+
+func TestNamespaceFilterFailsOnInvalidPatterns(t *testing.T) {
+	_, err := remediator.NewNamespaceFilter([]string{"/[/"}, []string{})
+	assert.Assert(t, err != nil)
+	_, err = remediator.NewNamespaceFilter([]string{}, []string{"[-"})
+	assert.Assert(t, err != nil)
+}
+
+func TestNamespaceFilterFailsOnContradictions(t *testing.T) {
+	_, err := remediator.NewNamespaceFilter([]string{"team-a"}, []string{"team-*"})
+	assert.ErrorContains(t, err, `namespace "team-a" is both included and excluded`)
+	_, err = remediator.NewNamespaceFilter([]string{"team-*"}, []string{"team-a"})
+	assert.NilError(t, err)
+}
+
+func TestNamespaceFilterExcludesPlainNamespacesInFieldSelector(t *testing.T) {
+	filter, err := remediator.NewNamespaceFilter([]string{}, []string{"kube-system", "team-*", "monitoring"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, filter.FieldSelector(), "metadata.namespace!=kube-system,metadata.namespace!=monitoring")
+
+	var nilFilter *remediator.NamespaceFilter
+	assert.Equal(t, nilFilter.FieldSelector(), "")
+}
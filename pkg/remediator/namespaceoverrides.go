@@ -0,0 +1,113 @@
+package remediator
+
+import (
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"k8s.io/apimachinery/pkg/labels"
+	"time"
+)
+
+type NamespaceOverrideConfig struct {
+	Namespaces        []string      `mapstructure:"namespaces"`        // names, globs or /regex/
+	NamespaceSelector string        `mapstructure:"namespaceSelector"` // labels of the Namespace
+	FailureThreshold  int32         `mapstructure:"failureThreshold"`
+	Interval          time.Duration `mapstructure:"interval"`
+	OwnerCooldown     time.Duration `mapstructure:"ownerCooldown"`
+	DryRun            *bool         `mapstructure:"dryRun"`
+}
+
+// Settings for some namespaces that differ from the global ones, unset values use the global ones
+type NamespaceOverride struct {
+	namespaces *NamespaceFilter
+	selector   labels.Selector
+
+	FailureThreshold int32     // 0 means global
+	Interval         *Cooldown // remediate at most one Pod in the namespace per interval, nil means global
+	Cooldown         *Cooldown // nil means global
+	DryRun           *bool     // nil means global
+}
+
+// Overrides of which the first matching a namespace is used, so prod and dev namespaces can be treated differently
+type NamespaceOverrides struct {
+	overrides []*NamespaceOverride
+
+	// needed to match namespaceSelector
+	Namespaces *k8s.NamespaceCache
+}
+
+func NewNamespaceOverrides(configs []NamespaceOverrideConfig) (*NamespaceOverrides, error) {
+	overrides := &NamespaceOverrides{}
+	for i, config := range configs {
+		if len(config.Namespaces) == 0 && config.NamespaceSelector == "" {
+			return nil, fmt.Errorf("namespace override needs namespaces or namespaceSelector")
+		}
+		if config.FailureThreshold < 0 || config.Interval < 0 || config.OwnerCooldown < 0 {
+			return nil, fmt.Errorf("namespace override %d: failureThreshold, interval and ownerCooldown must not be negative", i)
+		}
+		override := &NamespaceOverride{FailureThreshold: config.FailureThreshold, DryRun: config.DryRun}
+		var err error
+		if len(config.Namespaces) > 0 {
+			if override.namespaces, err = NewNamespaceFilter(config.Namespaces, nil); err != nil {
+				return nil, err
+			}
+		}
+		if config.NamespaceSelector != "" {
+			if override.selector, err = labels.Parse(config.NamespaceSelector); err != nil {
+				return nil, err
+			}
+		}
+		if config.Interval > 0 {
+			override.Interval = NewCooldown(config.Interval)
+		}
+		if config.OwnerCooldown > 0 {
+			override.Cooldown = NewCooldown(config.OwnerCooldown)
+		}
+		overrides.overrides = append(overrides.overrides, override)
+	}
+	return overrides, nil
+}
+
+// owner cooldowns and namespace intervals of all overrides, for /debug/state
+func (o *NamespaceOverrides) State() (cooldowns map[string]time.Time, intervals map[string]time.Time) {
+	cooldowns, intervals = map[string]time.Time{}, map[string]time.Time{}
+	if o == nil {
+		return
+	}
+	for _, override := range o.overrides {
+		for owner, last := range override.Cooldown.State() {
+			cooldowns[owner] = last
+		}
+		for namespace, last := range override.Interval.State() {
+			intervals[namespace] = last
+		}
+	}
+	return
+}
+
+// Namespaces need to be set
+func (o *NamespaceOverrides) UsesNamespaceSelector() bool {
+	for _, override := range o.overrides {
+		if override.selector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// first override matching the namespace, nil when there is none
+func (o *NamespaceOverrides) For(namespace string) *NamespaceOverride {
+	if o == nil {
+		return nil
+	}
+	for _, override := range o.overrides {
+		if override.namespaces != nil && override.namespaces.Matches(namespace) {
+			return override
+		}
+		if override.selector != nil && o.Namespaces != nil {
+			if ns, err := o.Namespaces.GetNamespace(namespace); err == nil && override.selector.Matches(labels.Set(ns.ObjectMeta.Labels)) {
+				return override
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestNamespaceOverridesMatchNothingWhenNil(t *testing.T) {
+	var overrides *remediator.NamespaceOverrides
+	assert.Assert(t, overrides.For("default") == nil)
+}
+
+func TestNamespaceOverridesUseFirstMatchByName(t *testing.T) {
+	overrides, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{
+		{Namespaces: []string{"dev-*"}, FailureThreshold: 3},
+		{Namespaces: []string{"dev-a", "prod"}, FailureThreshold: 10},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, overrides.For("dev-a").FailureThreshold, int32(3))
+	assert.Equal(t, overrides.For("prod").FailureThreshold, int32(10))
+	assert.Assert(t, overrides.For("default") == nil)
+	assert.Equal(t, overrides.UsesNamespaceSelector(), false)
+}
+
+func TestNamespaceOverridesMatchByNamespaceLabels(t *testing.T) {
+	overrides, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{
+		{NamespaceSelector: "env=prod", FailureThreshold: 10},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, overrides.UsesNamespaceSelector(), true)
+
+	clientSet := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	)
+	mockClient := mock_k8s.NewMockClientInterface(gomock.NewController(t))
+	mockClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	overrides.Namespaces, err = k8s.NewNamespaceCache(mockClient)
+	assert.Equal(t, err, nil)
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.Equal(t, overrides.Namespaces.Start(stop), true)
+
+	assert.Equal(t, overrides.For("payments").FailureThreshold, int32(10))
+	assert.Assert(t, overrides.For("default") == nil)
+	assert.Assert(t, overrides.For("unknown") == nil)
+}
+
+func TestNamespaceOverridesFailOnInvalidConfig(t *testing.T) {
+	for _, config := range []remediator.NamespaceOverrideConfig{
+		{FailureThreshold: 3},
+		{Namespaces: []string{"/[/"}},
+		{NamespaceSelector: "env in"},
+		{Namespaces: []string{"dev"}, FailureThreshold: -1},
+		{Namespaces: []string{"dev"}, Interval: -time.Minute},
+	} {
+		_, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{config})
+		assert.Assert(t, err != nil)
+	}
+}
@@ -0,0 +1,146 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nodeActionCordon     = "cordon"
+	nodeActionReschedule = "reschedule"
+)
+
+type NodeProblemConfig struct {
+	Conditions map[string][]string `mapstructure:"conditions"` // condition type -> actions
+}
+
+func DefaultNodeProblemConfig() NodeProblemConfig {
+	return NodeProblemConfig{Conditions: map[string][]string{
+		"KernelDeadlock":         {nodeActionCordon, nodeActionReschedule},
+		"ReadonlyFilesystem":     {nodeActionCordon, nodeActionReschedule},
+		"FrequentKubeletRestart": {nodeActionCordon},
+	}}
+}
+
+func (c NodeProblemConfig) Validate() error {
+	for condition, actions := range c.Conditions {
+		for _, action := range actions {
+			if action != nodeActionCordon && action != nodeActionReschedule {
+				return fmt.Errorf("unknown action %q for condition %q", action, condition)
+			}
+		}
+	}
+	return nil
+}
+
+// Reacts to node conditions reported by node-problem-detector (KernelDeadlock, ReadonlyFilesystem, ...)
+type NodeProblemRemediator struct {
+	Base
+	Config     NodeProblemConfig
+	conditions map[string][]string // lowercase condition type -> actions
+	filters    FilterChain
+}
+
+func init() {
+	Register("NodeProblemRemediator", func(configs Configs) Remediator {
+		return &NodeProblemRemediator{Config: configs.NodeProblemRemediator}
+	})
+}
+
+func (p *NodeProblemRemediator) Name() string {
+	return "NodeProblemRemediator"
+}
+
+func (p *NodeProblemRemediator) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	if err := p.Config.Validate(); err != nil {
+		return err
+	}
+	logger.Sugar().Infof("Config %v", p.Config.Conditions)
+
+	// viper lowercases keys, so conditions are matched case-insensitive
+	p.conditions = map[string][]string{}
+	for condition, actions := range p.Config.Conditions {
+		p.conditions[strings.ToLower(condition)] = actions
+	}
+
+	p.filters = FilterChain{
+		OwnerFilter(),
+		OwnerKindFilter("DaemonSet"), // DaemonSet pods would come right back on the same node
+	}
+
+	// we cordon Nodes ourselves before rescheduling their Pods
+	nodePolicy := *policy
+	nodePolicy.Nodes = nil
+	return p.Base.Setup(logger, client, &nodePolicy)
+}
+
+func (p *NodeProblemRemediator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	p.reconcileEvery(ctx, p.remediateNodes, 1*time.Minute)
+}
+
+func (p *NodeProblemRemediator) remediateNodes(ctx context.Context) {
+	p.logger.Info("Running")
+
+	_, span := p.policy.Tracer.Start(ctx, "list Nodes", tracing.KindClient, nil)
+	nodes, err := p.client.GetNodes(ctx, metav1.ListOptions{})
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		p.logger.Error("Error getting node list", zap.Error(err))
+		p.listFailed()
+		return
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		actions, reason := p.actionsFor(node)
+		if actions[nodeActionCordon] && !node.Spec.Unschedulable {
+			p.cordonNode(ctx, node, reason)
+		}
+		if actions[nodeActionReschedule] {
+			p.reschedulePods(ctx, node, reason)
+		}
+	}
+}
+
+// union of all actions configured for the problem conditions the node currently has,
+// and those conditions as reason
+func (p *NodeProblemRemediator) actionsFor(node *v1.Node) (map[string]bool, string) {
+	actions := map[string]bool{}
+	var problems []string
+	for _, condition := range node.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		configured := p.conditions[strings.ToLower(string(condition.Type))]
+		for _, action := range configured {
+			actions[action] = true
+		}
+		if len(configured) > 0 {
+			problems = append(problems, string(condition.Type))
+		}
+	}
+	return actions, strings.Join(problems, ",")
+}
+
+func (p *NodeProblemRemediator) reschedulePods(ctx context.Context, node *v1.Node, reason string) {
+	pods := p.listPods(ctx, p.policy.Namespaces.ListNamespaces(), metav1.ListOptions{FieldSelector: "spec.nodeName=" + node.ObjectMeta.Name}, p.shouldReschedule)
+
+	for _, pod := range pods {
+		p.remediatePod(ctx, pod, reason, ActionEvict, p.shouldReschedule)
+	}
+}
+
+func (p *NodeProblemRemediator) shouldReschedule(pod *v1.Pod) bool {
+	return p.passes(p.filters, pod)
+}
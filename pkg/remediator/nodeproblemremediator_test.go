@@ -0,0 +1,154 @@
+package remediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sync"
+	"testing"
+	"time"
+)
+
+type TestNodeProblemRemediatorSuite struct {
+	suite.Suite
+	logger         *zap.Logger
+	mockController *gomock.Controller
+	mockClient     *mock_k8s.MockClientInterface
+	nodes          []corev1.Node
+	pods           []corev1.Pod
+	policy         remediator.Policy
+	t              *testing.T
+}
+
+func TestSuiteNodeProblemRemediator(t *testing.T) {
+	suite.Run(t, &TestNodeProblemRemediatorSuite{t: t})
+}
+
+func (suite *TestNodeProblemRemediatorSuite) SetupTest() {
+	suite.logger, _ = zap.NewDevelopment()
+	suite.mockController = gomock.NewController(suite.t)
+	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
+	suite.nodes = []corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: "Ready", Status: corev1.ConditionTrue},
+				{Type: "KernelDeadlock", Status: corev1.ConditionTrue},
+			},
+		},
+	}}
+	suite.pods = []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "controller"}},
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1"},
+	}}
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TearDownTest() {
+	suite.mockController.Finish()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) run() {
+	r := remediator.NodeProblemRemediator{Config: remediator.DefaultNodeProblemConfig()}
+	err := r.Setup(suite.logger, suite.mockClient, &suite.policy)
+	assert.Equal(suite.t, err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // cancel first so we can just run once and exit
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	r.Run(ctx, &wg)
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestCordonsAndReschedules() {
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestOnlyCordonsWhenConfigured() {
+	suite.nodes[0].Status.Conditions[1].Type = "FrequentKubeletRestart"
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestDoesNotCordonCordonedNode() {
+	suite.nodes[0].Spec.Unschedulable = true
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestDoesNotCordonOutsideMaintenanceWindow() {
+	allDay := remediator.ScheduleConfig{Blackouts: []remediator.WindowConfig{{Start: "00:00", End: "00:00"}}}
+	maintenance, err := remediator.MaintenanceConfig{ScheduleConfig: allDay}.Build("NodeProblemRemediator")
+	assert.Equal(suite.t, err, nil)
+	suite.policy.Maintenance = maintenance
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestCordonsWithinRateLimit() {
+	suite.policy.RateLimiter = remediator.NewRateLimiter(suite.logger, 1, time.Hour)
+	second := *suite.nodes[0].DeepCopy()
+	second.ObjectMeta.Name = "node-2"
+	suite.nodes = append(suite.nodes, second)
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{}, nil).Times(2)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestDoesNotCordonNodeInCooldown() {
+	suite.policy.Cooldown = remediator.NewCooldown(time.Hour)
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil).Times(2)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{}, nil).Times(2)
+	suite.run()
+	suite.run() // the Node flaps back before the cordon shows
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestIgnoresResolvedConditions() {
+	suite.nodes[0].Status.Conditions[1].Status = corev1.ConditionFalse
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestKeepsPodsWithoutOwnerOrFromDaemonSets() {
+	daemonSetPod := suite.pods[0]
+	daemonSetPod.ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{}
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, daemonSetPod)}, nil)
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestDoesNotCrashWhenListFails() {
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(nil, errors.New("Foo"))
+	suite.run()
+}
+
+func (suite *TestNodeProblemRemediatorSuite) TestDoesNotCrashWhenCordonFails() {
+	suite.mockClient.EXPECT().GetNodes(gomock.Any(), gomock.Any()).Return(&corev1.NodeList{Items: suite.nodes}, nil)
+	suite.mockClient.EXPECT().CordonNode(gomock.Any(), &suite.nodes[0]).Return(errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, errors.New("Foo"))
+	suite.run()
+}
@@ -0,0 +1,23 @@
+package remediator
+
+import (
+	"strings"
+	"time"
+)
+
+// How long remediators only log what they would do after starting, to validate them on real traffic first
+type ObservationConfig struct {
+	Period      time.Duration            `mapstructure:"period"`
+	Remediators map[string]time.Duration `mapstructure:"remediators"`
+}
+
+// end of the observation period of a remediator started at the given time
+func (c ObservationConfig) Build(remediator string, started time.Time) time.Time {
+	period := c.Period
+	for name, override := range c.Remediators {
+		if strings.EqualFold(name, remediator) { // viper lowercases keys
+			period = override
+		}
+	}
+	return started.Add(period)
+}
@@ -0,0 +1,18 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestObservationConfigUsesRemediatorOverrides(t *testing.T) {
+	config := remediator.ObservationConfig{
+		Period:      time.Minute,
+		Remediators: map[string]time.Duration{"nodeproblemremediator": time.Hour},
+	}
+	started := time.Now()
+	assert.Equal(t, config.Build("NodeProblemRemediator", started), started.Add(time.Hour))
+	assert.Equal(t, config.Build("OldPodDeleter", started), started.Add(time.Minute))
+}
@@ -2,7 +2,9 @@ package remediator
 
 import (
 	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sync"
 	"time"
@@ -10,6 +12,20 @@ import (
 
 type OldPodDeleter struct {
 	Base
+	filters FilterChain
+}
+
+func init() {
+	Register("OldPodDeleter", func(Configs) Remediator { return &OldPodDeleter{} })
+}
+
+func (p *OldPodDeleter) Name() string {
+	return "OldPodDeleter"
+}
+
+func (p *OldPodDeleter) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	p.filters = FilterChain{AgeFilter(24 * time.Hour)}
+	return p.Base.Setup(logger, client, policy)
 }
 
 func (p *OldPodDeleter) Run(ctx context.Context, wg *sync.WaitGroup) {
@@ -18,24 +34,20 @@ func (p *OldPodDeleter) Run(ctx context.Context, wg *sync.WaitGroup) {
 	p.reconcileEvery(ctx, p.deleteOldPods, 1*time.Hour)
 }
 
-func (p *OldPodDeleter) deleteOldPods() {
+func (p *OldPodDeleter) deleteOldPods(ctx context.Context) {
 	p.logger.Info("Running")
 
 	// get all pods that opted in to deletion
-	pods, err := p.client.GetPods("", metav1.ListOptions{
+	// only keep those that are too old
+	pods := p.listPods(ctx, p.policy.Namespaces.ListNamespaces(), p.policy.listOptions(metav1.ListOptions{
 		LabelSelector: "kube-remediator/OldPodDeleter=true",
-	})
-	if err != nil {
-		p.logger.Error("Error getting pod list", zap.Error(err))
-		return
-	}
+	}), p.isOld)
 
-	// deleteOldPods those that are too old
-	cutoff := time.Now().Add(-24 * time.Hour)
-	for _, pod := range pods.Items {
-		if pod.ObjectMeta.CreationTimestamp.Time.After(cutoff) {
-			continue
-		}
-		p.deletePod(pod)
+	for _, pod := range pods {
+		p.remediatePod(ctx, pod, "Old", ActionEvict, p.isOld)
 	}
 }
+
+func (p *OldPodDeleter) isOld(pod *v1.Pod) bool {
+	return p.passes(p.filters, pod)
+}
@@ -3,6 +3,7 @@ package remediator_test
 import (
 	"context"
 	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
 	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
 	"github.com/aksgithub/kube_remediator/pkg/remediator"
 	"github.com/golang/mock/gomock"
@@ -10,7 +11,11 @@ import (
 	"go.uber.org/zap"
 	"gotest.tools/assert"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
 	"sync"
 	"testing"
 	"time"
@@ -22,6 +27,7 @@ type TestOldPodDeleterSuite struct {
 	mockController *gomock.Controller
 	mockClient     *mock_k8s.MockClientInterface
 	pods           []corev1.Pod
+	policy         remediator.Policy
 	t              *testing.T
 }
 
@@ -33,6 +39,7 @@ func (suite *TestOldPodDeleterSuite) SetupTest() {
 	suite.logger, _ = zap.NewDevelopment()
 	suite.mockController = gomock.NewController(suite.t)
 	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
 	suite.pods = []corev1.Pod{{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              "foo",
@@ -49,9 +56,9 @@ func (suite *TestOldPodDeleterSuite) TeardownTest() {
 	suite.mockController.Finish()
 }
 
-func (suite *TestOldPodDeleterSuite) run() {
-	oldPodDeleter := remediator.OldPodDeleter{}
-	err := oldPodDeleter.Setup(suite.logger, suite.mockClient)
+func (suite *TestOldPodDeleterSuite) run() *remediator.OldPodDeleter {
+	oldPodDeleter := &remediator.OldPodDeleter{}
+	err := oldPodDeleter.Setup(suite.logger, suite.mockClient, &suite.policy)
 	assert.Equal(suite.t, err, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -61,27 +68,315 @@ func (suite *TestOldPodDeleterSuite) run() {
 	wg.Add(1)
 
 	oldPodDeleter.Run(ctx, &wg)
+	return oldPodDeleter
+}
+
+func (suite *TestOldPodDeleterSuite) runWithNode(node corev1.Node) {
+	suite.pods[0].Spec.NodeName = node.ObjectMeta.Name
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(&node), 0)
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(informerFactory, nil)
+	nodes, err := k8s.NewNodeCache(suite.mockClient, false)
+	assert.Equal(suite.t, err, nil)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.Equal(suite.t, nodes.Start(stop), true)
+	suite.policy.Nodes = nodes
+	suite.run()
 }
 
 func (suite *TestOldPodDeleterSuite) TestDeletesOldPods() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
 	suite.run()
 }
 
 func (suite *TestOldPodDeleterSuite) TestKeepsNewPods() {
 	suite.pods[0].ObjectMeta.CreationTimestamp = metav1.NewTime(time.Now().Add(-23 * time.Hour))
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestStartsNothingWhileDraining() {
+	suite.policy.Drain = remediator.NewDrain(time.Minute)
+	suite.policy.Drain.Start()
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestEvictsWithDrainContext() {
+	suite.policy.Drain = remediator.NewDrain(time.Minute)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).DoAndReturn(
+		func(ctx context.Context, pod *corev1.Pod, options *metav1.DeleteOptions) error {
+			assert.NilError(suite.t, ctx.Err()) // the scan was cancelled, the eviction is not
+			return nil
+		})
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestDoesNotCrashWhenEvictPanics() {
+	secondPod := suite.pods[0]
+	secondPod.ObjectMeta.Name = "bar"
+	suite.pods = append(suite.pods, secondPod)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Do(
+		func(ctx context.Context, pod *corev1.Pod, options *metav1.DeleteOptions) { panic("bug") })
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[1], gomock.Any()).Return(nil) // still handled
 	suite.run()
 }
 
 func (suite *TestOldPodDeleterSuite) TestDoesNotCrashWhenListFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(nil, errors.New("Foo"))
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, errors.New("Foo"))
+	state := suite.run().State()
+	assert.Assert(suite.t, state.LastProgress.Before(state.LastScan)) // only when the loop started, for the Watchdog
+}
+
+func (suite *TestOldPodDeleterSuite) TestDoesNotCrashWhenEvictFails() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(errors.New("Foo"))
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestListsPodsInPages() {
+	secondPod := suite.pods[0]
+	secondPod.ObjectMeta.Name = "bar"
+	suite.policy.PageSize = 1
+	firstPage := &corev1.PodList{ListMeta: metav1.ListMeta{Continue: "next"}, Items: suite.pods}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(firstPage, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: []corev1.Pod{secondPod}}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &secondPod, gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOfEarlierPagesWhenListFails() {
+	suite.policy.PageSize = 1
+	firstPage := &corev1.PodList{ListMeta: metav1.ListMeta{Continue: "next"}, Items: suite.pods}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(firstPage, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(nil, apierrors.NewResourceExpired("continue expired"))
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodWhenEvictionIsBlocked() {
+	pdbs := &policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-pdb"},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"kube-remediator/OldPodDeleter": "true"}},
+		},
+	}}}
+	suite.policy.DeleteAfterBlockedEvictions = 2
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(&k8s.EvictionBlockedError{Message: "blocked"})
+	suite.mockClient.EXPECT().GetPodDisruptionBudgets(gomock.Any(), "default").Return(pdbs, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestRemembersStateForDebugging() {
+	suite.pods[0].ObjectMeta.UID = "123"
+	suite.policy.DeleteAfterBlockedEvictions = 2
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(&k8s.EvictionBlockedError{Message: "blocked"})
+	suite.mockClient.EXPECT().GetPodDisruptionBudgets(gomock.Any(), "default").Return(&policyv1beta1.PodDisruptionBudgetList{}, nil)
+	state := suite.run().State()
+	assert.Assert(suite.t, time.Since(state.LastScan) < time.Minute)
+	assert.Assert(suite.t, state.LastProgress.After(state.LastScan))
+	assert.Equal(suite.t, state.Unhealthy, 0)
+	assert.DeepEqual(suite.t, state.BlockedEvictions, map[string]int{"123": 1})
+	assert.Equal(suite.t, state.InWindow, true)
+}
+
+func (suite *TestOldPodDeleterSuite) TestDeletesPodWhenEvictionIsBlockedTooOften() {
+	suite.policy.DeleteAfterBlockedEvictions = 1
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(&k8s.EvictionBlockedError{Message: "blocked"})
+	suite.mockClient.EXPECT().GetPodDisruptionBudgets(gomock.Any(), "default").Return(nil, errors.New("Foo"))
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestUsesPodDisruptionBudgetNamedByEviction() {
+	suite.policy.DeleteAfterBlockedEvictions = 1
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).
+		Return(&k8s.EvictionBlockedError{PodDisruptionBudget: "foo-pdb", Message: "blocked"})
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestDoesNotCountThrottledEvictionAsBlocked() {
+	suite.policy.DeleteAfterBlockedEvictions = 1
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).
+		Return(apierrors.NewTooManyRequests("Too many requests, please try again later.", 1))
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestQueuesEvictionsOverRateLimit() {
+	secondPod := suite.pods[0]
+	secondPod.ObjectMeta.Name = "bar"
+	suite.policy.RateLimiter = remediator.NewRateLimiter(suite.logger, 1, time.Hour)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, secondPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOfOwnerInCooldown() {
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "foo"}}
+	secondPod := suite.pods[0]
+	secondPod.ObjectMeta.Name = "bar"
+	suite.policy.Cooldown = remediator.NewCooldown(time.Hour)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, secondPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestEvictsPodsOfDifferentOwnersInCooldown() {
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "foo"}}
+	secondPod := *suite.pods[0].DeepCopy()
+	secondPod.ObjectMeta.Name = "bar"
+	secondPod.ObjectMeta.OwnerReferences[0].Name = "bar"
+	suite.policy.Cooldown = remediator.NewCooldown(time.Hour)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: append(suite.pods, secondPod)}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &secondPod, gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOfOwnerWithTooManyUnavailable() {
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "foo"}}
+	terminating := *suite.pods[0].DeepCopy()
+	terminating.ObjectMeta.Name = "bar"
+	terminating.ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	limit, err := remediator.NewUnavailableLimit("1")
+	assert.Equal(suite.t, err, nil)
+	suite.policy.UnavailableLimit = limit
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "default").Return(&corev1.PodList{Items: append(suite.pods, terminating)}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOfOwnerInBackoff() {
+	suite.pods[0].ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "foo"}}
+	suite.policy.Backoff = remediator.NewBackoff(time.Minute, 5, time.Hour, time.Hour)
+	suite.policy.Backoff.Attempt("default/ReplicaSet/foo")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOutsideMaintenanceWindow() {
+	maintenance, err := remediator.MaintenanceConfig{
+		ScheduleConfig: remediator.ScheduleConfig{
+			Blackouts: []remediator.WindowConfig{{Start: "00:00", End: "00:00"}},
+		},
+	}.Build("OldPodDeleter")
+	assert.Equal(suite.t, err, nil)
+	suite.policy.Maintenance = maintenance
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestEvictsPodsOnSchedulableNodes() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.runWithNode(corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOnCordonedNodes() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.runWithNode(corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+	})
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsOnNodesRemovedByClusterAutoscaler() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.runWithNode(corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+			{Key: "ToBeDeletedByClusterAutoscaler", Effect: corev1.TaintEffectNoSchedule},
+		}},
+	})
+}
+
+func (suite *TestOldPodDeleterSuite) useApproval(requested time.Time, approved string) {
+	suite.policy.Approval = &remediator.Approval{
+		RequestAnnotation: "kube-remediator/approval-requested",
+		ApproveAnnotation: "kube-remediator/approved",
+		Expiry:            time.Hour,
+	}
+	suite.pods[0].ObjectMeta.Annotations = map[string]string{"kube-remediator/approved": approved}
+	if !requested.IsZero() {
+		suite.pods[0].ObjectMeta.Annotations["kube-remediator/approval-requested"] = requested.Format(time.RFC3339)
+	}
+}
+
+func (suite *TestOldPodDeleterSuite) TestRequestsApproval() {
+	suite.useApproval(time.Time{}, "")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().AnnotatePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestWaitsForApproval() {
+	suite.useApproval(time.Now(), "")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestEvictsApprovedPods() {
+	suite.useApproval(time.Now(), "true")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestRenewsExpiredApprovalRequests() {
+	suite.useApproval(time.Now().Add(-2*time.Hour), "true")
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().AnnotatePod(gomock.Any(), &suite.pods[0], gomock.Any()).DoAndReturn(
+		func(_ context.Context, pod *corev1.Pod, annotations map[string]*string) error {
+			approved, ok := annotations["kube-remediator/approved"]
+			assert.Assert(suite.t, ok && approved == nil) // discards the approval
+			return nil
+		})
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestOnlyObservesDuringObservationPeriod() {
+	suite.policy.ObserveUntil = time.Now().Add(time.Hour)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestKeepsPodsWhileKillSwitchEngaged() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	clientSet := fake.NewSimpleClientset(killSwitchConfigMap(map[string]string{"paused": "true"}))
+	suite.policy.KillSwitch = runKillSwitch(suite.t, ctx, &wg, clientSet, nil)
+	assert.Assert(suite.t, waitForKillSwitch(suite.policy.KillSwitch, true))
+
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestOldPodDeleterSuite) TestDoesNotScanWhileFollowing() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	suite.policy.Leader = runLeaderElection(suite.t, ctx, &wg, fake.NewSimpleClientset(heldLease("replica-2")))
 	suite.run()
 }
 
-func (suite *TestOldPodDeleterSuite) TestDoesNotCrashWhenDeleteFails() {
-	suite.mockClient.EXPECT().GetPods("").Return(&corev1.PodList{Items: suite.pods}, nil)
-	suite.mockClient.EXPECT().DeletePod(&suite.pods[0]).Return(errors.New("Foo"))
+func (suite *TestOldPodDeleterSuite) TestOnlyLogsInDryRun() {
+	suite.policy.DryRun = true
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
 	suite.run()
 }
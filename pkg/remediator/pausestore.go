@@ -0,0 +1,58 @@
+package remediator
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"strings"
+)
+
+// Keeps what Controls pause in a ConfigMap, so remediators paused through the admin API stay paused after a restart:
+// kubectl get configmap kube-remediator-paused -o yaml
+type PauseStore struct {
+	client    k8s.ClientInterface
+	namespace string
+	name      string
+}
+
+const (
+	pausedAllKey         = "all"         // "true" pauses all remediators
+	pausedRemediatorsKey = "remediators" // lowercase names, comma separated
+)
+
+func NewPauseStore(client k8s.ClientInterface, namespace string, name string) *PauseStore {
+	return &PauseStore{client: client, namespace: namespace, name: name}
+}
+
+// pauses what was kept, nothing when the ConfigMap does not exist yet
+func (s *PauseStore) Load(ctx context.Context, controls *Controls) error {
+	configMap, err := s.client.GetConfigMap(ctx, s.namespace, s.name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data[pausedAllKey] == "true" {
+		controls.Pause("")
+	}
+	for _, name := range strings.Split(configMap.Data[pausedRemediatorsKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			controls.Pause(name)
+		}
+	}
+	return nil
+}
+
+// keeps what controls pause now, a nil PauseStore keeps nothing
+func (s *PauseStore) Save(ctx context.Context, controls *Controls) error {
+	if s == nil {
+		return nil
+	}
+	all, paused := controls.PausedRemediators()
+	data := map[string]string{pausedAllKey: "false", pausedRemediatorsKey: strings.Join(paused, ",")}
+	if all {
+		data[pausedAllKey] = "true"
+	}
+	return s.client.SetConfigMapData(ctx, s.namespace, s.name, data)
+}
@@ -0,0 +1,50 @@
+package remediator_test
+
+import (
+	"context"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestKeepsPausesAcrossRestarts(t *testing.T) {
+	client := fake.NewClient()
+	store := remediator.NewPauseStore(client, "kube-system", "kube-remediator-paused")
+	ctx := context.Background()
+
+	controls := remediator.NewControls()
+	assert.NilError(t, store.Load(ctx, controls)) // no ConfigMap yet
+	controls.Pause("CrashLoopBackOffRescheduler")
+	controls.Pause("OldPodDeleter")
+	assert.NilError(t, store.Save(ctx, controls))
+	controls.Resume("OldPodDeleter")
+	assert.NilError(t, store.Save(ctx, controls))
+
+	configMap, err := client.GetConfigMap(ctx, "kube-system", "kube-remediator-paused")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, configMap.Data, map[string]string{"all": "false", "remediators": "crashloopbackoffrescheduler"})
+
+	restarted := remediator.NewControls()
+	assert.NilError(t, store.Load(ctx, restarted))
+	assert.Equal(t, restarted.Paused("CrashLoopBackOffRescheduler"), true)
+	assert.Equal(t, restarted.Paused("OldPodDeleter"), false)
+
+	restarted.Pause("")
+	assert.NilError(t, store.Save(ctx, restarted))
+	again := remediator.NewControls()
+	assert.NilError(t, store.Load(ctx, again))
+	all, _ := again.PausedRemediators()
+	assert.Equal(t, all, true)
+}
+
+func TestFailsLoadingPausesWhenTheConfigMapCannotBeRead(t *testing.T) {
+	client := fake.NewClient()
+	client.Fail("GetConfigMap", errors.New("forbidden"))
+	err := remediator.NewPauseStore(client, "default", "kube-remediator-paused").Load(context.Background(), remediator.NewControls())
+	assert.ErrorContains(t, err, "forbidden")
+
+	var store *remediator.PauseStore
+	assert.NilError(t, store.Save(context.Background(), remediator.NewControls()))
+}
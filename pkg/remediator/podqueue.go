@@ -0,0 +1,101 @@
+package remediator
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sync"
+)
+
+// gets of a Pod that fail before the queue gives up on it until the next update or scan
+const podQueueRetries = 5
+
+// Pods between the informer or event stream that noticed them and the remediation: handlers only add the key, so
+// they never wait for the api-server, and workers get the Pod again before handling it, so a Pod updated many
+// times while waiting is handled once with its latest state, failed gets are retried with backoff
+type podQueue struct {
+	logger *zap.Logger
+	client k8s.ClientInterface
+	pods   *k8s.PodCache // nil gets Pods from the api-server
+	queue  workqueue.RateLimitingInterface
+	handle func(context.Context, *v1.Pod)
+	wg     sync.WaitGroup
+}
+
+// name labels the workqueue metrics
+func newPodQueue(logger *zap.Logger, client k8s.ClientInterface, pods *k8s.PodCache, name string, handle func(context.Context, *v1.Pod)) *podQueue {
+	return &podQueue{
+		logger: logger,
+		client: client,
+		pods:   pods,
+		queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		handle: handle,
+	}
+}
+
+// a k8s.PodHandler
+func (q *podQueue) Add(pod *v1.Pod) {
+	key, err := cache.MetaNamespaceKeyFunc(pod)
+	if err != nil {
+		q.logger.Error("Error queueing Pod", append(podInfo(pod), zap.Error(err))...) // untested section
+		return
+	}
+	q.queue.Add(key)
+}
+
+// handles queued Pods with that many workers until Stop
+func (q *podQueue) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for q.next() {
+			}
+		}()
+	}
+}
+
+// drops the queued Pods and waits for the ones being handled
+func (q *podQueue) Stop() {
+	q.queue.ShutDown()
+	q.wg.Wait()
+}
+
+// false once stopped
+func (q *podQueue) next() bool {
+	item, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(item)
+	key := item.(string)
+
+	// queued Pods are not part of a scan, each starts its own trace
+	ctx := context.Background()
+	namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+	pod, err := q.get(ctx, namespace, name)
+	switch {
+	case err == nil:
+		q.handle(ctx, pod)
+	case k8s.Classify(err) == k8s.ErrorNotFound:
+		// gone while it waited
+	case q.queue.NumRequeues(item) < podQueueRetries:
+		q.logger.Info("Retrying Pod", zap.String("pod", key), zap.Error(err))
+		q.queue.AddRateLimited(item)
+		return true
+	default:
+		q.logger.Error("Error getting Pod, giving up until it changes", zap.String("pod", key), zap.Error(err))
+	}
+	q.queue.Forget(item)
+	return true
+}
+
+func (q *podQueue) get(ctx context.Context, namespace string, name string) (*v1.Pod, error) {
+	if q.pods == nil {
+		return q.client.GetPod(ctx, namespace, name)
+	}
+	return q.pods.GetPod(namespace, name)
+}
@@ -0,0 +1,325 @@
+package remediator
+
+import (
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/diagnostics"
+	"github.com/aksgithub/kube_remediator/pkg/healthz"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"strings"
+	"time"
+)
+
+const (
+	OptOut = "opt-out" // act on all Pods unless they opt out
+	OptIn  = "opt-in"  // only act on Pods that opt in
+)
+
+// Rules every remediator follows before acting on a Pod
+type Policy struct {
+	// counts Pods skipped on purpose, nil means not counted
+	Skipped *metrics.Skipped_Metrics
+
+	// counts actions, errors and scan durations, nil means not counted
+	Metrics *metrics.Remediation_Metrics
+
+	// name of this remediator in metrics and the audit log
+	Remediator string
+
+	// name of the cluster in audit records and notifications, "" when there is only the one cluster
+	Cluster string
+
+	// every decision is written to it, nil means no audit log
+	Audit *audit.Log
+
+	// the latest decisions for the status API, nil means none are kept
+	History *audit.History
+
+	// told about actions and failures, empty means nobody is notified
+	Notifiers notify.Notifiers
+
+	// spans of scans, decisions, api calls and notifications, nil means not traced
+	Tracer *tracing.Tracer
+
+	// remediators report their loops and caches to it, nil means not checked
+	Health *healthz.Health
+
+	// pauses all remediators while engaged, nil means never
+	KillSwitch *KillSwitch
+
+	// pauses remediators and overrides DryRun at runtime, nil means never
+	Controls *Controls
+
+	// alerts received from Alertmanager, nil means none are
+	Alerts *Alerts
+
+	// only the leader of several replicas remediates, nil means this replica always does
+	Leader *LeaderElection
+
+	// Pods younger than this are never remediated, so fresh rollouts can warm up
+	MinPodAge time.Duration
+
+	// lines of the previous logs of the failing container kept in the audit log and notifications, 0 means none
+	CaptureLogLines int64
+
+	// most recent Warning events of the Pod sent in notifications, 0 means none
+	CaptureEvents int
+
+	// stores a bundle of the Pod, its events and logs before deleting or evicting it, nil means none
+	Diagnostics *diagnostics.Collector
+
+	// skip the remediation when the diagnostics bundle could not be stored
+	RequireDiagnostics bool
+
+	// evictions blocked by a PodDisruptionBudget before falling back to deleting the Pod, 0 means never
+	DeleteAfterBlockedEvictions int
+
+	// shared by all remediators, nil means unlimited
+	RateLimiter *RateLimiter
+
+	// shared by all remediators, lets started remediations finish on shutdown, nil cancels them with the scan
+	Drain *Drain
+
+	// shared by all remediators, nil means no cooldown
+	Cooldown *Cooldown
+
+	// shared by all remediators, nil means none
+	NamespaceOverrides *NamespaceOverrides
+
+	// settings from Namespace annotations, nil means they are not read
+	NamespaceAnnotations *NamespaceAnnotations
+
+	// only log what would be done
+	DryRun bool
+
+	// dry runs send the remediation to the api-server as a dry run, so RBAC, admission webhooks and
+	// PodDisruptionBudgets that would reject it show up
+	ServerSideDryRun bool
+
+	// shared by all remediators, nil means no backoff
+	Backoff *Backoff
+
+	// skip Pods on cordoned or draining Nodes, nil means no check
+	Nodes *k8s.NodeCache
+
+	// Pods per list request when scanning, 0 means all at once
+	PageSize int64
+
+	// handle the Pods that informers noticed, 0 means one, the event stream shares workers of its own
+	Workers int
+
+	// Pods watched once for all remediators that react to Pod updates, they also scan it instead of listing Pods,
+	// nil means each of them watches on its own and lists from the api-server
+	Pods *k8s.PodCache
+
+	// shared by all remediators, nil means unlimited
+	UnavailableLimit *UnavailableLimit
+
+	// remediations of an owner before we only warn, 0 means unlimited
+	MaxAttemptsPerOwner int
+
+	// annotation on the owner counting its remediations
+	AttemptsAnnotation string
+
+	// after acting the owner gets <prefix>last-action, last-action-time and action-count, "" means it does not
+	OwnerAnnotationPrefix string
+
+	// other Ready Pods the owner of a crashlooping Pod needs to have left, otherwise we only warn, 0 means no check
+	MinReadyReplicas int
+
+	// namespaces all remediators act in, nil means all
+	Namespaces *NamespaceFilter
+
+	// labels of Pods all remediators act on, nil means all
+	LabelSelector labels.Selector
+
+	// Pods with these priorityClassNames are never remediated, like control-plane or CNI Pods
+	ExcludedPriorityClasses []string
+
+	// only Pods owned by controllers of these kinds are remediated, empty means any
+	OwnerKinds []string
+
+	// OptOut (default) or OptIn
+	OptMode string
+
+	// Pods annotated with "true" are remediated in OptIn mode
+	OptInAnnotation string
+
+	// Pods annotated with "true" are never remediated, "" to disable
+	OptOutAnnotation string
+
+	// until then this remediator only logs what it would do, zero means act right away
+	ObserveUntil time.Time
+
+	// wait for a human to approve, nil means act right away
+	Approval *Approval
+
+	// called before and after each action, pre hooks can veto it, nil means none
+	Hooks *Hooks
+
+	// how often this remediator scans, nil means its default without jitter
+	Reconcile *Reconcile
+
+	// scan one time and stop instead of watching and scanning every interval, for --once
+	Once bool
+
+	// when this remediator may act, nil means always
+	Maintenance *Maintenance
+
+	// fetch Pods again right before acting and check they still need it
+	ConfirmBeforeAction bool
+
+	// checked after the own filters of this remediator, nil means none
+	Filters FilterChain
+
+	// names of actions by reason, like OutOfmemory, or by remediator, any case, the reason wins,
+	// nil means the default action of each remediator
+	Actions map[string]string
+
+	// used when this remediator deletes or evicts Pods, nil means api-server defaults
+	DeleteOptions *metav1.DeleteOptions
+
+	// also skip deletes when the Pod changed since we looked at it, not just when it was replaced
+	PreconditionResourceVersion bool
+}
+
+func (p *Policy) Validate() error {
+	if p.OptMode != "" && p.OptMode != OptOut && p.OptMode != OptIn {
+		return fmt.Errorf("unknown optMode %q, use %q or %q", p.OptMode, OptOut, OptIn)
+	}
+	for key, action := range p.Actions {
+		if _, ok := actions[strings.ToLower(action)]; !ok {
+			return fmt.Errorf("actions.%s: unknown action %q, use %s", key, action, strings.Join(ActionNames(), ", "))
+		}
+	}
+	return nil
+}
+
+func (p *Policy) optedIn(pod *v1.Pod) bool {
+	annotations := pod.ObjectMeta.Annotations
+	if p.OptOutAnnotation != "" && annotations[p.OptOutAnnotation] == "true" {
+		return false
+	}
+	if p.OptMode == OptIn {
+		return annotations[p.OptInAnnotation] == "true"
+	}
+	return true
+}
+
+// static Pods are run by kubelet from files, deleting their mirror Pods on the api-server does nothing
+func isStaticPod(pod *v1.Pod) bool {
+	annotations := pod.ObjectMeta.Annotations
+	if _, mirror := annotations["kubernetes.io/config.mirror"]; mirror {
+		return true
+	}
+	if source, ok := annotations["kubernetes.io/config.source"]; ok && source != "api" {
+		return true
+	}
+	for _, ownerReference := range pod.ObjectMeta.OwnerReferences {
+		if ownerReference.Kind == "Node" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) excludedPriorityClass(pod *v1.Pod) bool {
+	for _, priorityClass := range p.ExcludedPriorityClasses {
+		if pod.Spec.PriorityClassName == priorityClass {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) allowedOwnerKind(pod *v1.Pod) bool {
+	if len(p.OwnerKinds) == 0 {
+		return true
+	}
+	owner := ownerReference(pod)
+	if owner == nil {
+		return false
+	}
+	for _, kind := range p.OwnerKinds {
+		if owner.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) matchesLabels(pod *v1.Pod) bool {
+	return p.LabelSelector == nil || p.LabelSelector.Matches(labels.Set(pod.ObjectMeta.Labels))
+}
+
+// add the label selector to list options, so filtering happens on the api-server
+func (p *Policy) listOptions(options metav1.ListOptions) metav1.ListOptions {
+	options.FieldSelector = joinSelectors(options.FieldSelector, p.Namespaces.FieldSelector())
+	if p.LabelSelector != nil && !p.LabelSelector.Empty() {
+		options.LabelSelector = joinSelectors(options.LabelSelector, p.LabelSelector.String())
+	}
+	return options
+}
+
+// all of the selectors have to match, empty ones are left out
+func joinSelectors(selectors ...string) string {
+	var joined []string
+	for _, selector := range selectors {
+		if selector != "" {
+			joined = append(joined, selector)
+		}
+	}
+	return strings.Join(joined, ",")
+}
+
+// owner cooldown for Pods in the namespace, nil means none
+func (p *Policy) cooldown(namespace string) *Cooldown {
+	if override := p.NamespaceOverrides.For(namespace); override != nil && override.Cooldown != nil {
+		return override.Cooldown
+	}
+	return p.Cooldown
+}
+
+// time between remediations of Pods in the namespace, nil means unlimited
+func (p *Policy) interval(namespace string) *Cooldown {
+	if override := p.NamespaceOverrides.For(namespace); override != nil {
+		return override.Interval
+	}
+	return nil
+}
+
+// Controls turning dry runs on wins over namespaces, turning them off only changes DryRun
+func (p *Policy) dryRun(namespace string) bool {
+	if dryRun := p.Controls.DryRun(); dryRun != nil && *dryRun {
+		return true
+	}
+	if override := p.NamespaceOverrides.For(namespace); override != nil && override.DryRun != nil {
+		return *override.DryRun
+	}
+	return p.globalDryRun() || p.NamespaceAnnotations.dryRun(namespace)
+}
+
+// DryRun unless Controls override it
+func (p *Policy) globalDryRun() bool {
+	if dryRun := p.Controls.DryRun(); dryRun != nil {
+		return *dryRun
+	}
+	return p.DryRun
+}
+
+// failure threshold for Pods in the namespace, fallback when not overridden
+func (p *Policy) failureThreshold(namespace string, fallback int32) int32 {
+	if override := p.NamespaceOverrides.For(namespace); override != nil && override.FailureThreshold > 0 {
+		return override.FailureThreshold
+	}
+	if threshold := p.NamespaceAnnotations.failureThreshold(namespace); threshold > 0 {
+		return threshold
+	}
+	return fallback
+}
@@ -0,0 +1,144 @@
+package remediator
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+type queuedAction struct {
+	ctx    context.Context // of the remediator, done when it stopped
+	key    string
+	action func(take func() bool) bool
+}
+
+// Caps how many remediations all remediators together can do per interval,
+// actions over the limit are queued for the next window
+type RateLimiter struct {
+	logger   *zap.Logger
+	max      int
+	interval time.Duration
+	metrics  *metrics.RateLimiter_Metrics
+
+	lock   sync.Mutex
+	tokens int
+	queue  []queuedAction
+	queued map[string]bool
+}
+
+func NewRateLimiter(logger *zap.Logger, max int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		logger:   logger,
+		max:      max,
+		interval: interval,
+		metrics:  metrics.NewRateLimiterMetrics(logger),
+		tokens:   max,
+		queued:   map[string]bool{},
+	}
+}
+
+// run the action now, it calls take once its checks passed and it is about to act, take is false when the current
+// window has no capacity left, the action then stops and is queued (once per key) to run and check again in the next
+// window unless ctx is done by then, it returns whether it acted, the token of one that did not is given back,
+// returns false when the action was queued, a nil RateLimiter allows everything
+func (r *RateLimiter) Do(ctx context.Context, key string, action func(take func() bool) bool) bool {
+	if r == nil {
+		action(func() bool { return true })
+		return true
+	}
+	return r.run(queuedAction{ctx: ctx, key: key, action: action})
+}
+
+func (r *RateLimiter) run(a queuedAction) bool {
+	took, queued := false, false
+	acted := a.action(func() bool {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.tokens > 0 {
+			r.tokens--
+			took = true
+			return true
+		}
+		if !r.queued[a.key] {
+			r.queued[a.key] = true
+			r.queue = append(r.queue, a)
+			r.metrics.UpdateThrottledCount()
+			r.metrics.SetQueued(len(r.queue))
+		}
+		queued = true
+		return false
+	})
+	if took && !acted {
+		r.lock.Lock()
+		if r.tokens < r.max { // a new window may have started meanwhile
+			r.tokens++
+		}
+		r.lock.Unlock()
+	}
+	return !queued
+}
+
+type RateLimiterState struct {
+	Tokens int `json:"tokens"` // left in the current window
+	Queued int `json:"queued"`
+}
+
+// for /debug/state, nil when unlimited
+func (r *RateLimiter) State() *RateLimiterState {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return &RateLimiterState{Tokens: r.tokens, Queued: len(r.queue)}
+}
+
+func (r *RateLimiter) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.metrics.Register()
+	defer r.metrics.UnRegister()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.startWindow()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refill and run as many queued actions as the new window allows, they take their tokens themselves, those of
+// remediators that stopped, like when reloading config, are dropped since the new ones find the Pods again
+func (r *RateLimiter) startWindow() {
+	r.lock.Lock()
+	r.tokens = r.max
+	var due, kept []queuedAction
+	for _, a := range r.queue {
+		switch {
+		case a.ctx.Err() != nil:
+			delete(r.queued, a.key)
+		case len(due) < r.max:
+			delete(r.queued, a.key)
+			due = append(due, a)
+		default:
+			kept = append(kept, a)
+		}
+	}
+	r.queue = kept
+	queued := len(r.queue)
+	r.metrics.SetQueued(queued)
+	r.lock.Unlock()
+
+	if len(due) > 0 || queued > 0 {
+		r.logger.Info("Running queued remediations", zap.Int("count", len(due)), zap.Int("queued", queued))
+	}
+	for _, a := range due {
+		r.run(a)
+	}
+}
@@ -0,0 +1,116 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type TestRateLimiterSuite struct {
+	suite.Suite
+	logger *zap.Logger
+	lock   sync.Mutex
+	ran    []string
+	t      *testing.T
+}
+
+func TestSuiteRateLimiter(t *testing.T) {
+	suite.Run(t, &TestRateLimiterSuite{t: t})
+}
+
+func (suite *TestRateLimiterSuite) SetupTest() {
+	suite.logger, _ = zap.NewDevelopment()
+	suite.ran = nil
+}
+
+func (suite *TestRateLimiterSuite) action(key string) func(func() bool) bool {
+	return func(take func() bool) bool {
+		if !take() {
+			return false
+		}
+		suite.lock.Lock()
+		defer suite.lock.Unlock()
+		suite.ran = append(suite.ran, key)
+		return true
+	}
+}
+
+// checks fail before taking a token or after it, like a veto of a hook
+func skipped(takes bool) func(func() bool) bool {
+	return func(take func() bool) bool {
+		return takes && !take()
+	}
+}
+
+func (suite *TestRateLimiterSuite) ranActions() []string {
+	suite.lock.Lock()
+	defer suite.lock.Unlock()
+	return append([]string{}, suite.ran...)
+}
+
+func (suite *TestRateLimiterSuite) TestAllowsEverythingWhenNil() {
+	var limiter *remediator.RateLimiter
+	assert.Equal(suite.t, limiter.Do(context.Background(), "a", suite.action("a")), true)
+	assert.DeepEqual(suite.t, suite.ranActions(), []string{"a"})
+}
+
+func (suite *TestRateLimiterSuite) TestQueuesActionsOverTheLimit() {
+	limiter := remediator.NewRateLimiter(suite.logger, 1, time.Hour)
+	assert.Equal(suite.t, limiter.Do(context.Background(), "a", suite.action("a")), true)
+	assert.Equal(suite.t, limiter.Do(context.Background(), "b", suite.action("b")), false)
+	assert.DeepEqual(suite.t, suite.ranActions(), []string{"a"})
+}
+
+func (suite *TestRateLimiterSuite) TestKeepsTokensOfSkippedActions() {
+	limiter := remediator.NewRateLimiter(suite.logger, 1, time.Hour)
+	assert.Equal(suite.t, limiter.Do(context.Background(), "a", skipped(false)), true)
+	assert.Equal(suite.t, limiter.Do(context.Background(), "b", skipped(true)), true)
+	assert.Equal(suite.t, limiter.Do(context.Background(), "c", suite.action("c")), true)
+	assert.DeepEqual(suite.t, suite.ranActions(), []string{"c"})
+}
+
+func (suite *TestRateLimiterSuite) TestRunsQueuedActionsInNextWindow() {
+	limiter := remediator.NewRateLimiter(suite.logger, 1, 10*time.Millisecond)
+	limiter.Do(context.Background(), "a", suite.action("a"))
+	limiter.Do(context.Background(), "b", suite.action("b"))
+	limiter.Do(context.Background(), "b", suite.action("b")) // queued only once
+	limiter.Do(context.Background(), "c", suite.action("c"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go limiter.Run(ctx, &wg)
+	for i := 0; i < 100 && len(suite.ranActions()) < 2; i++ {
+		time.Sleep(time.Millisecond) // wait for the next window
+	}
+
+	cancel()
+	wg.Wait()
+	assert.DeepEqual(suite.t, suite.ranActions()[:2], []string{"a", "b"})
+}
+
+func (suite *TestRateLimiterSuite) TestDropsQueuedActionsOfStoppedRemediators() {
+	limiter := remediator.NewRateLimiter(suite.logger, 1, 10*time.Millisecond)
+	stopped, stop := context.WithCancel(context.Background())
+	limiter.Do(context.Background(), "a", suite.action("a"))
+	limiter.Do(stopped, "b", suite.action("b"))
+	limiter.Do(context.Background(), "c", suite.action("c"))
+	stop() // like when reloading config
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go limiter.Run(ctx, &wg)
+	for i := 0; i < 100 && len(suite.ranActions()) < 2; i++ {
+		time.Sleep(time.Millisecond) // wait for the next window
+	}
+
+	cancel()
+	wg.Wait()
+	assert.DeepEqual(suite.t, suite.ranActions(), []string{"a", "c"})
+}
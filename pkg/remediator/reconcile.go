@@ -0,0 +1,83 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// When remediators scan for unhealthy Pods, randomized so replicas and remediators do not all hit the api-server at once
+type ReconcileConfig struct {
+	Jitter       float64                  `mapstructure:"jitter"`       // 0.1 waits between 90% and 110% of the interval
+	StartupDelay time.Duration            `mapstructure:"startupDelay"` // first scan after a random delay up to this
+	Remediators  map[string]time.Duration `mapstructure:"remediators"`  // interval per remediator, missing use their default
+}
+
+type Reconcile struct {
+	Interval     time.Duration // 0 means the default of the remediator
+	Jitter       float64
+	StartupDelay time.Duration
+	random       *rand.Rand // not shared, only used by the remediator's goroutine
+}
+
+func (c ReconcileConfig) Validate() error {
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		return fmt.Errorf("reconcile.jitter must be at least 0 and less than 1, got %v", c.Jitter)
+	}
+	if c.StartupDelay < 0 {
+		return fmt.Errorf("reconcile.startupDelay must not be negative, got %v", c.StartupDelay)
+	}
+	for name, interval := range c.Remediators {
+		if interval <= 0 {
+			return fmt.Errorf("reconcile.remediators.%s must be positive, got %v", name, interval)
+		}
+	}
+	return nil
+}
+
+// Reconcile of a remediator, seeded separately so replicas started at the same time still spread out
+func (c ReconcileConfig) Build(remediator string) *Reconcile {
+	reconcile := &Reconcile{
+		Jitter:       c.Jitter,
+		StartupDelay: c.StartupDelay,
+		random:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for name, interval := range c.Remediators {
+		if strings.EqualFold(name, remediator) { // viper lowercases keys
+			reconcile.Interval = interval
+		}
+	}
+	return reconcile
+}
+
+// time until the next scan, a nil Reconcile uses the default without jitter
+func (r *Reconcile) Next(defaultInterval time.Duration) time.Duration {
+	if r == nil {
+		return defaultInterval
+	}
+	interval := defaultInterval
+	if r.Interval > 0 {
+		interval = r.Interval
+	}
+	if r.Jitter == 0 {
+		return interval
+	}
+	return time.Duration(float64(interval) * (1 + r.Jitter*(2*r.random.Float64()-1)))
+}
+
+// wait a random part of the startup delay, false when stopped while waiting
+func (r *Reconcile) WaitForStart(ctx context.Context) bool {
+	if r == nil || r.StartupDelay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(time.Duration(r.random.Int63n(int64(r.StartupDelay))))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReconcileUsesDefaultIntervalWhenNil(t *testing.T) {
+	var reconcile *remediator.Reconcile
+	assert.Equal(t, reconcile.Next(time.Hour), time.Hour)
+	assert.Equal(t, reconcile.WaitForStart(context.Background()), true)
+}
+
+func TestReconcileUsesIntervalOfRemediator(t *testing.T) {
+	config := remediator.ReconcileConfig{Remediators: map[string]time.Duration{"oldpoddeleter": 10 * time.Minute}}
+	assert.Equal(t, config.Build("OldPodDeleter").Next(time.Hour), 10*time.Minute)
+	assert.Equal(t, config.Build("CompletedPodDeleter").Next(time.Hour), time.Hour)
+}
+
+func TestReconcileJittersInterval(t *testing.T) {
+	reconcile := remediator.ReconcileConfig{Jitter: 0.1}.Build("OldPodDeleter")
+	varies := false
+	for i := 0; i < 100; i++ {
+		next := reconcile.Next(time.Hour)
+		assert.Assert(t, next >= 54*time.Minute && next <= 66*time.Minute, next)
+		varies = varies || next != time.Hour
+	}
+	assert.Assert(t, varies)
+}
+
+func TestReconcileStopsWaitingForStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reconcile := remediator.ReconcileConfig{StartupDelay: time.Hour}.Build("OldPodDeleter")
+	assert.Equal(t, reconcile.WaitForStart(ctx), false)
+
+	reconcile = remediator.ReconcileConfig{StartupDelay: time.Millisecond}.Build("OldPodDeleter")
+	assert.Equal(t, reconcile.WaitForStart(context.Background()), true)
+}
+
+func TestScansOnceAndStops(t *testing.T) {
+	old := fake.OwnedBy(fake.Pod("default", "api"), fake.ReplicaSet("default", "api", 1))
+	old.ObjectMeta.CreationTimestamp.Time = time.Now().Add(-25 * time.Hour)
+	old.ObjectMeta.Labels = map[string]string{"kube-remediator/OldPodDeleter": "true"}
+	client := fake.NewClient(old)
+	assert.NilError(t, client.AddOwner(fake.ReplicaSet("default", "api", 1)))
+	deleter := remediator.OldPodDeleter{}
+	assert.NilError(t, deleter.Setup(zap.NewNop(), client, &remediator.Policy{Remediator: "OldPodDeleter", Once: true}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	deleter.Run(context.Background(), &wg) // returns without being canceled
+	assert.DeepEqual(t, client.Actions(), []fake.Action{{Verb: "evict", Kind: "Pod", Namespace: "default", Name: "api"}})
+}
+
+func TestReconcileConfigFailsOnInvalidValues(t *testing.T) {
+	for _, config := range []remediator.ReconcileConfig{
+		{Jitter: 1},
+		{Jitter: -0.1},
+		{StartupDelay: -time.Second},
+		{Remediators: map[string]time.Duration{"oldpoddeleter": 0}},
+	} {
+		assert.Assert(t, config.Validate() != nil)
+	}
+}
@@ -0,0 +1,92 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// what cmd/remediator starts for every enabled name of the registry, embedding Base implements all but Run and Name
+type Remediator interface {
+	Setup(*zap.Logger, k8s.ClientInterface, *Policy) error
+	Run(context.Context, *sync.WaitGroup) // calls Done of the WaitGroup once stopped
+	Name() string                         // the name it registered with, also the remediator of metrics, logs and --remediators
+	Healthy() bool                        // false when its loop is stuck, like the liveness probe
+	State() RemediatorState
+	// since its scan loop last made progress and how often it should, see Watchdog
+	Progress() (since time.Duration, interval time.Duration)
+}
+
+// which remediators run and the settings of those that have some, the remediators section of the config file
+type Configs struct {
+	Enabled                     []string               `mapstructure:"enabled"` // names, any case, empty means all
+	AlertmanagerRemediator      AlertmanagerConfig     `mapstructure:"alertmanagerRemediator"`
+	CrashLoopBackOffRescheduler CrashLoopBackOffConfig `mapstructure:"crashLoopBackOffRescheduler"`
+	NodeProblemRemediator       NodeProblemConfig      `mapstructure:"nodeProblemRemediator"`
+	RuleRemediator              RulesConfig            `mapstructure:"ruleRemediator"`
+}
+
+// a new remediator, before Setup, with its part of the configs
+type Factory func(Configs) Remediator
+
+var registry = struct {
+	lock      sync.Mutex
+	factories map[string]Factory
+}{factories: map[string]Factory{}}
+
+// called from an init func of the file defining the remediator, a name registered twice panics
+// since one of the remediators would never run
+func Register(name string, factory Factory) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	if _, ok := registry.factories[name]; ok {
+		panic(fmt.Sprintf("remediator %s registered twice", name))
+	}
+	registry.factories[name] = factory
+}
+
+// all registered remediators, sorted
+func Names() []string {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// the registered name that name is in any case, or else the only one starting with it, like crashloop for
+// CrashLoopBackOffRescheduler, false when there is none or several
+func Lookup(name string) (string, bool) {
+	var found []string
+	for _, registered := range Names() {
+		if strings.EqualFold(registered, name) {
+			return registered, true
+		}
+		if name != "" && strings.HasPrefix(strings.ToLower(registered), strings.ToLower(name)) {
+			found = append(found, registered)
+		}
+	}
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// the registered remediator, false when there is none of that name
+func New(name string, configs Configs) (Remediator, bool) {
+	registry.lock.Lock()
+	factory, ok := registry.factories[name]
+	registry.lock.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(configs), true
+}
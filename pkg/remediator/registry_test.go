@@ -0,0 +1,51 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestRegistersAllRemediators(t *testing.T) {
+	assert.DeepEqual(t, remediator.Names(), []string{
+		"AlertmanagerRemediator", "CompletedPodDeleter", "CrashLoopBackOffRescheduler", "FailedPodRescheduler", "NodeProblemRemediator", "OldPodDeleter", "RuleRemediator",
+	})
+	for _, name := range remediator.Names() {
+		r, ok := remediator.New(name, remediator.Configs{})
+		assert.Assert(t, ok)
+		assert.Equal(t, r.Name(), name)
+		assert.Assert(t, r.Healthy(), name) // not scanning yet
+	}
+}
+
+func TestCreatesRemediatorsWithTheirConfig(t *testing.T) {
+	configs := remediator.Configs{CrashLoopBackOffRescheduler: remediator.DefaultCrashLoopBackOffConfig()}
+	configs.CrashLoopBackOffRescheduler.FailureThreshold = 9
+	r, ok := remediator.New("CrashLoopBackOffRescheduler", configs)
+	assert.Assert(t, ok)
+	assert.Equal(t, r.(*remediator.CrashLoopBackOffRescheduler).Config.FailureThreshold, int32(9))
+
+	_, ok = remediator.New("PersistentVolumeClaimCleaner", configs)
+	assert.Assert(t, !ok)
+}
+
+func TestLooksUpRemediatorsByPrefix(t *testing.T) {
+	name, ok := remediator.Lookup("oldpoddeleter")
+	assert.Assert(t, ok)
+	assert.Equal(t, name, "OldPodDeleter")
+	name, ok = remediator.Lookup("crashloop")
+	assert.Assert(t, ok)
+	assert.Equal(t, name, "CrashLoopBackOffRescheduler")
+
+	_, ok = remediator.Lookup("c") // CompletedPodDeleter or CrashLoopBackOffRescheduler
+	assert.Assert(t, !ok)
+	_, ok = remediator.Lookup("")
+	assert.Assert(t, !ok)
+}
+
+func TestRejectsRegisteringTwice(t *testing.T) {
+	defer func() {
+		assert.Assert(t, recover() != nil)
+	}()
+	remediator.Register("OldPodDeleter", func(remediator.Configs) remediator.Remediator { return &remediator.OldPodDeleter{} })
+}
@@ -2,28 +2,55 @@ package remediator
 
 import (
 	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/audit"
+	"github.com/aksgithub/kube_remediator/pkg/events"
 	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-// will later be used to make arrays or remediators / testing
-type BaseIntf interface {
-	Setup(*zap.Logger, k8s.ClientInterface) error
-	Run(context.Context, *sync.WaitGroup)
+// remediators that can be fed Pods from the event stream instead of watching all Pods themselves
+type EventDriven interface {
+	UseEventStream(*events.Stream)
 }
 
 type Base struct {
-	BaseIntf
+	Remediator
 	client k8s.ClientInterface
 	logger *zap.Logger
+	policy *Policy
+
+	lock             sync.Mutex
+	blockedEvictions map[types.UID]int
+	unhealthySince   map[types.UID]time.Time // when we first saw the Pod needing remediation
+	lastScan         time.Time
+	ticked           time.Time     // when the scan loop last ticked Policy.Health
+	next             time.Duration // until its next tick, 0 without a scan loop
+	progressed       time.Time     // when the scan loop started or last finished a scan that listed everything
+	scanFailed       bool          // the running scan could not list everything
+
+	// Policy.MinReadyReplicas applies, set by remediators replacing Pods that may still serve some requests
+	checksReadyReplicas bool
 }
 
-func (p *Base) Setup(logger *zap.Logger, client k8s.ClientInterface) error {
+func (p *Base) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
 	p.client = client
 	p.logger = logger
+	p.policy = policy
+	p.blockedEvictions = map[types.UID]int{}
+	p.unhealthySince = map[types.UID]time.Time{}
 	return nil
 }
 
@@ -33,39 +60,1124 @@ func (p *Base) logStartAndStop(fn func()) {
 	fn()
 }
 
-func (p *Base) reconcileEvery(ctx context.Context, fn func(), interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
+// interval is the default of the remediator, Policy.Reconcile can change it and add jitter,
+// every scan is a tick of Policy.Health so a stuck loop fails liveness
+func (p *Base) reconcileEvery(ctx context.Context, fn func(context.Context), interval time.Duration) {
 	p.logStartAndStop(func() {
 		// Run on start
-		fn()
+		if !p.policy.Reconcile.WaitForStart(ctx) {
+			return
+		}
+		defer p.policy.Health.Remove(p.policy.Remediator)
+		scans, stopScans := p.policy.Controls.scanRequests(p.policy.Remediator)
+		defer stopScans()
+		p.tick(interval)
+		p.lock.Lock()
+		p.progressed = time.Now()
+		p.lock.Unlock()
+		p.scan(ctx, fn)
+		if p.policy.Once {
+			return
+		}
 
 		for {
+			next := p.policy.Reconcile.Next(interval)
+			p.tick(next)
+			timer := time.NewTimer(next)
 			select {
-			case <-ticker.C:
-				fn() // untested section
+			case <-timer.C:
+				p.scan(ctx, fn) // untested section
+			case <-scans:
+				timer.Stop()
+				p.logger.Info("Scanning, requested through the control API")
+				p.scan(ctx, fn)
 			case <-ctx.Done():
+				timer.Stop()
 				return
 			}
 		}
 	})
+}
 
+func (p *Base) tick(next time.Duration) {
+	p.policy.Health.Tick(p.policy.Remediator, next)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.ticked, p.next = time.Now(), next
 }
 
-func (p *Base) deletePod(pod v1.Pod) {
-	podInfo := []zap.Field{
-		zap.String("name", pod.ObjectMeta.Name),
-		zap.String("namespace", pod.ObjectMeta.Namespace),
+// false when the scan loop did not tick within twice the time it expected, like the liveness probe,
+// remediators that only react to events are always healthy
+func (p *Base) Healthy() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.next == 0 || time.Since(p.ticked) <= 2*p.next
+}
+
+// since the scan loop last made progress and how often it should, interval 0 without a scan loop,
+// a loop that keeps ticking while its lists fail makes none
+func (p *Base) Progress() (since time.Duration, interval time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.next == 0 {
+		return 0, 0
+	}
+	return time.Since(p.progressed), p.next
+}
+
+// the running scan missed Pods or Nodes it could not list
+func (p *Base) listFailed() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.scanFailed = true
+}
+
+// run a scan for unhealthy Pods or Nodes in its own trace and record how long it took
+func (p *Base) scan(ctx context.Context, fn func(context.Context)) {
+	if !p.policy.Leader.Leading() {
+		p.logger.Debug("Skipping scan, not the leader")
+		p.lock.Lock()
+		p.progressed = time.Now() // a follower has nothing to do
+		p.lock.Unlock()
+		return
+	}
+	ctx, span := p.policy.Tracer.Start(ctx, "scan", tracing.KindInternal, map[string]string{"remediator": p.policy.Remediator})
+	defer span.End()
+	p.lock.Lock()
+	p.scanFailed = false
+	p.lock.Unlock()
+	start := time.Now()
+	fn(ctx)
+	p.policy.Metrics.ObserveScanDuration(p.policy.Remediator, time.Since(start))
+	p.lock.Lock()
+	p.lastScan = start
+	if !p.scanFailed {
+		p.progressed = time.Now()
+	}
+	p.lock.Unlock()
+}
+
+// reason is the problem that was detected, fallback the action of the remediator when Policy.Actions has none,
+// stillNeeded re-checks the Pod when confirming before acting
+func (p *Base) remediatePod(ctx context.Context, pod v1.Pod, reason string, fallback string, stillNeeded func(*v1.Pod) bool) {
+	p.remediate(ctx, pod, reason, p.action(reason, fallback), stillNeeded)
+}
+
+// run the action unless the Pod is out of scope, too young, on a draining Node or not approved, we are observing,
+// dry running or outside the maintenance window, a pre hook vetoed, its namespace was remediated within its interval
+// or the Pods owner is in cooldown, backoff or has too many unavailable Pods, when the shared rate limit is exceeded
+// it is queued for the next window, every decision is counted, audited and traced, followers of a leader election
+// decide nothing
+func (p *Base) remediate(ctx context.Context, pod v1.Pod, reason string, run Action, stillNeeded func(*v1.Pod) bool) {
+	action := run.Done()
+	object := podRef(&pod)
+	ctx, span := p.policy.Tracer.Start(ctx, "decide", tracing.KindInternal, map[string]string{
+		"namespace": object.Namespace, "pod": object.Name, "reason": reason, "action": action,
+	})
+	defer span.End()
+	if !p.policy.Leader.Leading() { // the leader records its decisions, followers would only repeat them
+		p.logger.Debug("Skipping, not the leader", podInfo(&pod)...)
+		return
+	}
+	if why := p.outOfScope(&pod); why != "" {
+		p.record(ctx, object, reason, action, metrics.ResultSkipped, why)
+		return
+	}
+	p.noticeUnhealthy(pod.ObjectMeta.UID)
+
+	namespace := pod.ObjectMeta.Namespace
+	owner := ownerKey(&pod)
+	if why := p.notAllowed(ctx, &pod, owner); why != "" {
+		p.record(ctx, object, reason, action, metrics.ResultSkipped, why)
+		return
+	}
+	if p.observing(&pod) {
+		p.record(ctx, object, reason, action, metrics.ResultDryRun, "observing")
+		return
+	}
+	if p.dryRunning(&pod) {
+		p.record(ctx, object, reason, action, metrics.ResultDryRun, p.dryRunPod(ctx, &pod, run))
+		return
 	}
-	p.tryWithLogging("Deleting Pod", podInfo, func() error {
-		return p.client.DeletePod(&pod)
+	if !p.approved(ctx, &pod) {
+		p.record(ctx, object, reason, action, metrics.ResultSkipped, "waiting for approval")
+		return
+	}
+
+	// queued actions run after the decision span ended, still as its children, the token of the rate limiter is only
+	// taken once the checks passed, so skipped actions do not use it up
+	waited := false
+	queued := !p.policy.RateLimiter.Do(ctx, string(pod.ObjectMeta.UID), func(take func() bool) (acted bool) {
+		defer p.recoverPanic("action")
+		ctx, started := p.startAction(ctx)
+		if !started {
+			return false
+		}
+		// things could have changed while queued
+		why := p.notAllowed(ctx, &pod, owner)
+		if why == "" && !p.confirmed(ctx, &pod, stillNeeded, waited) {
+			why = "Pod recovered, was replaced or could not be fetched"
+		}
+		if why == "" && !take() {
+			waited = true
+			return false
+		}
+		if why == "" {
+			why = p.preHooks(ctx, object, reason, action) // before the attempt counts
+		}
+		// notAllowed checked them without starting anything, starting them right before acting keeps concurrent workers
+		// from both acting, they end again when the attempt can not be counted
+		cooldown, interval := p.policy.cooldown(namespace), p.policy.interval(namespace)
+		switch {
+		case why != "":
+		case !cooldown.TryStart(owner):
+			why = "owner in cooldown"
+		case !interval.TryStart(namespace):
+			cooldown.Cancel(owner)
+			why = "namespace remediated within its interval"
+		case !p.countAttempt(ctx, &pod):
+			cooldown.Cancel(owner)
+			interval.Cancel(namespace)
+			why = "owner reached its remediation limit or could not be annotated"
+		}
+		if why != "" {
+			p.record(ctx, object, reason, action, metrics.ResultSkipped, why)
+			return false
+		}
+		p.policy.Backoff.Attempt(owner)
+		p.policy.UnavailableLimit.Record(owner)
+		p.policy.Metrics.ObserveLatency(p.policy.Remediator, p.forgetUnhealthy(pod.ObjectMeta.UID))
+		run.Run(ctx, p, pod, reason)
+		return true
 	})
+	if queued {
+		p.logger.Info("Rate limited, queued for next window", podInfo(&pod)...)
+	}
+}
+
+// why we are not supposed to touch the Pod at all, logged at debug since it is not interesting, "" when in scope
+func (p *Base) outOfScope(pod *v1.Pod) string {
+	why := ""
+	switch {
+	case isStaticPod(pod):
+		why = "static Pod"
+	case !p.policy.Namespaces.Matches(pod.ObjectMeta.Namespace):
+		why = "namespace excluded"
+	case p.policy.NamespaceAnnotations.disabled(pod.ObjectMeta.Namespace):
+		why = "disabled by namespace annotation"
+	case !p.policy.matchesLabels(pod):
+		why = "labels not selected"
+	case p.policy.excludedPriorityClass(pod):
+		why = "priorityClass excluded"
+	case !p.policy.allowedOwnerKind(pod):
+		why = "owner kind not allowed"
+		if ownerReference(pod) == nil {
+			why = "no owner"
+		}
+	case !p.policy.optedIn(pod):
+		why = "opted out"
+	default:
+		return ""
+	}
+	p.logger.Debug("Skipping, "+why, podInfo(pod)...)
+	return why
+}
+
+// why a safety check holds the remediation back, "" when allowed
+func (p *Base) notAllowed(ctx context.Context, pod *v1.Pod, owner string) string {
+	if !p.policy.Leader.Leading() { // lost leadership while queued
+		p.logger.Info("Skipping, not the leader", podInfo(pod)...)
+		return "not the leader"
+	}
+	if p.policy.KillSwitch.Engaged() {
+		p.logger.Info("Skipping, kill switch engaged", podInfo(pod)...)
+		return "kill switch engaged"
+	}
+	if p.policy.Controls.Paused(p.policy.Remediator) {
+		p.logger.Info("Skipping, paused", podInfo(pod)...)
+		return "paused"
+	}
+	if age := time.Since(pod.ObjectMeta.CreationTimestamp.Time); age < p.policy.MinPodAge {
+		p.logger.Info("Skipping, Pod too young", append(podInfo(pod), zap.Duration("age", age))...)
+		return "Pod too young"
+	}
+	if p.onDrainingNode(pod) {
+		p.logger.Info("Skipping, Node is cordoned or draining", append(podInfo(pod), zap.String("node", pod.Spec.NodeName))...)
+		return "Node is cordoned or draining"
+	}
+	if !p.policy.Maintenance.Allows(pod.ObjectMeta.Namespace, time.Now()) {
+		p.logger.Info("Skipping, outside maintenance window", podInfo(pod)...)
+		return "outside maintenance window"
+	}
+	if p.policy.cooldown(pod.ObjectMeta.Namespace).Active(owner) {
+		p.logger.Info("Skipping, owner in cooldown", append(podInfo(pod), zap.String("owner", owner))...)
+		return "owner in cooldown"
+	}
+	if p.policy.interval(pod.ObjectMeta.Namespace).Active(pod.ObjectMeta.Namespace) {
+		p.logger.Info("Skipping, namespace remediated within its interval", podInfo(pod)...)
+		return "namespace remediated within its interval"
+	}
+	if wait := p.policy.Backoff.Wait(owner); wait > 0 {
+		p.logger.Info("Skipping, owner in backoff", append(podInfo(pod), zap.String("owner", owner), zap.Duration("wait", wait))...)
+		return "owner in backoff"
+	}
+	if !p.ownerCanLosePod(ctx, pod, owner) {
+		return "owner can not lose another Pod"
+	}
+	return ""
+}
+
+// only log what we would do during the observation period
+func (p *Base) observing(pod *v1.Pod) bool {
+	if !time.Now().Before(p.policy.ObserveUntil) {
+		return false
+	}
+	p.logger.Info("Observing, would remediate", append(podInfo(pod), zap.Time("observeUntil", p.policy.ObserveUntil))...)
+	return true
+}
+
+func (p *Base) dryRunning(pod *v1.Pod) bool {
+	if !p.policy.dryRun(pod.ObjectMeta.Namespace) {
+		return false
+	}
+	p.logger.Info("Dry run, would remediate", podInfo(pod)...)
+	return true
+}
+
+// "dry run", with Policy.ServerSideDryRun whether the api-server accepted the action as a dry run,
+// like "dry run rejected: admission webhook ... denied the request", nothing is changed either way
+func (p *Base) dryRunPod(ctx context.Context, pod *v1.Pod, action Action) string {
+	if !p.policy.ServerSideDryRun {
+		return "dry run"
+	}
+	ctx = k8s.WithDryRun(ctx)
+	_, call := p.policy.Tracer.Start(ctx, "dry run", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	err := action.DryRun(ctx, p, pod)
+	call.SetError(err)
+	call.End()
+	return p.dryRunResult(podInfo(pod), err)
+}
+
+// a rejected dry run is what the real remediation would have run into, so it is logged as a warning
+func (p *Base) dryRunResult(info []zap.Field, err error) string {
+	if err == nil {
+		p.logger.Info("Dry run accepted by the api-server", info...)
+		return "dry run accepted"
+	}
+	p.logger.Warn("Dry run rejected by the api-server", append(info, zap.Error(err))...)
+	return "dry run rejected: " + err.Error()
+}
+
+// the Pod we looked at can be a full interval old, so fetch it again and make sure it still needs remediation,
+// always when it waited in the queue of the rate limiter, otherwise with ConfirmBeforeAction
+func (p *Base) confirmed(ctx context.Context, pod *v1.Pod, stillNeeded func(*v1.Pod) bool, always bool) bool {
+	if !p.policy.ConfirmBeforeAction && !always {
+		return true
+	}
+	current, err := p.client.GetPod(ctx, pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	if k8s.Classify(err) == k8s.ErrorNotFound {
+		p.logger.Info("Skipping, Pod is gone", podInfo(pod)...)
+		return false
+	}
+	if err != nil {
+		p.callFailed("Error getting Pod", podInfo(pod), err)
+		return false
+	}
+	if current.ObjectMeta.UID != pod.ObjectMeta.UID || !stillNeeded(current) {
+		p.logger.Info("Skipping, Pod recovered or was replaced", podInfo(pod)...)
+		return false
+	}
+	return true
+}
+
+// remediations are counted in an annotation on the owner, once it reached the limit we only warn, since endlessly
+// restarting something that never recovers hides the problem
+func (p *Base) countAttempt(ctx context.Context, pod *v1.Pod) bool {
+	owner := ownerReference(pod)
+	if p.policy.MaxAttemptsPerOwner == 0 || owner == nil {
+		return true
+	}
+	info := append(podInfo(pod), zap.String("owner", ownerKey(pod)))
+
+	object, err := p.client.GetOwner(ctx, pod.ObjectMeta.Namespace, *owner)
+	if err != nil {
+		p.callFailed("Error getting owner", info, err)
+		return false
+	}
+	attempts, _ := strconv.Atoi(object.GetAnnotations()[p.policy.AttemptsAnnotation]) // missing or invalid is 0
+	if attempts >= p.policy.MaxAttemptsPerOwner {
+		p.logger.Warn("Not remediating, owner reached its remediation limit", append(info, zap.Int("attempts", attempts))...)
+		return false
+	}
+
+	count := strconv.Itoa(attempts + 1)
+	err = p.client.AnnotateOwner(ctx, pod.ObjectMeta.Namespace, *owner, map[string]*string{p.policy.AttemptsAnnotation: &count})
+	if err != nil {
+		p.callFailed("Error counting remediation on owner", info, err)
+		return false
+	}
+	return true
+}
+
+// tell whoever investigates why the owner's Pods restart that we did it, failing only logs since the action is done
+func (p *Base) stampOwner(ctx context.Context, pod *v1.Pod, reason string, action string) {
+	owner := ownerReference(pod)
+	if p.policy.OwnerAnnotationPrefix == "" || owner == nil {
+		return
+	}
+	info := append(podInfo(pod), zap.String("owner", ownerKey(pod)))
+	prefix := p.policy.OwnerAnnotationPrefix
+
+	object, err := p.client.GetOwner(ctx, pod.ObjectMeta.Namespace, *owner)
+	if err != nil {
+		p.callFailed("Error getting owner", info, err)
+		return
+	}
+	count, _ := strconv.Atoi(object.GetAnnotations()[prefix+"action-count"]) // missing or invalid is 0
+	lastAction := fmt.Sprintf("%s %s Pod %s: %s", p.policy.Remediator, action, pod.ObjectMeta.Name, reason)
+	lastActionTime := time.Now().UTC().Format(time.RFC3339)
+	actionCount := strconv.Itoa(count + 1)
+	p.tryWithLogging("Annotating owner", info, func() error {
+		return p.client.AnnotateOwner(ctx, pod.ObjectMeta.Namespace, *owner, map[string]*string{
+			prefix + "last-action":      &lastAction,
+			prefix + "last-action-time": &lastActionTime,
+			prefix + "action-count":     &actionCount,
+		})
+	})
+}
+
+// request approval when needed, a human has to approve before we act
+func (p *Base) approved(ctx context.Context, pod *v1.Pod) bool {
+	granted, annotations := p.policy.Approval.evaluate(pod, time.Now())
+	if granted {
+		return true
+	}
+	if annotations == nil {
+		p.logger.Info("Skipping, waiting for approval", podInfo(pod)...)
+		return false
+	}
+	p.tryWithLogging("Requesting approval", podInfo(pod), func() error {
+		return p.client.AnnotatePod(ctx, pod, annotations)
+	})
+	return false
+}
+
+// Pods on Nodes that are being drained are handled by drain tooling or the cluster-autoscaler, touching them interferes
+func (p *Base) onDrainingNode(pod *v1.Pod) bool {
+	if p.policy.Nodes == nil || pod.Spec.NodeName == "" {
+		return false
+	}
+	node, err := p.policy.Nodes.GetNode(pod.Spec.NodeName)
+	if err != nil {
+		return false // Node is gone
+	}
+	return k8s.IsNodeDraining(node)
+}
+
+// Pods of all namespaces that keep returns true for, nil keeps all, listed in pages of Policy.PageSize so only
+// the kept Pods of a large cluster are held, a namespace that can not be listed is logged and left out
+// with the Pods of the pages before
+func (p *Base) listPods(ctx context.Context, namespaces []string, options metav1.ListOptions, keep func(*v1.Pod) bool) []v1.Pod {
+	var pods []v1.Pod
+	options.Limit = p.policy.PageSize
+	for _, namespace := range namespaces {
+		_, span := p.policy.Tracer.Start(ctx, "list Pods", tracing.KindClient, map[string]string{"namespace": namespace})
+		start := time.Now()
+		err := k8s.ListPodPages(ctx, p.client, namespace, options, func(page *v1.PodList) {
+			for i := range page.Items {
+				if keep == nil || keep(&page.Items[i]) {
+					pods = append(pods, page.Items[i])
+				}
+			}
+		})
+		p.policy.Metrics.ObserveListDuration(p.policy.Remediator, time.Since(start))
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			p.logger.Error("Error getting pod list", zap.String("namespace", namespace), zap.Error(err))
+			p.listFailed()
+		}
+	}
+	return pods
+}
+
+// Pods from Policy.Pods when there is one, like listPods would return them
+func (p *Base) cachedPods(ctx context.Context, namespaces []string, options metav1.ListOptions, keep func(*v1.Pod) bool) []v1.Pod {
+	if p.policy.Pods == nil {
+		return p.listPods(ctx, namespaces, options, keep)
+	}
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		p.logger.Error("Error getting pod list", zap.Error(err)) // untested section
+		p.listFailed()
+		return nil
+	}
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		p.logger.Error("Error getting pod list", zap.Error(err)) // untested section
+		p.listFailed()
+		return nil
+	}
+	cached, err := p.policy.Pods.ListPods(namespaces, labelSelector, fieldSelector)
+	if err != nil {
+		p.logger.Error("Error getting pod list", zap.Error(err))
+		p.listFailed()
+		return nil
+	}
+	var pods []v1.Pod
+	for _, pod := range cached {
+		if keep == nil || keep(pod) {
+			pods = append(pods, *pod)
+		}
+	}
+	return pods
+}
+
+// Policy.Pods, or a cache of our own that stops watching with ctx
+func (p *Base) podCache(ctx context.Context) *k8s.PodCache {
+	if p.policy.Pods != nil {
+		return p.policy.Pods
+	}
+	return k8s.NewPodCache(p.client, ctx.Done(), false)
+}
+
+// call fn with every updated Pod in the namespaces of pods, from podCache, waiting until Policy.Pods has them cached,
+// false when stopped or failed before that, otherwise the returned func stops the calls
+func (p *Base) watchPods(ctx context.Context, pods *k8s.PodCache, namespaces []string, fn k8s.PodHandler) (func(), bool) {
+	if err := pods.Watch(namespaces); err != nil {
+		p.logger.Error("Error watching Pods", zap.Error(err)) // untested section
+		return nil, false
+	}
+	var unsubscribes []func()
+	for _, namespace := range namespaces {
+		unsubscribes = append(unsubscribes, pods.Subscribe(namespace, p.recovered(fn)))
+	}
+	stop := func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}
+	if pods == p.policy.Pods && !pods.WaitForSync(ctx.Done(), namespaces) {
+		stop()
+		return nil, false
+	}
+	return stop, true
+}
+
+// call handle with the Pod of every Event with one of the reasons in the namespaces, from the workers the stream
+// shares with all remediators, false when the Events can not be watched, otherwise the returned func stops the calls
+func (p *Base) subscribeEvents(stream *events.Stream, namespaces []string, reasons []string, handle func(context.Context, *v1.Pod)) (func(), bool) {
+	if err := stream.Watch(namespaces); err != nil {
+		p.logger.Error("Error watching Events", zap.Error(err)) // untested section
+		return nil, false
+	}
+	var unsubscribes []func()
+	for _, namespace := range namespaces {
+		unsubscribes = append(unsubscribes, stream.Subscribe(namespace, reasons, func(ctx context.Context, pod *v1.Pod) {
+			defer p.recoverPanic("handler")
+			handle(ctx, pod)
+		}))
+	}
+	return func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}, true
+}
+
+// fn for informers, whose goroutines a panic would crash
+func (p *Base) recovered(fn k8s.PodHandler) k8s.PodHandler {
+	return func(pod *v1.Pod) {
+		defer p.recoverPanic("handler")
+		fn(pod)
+	}
+}
+
+// deferred where a panic would otherwise crash the process, like handlers and actions: logs and counts it instead,
+// the Pod is handled again on its next update or scan, panics of Run are left to the Supervisor
+func (p *Base) recoverPanic(in string) {
+	if r := recover(); r != nil {
+		p.policy.Metrics.UpdatePanicCount(p.policy.Remediator, in)
+		p.logger.Error("Recovered from panic", zap.String("in", in), zap.Any("panic", r), zap.Stack("stack"))
+	}
+}
+
+// a queue of Pods that Policy.Workers workers get from pods, or the api-server when nil, and pass to handle
+// until it is stopped, its workqueue metrics are named after the remediator and the cluster
+func (p *Base) startPodQueue(pods *k8s.PodCache, handle func(context.Context, *v1.Pod)) *podQueue {
+	name := p.policy.Remediator
+	if p.policy.Cluster != "" {
+		name = p.policy.Cluster + "/" + name
+	}
+	queue := newPodQueue(p.logger, p.client, pods, name, func(ctx context.Context, pod *v1.Pod) {
+		defer p.recoverPanic("handler")
+		handle(ctx, pod)
+	})
+	workers := p.policy.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queue.Start(workers)
+	return queue
+}
+
+// checks the other Pods of the owner, only listing them when a check is configured
+func (p *Base) ownerCanLosePod(ctx context.Context, pod *v1.Pod, owner string) bool {
+	minReadyReplicas := 0
+	if p.checksReadyReplicas {
+		minReadyReplicas = p.policy.MinReadyReplicas
+	}
+	if owner == "" || (p.policy.UnavailableLimit == nil && minReadyReplicas == 0) {
+		return true
+	}
+	pods, err := p.ownerPods(ctx, pod, owner)
+	if err != nil {
+		p.callFailed("Error getting Pod list", podInfo(pod), err)
+		return false
+	}
+
+	// deleting the last semi-working replica turns a partial outage into a full one, so a human has to look at it
+	if ready := countOtherReadyPods(pod, pods); ready < minReadyReplicas {
+		p.logger.Warn("Not remediating, owner has too few other Ready Pods", append(podInfo(pod),
+			zap.String("owner", owner),
+			zap.Int("ready", ready),
+			zap.Int("minReadyReplicas", minReadyReplicas),
+		)...)
+		return false
+	}
+
+	if !p.policy.UnavailableLimit.Allows(owner, pods) {
+		p.logger.Info("Skipping, too many Pods of owner unavailable", append(podInfo(pod), zap.String("owner", owner))...)
+		return false
+	}
+	return true
+}
+
+// labels that differ between the Pods of one owner, the name of a StatefulSet Pod and the revision a Pod runs
+var podLabels = []string{
+	"statefulset.kubernetes.io/pod-name",
+	"apps.kubernetes.io/pod-index",
+	"controller-revision-hash",
+	"pod-template-generation",
+}
+
+// the Pods of the owner, from Policy.Pods when it has the namespace cached, otherwise listed with the labels the Pod
+// shares with the other Pods of its owner, so not the whole namespace is listed for every Pod
+func (p *Base) ownerPods(ctx context.Context, pod *v1.Pod, owner string) ([]v1.Pod, error) {
+	namespace := pod.ObjectMeta.Namespace
+	var pods []v1.Pod
+	keep := func(other *v1.Pod) {
+		if ownerKey(other) == owner {
+			pods = append(pods, *other)
+		}
+	}
+	if p.policy.Pods != nil && p.policy.Pods.HasSynced() {
+		inNamespace := fields.OneTermEqualSelector("metadata.namespace", namespace)
+		for _, watched := range []string{namespace, ""} {
+			cached, err := p.policy.Pods.ListPods([]string{watched}, labels.Everything(), inNamespace)
+			if err != nil {
+				continue // not watched
+			}
+			for _, other := range cached {
+				keep(other)
+			}
+			return pods, nil
+		}
+	}
+
+	shared := labels.Set{}
+	for key, value := range pod.ObjectMeta.Labels {
+		shared[key] = value
+	}
+	for _, key := range podLabels {
+		delete(shared, key)
+	}
+	list, err := p.client.GetPods(ctx, namespace, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(shared).String()})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		keep(&list.Items[i])
+	}
+	return pods, nil
+}
+
+// pods are the Pods of its owner
+func countOtherReadyPods(pod *v1.Pod, pods []v1.Pod) int {
+	ready := 0
+	for i := range pods {
+		other := &pods[i]
+		if other.ObjectMeta.Name != pod.ObjectMeta.Name && other.ObjectMeta.DeletionTimestamp == nil && isPodReady(other) {
+			ready++
+		}
+	}
+	return ready
+}
+
+// cordon unless a safety check holds it back, we are observing or dry running, a pre hook vetoed or the Node is in
+// cooldown, when the shared rate limit is exceeded it is queued for the next window like actions on Pods
+func (p *Base) cordonNode(ctx context.Context, node *v1.Node, reason string) {
+	nodeInfo := []zap.Field{zap.String("node", node.ObjectMeta.Name)}
+	object := audit.ObjectRef{Kind: "Node", Name: node.ObjectMeta.Name, UID: string(node.ObjectMeta.UID)}
+	ctx, span := p.policy.Tracer.Start(ctx, "decide", tracing.KindInternal, map[string]string{
+		"node": object.Name, "reason": reason, "action": "cordoned",
+	})
+	defer span.End()
+	if !p.policy.Leader.Leading() {
+		p.logger.Debug("Skipping, not the leader", nodeInfo...)
+		return
+	}
+	if why := p.nodeNotAllowed(node); why != "" {
+		p.record(ctx, object, reason, "cordoned", metrics.ResultSkipped, why)
+		return
+	}
+	if time.Now().Before(p.policy.ObserveUntil) {
+		p.logger.Info("Observing, would cordon", nodeInfo...)
+		p.record(ctx, object, reason, "cordoned", metrics.ResultDryRun, "observing")
+		return
+	}
+	if p.policy.dryRun("") {
+		p.logger.Info("Dry run, would cordon", nodeInfo...)
+		detail := "dry run"
+		if p.policy.ServerSideDryRun {
+			detail = p.dryRunResult(nodeInfo, p.client.CordonNode(k8s.WithDryRun(ctx), node))
+		}
+		p.record(ctx, object, reason, "cordoned", metrics.ResultDryRun, detail)
+		return
+	}
+
+	key := nodeKey(node)
+	waited := false
+	queued := !p.policy.RateLimiter.Do(ctx, key, func(take func() bool) bool {
+		defer p.recoverPanic("action")
+		ctx, started := p.startAction(ctx)
+		if !started {
+			return false
+		}
+		why := p.nodeNotAllowed(node)
+		if why == "" && waited { // someone could have cordoned it while queued
+			current, err := p.client.GetNode(ctx, node.ObjectMeta.Name)
+			if err != nil || current.Spec.Unschedulable {
+				why = "Node was cordoned or could not be fetched"
+			}
+		}
+		if why == "" && !take() {
+			waited = true
+			return false
+		}
+		if why == "" {
+			why = p.preHooks(ctx, object, reason, "cordoned")
+		}
+		if why == "" && !p.policy.Cooldown.TryStart(key) {
+			why = "Node in cooldown"
+		}
+		if why != "" {
+			p.record(ctx, object, reason, "cordoned", metrics.ResultSkipped, why)
+			return false
+		}
+		p.tryCordonNode(ctx, node, reason)
+		return true
+	})
+	if queued {
+		p.logger.Info("Rate limited, queued for next window", nodeInfo...)
+	}
+}
+
+// why a safety check holds cordoning the Node back, "" when allowed
+func (p *Base) nodeNotAllowed(node *v1.Node) string {
+	nodeInfo := []zap.Field{zap.String("node", node.ObjectMeta.Name)}
+	if !p.policy.Leader.Leading() { // lost leadership while queued
+		p.logger.Info("Skipping, not the leader", nodeInfo...)
+		return "not the leader"
+	}
+	if p.policy.KillSwitch.Engaged() {
+		p.logger.Info("Skipping, kill switch engaged", nodeInfo...)
+		return "kill switch engaged"
+	}
+	if p.policy.Controls.Paused(p.policy.Remediator) {
+		p.logger.Info("Skipping, paused", nodeInfo...)
+		return "paused"
+	}
+	if !p.policy.Maintenance.Allows("", time.Now()) { // Nodes have no namespace, so only the default schedule applies
+		p.logger.Info("Skipping, outside maintenance window", nodeInfo...)
+		return "outside maintenance window"
+	}
+	if p.policy.Cooldown.Active(nodeKey(node)) {
+		p.logger.Info("Skipping, Node in cooldown", nodeInfo...)
+		return "Node in cooldown"
+	}
+	return ""
+}
+
+// Nodes cool down like owners, without a namespace their key can not be the one of an owner
+func nodeKey(node *v1.Node) string {
+	return "/Node/" + node.ObjectMeta.Name
+}
+
+// ctx for a remediation about to start, false once shutdown began, so nothing new starts while started ones get
+// until the drain timed out
+func (p *Base) startAction(ctx context.Context) (context.Context, bool) {
+	if p.policy.Drain.Draining() {
+		p.logger.Debug("Skipping, shutting down")
+		return ctx, false
+	}
+	return p.policy.Drain.Context(ctx), true
+}
+
+func (p *Base) tryCordonNode(ctx context.Context, node *v1.Node, reason string) {
+	object := audit.ObjectRef{Kind: "Node", Name: node.ObjectMeta.Name, UID: string(node.ObjectMeta.UID)}
+	p.tryWithLogging("Cordoning Node", []zap.Field{zap.String("node", object.Name)}, func() error {
+		_, call := p.policy.Tracer.Start(ctx, "cordon Node", tracing.KindClient, map[string]string{"node": object.Name})
+		err := p.client.CordonNode(ctx, node)
+		call.SetError(err)
+		call.End()
+		p.recordResult(ctx, notify.Event{Object: object, Reason: reason, Action: "cordoned"}, err)
+		return err
+	})
+}
+
+func (p *Base) tryDeletePod(ctx context.Context, pod v1.Pod, reason string) {
+	info := podInfo(&pod)
+	event := podEvent(&pod, reason, "deleted")
+	if !p.gatherEvidence(ctx, &pod, &event) {
+		return
+	}
+
+	p.logger.Info("Deleting Pod", info...)
+	_, call := p.policy.Tracer.Start(ctx, "delete Pod", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	err := p.client.DeletePod(ctx, &pod, p.deleteOptions(&pod))
+	call.SetError(err)
+	call.End()
+	switch k8s.Classify(err) {
+	case k8s.ErrorConflict:
+		p.logger.Info("Pod changed since we looked at it, not deleting", info...)
+		return
+	case k8s.ErrorNotFound:
+		p.logger.Info("Pod already gone", info...)
+		err = nil // what deleting it was for
+	}
+	p.recordResult(ctx, event, err)
+	if err != nil {
+		p.callFailed("Error Deleting Pod", info, err)
+		return
+	}
+	p.stampOwner(ctx, &pod, reason, "deleted")
+}
+
+func (p *Base) tryEvictPod(ctx context.Context, pod v1.Pod, reason string) {
+	info := podInfo(&pod)
+	event := podEvent(&pod, reason, "evicted")
+	if !p.gatherEvidence(ctx, &pod, &event) {
+		return
+	}
+
+	p.logger.Info("Evicting Pod", info...)
+	_, call := p.policy.Tracer.Start(ctx, "evict Pod", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	err := p.client.EvictPod(ctx, &pod, p.deleteOptions(&pod))
+	call.SetError(err)
+	call.End()
+	if k8s.Classify(err) == k8s.ErrorNotFound {
+		p.logger.Info("Pod already gone", info...)
+		err = nil // what evicting it was for
+	}
+	if err == nil {
+		p.forgetBlockedEvictions(pod.ObjectMeta.UID)
+		p.recordResult(ctx, event, nil)
+		p.stampOwner(ctx, &pod, reason, "evicted")
+		return
+	}
+	if k8s.Classify(err) == k8s.ErrorConflict {
+		p.forgetBlockedEvictions(pod.ObjectMeta.UID)
+		p.logger.Info("Pod changed since we looked at it, not evicting", info...)
+		return
+	}
+	blockedBy, ok := err.(*k8s.EvictionBlockedError)
+	if !ok {
+		p.recordResult(ctx, event, err)
+		p.callFailed("Error Evicting Pod", info, err)
+		return
+	}
+	budget := blockedBy.PodDisruptionBudget
+	if budget == "" {
+		budget = p.blockingPodDisruptionBudget(ctx, &pod)
+	}
+
+	blocked := p.countBlockedEviction(pod.ObjectMeta.UID)
+	p.policy.Skipped.UpdateSkippedCount("pod-disruption-budget")
+	p.logger.Info("Eviction blocked", append(info,
+		zap.String("podDisruptionBudget", budget),
+		zap.Int("blockedEvictions", blocked),
+	)...)
+
+	if p.policy.DeleteAfterBlockedEvictions > 0 && blocked >= p.policy.DeleteAfterBlockedEvictions {
+		p.forgetBlockedEvictions(pod.ObjectMeta.UID)
+		p.tryDeletePod(ctx, pod, reason)
+	}
+}
+
+// count what happened to a remediation in metrics, write it to the audit log and add it to the span in ctx,
+// skips and dry runs are also counted and logged with why
+func (p *Base) record(ctx context.Context, object audit.ObjectRef, reason string, action string, result string, detail string) {
+	p.recordAudit(ctx, audit.Record{Object: object, Reason: reason, Action: action, Outcome: result, Detail: detail})
+}
+
+// record with the remediator, decision, dry run and time filled in
+func (p *Base) recordAudit(ctx context.Context, record audit.Record) {
+	object, result := record.Object, record.Outcome
+	p.policy.Metrics.UpdateRemediationCount(p.policy.Remediator, object.Namespace, record.Reason, record.Action, result)
+	if result == metrics.ResultSkipped || result == metrics.ResultDryRun {
+		skipped := skipReason(record.Detail)
+		p.policy.Skipped.UpdateSkippedCount(skipped)
+		p.logger.Debug("Not remediated", zap.String("kind", object.Kind), zap.String("name", object.Name),
+			zap.String("namespace", object.Namespace), zap.String("reason", record.Reason), zap.String("skipReason", skipped))
+	}
+	span := tracing.SpanFromContext(ctx)
+	span.SetAttribute("result", result)
+	if record.Detail != "" {
+		span.SetAttribute("detail", record.Detail)
+	}
+
+	record.Decision = audit.DecisionRemediate
+	if result == metrics.ResultSkipped {
+		record.Decision = audit.DecisionSkip
+	}
+	record.Time = time.Now().UTC()
+	record.Remediator = p.policy.Remediator
+	record.Cluster = p.policy.Cluster
+	record.DryRun = result == metrics.ResultDryRun
+	p.policy.History.Record(record)
+	if err := p.policy.Audit.Record(record); err != nil {
+		p.logger.Error("Error writing audit log", zap.Error(err)) // untested section
+	}
+}
+
+// record the outcome of an action, notify humans about it and call the post hooks
+func (p *Base) recordResult(ctx context.Context, event notify.Event, err error) {
+	event.Type = notify.EventRemediation
+	event.Remediator = p.policy.Remediator
+	event.Cluster = p.policy.Cluster
+	event.Outcome = metrics.ResultSuccess
+	if err != nil {
+		event.Outcome, event.Detail = metrics.ResultError, err.Error()
+	}
+	p.recordAudit(ctx, audit.Record{
+		Object: event.Object, Reason: event.Reason, Action: event.Action, Outcome: event.Outcome, Detail: event.Detail,
+		Logs: event.Logs, Diagnostics: event.Diagnostics,
+	})
+	_, span := p.policy.Tracer.Start(ctx, "notify", tracing.KindInternal, nil)
+	p.policy.Notifiers.Notify(event)
+	span.End()
+	p.postHooks(ctx, HookRequest{Object: event.Object, Reason: event.Reason, Action: event.Action, Outcome: event.Outcome, Detail: event.Detail})
+}
+
+// "owner in cooldown" -> "owner-in-cooldown", the label of the remediations_skipped metric,
+// what follows a colon like the error of a rejected dry run is left out
+func skipReason(why string) string {
+	return skipReasonReplacer.Replace(strings.ToLower(strings.SplitN(why, ":", 2)[0]))
+}
+
+var skipReasonReplacer = strings.NewReplacer(" ", "-", ",", "")
+
+// logs, events and diagnostics bundle of the Pod for the event, false when the bundle is required but could not be stored
+func (p *Base) gatherEvidence(ctx context.Context, pod *v1.Pod, event *notify.Event) bool {
+	event.Logs = p.captureLogs(ctx, pod)
+	event.Events = p.captureEvents(ctx, pod)
+	if p.policy.Diagnostics == nil {
+		return true
+	}
+	_, call := p.policy.Tracer.Start(ctx, "collect diagnostics", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	location, err := p.policy.Diagnostics.Collect(ctx, pod)
+	call.SetError(err)
+	call.End()
+	if err == nil {
+		event.Diagnostics = location
+		return true
+	}
+	p.logger.Warn("Error collecting diagnostics", append(podInfo(pod), zap.Error(err))...)
+	if !p.policy.RequireDiagnostics {
+		return true
+	}
+	p.record(ctx, event.Object, event.Reason, event.Action, metrics.ResultSkipped, "Diagnostics not stored")
+	return false
+}
+
+// the Pod takes the logs of its failing container with it, so keep the end of the previous run as evidence,
+// nil when disabled, no container restarted or fetching failed
+func (p *Base) captureLogs(ctx context.Context, pod *v1.Pod) *audit.ContainerLogs {
+	if p.policy.CaptureLogLines <= 0 {
+		return nil
+	}
+	container := failingContainer(pod)
+	if container == "" {
+		return nil
+	}
+	_, call := p.policy.Tracer.Start(ctx, "get Pod logs", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	lines := p.policy.CaptureLogLines
+	logs, err := p.client.GetPodLogs(ctx, pod, &v1.PodLogOptions{Container: container, Previous: true, TailLines: &lines})
+	call.SetError(err)
+	call.End()
+	if err != nil {
+		p.callFailed("Error getting logs", append(podInfo(pod), zap.String("container", container)), err)
+		return nil
+	}
+	return &audit.ContainerLogs{Container: container, Tail: logs}
+}
+
+// the last Warning events of the Pod, like "BackOff (x12): Back-off restarting failed container", tell humans why it was
+// failing without the Pod, nil when disabled, there were none or fetching failed
+func (p *Base) captureEvents(ctx context.Context, pod *v1.Pod) []string {
+	if p.policy.CaptureEvents <= 0 {
+		return nil
+	}
+	_, call := p.policy.Tracer.Start(ctx, "get Pod events", tracing.KindClient, map[string]string{"namespace": pod.ObjectMeta.Namespace, "pod": pod.ObjectMeta.Name})
+	events, err := k8s.GetPodEvents(ctx, p.client, pod)
+	call.SetError(err)
+	call.End()
+	if err != nil {
+		p.callFailed("Error getting events", podInfo(pod), err)
+		return nil
+	}
+	var warnings []string
+	for i := range events {
+		event := &events[i]
+		if event.Type != v1.EventTypeWarning {
+			continue
+		}
+		warning := event.Reason
+		if count := k8s.EventCount(event); count > 1 {
+			warning += fmt.Sprintf(" (x%d)", count)
+		}
+		warnings = append(warnings, warning+": "+event.Message)
+	}
+	if len(warnings) > p.policy.CaptureEvents {
+		warnings = warnings[len(warnings)-p.policy.CaptureEvents:]
+	}
+	return warnings
+}
+
+// the container in CrashLoopBackOff, otherwise the one that restarted most, "" when none restarted
+func failingContainer(pod *v1.Pod) string {
+	failing, restarts := "", int32(0)
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return status.Name
+		}
+		if status.RestartCount > restarts {
+			failing, restarts = status.Name, status.RestartCount
+		}
+	}
+	return failing
+}
+
+func podEvent(pod *v1.Pod, reason string, action string) notify.Event {
+	event := notify.Event{Object: podRef(pod), Owner: ownerKey(pod), Reason: reason, Action: action}
+	for _, status := range pod.Status.ContainerStatuses {
+		event.Restarts += status.RestartCount
+	}
+	return event
+}
+
+func podRef(pod *v1.Pod) audit.ObjectRef {
+	return audit.ObjectRef{Kind: "Pod", Namespace: pod.ObjectMeta.Namespace, Name: pod.ObjectMeta.Name, UID: string(pod.ObjectMeta.UID)}
+}
+
+// only remove the Pod we looked at, not a replacement that got its name since
+func (p *Base) deleteOptions(pod *v1.Pod) *metav1.DeleteOptions {
+	options := &metav1.DeleteOptions{}
+	if p.policy.DeleteOptions != nil {
+		options = p.policy.DeleteOptions.DeepCopy()
+	}
+	uid := pod.ObjectMeta.UID
+	options.Preconditions = &metav1.Preconditions{UID: &uid}
+	if p.policy.PreconditionResourceVersion {
+		resourceVersion := pod.ObjectMeta.ResourceVersion
+		options.Preconditions.ResourceVersion = &resourceVersion
+	}
+	return options
+}
+
+func (p *Base) countBlockedEviction(uid types.UID) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.blockedEvictions[uid]++
+	return p.blockedEvictions[uid]
+}
+
+func (p *Base) forgetBlockedEvictions(uid types.UID) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.blockedEvictions, uid)
+}
+
+// remember when the Pod was first seen unhealthy, Pods that recovered on their own are forgotten after a day
+func (p *Base) noticeUnhealthy(uid types.UID) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now()
+	if _, ok := p.unhealthySince[uid]; ok {
+		return
+	}
+	for other, since := range p.unhealthySince {
+		if now.Sub(since) > 24*time.Hour {
+			delete(p.unhealthySince, other)
+		}
+	}
+	p.unhealthySince[uid] = now
+}
+
+// how long the Pod was unhealthy before we remediated it
+func (p *Base) forgetUnhealthy(uid types.UID) time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	since, ok := p.unhealthySince[uid]
+	if !ok {
+		return 0 // untested section
+	}
+	delete(p.unhealthySince, uid)
+	return time.Since(since)
+}
+
+// name of the PodDisruptionBudget covering the Pod, for logging why an eviction was blocked
+func (p *Base) blockingPodDisruptionBudget(ctx context.Context, pod *v1.Pod) string {
+	pdbs, err := p.client.GetPodDisruptionBudgets(ctx, pod.ObjectMeta.Namespace)
+	if err != nil {
+		p.callFailed("Error getting PodDisruptionBudget list", podInfo(pod), err)
+		return ""
+	}
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector) // nil selects nothing, empty everything
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+			return pdb.ObjectMeta.Name
+		}
+	}
+	return ""
 }
 
 func (p *Base) tryWithLogging(message string, logInfo []zap.Field, fn func() error) {
 	p.logger.Info(message, logInfo...)
 	if err := fn(); err != nil {
-		p.logger.Warn("Error "+message, append(logInfo, zap.Error(err))...)
+		p.callFailed("Error "+message, logInfo, err)
+	}
+}
+
+// count a failed api call by its class, Forbidden is logged as an error since only fixing RBAC helps
+func (p *Base) callFailed(message string, logInfo []zap.Field, err error) {
+	class := k8s.Classify(err)
+	p.policy.Metrics.UpdateAPIErrorCount(p.policy.Remediator, string(class))
+	logInfo = append(logInfo, zap.String("errorClass", string(class)), zap.Error(err))
+	if class == k8s.ErrorForbidden {
+		p.logger.Error(message+", check the RBAC of kube-remediator", logInfo...)
+		return
+	}
+	p.logger.Warn(message, logInfo...)
+}
+
+func podInfo(pod *v1.Pod) []zap.Field {
+	return []zap.Field{
+		zap.String("name", pod.ObjectMeta.Name),
+		zap.String("namespace", pod.ObjectMeta.Namespace),
 	}
 }
@@ -0,0 +1,234 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/events"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// a condition of Pods the other remediators do not cover and what to do about them, like
+// status.containerStatuses.exists(c, c.restartCount > 3 && c.lastState.terminated.exitCode == 137)
+type RuleConfig struct {
+	Name       string `mapstructure:"name"`       // the reason of actions, metrics and audit records, like OOMKilledRepeatedly
+	Expression string `mapstructure:"expression"` // CEL over metadata, spec and status of the Pod, true remediates it
+	Action     string `mapstructure:"action"`     // "" evicts, actions can override it by name
+}
+
+type RulesConfig struct {
+	Rules []RuleConfig `mapstructure:"rules"` // the first matching rule remediates a Pod
+}
+
+func DefaultRulesConfig() RulesConfig {
+	return RulesConfig{Rules: []RuleConfig{}}
+}
+
+func (c RulesConfig) Validate() error {
+	_, err := compileRules(c.Rules)
+	return err
+}
+
+// a compiled RuleConfig
+type rule struct {
+	RuleConfig
+	program cel.Program
+}
+
+// the fields of a Pod rules can use, like kubectl get pod -o yaml shows them
+var ruleDeclarations = cel.Declarations(
+	decls.NewIdent("metadata", decls.Dyn, nil),
+	decls.NewIdent("spec", decls.Dyn, nil),
+	decls.NewIdent("status", decls.Dyn, nil),
+)
+
+func compileRules(configs []RuleConfig) ([]rule, error) {
+	env, err := cel.NewEnv(ruleDeclarations)
+	if err != nil {
+		return nil, err // untested section
+	}
+	names := map[string]bool{}
+	rules := make([]rule, 0, len(configs))
+	for i, config := range configs {
+		if config.Name == "" {
+			return nil, fmt.Errorf("rules[%d]: name is required", i)
+		}
+		// actions match reasons in any case
+		if names[strings.ToLower(config.Name)] {
+			return nil, fmt.Errorf("rules[%d]: name %q is used twice", i, config.Name)
+		}
+		names[strings.ToLower(config.Name)] = true
+		if _, ok := actions[strings.ToLower(config.Action)]; config.Action != "" && !ok {
+			return nil, fmt.Errorf("rules[%d]: unknown action %q, use %s", i, config.Action, strings.Join(ActionNames(), ", "))
+		}
+		program, err := compileRule(env, config.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %s: %v", i, config.Name, err)
+		}
+		rules = append(rules, rule{RuleConfig: config, program: program})
+	}
+	return rules, nil
+}
+
+// expressions have to be true or false, fields of Pods are only known when evaluated
+func compileRule(env cel.Env, expression string) (cel.Program, error) {
+	parsed, issues := env.Parse(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	checked, issues := env.Check(parsed)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if result := checked.ResultType(); !proto.Equal(result, decls.Bool) && !proto.Equal(result, decls.Dyn) {
+		return nil, fmt.Errorf("expression must be a bool, got %v", result)
+	}
+	return env.Program(checked)
+}
+
+// an expression that fails, like for a field the Pod does not have, does not match
+func (r *rule) matches(pod *v1.Pod) (bool, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return false, err // untested section
+	}
+	value, _, err := r.program.Eval(map[string]interface{}{
+		"metadata": object["metadata"],
+		"spec":     object["spec"],
+		"status":   object["status"],
+	})
+	if err != nil {
+		return false, err
+	}
+	matched, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression is %v, not a bool", value.Value())
+	}
+	return matched, nil
+}
+
+// Remediates Pods matching custom rules in CEL, for conditions the built-in remediators do not cover
+type RuleRemediator struct {
+	Base
+	Config RulesConfig
+	rules  []rule
+	stream *events.Stream
+
+	lock   sync.Mutex
+	failed map[string]error // rule name -> an error of the running scan
+}
+
+func init() {
+	Register("RuleRemediator", func(configs Configs) Remediator {
+		return &RuleRemediator{Config: configs.RuleRemediator}
+	})
+}
+
+func (p *RuleRemediator) Name() string {
+	return "RuleRemediator"
+}
+
+func (p *RuleRemediator) Setup(logger *zap.Logger, client k8s.ClientInterface, policy *Policy) error {
+	rules, err := compileRules(p.Config.Rules)
+	if err != nil {
+		return err
+	}
+	p.rules = rules
+	p.failed = map[string]error{}
+	return p.Base.Setup(logger, client, policy)
+}
+
+// also check the Pods of Events right away, rules can be about any of them
+func (p *RuleRemediator) UseEventStream(stream *events.Stream) {
+	p.stream = stream
+}
+
+func (p *RuleRemediator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if len(p.rules) == 0 {
+		p.logger.Debug("No rules, stopping")
+		return
+	}
+	if p.stream != nil && !p.policy.Once {
+		stop, ok := p.subscribeEvents(p.stream, p.policy.Namespaces.ListNamespaces(), events.Reasons, p.remediate)
+		if !ok {
+			return
+		}
+		defer stop() // the stream outlives us when reloading config
+	}
+	// scans still find the Pods that match without an Event
+	p.reconcileEvery(ctx, p.remediatePods, 5*time.Minute)
+}
+
+func (p *RuleRemediator) remediatePods(ctx context.Context) {
+	p.logger.Info("Running")
+	defer p.logFailedRules()
+
+	matching := map[string]*rule{} // by namespace/name
+	pods := p.cachedPods(ctx, p.policy.Namespaces.ListNamespaces(), p.policy.listOptions(metav1.ListOptions{}), func(pod *v1.Pod) bool {
+		matching[pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name] = p.matching(pod)
+		return matching[pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name] != nil
+	})
+
+	for _, pod := range pods {
+		p.remediateWith(ctx, pod, matching[pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name])
+	}
+}
+
+// a Pod of an Event, failing rules are logged with the next scan
+func (p *RuleRemediator) remediate(ctx context.Context, pod *v1.Pod) {
+	if rule := p.matching(pod); rule != nil {
+		p.remediateWith(ctx, *pod, rule)
+	}
+}
+
+func (p *RuleRemediator) remediateWith(ctx context.Context, pod v1.Pod, rule *rule) {
+	action := strings.ToLower(rule.Action)
+	if action == "" {
+		action = ActionEvict
+	}
+	p.remediatePod(ctx, pod, rule.Name, action, func(pod *v1.Pod) bool { return p.matches(rule, pod) })
+}
+
+// the first rule the Pod matches, nil when none does or the Pod is filtered
+func (p *RuleRemediator) matching(pod *v1.Pod) *rule {
+	for i := range p.rules {
+		if p.matches(&p.rules[i], pod) {
+			if !p.passes(nil, pod) {
+				return nil
+			}
+			return &p.rules[i]
+		}
+	}
+	return nil
+}
+
+func (p *RuleRemediator) matches(rule *rule, pod *v1.Pod) bool {
+	matched, err := rule.matches(pod)
+	if err != nil {
+		p.lock.Lock()
+		p.failed[rule.Name] = err
+		p.lock.Unlock()
+	}
+	return matched
+}
+
+// once per rule and scan, an expression failing for some Pods is often fine, for all of them it has a typo
+func (p *RuleRemediator) logFailedRules() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for name, err := range p.failed {
+		p.logger.Info("Rule failed for some Pods, has() checks optional fields", zap.String("rule", name), zap.Error(err))
+		delete(p.failed, name)
+	}
+}
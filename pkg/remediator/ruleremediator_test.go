@@ -0,0 +1,157 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/events"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/mock"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"sync"
+	"testing"
+)
+
+const oomKilledRepeatedly = "status.containerStatuses.exists(c, c.restartCount > 3 && c.lastState.terminated.exitCode == 137)"
+
+type TestRuleRemediatorSuite struct {
+	suite.Suite
+	logger         *zap.Logger
+	mockController *gomock.Controller
+	mockClient     *mock_k8s.MockClientInterface
+	pods           []corev1.Pod
+	policy         remediator.Policy
+	config         remediator.RulesConfig
+	t              *testing.T
+}
+
+func TestSuiteRuleRemediator(t *testing.T) {
+	suite.Run(t, &TestRuleRemediatorSuite{t: t})
+}
+
+func (suite *TestRuleRemediatorSuite) SetupTest() {
+	suite.logger, _ = zap.NewDevelopment()
+	suite.mockController = gomock.NewController(suite.t)
+	suite.mockClient = mock_k8s.NewMockClientInterface(suite.mockController)
+	suite.policy = remediator.Policy{}
+	suite.config = remediator.RulesConfig{Rules: []remediator.RuleConfig{{Name: "OOMKilledRepeatedly", Expression: oomKilledRepeatedly}}}
+	suite.pods = []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "sidecar", RestartCount: 0},
+			{Name: "app", RestartCount: 5, LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137}}},
+		}},
+	}}
+}
+
+func (suite *TestRuleRemediatorSuite) TeardownTest() {
+	suite.mockController.Finish()
+}
+
+func (suite *TestRuleRemediatorSuite) run() {
+	ruleRemediator := &remediator.RuleRemediator{Config: suite.config}
+	err := ruleRemediator.Setup(suite.logger, suite.mockClient, &suite.policy)
+	assert.Equal(suite.t, err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // cancel first so we can just run once and exit
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	ruleRemediator.Run(ctx, &wg)
+}
+
+func (suite *TestRuleRemediatorSuite) TestEvictsPodsMatchingARule() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestRuleRemediatorSuite) TestKeepsPodsMatchingNoRule() {
+	suite.pods[0].Status.ContainerStatuses[1].RestartCount = 3
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestRuleRemediatorSuite) TestUsesActionOfFirstMatchingRule() {
+	suite.config.Rules = append([]remediator.RuleConfig{
+		{Name: "Unscheduled", Expression: `has(spec.nodeName)`, Action: "notify"}, // does not match
+		{Name: "TwoContainers", Expression: `status.containerStatuses.size() == 2`, Action: "Delete"},
+	}, suite.config.Rules...)
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().DeletePod(gomock.Any(), &suite.pods[0], gomock.Any()).Return(nil)
+	suite.run()
+}
+
+func (suite *TestRuleRemediatorSuite) TestDoesNotMatchWhenExpressionFails() {
+	suite.config.Rules[0].Expression = `status.reason == "Evicted"` // the Pod has no reason
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.run()
+}
+
+func (suite *TestRuleRemediatorSuite) TestEvaluatesRuleAgainWhenConfirming() {
+	suite.policy.ConfirmBeforeAction = true
+	recovered := suite.pods[0]
+	recovered.Status = corev1.PodStatus{}
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{Items: suite.pods}, nil)
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&recovered, nil)
+	suite.run()
+}
+
+func (suite *TestRuleRemediatorSuite) TestChecksPodsOfEvents() {
+	suite.mockClient.EXPECT().GetPods(gomock.Any(), "").Return(&corev1.PodList{}, nil) // the Pod only matches after the scan
+	suite.mockClient.EXPECT().GetPod(gomock.Any(), "default", "foo").Return(&suite.pods[0], nil)
+	evicted := make(chan string, 1)
+	suite.mockClient.EXPECT().EvictPod(gomock.Any(), &suite.pods[0], gomock.Any()).
+		DoAndReturn(func(_ context.Context, pod *corev1.Pod, _ *metav1.DeleteOptions) error {
+			evicted <- pod.ObjectMeta.Name
+			return nil
+		})
+	clientSet := fake.NewSimpleClientset(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "foo.unhealthy"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "foo"},
+		Reason:         "Unhealthy",
+	})
+	suite.mockClient.EXPECT().NewSharedInformerFactory("").Return(informers.NewSharedInformerFactory(clientSet, 0), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := events.NewStream(suite.logger, suite.mockClient, ctx.Done(), 1)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	wg.Add(2)
+	go stream.Run(ctx, &wg)
+	ruleRemediator := &remediator.RuleRemediator{Config: suite.config}
+	ruleRemediator.UseEventStream(stream)
+	assert.NilError(suite.t, ruleRemediator.Setup(suite.logger, suite.mockClient, &suite.policy))
+	go ruleRemediator.Run(ctx, &wg)
+	assert.Equal(suite.t, <-evicted, "foo")
+}
+
+func (suite *TestRuleRemediatorSuite) TestDoesNothingWithoutRules() {
+	suite.config.Rules = nil
+	suite.run()
+}
+
+func TestRejectsInvalidRules(t *testing.T) {
+	valid := remediator.RuleConfig{Name: "OOMKilledRepeatedly", Expression: oomKilledRepeatedly, Action: "evict"}
+	assert.NilError(t, remediator.RulesConfig{Rules: []remediator.RuleConfig{valid}}.Validate())
+
+	invalid := map[string]remediator.RuleConfig{
+		"rules[1]: name is required":                                {Expression: "true"},
+		`rules[1]: name "oomkilledrepeatedly" is used twice`:        {Name: "oomkilledrepeatedly", Expression: "true"},
+		`rules[1]: unknown action "reboot"`:                         {Name: "Other", Expression: "true", Action: "reboot"},
+		"rules[1]: Other: ERROR: <input>:1:23: Syntax error":        {Name: "Other", Expression: "status.phase == 'x' &&"},
+		"rules[1]: Other: expression must be a bool, got primitive": {Name: "Other", Expression: "1 + 2"},
+	}
+	for message, rule := range invalid {
+		err := remediator.RulesConfig{Rules: []remediator.RuleConfig{valid, rule}}.Validate()
+		assert.ErrorContains(t, err, message)
+	}
+}
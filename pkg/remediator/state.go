@@ -0,0 +1,57 @@
+package remediator
+
+import (
+	"time"
+)
+
+// what the shared safety checks remember, served on /debug/state to debug memory growth and stuck remediations
+type PolicyState struct {
+	KillSwitchEngaged bool                    `json:"killSwitchEngaged"`
+	Leader            bool                    `json:"leader"`             // always true without leader election
+	RateLimiter       *RateLimiterState       `json:"rateLimiter"`        // nil when unlimited
+	Cooldowns         map[string]time.Time    `json:"cooldowns"`          // owner -> last remediation
+	Intervals         map[string]time.Time    `json:"namespaceIntervals"` // namespace -> last remediation
+	Backoffs          map[string]BackoffState `json:"backoffs"`           // owner -> attempts
+	Unavailable       map[string]time.Time    `json:"unavailableSince"`   // owner -> first remediation not Ready yet
+}
+
+// what a single remediator remembers
+type RemediatorState struct {
+	LastScan         time.Time      `json:"lastScan"`            // zero before the first scan
+	LastProgress     time.Time      `json:"lastProgress"`        // last scan that listed everything, see Watchdog
+	Unhealthy        int            `json:"unhealthy"`           // Pods seen needing remediation, not remediated yet
+	BlockedEvictions map[string]int `json:"blockedEvictions"`    // Pod UID -> evictions blocked by a PodDisruptionBudget
+	DryRun           bool           `json:"dryRun"`              // only logs what it would do, namespaces can override it
+	ObserveUntil     time.Time      `json:"observeUntil"`        // only logs until then, zero when acting right away
+	InWindow         bool           `json:"inMaintenanceWindow"` // may act now, per namespace schedules can differ
+}
+
+func (p *Policy) State() PolicyState {
+	cooldowns, intervals := p.NamespaceOverrides.State()
+	for owner, last := range p.Cooldown.State() {
+		cooldowns[owner] = last
+	}
+	return PolicyState{
+		KillSwitchEngaged: p.KillSwitch.Engaged(),
+		Leader:            p.Leader.Leading(),
+		RateLimiter:       p.RateLimiter.State(),
+		Cooldowns:         cooldowns,
+		Intervals:         intervals,
+		Backoffs:          p.Backoff.State(),
+		Unavailable:       p.UnavailableLimit.State(),
+	}
+}
+
+func (p *Base) State() RemediatorState {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	state := RemediatorState{LastScan: p.lastScan, LastProgress: p.progressed, Unhealthy: len(p.unhealthySince), BlockedEvictions: map[string]int{}, InWindow: true}
+	if p.policy != nil { // nil before Setup
+		state.DryRun, state.ObserveUntil = p.policy.globalDryRun(), p.policy.ObserveUntil
+		state.InWindow = p.policy.Maintenance.Allows("", time.Now())
+	}
+	for uid, blocked := range p.blockedEvictions {
+		state.BlockedEvictions[string(uid)] = blocked
+	}
+	return state
+}
@@ -0,0 +1,48 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestPolicyStateShowsSharedSafetyChecks(t *testing.T) {
+	overrides, err := remediator.NewNamespaceOverrides([]remediator.NamespaceOverrideConfig{
+		{Namespaces: []string{"prod"}, Interval: time.Hour, OwnerCooldown: time.Hour},
+	})
+	assert.NilError(t, err)
+	unavailableLimit, err := remediator.NewUnavailableLimit("1")
+	assert.NilError(t, err)
+	policy := remediator.Policy{
+		Cooldown:           remediator.NewCooldown(time.Hour),
+		NamespaceOverrides: overrides,
+		Backoff:            remediator.NewBackoff(time.Minute, 5, time.Hour, time.Hour),
+		UnavailableLimit:   unavailableLimit,
+		RateLimiter:        remediator.NewRateLimiter(zap.NewNop(), 3, time.Minute),
+	}
+	policy.Cooldown.TryStart("default/ReplicaSet/foo")
+	overrides.For("prod").Cooldown.TryStart("prod/ReplicaSet/bar")
+	overrides.For("prod").Interval.TryStart("prod")
+	policy.Backoff.Attempt("default/ReplicaSet/foo")
+	policy.UnavailableLimit.Record("default/ReplicaSet/foo")
+	policy.RateLimiter.Do(context.Background(), "123", func(take func() bool) bool { return take() })
+
+	state := policy.State()
+	assert.Equal(t, state.KillSwitchEngaged, false)
+	assert.DeepEqual(t, *state.RateLimiter, remediator.RateLimiterState{Tokens: 2, Queued: 0})
+	assert.Equal(t, len(state.Cooldowns), 2)
+	assert.Assert(t, !state.Cooldowns["prod/ReplicaSet/bar"].IsZero())
+	assert.Assert(t, !state.Intervals["prod"].IsZero())
+	assert.Equal(t, state.Backoffs["default/ReplicaSet/foo"].Attempts, 1)
+	assert.Assert(t, !state.Unavailable["default/ReplicaSet/foo"].IsZero())
+}
+
+func TestPolicyStateWithoutSafetyChecks(t *testing.T) {
+	state := (&remediator.Policy{}).State()
+	assert.Assert(t, state.RateLimiter == nil)
+	assert.Equal(t, len(state.Cooldowns), 0)
+	assert.Equal(t, len(state.Backoffs), 0)
+}
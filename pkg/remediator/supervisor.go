@@ -0,0 +1,80 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/k8s"
+	"github.com/aksgithub/kube_remediator/pkg/metrics"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Runs a remediator again after it panicked instead of letting it take down the process with every other
+// remediator, waiting longer the more often it panicked in a row (1s, 2s, 4s ... 5m), starting over after 10m
+// without a panic
+type Supervisor struct {
+	logger     *zap.Logger
+	remediator Remediator
+	name       string
+	metrics    *metrics.Remediation_Metrics
+	restarts   *Backoff
+}
+
+func NewSupervisor(logger *zap.Logger, remediator Remediator, metrics *metrics.Remediation_Metrics) *Supervisor {
+	return &Supervisor{
+		logger:     logger,
+		remediator: remediator,
+		name:       remediator.Name(),
+		metrics:    metrics,
+		restarts:   NewBackoff(time.Second, 2, 5*time.Minute, 10*time.Minute),
+	}
+}
+
+// Sets up the remediator and runs it supervised until ctx is done, how cmd/remediator starts each enabled one, so a
+// program embedding remediators, like an operator, builds the client and Policy itself and gets the error of a bad
+// config instead of a panic, panics while running count in policy.Metrics
+func Start(ctx context.Context, wg *sync.WaitGroup, logger *zap.Logger, remediator Remediator, client k8s.ClientInterface, policy *Policy) error {
+	if err := remediator.Setup(logger, client, policy); err != nil {
+		return err
+	}
+	wg.Add(1)
+	go NewSupervisor(logger, remediator, policy.Metrics).Run(ctx, wg)
+	return nil
+}
+
+// like Remediator.Run, until ctx is done or the remediator returned without panicking
+func (s *Supervisor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		err := s.runOnce(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		s.restarts.Attempt(s.name)
+		wait := s.restarts.Wait(s.name)
+		s.logger.Error("Restarting after panic", zap.Duration("in", wait), zap.Error(err))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// the panic of the remediator as an error, nil when it returned
+func (s *Supervisor) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.metrics.UpdatePanicCount(s.name, "run")
+			s.logger.Error("Recovered from panic", zap.String("in", "run"), zap.Any("panic", r), zap.Stack("stack"))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	var wg sync.WaitGroup // Run is done with it also when it panicked
+	wg.Add(1)
+	s.remediator.Run(ctx, &wg)
+	return nil
+}
@@ -0,0 +1,61 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/k8s/fake"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// panics the first times it runs
+type panickingRemediator struct {
+	remediator.OldPodDeleter
+	panics int
+	runs   int
+}
+
+func (r *panickingRemediator) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	r.runs++
+	if r.runs <= r.panics {
+		var pod interface{} = "not a Pod"
+		_ = pod.(int)
+	}
+}
+
+func TestRestartsRemediatorAfterPanic(t *testing.T) {
+	r := &panickingRemediator{panics: 1}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	remediator.NewSupervisor(zap.NewNop(), r, nil).Run(context.Background(), &wg)
+	wg.Wait()
+	assert.Equal(t, r.runs, 2)
+}
+
+func TestStopsRestartingWhenCancelled(t *testing.T) {
+	r := &panickingRemediator{panics: 100}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go remediator.NewSupervisor(zap.NewNop(), r, nil).Run(ctx, &wg)
+	time.Sleep(10 * time.Millisecond) // waiting to restart
+	cancel()
+	wg.Wait()
+	assert.Equal(t, r.runs, 1)
+}
+
+func TestStartsRemediatorSupervised(t *testing.T) {
+	r := &panickingRemediator{panics: 1}
+	var wg sync.WaitGroup
+	assert.NilError(t, remediator.Start(context.Background(), &wg, zap.NewNop(), r, fake.NewClient(), &remediator.Policy{}))
+	wg.Wait()
+	assert.Equal(t, r.runs, 2)
+
+	invalid := &remediator.RuleRemediator{Config: remediator.RulesConfig{Rules: []remediator.RuleConfig{{Expression: "true"}}}}
+	err := remediator.Start(context.Background(), &wg, zap.NewNop(), invalid, fake.NewClient(), &remediator.Policy{})
+	assert.ErrorContains(t, err, "rules[0]: name is required")
+}
@@ -0,0 +1,97 @@
+package remediator
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sync"
+	"time"
+)
+
+// Caps how many Pods of an owner may be down because of us at once, like maxUnavailable of a rolling update,
+// a Pod counts as down while it terminates and its replacement counts until it is Ready
+type UnavailableLimit struct {
+	max intstr.IntOrString
+
+	lock       sync.Mutex
+	remediated map[string]time.Time // owner key -> first remediation whose replacements are not all Ready yet
+}
+
+// max is a number of Pods ("2") or a percentage of the owners Pods ("25%")
+func NewUnavailableLimit(max string) (*UnavailableLimit, error) {
+	limit := &UnavailableLimit{max: intstr.Parse(max), remediated: map[string]time.Time{}}
+	if _, err := intstr.GetValueFromIntOrPercent(&limit.max, 1, false); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// whether one more Pod of the owner may be remediated, pods are all Pods in the owners namespace
+// a nil UnavailableLimit always allows
+func (l *UnavailableLimit) Allows(owner string, pods []v1.Pod) bool {
+	if l == nil || owner == "" {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	since, remediated := l.remediated[owner]
+
+	total := 0
+	unavailable := 0
+	for i := range pods {
+		pod := &pods[i]
+		if ownerKey(pod) != owner {
+			continue
+		}
+		total++
+		if pod.ObjectMeta.DeletionTimestamp != nil {
+			unavailable++
+		} else if remediated && !isPodReady(pod) && !pod.ObjectMeta.CreationTimestamp.Time.Before(since) {
+			unavailable++ // replacement is not Ready yet
+		}
+	}
+
+	if unavailable == 0 {
+		delete(l.remediated, owner) // all replacements are Ready
+	}
+
+	max, _ := intstr.GetValueFromIntOrPercent(&l.max, total, false) // validated in constructor
+	if max < 1 {
+		max = 1 // never block an owner completely
+	}
+	return unavailable < max
+}
+
+// record a remediation of the owner, so its replacement Pods are counted until they are Ready
+func (l *UnavailableLimit) Record(owner string) {
+	if l == nil || owner == "" {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, ok := l.remediated[owner]; !ok {
+		l.remediated[owner] = time.Now().Truncate(time.Second) // CreationTimestamp has second precision
+	}
+}
+
+// owners whose replacement Pods are not all Ready yet, for /debug/state
+func (l *UnavailableLimit) State() map[string]time.Time {
+	state := map[string]time.Time{}
+	if l == nil {
+		return state
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for owner, since := range l.remediated {
+		state[owner] = since
+	}
+	return state
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package remediator_test
+
+import (
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+const owner = "default/ReplicaSet/foo"
+
+func ownedPods(count int, ready bool) []corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	var pods []corev1.Pod
+	for i := 0; i < count; i++ {
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				CreationTimestamp: metav1.Time{Time: time.Now()},
+				OwnerReferences:   []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "foo"}},
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		})
+	}
+	return pods
+}
+
+func TestUnavailableLimitAllowsEverythingWhenNil(t *testing.T) {
+	var limit *remediator.UnavailableLimit
+	limit.Record(owner)
+	assert.Equal(t, limit.Allows(owner, ownedPods(1, false)), true)
+}
+
+func TestUnavailableLimitCountsTerminatingPods(t *testing.T) {
+	limit, err := remediator.NewUnavailableLimit("1")
+	assert.Equal(t, err, nil)
+	pods := ownedPods(3, true)
+	assert.Equal(t, limit.Allows(owner, pods), true)
+	pods[0].ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Equal(t, limit.Allows(owner, pods), false)
+	assert.Equal(t, limit.Allows("default/ReplicaSet/bar", pods), true)
+}
+
+func TestUnavailableLimitWaitsForReplacementsToBeReady(t *testing.T) {
+	limit, err := remediator.NewUnavailableLimit("1")
+	assert.Equal(t, err, nil)
+	crashing := ownedPods(2, false) // existed before, so do not count
+	for i := range crashing {
+		crashing[i].ObjectMeta.CreationTimestamp = metav1.Time{Time: time.Now().Add(-time.Hour)}
+	}
+	assert.Equal(t, limit.Allows(owner, crashing), true)
+
+	limit.Record(owner)
+	replacement := ownedPods(1, false)
+	assert.Equal(t, limit.Allows(owner, append(crashing, replacement...)), false)
+	replacement[0].Status.Conditions[0].Status = corev1.ConditionTrue
+	assert.Equal(t, limit.Allows(owner, append(crashing, replacement...)), true)
+}
+
+func TestUnavailableLimitUsesPercentage(t *testing.T) {
+	limit, err := remediator.NewUnavailableLimit("50%")
+	assert.Equal(t, err, nil)
+	pods := ownedPods(4, true)
+	pods[0].ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Equal(t, limit.Allows(owner, pods), true)
+	pods[1].ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Equal(t, limit.Allows(owner, pods), false)
+}
+
+func TestUnavailableLimitFailsOnInvalidValue(t *testing.T) {
+	_, err := remediator.NewUnavailableLimit("foo%")
+	assert.Assert(t, err != nil)
+}
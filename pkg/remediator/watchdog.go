@@ -0,0 +1,143 @@
+package remediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fails readiness and tells humans when a remediator made no progress for multiple times its interval, like a scan
+// that hangs on a deadlock or whose lists keep failing, the liveness probe only catches loops that stopped ticking,
+// a nil Watchdog watches nothing
+type Watchdog struct {
+	logger   *zap.Logger
+	multiple float64
+	notifier notify.Notifier
+
+	lock        sync.Mutex
+	remediators map[string]Remediator
+	stuck       map[string]bool // notified as stuck, not recovered yet
+}
+
+func NewWatchdog(logger *zap.Logger, multiple float64) *Watchdog {
+	return &Watchdog{logger: logger, multiple: multiple, remediators: map[string]Remediator{}, stuck: map[string]bool{}}
+}
+
+// tell humans when a remediator is stuck and when it recovered
+func (w *Watchdog) UseNotifier(notifier notify.Notifier) {
+	w.notifier = notifier
+}
+
+// the remediators running now, replacing those of before a reload
+func (w *Watchdog) Watch(remediators map[string]Remediator) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.remediators = make(map[string]Remediator, len(remediators))
+	for name, remediator := range remediators {
+		w.remediators[name] = remediator
+	}
+}
+
+// one problem per stuck remediator, sorted by name
+func (w *Watchdog) Stuck() []string {
+	if w == nil {
+		return nil
+	}
+	var problems []string
+	for name, problem := range w.problems() {
+		problems = append(problems, name+": "+problem)
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// the ready check, fails while a remediator is stuck
+func (w *Watchdog) Check() error {
+	if problems := w.Stuck(); len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// checks every interval until ctx is done, notifying once when a remediator got stuck and once when it recovered
+func (w *Watchdog) Run(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+	defer w.logger.Info("Stopping", zap.String("reason", "Signal"))
+	w.logger.Info("Starting")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logs and notifies what changed since the last check
+func (w *Watchdog) check() {
+	problems := w.problems()
+	w.lock.Lock()
+	var got, recovered []string
+	for name := range problems {
+		if !w.stuck[name] {
+			got = append(got, name)
+		}
+	}
+	stuck := map[string]bool{}
+	for name := range w.stuck {
+		if _, running := w.remediators[name]; running && problems[name] == "" {
+			recovered = append(recovered, name)
+		}
+	}
+	for name := range problems {
+		stuck[name] = true
+	}
+	w.stuck = stuck
+	w.lock.Unlock()
+
+	sort.Strings(got)
+	sort.Strings(recovered)
+	for _, name := range got {
+		w.logger.Error("Remediator stuck", zap.String("remediator", name), zap.String("detail", problems[name]))
+		w.notify(notify.Event{Remediator: name, Action: "stuck", Severity: notify.SeverityError, Detail: problems[name]})
+	}
+	for _, name := range recovered {
+		w.logger.Info("Remediator recovered", zap.String("remediator", name))
+		w.notify(notify.Event{Remediator: name, Action: "recovered", Severity: notify.SeverityWarning})
+	}
+}
+
+func (w *Watchdog) notify(event notify.Event) {
+	if w.notifier == nil {
+		return
+	}
+	event.Type = notify.EventWatchdog
+	event.Time = time.Now()
+	w.notifier.Notify(event)
+}
+
+// name -> problem like "no progress for 5m0s, expected every 1m0s" of the stuck remediators
+func (w *Watchdog) problems() map[string]string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	problems := map[string]string{}
+	for name, remediator := range w.remediators {
+		since, interval := remediator.Progress()
+		if interval > 0 && since > time.Duration(w.multiple*float64(interval)) {
+			problems[name] = fmt.Sprintf("no progress for %v, expected every %v", since.Round(time.Second), interval)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,78 @@
+package remediator_test
+
+import (
+	"context"
+	"github.com/aksgithub/kube_remediator/pkg/notify"
+	"github.com/aksgithub/kube_remediator/pkg/remediator"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// made no progress for since
+type stalledRemediator struct {
+	remediator.OldPodDeleter
+	lock     sync.Mutex
+	since    time.Duration
+	interval time.Duration
+}
+
+func (r *stalledRemediator) Progress() (time.Duration, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.since, r.interval
+}
+
+func (r *stalledRemediator) stall(since time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.since = since
+}
+
+func TestWatchdogFailsReadinessWhileStuck(t *testing.T) {
+	stalled := &stalledRemediator{since: 2 * time.Minute, interval: time.Minute}
+	reacting := &stalledRemediator{since: time.Hour} // no scan loop
+	watchdog := remediator.NewWatchdog(zap.NewNop(), 3)
+	watchdog.Watch(map[string]remediator.Remediator{"OldPodDeleter": stalled, "CrashLoopBackOffRescheduler": reacting})
+	assert.NilError(t, watchdog.Check())
+
+	stalled.stall(10 * time.Minute)
+	assert.Error(t, watchdog.Check(), "OldPodDeleter: no progress for 10m0s, expected every 1m0s")
+
+	watchdog.Watch(map[string]remediator.Remediator{"CrashLoopBackOffRescheduler": reacting}) // reloaded without it
+	assert.NilError(t, watchdog.Check())
+
+	var none *remediator.Watchdog
+	none.Watch(map[string]remediator.Remediator{"OldPodDeleter": stalled})
+	assert.NilError(t, none.Check())
+}
+
+func TestWatchdogNotifiesWhenStuckAndRecovered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	stalled := &stalledRemediator{since: 10 * time.Minute, interval: time.Minute}
+	notifier := make(channelNotifier, 2)
+	watchdog := remediator.NewWatchdog(zap.NewNop(), 3)
+	watchdog.UseNotifier(notifier)
+	watchdog.Watch(map[string]remediator.Remediator{"OldPodDeleter": stalled})
+	wg.Add(1)
+	go watchdog.Run(ctx, &wg, time.Millisecond)
+
+	stuck := <-notifier
+	assert.Equal(t, stuck.Type, notify.EventWatchdog)
+	assert.Equal(t, stuck.Remediator, "OldPodDeleter")
+	assert.Equal(t, stuck.Action, "stuck")
+	assert.Equal(t, stuck.Detail, "no progress for 10m0s, expected every 1m0s")
+	assert.Equal(t, notify.Summary(stuck), "kube-remediator OldPodDeleter stuck: no progress for 10m0s, expected every 1m0s")
+
+	stalled.stall(0)
+	recovered := <-notifier
+	assert.Equal(t, recovered.Action, "recovered")
+	assert.Equal(t, notify.Severity(recovered), notify.SeverityWarning)
+	assert.Equal(t, len(notifier), 0) // once each
+}
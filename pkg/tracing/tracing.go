@@ -0,0 +1,243 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// span kinds of OTLP
+const (
+	KindInternal = 1
+	KindClient   = 3 // calls to the api-server
+)
+
+const maxBufferedSpans = 2048
+
+// Records spans of scans and remediations and exports them in batches to an OTLP/HTTP endpoint,
+// JSON encoded so no OpenTelemetry SDK is needed, a nil Tracer records nothing
+type Tracer struct {
+	logger      *zap.Logger
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+
+	lock  sync.Mutex
+	spans []*Span
+}
+
+// part of a trace, a nil Span ignores everything
+type Span struct {
+	tracer     *Tracer
+	traceID    string
+	spanID     string
+	parentID   string
+	name       string
+	kind       int
+	start      time.Time
+	end        time.Time
+	attributes map[string]string
+	err        string
+	lock       sync.Mutex
+}
+
+type spanKey struct{}
+
+func NewTracer(logger *zap.Logger, endpoint string, headers map[string]string, serviceName string) *Tracer {
+	return &Tracer{
+		logger:      logger,
+		endpoint:    endpoint,
+		headers:     headers,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// starts a span, a child of the span in ctx if there is one
+func (t *Tracer) Start(ctx context.Context, name string, kind int, attributes map[string]string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, spanID: randomID(8), name: name, kind: kind, start: time.Now(), attributes: map[string]string{}}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID, span.parentID = parent.traceID, parent.spanID
+	} else {
+		span.traceID = randomID(16)
+	}
+	for key, value := range attributes {
+		span.attributes[key] = value
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attributes[key] = value
+}
+
+// marks the span as failed, nil errors are ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err.Error()
+}
+
+// finished spans are exported with the next batch
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	s.end = time.Now()
+	s.lock.Unlock()
+	s.tracer.add(s)
+}
+
+func (t *Tracer) add(span *Span) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.spans) >= maxBufferedSpans {
+		t.logger.Warn("Dropping span, too many waiting for export", zap.String("span", span.name))
+		return
+	}
+	t.spans = append(t.spans, span)
+}
+
+// exports every interval and once more when stopped
+func (t *Tracer) Run(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+	defer t.logger.Info("Stopping", zap.String("reason", "Signal"))
+	t.logger.Info("Starting", zap.String("endpoint", t.endpoint))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			t.logExportError(t.Export())
+			return
+		}
+		t.logExportError(t.Export())
+	}
+}
+
+// sends all finished spans, they are dropped when the endpoint fails
+func (t *Tracer) Export() error {
+	t.lock.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.lock.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return err // untested section
+	}
+	request, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range t.headers {
+		request.Header.Set(name, value)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := t.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("collector responded with %s", response.Status)
+	}
+	return nil
+}
+
+func (t *Tracer) logExportError(err error) {
+	if err != nil {
+		t.logger.Warn("Error exporting spans", zap.Error(err))
+	}
+}
+
+// ExportTraceServiceRequest of https://github.com/open-telemetry/opentelemetry-proto in its JSON encoding
+func (t *Tracer) request(spans []*Span) map[string]interface{} {
+	var otlpSpans []map[string]interface{}
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, span.otlp())
+	}
+	return map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": attributes(map[string]string{"service.name": t.serviceName}),
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]string{"name": "kube-remediator"},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+}
+
+func (s *Span) otlp() map[string]interface{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	span := map[string]interface{}{
+		"traceId":           s.traceID,
+		"spanId":            s.spanID,
+		"name":              s.name,
+		"kind":              s.kind,
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+		"attributes":        attributes(s.attributes),
+	}
+	if s.parentID != "" {
+		span["parentSpanId"] = s.parentID
+	}
+	if s.err != "" {
+		span["status"] = map[string]interface{}{"code": 2, "message": s.err} // STATUS_CODE_ERROR
+	}
+	return span
+}
+
+func attributes(values map[string]string) []map[string]interface{} {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var otlp []map[string]interface{}
+	for _, key := range keys {
+		otlp = append(otlp, map[string]interface{}{"key": key, "value": map[string]string{"stringValue": values[key]}})
+	}
+	return otlp
+}
+
+func randomID(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,157 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/aksgithub/kube_remediator/pkg/tracing"
+	"go.uber.org/zap"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type otlpSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Start        string `json:"startTimeUnixNano"`
+	End          string `json:"endTimeUnixNano"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []struct {
+				Key   string `json:"key"`
+				Value struct {
+					StringValue string `json:"stringValue"`
+				} `json:"value"`
+			} `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func collector(t *testing.T, status int, requests chan<- otlpRequest, headers chan<- http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NilError(t, err)
+		var request otlpRequest
+		assert.NilError(t, json.Unmarshal(body, &request))
+		requests <- request
+		headers <- r.Header
+		w.WriteHeader(status)
+	}))
+}
+
+func TestExportsFinishedSpans(t *testing.T) {
+	requests, headers := make(chan otlpRequest, 1), make(chan http.Header, 1)
+	server := collector(t, http.StatusOK, requests, headers)
+	defer server.Close()
+	tracer := tracing.NewTracer(zap.NewNop(), server.URL, map[string]string{"Authorization": "Bearer foo"}, "remediator")
+
+	ctx, scan := tracer.Start(context.Background(), "scan", tracing.KindInternal, map[string]string{"remediator": "OldPodDeleter"})
+	_, call := tracer.Start(ctx, "delete Pod", tracing.KindClient, nil)
+	call.SetAttribute("pod", "foo")
+	call.SetError(errors.New("forbidden"))
+	call.End()
+	_, unfinished := tracer.Start(ctx, "evict Pod", tracing.KindClient, nil)
+	scan.End()
+	assert.NilError(t, tracer.Export())
+
+	request := <-requests
+	assert.Equal(t, (<-headers).Get("Authorization"), "Bearer foo")
+	assert.Equal(t, request.ResourceSpans[0].Resource.Attributes[0].Key, "service.name")
+	assert.Equal(t, request.ResourceSpans[0].Resource.Attributes[0].Value.StringValue, "remediator")
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, len(spans), 2)
+
+	child, parent := spans[0], spans[1]
+	assert.Equal(t, parent.Name, "scan")
+	assert.Equal(t, parent.Kind, tracing.KindInternal)
+	assert.Equal(t, len(parent.TraceID), 32)
+	assert.Equal(t, len(parent.SpanID), 16)
+	assert.Equal(t, parent.ParentSpanID, "")
+	assert.Assert(t, parent.Status == nil)
+	assert.Equal(t, parent.Attributes[0].Key, "remediator")
+	assert.Equal(t, parent.Attributes[0].Value.StringValue, "OldPodDeleter")
+	assert.Assert(t, parent.Start <= parent.End)
+
+	assert.Equal(t, child.Name, "delete Pod")
+	assert.Equal(t, child.Kind, tracing.KindClient)
+	assert.Equal(t, child.TraceID, parent.TraceID)
+	assert.Equal(t, child.ParentSpanID, parent.SpanID)
+	assert.Equal(t, child.Status.Code, 2)
+	assert.Equal(t, child.Status.Message, "forbidden")
+	assert.Equal(t, child.Attributes[0].Key, "pod")
+
+	unfinished.End()
+	assert.NilError(t, tracer.Export())
+	assert.Equal(t, (<-requests).ResourceSpans[0].ScopeSpans[0].Spans[0].Name, "evict Pod")
+}
+
+func TestDoesNotExportWithoutSpans(t *testing.T) {
+	requests, headers := make(chan otlpRequest, 1), make(chan http.Header, 1)
+	server := collector(t, http.StatusOK, requests, headers)
+	defer server.Close()
+
+	assert.NilError(t, tracing.NewTracer(zap.NewNop(), server.URL, nil, "remediator").Export())
+	assert.Equal(t, len(requests), 0)
+}
+
+func TestFailsWhenCollectorFails(t *testing.T) {
+	requests, headers := make(chan otlpRequest, 1), make(chan http.Header, 1)
+	server := collector(t, http.StatusServiceUnavailable, requests, headers)
+	defer server.Close()
+	tracer := tracing.NewTracer(zap.NewNop(), server.URL, nil, "remediator")
+
+	_, span := tracer.Start(context.Background(), "scan", tracing.KindInternal, nil)
+	span.End()
+	assert.ErrorContains(t, tracer.Export(), "503")
+}
+
+func TestExportsWhenStopped(t *testing.T) {
+	requests, headers := make(chan otlpRequest, 1), make(chan http.Header, 1)
+	server := collector(t, http.StatusOK, requests, headers)
+	defer server.Close()
+	tracer := tracing.NewTracer(zap.NewNop(), server.URL, nil, "remediator")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go tracer.Run(ctx, &wg, time.Hour)
+	_, span := tracer.Start(context.Background(), "scan", tracing.KindInternal, nil)
+	span.End()
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, (<-requests).ResourceSpans[0].ScopeSpans[0].Spans[0].Name, "scan")
+}
+
+func TestNilTracerRecordsNothing(t *testing.T) {
+	var tracer *tracing.Tracer
+	ctx, span := tracer.Start(context.Background(), "scan", tracing.KindInternal, nil)
+	assert.Assert(t, tracing.SpanFromContext(ctx) == nil)
+	span.SetAttribute("result", "success")
+	span.SetError(errors.New("failed"))
+	span.End()
+}